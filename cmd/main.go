@@ -24,6 +24,7 @@ import (
 	"github.com/konveyor/tackle2-hub/internal/migration"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/internal/reaper"
+	"github.com/konveyor/tackle2-hub/internal/secret"
 	"github.com/konveyor/tackle2-hub/internal/seed"
 	"github.com/konveyor/tackle2-hub/internal/task"
 	"github.com/konveyor/tackle2-hub/internal/tracker"
@@ -175,6 +176,14 @@ func main() {
 	trackerManager := tracker.Manager{
 		DB: db,
 	}
+	secretRotator := &secret.Rotator{
+		DB:     db,
+		Models: model.ALL,
+	}
+	err = secretRotator.Load()
+	if err != nil {
+		return
+	}
 	//
 	// Metrics
 	if Settings.Metrics.Enabled {
@@ -190,11 +199,17 @@ func main() {
 	}
 	// Web
 	router := gin.Default()
+	err = router.SetTrustedProxies(Settings.API.TrustedProxies)
+	if err != nil {
+		return
+	}
 	router.Use(
 		func(ctx *gin.Context) {
 			rtx := api.RichContext(ctx)
 			rtx.TaskManager = taskManager
-			rtx.DB = db
+			rtx.ImportManager = &importManager
+			rtx.SecretRotator = secretRotator
+			rtx.DB = api.BatchDB(ctx, db)
 			rtx.Client = client
 			defer rtx.Detach()
 			ctx.Next()
@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	qf "github.com/konveyor/tackle2-hub/internal/api/filter"
 	"github.com/konveyor/tackle2-hub/internal/api/resource"
+	"github.com/konveyor/tackle2-hub/internal/assessment"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/shared/api"
 	"github.com/konveyor/tackle2-hub/shared/tar"
@@ -65,7 +66,7 @@ func (h AnalysisHandler) AddRoutes(e *gin.Engine) {
 	// Application
 	routeGroup = e.Group("/")
 	routeGroup.Use(Required("applications.analyses"))
-	routeGroup.POST(api.AppAnalysesRoute, Transaction, h.AppCreate)
+	routeGroup.POST(api.AppAnalysesRoute, Idempotent, Transaction, h.AppCreate)
 	routeGroup.GET(api.AppAnalysesRoute, h.AppList)
 	routeGroup.GET(api.AppAnalysisRoute, h.AppLatest)
 	routeGroup.GET(api.AppAnalysisReportRoute, h.AppLatestReport)
@@ -100,6 +101,7 @@ func (h AnalysisHandler) Get(ctx *gin.Context) {
 // @summary List analyses.
 // @description List analyses.
 // @description Resources do not include relations.
+// @description Restricted to the applications permitted by constrained scopes.
 // @tags analyses
 // @produce json
 // @success 200 {object} []api.Analysis
@@ -128,6 +130,8 @@ func (h AnalysisHandler) List(ctx *gin.Context) {
 	db = db.Joins("Application")
 	db = db.Omit("Summary")
 	db = filter.Where(db)
+	constraint := AppConstraint{ctx: ctx}
+	db = constraint.Where(db, "Analysis.ApplicationID")
 	db = sort.Sorted(db)
 	var list []model.Analysis
 	var m model.Analysis
@@ -305,11 +309,17 @@ func (h AnalysisHandler) AppList(ctx *gin.Context) {
 // @description The encoding must be:
 // @description - application/json
 // @description - application/x-yaml
+// @description Supports the Idempotency-Key header. A retry with the same key
+// @description replays the original response.
+// @description The (auto-answer) conditions of the application assessments are
+// @description re-evaluated using the analysis. Assessments inherited from archetypes
+// @description are not. Auto-answer failures are logged and do not fail the upload.
 // @tags analyses
 // @produce json
 // @success 201 {object} api.Analysis
 // @router /application/{id}/analyses [post]
 // @param id path int true "Application ID"
+// @param Idempotency-Key header string false "Idempotency key"
 func (h AnalysisHandler) AppCreate(ctx *gin.Context) {
 	id := h.pk(ctx)
 	result := h.DB(ctx).First(&model.Application{}, id)
@@ -470,6 +480,9 @@ func (h AnalysisHandler) AppCreate(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	//
+	// Auto-answer assessments.
+	h.autoAnswer(ctx, id)
 
 	db = h.DB(ctx)
 	db = db.Preload("Application")
@@ -599,6 +612,7 @@ func (h AnalysisHandler) AppDeps(ctx *gin.Context) {
 // @description - category
 // @description - effort
 // @description - labels
+// @description - facts.(path)
 // @tags insights
 // @produce json
 // @success 200 {object} []api.Insight
@@ -623,6 +637,7 @@ func (h AnalysisHandler) AppInsights(ctx *gin.Context) {
 			{Field: "category", Kind: qf.STRING},
 			{Field: "effort", Kind: qf.LITERAL},
 			{Field: "labels", Kind: qf.STRING, And: true},
+			{Field: "facts", Kind: qf.JSON},
 		})
 	if err != nil {
 		_ = ctx.Error(err)
@@ -657,6 +672,7 @@ func (h AnalysisHandler) AppInsights(ctx *gin.Context) {
 // @description - category
 // @description - effort
 // @description - labels
+// @description - facts.(path)
 // @description - application.id
 // @description - application.name
 // @description - tag.id
@@ -677,6 +693,7 @@ func (h AnalysisHandler) Insights(ctx *gin.Context) {
 			{Field: "application.id", Kind: qf.LITERAL},
 			{Field: "application.name", Kind: qf.STRING},
 			{Field: "tag.id", Kind: qf.LITERAL, And: true},
+			{Field: "facts", Kind: qf.JSON},
 		})
 	if err != nil {
 		_ = ctx.Error(err)
@@ -711,6 +728,7 @@ func (h AnalysisHandler) Insights(ctx *gin.Context) {
 // @description - category
 // @description - effort
 // @description - labels
+// @description - facts.(path)
 // @tags insights
 // @produce json
 // @success 200 {object} []api.Insight
@@ -726,6 +744,7 @@ func (h AnalysisHandler) AnalysisInsights(ctx *gin.Context) {
 			{Field: "category", Kind: qf.STRING},
 			{Field: "effort", Kind: qf.LITERAL},
 			{Field: "labels", Kind: qf.STRING, And: true},
+			{Field: "facts", Kind: qf.JSON},
 		})
 	if err != nil {
 		_ = ctx.Error(err)
@@ -2034,6 +2053,49 @@ func (h *AnalysisHandler) archiveByApp(ctx *gin.Context) (err error) {
 	return
 }
 
+// autoAnswer re-evaluates the (auto-answer) conditions of the
+// application assessments using the latest analysis. Assessments
+// inherited from archetypes are shared by the members and are not
+// auto-answered. Failures are logged and do not fail the analysis.
+func (h *AnalysisHandler) autoAnswer(ctx *gin.Context, id uint) {
+	var list []model.Assessment
+	db := h.DB(ctx)
+	db = db.Where("ApplicationID", id)
+	err := db.Find(&list).Error
+	if err != nil {
+		Log.Error(err, "Auto-answer failed.", "application", id)
+		return
+	}
+	if len(list) == 0 {
+		return
+	}
+	subject := &assessment.Subject{}
+	err = subject.Load(h.DB(ctx), id)
+	if err != nil {
+		Log.Error(err, "Auto-answer failed.", "application", id)
+		return
+	}
+	for i := range list {
+		m := &list[i]
+		a := assessment.Assessment{}
+		a.With(m)
+		changed, err := a.AutoAnswer(subject)
+		if err != nil {
+			Log.Error(err, "Auto-answer failed.", "application", id, "assessment", m.ID)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		db := h.DB(ctx).Model(m)
+		db = db.Select("Sections")
+		err = db.Updates(m).Error
+		if err != nil {
+			Log.Error(err, "Auto-answer failed.", "application", id, "assessment", m.ID)
+		}
+	}
+}
+
 // archive
 // - Set the 'archived' flag.
 // - Set the 'summary' field with archived insights.
@@ -2137,15 +2199,22 @@ type InsightWriter struct {
 // Create an insights file and returns the path.
 func (r *InsightWriter) Create(id uint, filter qf.Filter) (path string, count int64, err error) {
 	ext := ".json"
-	accepted := r.ctx.NegotiateFormat(BindMIMEs...)
+	accepted := r.ctx.NegotiateFormat(RenderMIMEs...)
 	switch accepted {
 	case "",
 		binding.MIMEPOSTForm,
 		binding.MIMEJSON:
 	case binding.MIMEYAML:
 		ext = ".yaml"
+	case MIMECSV:
+		ext = ".csv"
+		Renderer{}.tabular(r.ctx)
+	case MIMEXLSX:
+		ext = ".xlsx"
+		Renderer{}.tabular(r.ctx)
 	default:
 		err = &BadRequestError{Reason: "MIME not supported."}
+		return
 	}
 	file, err := os.CreateTemp("", "insight-*"+ext)
 	if err != nil {
@@ -2179,6 +2248,7 @@ func (r *InsightWriter) Write(id uint, filter qf.Filter, output io.Writer) (coun
 	if err != nil {
 		return
 	}
+	facts := filter.Resource("facts")
 	r.beginList()
 	batch := 10
 	for b := page.Offset; ; b += batch {
@@ -2187,6 +2257,7 @@ func (r *InsightWriter) Write(id uint, filter qf.Filter, output io.Writer) (coun
 			db = db.Where("AnalysisID", id)
 		}
 		db = filter.Where(db)
+		db = facts.JsonWhere(db, "Facts")
 		db = db.Preload("Incidents")
 		db = db.Limit(batch)
 		db = db.Offset(b)
@@ -2236,6 +2307,7 @@ func (r *AnalysisWriter) Create(id uint) (path string, err error) {
 		ext = ".yaml"
 	default:
 		err = &BadRequestError{Reason: "MIME not supported."}
+		return
 	}
 	file, err := os.CreateTemp("", "report-*"+ext)
 	if err != nil {
@@ -1,14 +1,27 @@
 package api
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	qf "github.com/konveyor/tackle2-hub/internal/api/filter"
+	"github.com/konveyor/tackle2-hub/internal/auth"
+	"github.com/konveyor/tackle2-hub/internal/database"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"github.com/konveyor/tackle2-hub/shared/settings"
 	"github.com/onsi/gomega"
 	"gopkg.in/yaml.v2"
 )
@@ -148,6 +161,106 @@ func TestEncoder(t *testing.T) {
 	}
 }
 
+func TestTabularEncoder(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	apps := []Application{
+		{
+			Name:         "a,1",
+			Owner:        &Ref{ID: 2, Name: "elmer"},
+			Contributors: []Ref{{ID: 3, Name: "bugs"}, {ID: 4, Name: "daffy"}},
+			Effort:       10,
+		},
+		{
+			Name: "b<2>",
+		},
+	}
+	apps[0].ID = 1
+	apps[1].ID = 2
+
+	// columns
+	table := Table{}
+	row, err := table.Row(apps[0])
+	g.Expect(err).To(gomega.BeNil())
+	index := func(name string) (n int) {
+		n = -1
+		for i := range table.Columns {
+			if table.Columns[i] == name {
+				n = i
+				break
+			}
+		}
+		return
+	}
+	g.Expect(index("id")).ToNot(gomega.Equal(-1))
+	g.Expect(index("owner.id")).ToNot(gomega.Equal(-1))
+	g.Expect(index("owner.name")).ToNot(gomega.Equal(-1))
+	g.Expect(index("contributors.name")).ToNot(gomega.Equal(-1))
+	g.Expect(index("repository.url")).ToNot(gomega.Equal(-1))
+	g.Expect(index("owner")).To(gomega.Equal(-1))
+	g.Expect(row[index("id")]).To(gomega.Equal(json.Number("1")))
+	g.Expect(row[index("owner.name")]).To(gomega.Equal("elmer"))
+	g.Expect(row[index("contributors.name")]).To(gomega.Equal("bugs, daffy"))
+	row, err = table.Row(apps[1])
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(row[index("owner.name")]).To(gomega.Equal(""))
+
+	// csv
+	b := &bytes.Buffer{}
+	en := &csvEncoder{output: b}
+	en.beginList()
+	for i := range apps {
+		en.writeItem(0, i, apps[i])
+	}
+	en.endList()
+	g.Expect(en.error()).To(gomega.BeNil())
+	records, err := csv.NewReader(b).ReadAll()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(records)).To(gomega.Equal(3))
+	g.Expect(records[0]).To(gomega.Equal(table.Columns))
+	g.Expect(records[1][index("name")]).To(gomega.Equal("a,1"))
+	g.Expect(records[2][index("name")]).To(gomega.Equal("b<2>"))
+
+	// xlsx
+	b = &bytes.Buffer{}
+	xen := &xlsxEncoder{output: b}
+	xen.beginList()
+	for i := range apps {
+		xen.writeItem(0, i, apps[i])
+	}
+	xen.endList()
+	g.Expect(xen.error()).To(gomega.BeNil())
+	reader, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	g.Expect(err).To(gomega.BeNil())
+	var names []string
+	var sheet string
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			g.Expect(err).To(gomega.BeNil())
+			content, err := io.ReadAll(rc)
+			g.Expect(err).To(gomega.BeNil())
+			sheet = string(content)
+		}
+	}
+	g.Expect(names).To(gomega.ContainElements(
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels",
+		"xl/worksheets/sheet1.xml"))
+	g.Expect(sheet).To(gomega.ContainSubstring(`<row r="3">`))
+	g.Expect(sheet).To(gomega.ContainSubstring(`<c><v>1</v></c>`))
+	g.Expect(sheet).To(gomega.ContainSubstring(`b&lt;2&gt;`))
+	g.Expect(sheet).To(gomega.HaveSuffix(`</sheetData></worksheet>`))
+
+	// not a list.
+	en = &csvEncoder{output: &bytes.Buffer{}}
+	en.begin()
+	g.Expect(en.error()).ToNot(gomega.BeNil())
+}
+
 func TestEncoderError(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
 
@@ -330,3 +443,760 @@ func TestRequiredMiddleware(t *testing.T) {
 	// Should still be aborted
 	g.Expect(ctx.IsAborted()).To(gomega.BeTrue())
 }
+
+func TestIdempotencyDigest(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	digest := func(contentType string, body []byte) (d string) {
+		ctx := &gin.Context{
+			Request: httptest.NewRequest(
+				http.MethodPost,
+				"/applications",
+				bytes.NewReader(body)),
+		}
+		ctx.Request.Header.Set(ContentType, contentType)
+		d, err := idempotencyDigest(ctx)
+		g.Expect(err).To(gomega.BeNil())
+		// body restored.
+		b, err := io.ReadAll(ctx.Request.Body)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(b).To(gomega.Equal(body))
+		_ = ctx.Request.Body.Close()
+		return
+	}
+	// json
+	a := digest("application/json", []byte(`{"name":"a"}`))
+	b := digest("application/json", []byte(`{"name":"a"}`))
+	c := digest("application/json", []byte(`{"name":"b"}`))
+	g.Expect(a).To(gomega.Equal(b))
+	g.Expect(a).ToNot(gomega.Equal(c))
+	// multipart: boundary ignored.
+	form := func(content string) (contentType string, body []byte) {
+		bfr := &bytes.Buffer{}
+		writer := multipart.NewWriter(bfr)
+		part, _ := writer.CreateFormFile(FileField, "manifest")
+		_, _ = part.Write([]byte(content))
+		_ = writer.Close()
+		contentType = writer.FormDataContentType()
+		body = bfr.Bytes()
+		return
+	}
+	a = digest(form("hello"))
+	b = digest(form("hello"))
+	c = digest(form("world"))
+	g.Expect(a).To(gomega.Equal(b))
+	g.Expect(a).ToNot(gomega.Equal(c))
+}
+
+func TestIdempotent(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	db, err := database.OpenTest()
+	g.Expect(err).To(gomega.BeNil())
+	sqlDB, err := db.DB()
+	g.Expect(err).To(gomega.BeNil())
+	sqlDB.SetMaxOpenConns(1)
+	err = db.AutoMigrate(&model.Idempotency{})
+	g.Expect(err).To(gomega.BeNil())
+	saved := Settings.Hub.Idempotency.TTL
+	t.Cleanup(func() {
+		Settings.Hub.Idempotency.TTL = saved
+	})
+	Settings.Hub.Idempotency.TTL = time.Hour
+
+	created := 0
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Render())
+	router.Use(ErrorHandler())
+	router.Use(func(ctx *gin.Context) {
+		rtx := RichContext(ctx)
+		rtx.DB = db
+		rtx.Subject = "test"
+	})
+	router.POST(
+		"/things",
+		Idempotent,
+		Transaction,
+		func(ctx *gin.Context) {
+			b, _ := io.ReadAll(ctx.Request.Body)
+			if string(b) == "fail" {
+				_ = ctx.Error(&BadRequestError{Reason: "failed"})
+				return
+			}
+			created++
+			rtx := RichContext(ctx)
+			rtx.Respond(http.StatusCreated, api.Map{"n": created})
+		})
+	post := func(key, body string) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		request := httptest.NewRequest(
+			http.MethodPost,
+			"/things",
+			bytes.NewReader([]byte(body)))
+		request.Header.Set(ContentType, api.MIMEJSON)
+		request.Header.Set(IdempotencyKey, key)
+		router.ServeHTTP(w, request)
+		return
+	}
+
+	// replayed.
+	w := post("A", "a")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	g.Expect(w.Header().Get(Replayed)).To(gomega.BeEmpty())
+	w = post("A", "a")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	g.Expect(w.Header().Get(Replayed)).To(gomega.Equal("true"))
+	g.Expect(w.Body.String()).To(gomega.ContainSubstring(`"n":1`))
+	g.Expect(created).To(gomega.Equal(1))
+	// reused with a different request.
+	w = post("A", "b")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusUnprocessableEntity))
+	g.Expect(created).To(gomega.Equal(1))
+	// failed request not recorded.
+	w = post("B", "fail")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest))
+	n := int64(0)
+	err = db.Model(&model.Idempotency{}).Where("Key", "B").Count(&n).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(n).To(gomega.Equal(int64(0)))
+	// in progress.
+	ctx := &gin.Context{
+		Request: httptest.NewRequest(
+			http.MethodPost,
+			"/things",
+			bytes.NewReader([]byte("c"))),
+	}
+	ctx.Request.Header.Set(ContentType, api.MIMEJSON)
+	digest, err := idempotencyDigest(ctx)
+	g.Expect(err).To(gomega.BeNil())
+	_ = ctx.Request.Body.Close()
+	err = db.Create(
+		&model.Idempotency{
+			Key:        "C",
+			Subject:    "test",
+			Method:     http.MethodPost,
+			Path:       "/things",
+			Digest:     digest,
+			Expiration: time.Now().Add(time.Hour),
+		}).Error
+	g.Expect(err).To(gomega.BeNil())
+	w = post("C", "c")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusConflict))
+	g.Expect(created).To(gomega.Equal(1))
+}
+
+func TestAfterCommit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	db, err := database.OpenTest()
+	g.Expect(err).To(gomega.BeNil())
+	sqlDB, err := db.DB()
+	g.Expect(err).To(gomega.BeNil())
+	sqlDB.SetMaxOpenConns(1)
+
+	committed := 0
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Render())
+	router.Use(ErrorHandler())
+	router.Use(func(ctx *gin.Context) {
+		rtx := RichContext(ctx)
+		rtx.DB = db
+	})
+	router.POST(
+		"/things",
+		Transaction,
+		func(ctx *gin.Context) {
+			rtx := RichContext(ctx)
+			rtx.AfterCommit(func() {
+				committed++
+			})
+			g.Expect(committed).To(gomega.Equal(0))
+			b, _ := io.ReadAll(ctx.Request.Body)
+			if string(b) == "fail" {
+				_ = ctx.Error(&BadRequestError{Reason: "failed"})
+				return
+			}
+			rtx.Status(http.StatusCreated)
+		})
+	post := func(body string) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		request := httptest.NewRequest(
+			http.MethodPost,
+			"/things",
+			bytes.NewReader([]byte(body)))
+		router.ServeHTTP(w, request)
+		return
+	}
+	// committed.
+	w := post("a")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	g.Expect(committed).To(gomega.Equal(1))
+	// rolled back.
+	committed = 0
+	w = post("fail")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest))
+	g.Expect(committed).To(gomega.Equal(0))
+	// not in a transaction.
+	rtx := &Context{}
+	rtx.AfterCommit(func() {
+		committed++
+	})
+	g.Expect(committed).To(gomega.Equal(1))
+}
+
+func TestImportTable(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// csv
+	table := ImportTable{}
+	err := table.Read(
+		api.ImportCSV,
+		[]byte("Name,CMDB ID,Language\n\nelmer, 10,\"java,go\"\n"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(table.Header).To(gomega.Equal([]string{"Name", "CMDB ID", "Language"}))
+	g.Expect(len(table.Rows)).To(gomega.Equal(1))
+	g.Expect(table.Rows[0].Number).To(gomega.Equal(3))
+	g.Expect(table.Rows[0].Cells).To(gomega.Equal([]string{"elmer", "10", "java,go"}))
+
+	// json
+	table = ImportTable{}
+	err = table.Read(
+		api.ImportJSON,
+		[]byte(`[{"applicationName":"elmer","fact:id":10},{"applicationName":"bugs"}]`))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(table.Header).To(gomega.Equal([]string{"applicationName", "fact:id"}))
+	g.Expect(len(table.Rows)).To(gomega.Equal(2))
+	g.Expect(table.Rows[0].Cells).To(gomega.Equal([]string{"elmer", "10"}))
+	g.Expect(table.Rows[1].Cells).To(gomega.Equal([]string{"bugs", ""}))
+
+	// yaml
+	table = ImportTable{}
+	err = table.Read(
+		api.ImportYAML,
+		[]byte("- applicationName: elmer\n  contributors:\n  - a\n  - b\n"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(table.Header).To(gomega.Equal([]string{"applicationName", "contributors"}))
+	g.Expect(table.Rows[0].Cells).To(gomega.Equal([]string{"elmer", "a,b"}))
+
+	// not a list.
+	table = ImportTable{}
+	err = table.Read(api.ImportJSON, []byte(`{"applicationName":"elmer"}`))
+	g.Expect(errors.Is(err, &BadRequestError{})).To(gomega.BeTrue())
+
+	// xlsx
+	apps := []Application{
+		{Name: "elmer"},
+		{Name: "bugs"},
+	}
+	b := &bytes.Buffer{}
+	en := &xlsxEncoder{output: b}
+	en.beginList()
+	for i := range apps {
+		en.writeItem(0, i, apps[i])
+	}
+	en.endList()
+	g.Expect(en.error()).To(gomega.BeNil())
+	table = ImportTable{}
+	err = table.Read(api.ImportXLSX, b.Bytes())
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(table.Header).To(gomega.Equal(en.table.Columns))
+	g.Expect(len(table.Rows)).To(gomega.Equal(2))
+	g.Expect(table.Rows[1].Number).To(gomega.Equal(3))
+	name := -1
+	for i := range table.Header {
+		if table.Header[i] == "name" {
+			name = i
+		}
+	}
+	g.Expect(table.Rows[1].Cells[name]).To(gomega.Equal("bugs"))
+	g.Expect(table.column("AB12")).To(gomega.Equal(27))
+
+	// format.
+	format, err := ImportFormat("", "apps.YML")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(format).To(gomega.Equal(api.ImportYAML))
+	format, err = ImportFormat("", "apps")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(format).To(gomega.Equal(api.ImportCSV))
+	_, err = ImportFormat("xml", "apps.xml")
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestImportMapping(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	mapping := ImportMapping{
+		Profile: &model.ImportProfile{
+			Columns: []model.ImportColumn{
+				{Name: "Name", Field: "applicationName"},
+				{Name: "CMDB ID", Field: "fact:cmdb.id"},
+				{Name: "Language", Field: "tag:Language"},
+				{Name: "Missing", Field: "owner"},
+			},
+		},
+	}
+	g.Expect(mapping.Validate()).To(gomega.BeNil())
+	imp := mapping.Import(
+		[]string{"name", "CMDB ID", "Language"},
+		[]string{"elmer", "10", "java, go"})
+	g.Expect(imp.RecordType1).To(gomega.Equal(RecordTypeApplication))
+	g.Expect(imp.ApplicationName).To(gomega.Equal("elmer"))
+	g.Expect(imp.Facts).To(gomega.Equal(map[string]string{"cmdb.id": "10"}))
+	g.Expect(imp.ImportTags).To(gomega.Equal([]model.ImportTag{
+		{Category: "Language", Name: "java"},
+		{Category: "Language", Name: "go"},
+	}))
+	g.Expect(imp.Owner).To(gomega.Equal(""))
+
+	// invalid.
+	mapping.Profile.Columns = append(
+		mapping.Profile.Columns,
+		model.ImportColumn{Name: "x", Field: "tag:"})
+	g.Expect(mapping.Validate()).ToNot(gomega.BeNil())
+	mapping = ImportMapping{}
+	g.Expect(mapping.Identity([]string{"applicationName", "unknown"})).ToNot(gomega.BeNil())
+}
+
+func TestRanking(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	values := []Ranked{
+		{ID: 1, Name: "a", Risk: "green", Score: 90},
+		{ID: 2, Name: "b", Risk: "red", Score: 10},
+		{ID: 3, Name: "c", Risk: "yellow", Score: 60},
+		{ID: 4, Name: "d", Risk: "green", Score: 80},
+	}
+	rank := func(query string, page Page) (index []int) {
+		ctx := &gin.Context{
+			Request: httptest.NewRequest(
+				http.MethodGet,
+				"/applications?"+query,
+				nil),
+		}
+		filter, err := qf.New(ctx,
+			[]qf.Assert{
+				{Field: "risk", Kind: qf.STRING},
+				{Field: "score", Kind: qf.LITERAL},
+			})
+		g.Expect(err).To(gomega.BeNil())
+		ranking := Ranking{}
+		err = ranking.With(ctx, &filter)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(filter.Empty()).To(gomega.BeTrue())
+		index, total := ranking.Apply(values, page)
+		g.Expect(total >= len(index)).To(gomega.BeTrue())
+		return
+	}
+	g.Expect(rank("sort=desc:score", Page{})).To(gomega.Equal([]int{0, 3, 2, 1}))
+	g.Expect(rank("sort=risk,name", Page{})).To(gomega.Equal([]int{0, 3, 2, 1}))
+	g.Expect(rank("filter=score>=60&sort=score", Page{})).To(gomega.Equal([]int{2, 3, 0}))
+	g.Expect(rank("filter=risk=green", Page{})).To(gomega.Equal([]int{0, 3}))
+	g.Expect(rank("sort=desc:score", Page{Offset: 1, Limit: 2})).To(gomega.Equal([]int{3, 2}))
+	g.Expect(rank("sort=desc:score", Page{Offset: 9})).To(gomega.BeEmpty())
+	// Ranked only for computed fields.
+	ranked := func(query string) (b bool) {
+		ctx := &gin.Context{
+			Request: httptest.NewRequest(
+				http.MethodGet,
+				"/applications?"+query,
+				nil),
+		}
+		filter, err := qf.New(ctx, []qf.Assert{{Field: "risk", Kind: qf.STRING}})
+		g.Expect(err).To(gomega.BeNil())
+		ranking := Ranking{}
+		err = ranking.With(ctx, &filter)
+		g.Expect(err).To(gomega.BeNil())
+		b = ranking.Ranked()
+		return
+	}
+	g.Expect(ranked("sort=name")).To(gomega.BeFalse())
+	g.Expect(ranked("sort=desc:id,name")).To(gomega.BeFalse())
+	g.Expect(ranked("sort=name,score")).To(gomega.BeTrue())
+	g.Expect(ranked("filter=risk=green")).To(gomega.BeTrue())
+}
+
+func TestScimFilter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	f, err := ScimFilterWith(`userName eq "elmer@example.com"`)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(f.Attribute).To(gomega.Equal("userName"))
+	g.Expect(f.Value).To(gomega.Equal("elmer@example.com"))
+	f, err = ScimFilterWith(` displayName EQ "Fudd \"Elmer\"" `)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(f.Attribute).To(gomega.Equal("displayName"))
+	g.Expect(f.Value).To(gomega.Equal(`Fudd "Elmer"`))
+	for _, s := range []string{
+		`userName co "elmer"`,
+		`userName eq elmer`,
+		`userName eq "a" and active eq "true"`,
+	} {
+		_, err = ScimFilterWith(s)
+		g.Expect(errors.Is(err, &ScimFilterError{})).To(gomega.BeTrue(), s)
+	}
+}
+
+func TestScimPatchUser(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	m := &model.User{
+		Login: "elmer",
+		Name:  "Elmer",
+		Email: "elmer@example.com",
+	}
+	m.ID = 1001
+	r := &ScimUser{}
+	r.With(m)
+	// replace (no path); active as string.
+	patched := &ScimUser{}
+	err := ScimPatch(
+		r,
+		[]api.ScimOperation{
+			{
+				Op:    "Replace",
+				Value: map[string]any{"active": "False", "displayName": "Elmer Fudd"},
+			},
+		},
+		patched)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(*patched.Active).To(gomega.BeFalse())
+	g.Expect(patched.Model().Disabled).To(gomega.BeTrue())
+	g.Expect(patched.Model().Name).To(gomega.Equal("Elmer Fudd"))
+	// filtered path.
+	patched = &ScimUser{}
+	err = ScimPatch(
+		r,
+		[]api.ScimOperation{
+			{
+				Op:    api.ScimOpReplace,
+				Path:  `emails[type eq "work"].value`,
+				Value: "fudd@example.com",
+			},
+			{
+				Op:    api.ScimOpReplace,
+				Path:  "name.givenName",
+				Value: "Elmer",
+			},
+			{
+				Op:    api.ScimOpReplace,
+				Path:  "urn:ietf:params:scim:schemas:core:2.0:User:externalId",
+				Value: "x-1",
+			},
+		},
+		patched)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(patched.Model().Email).To(gomega.Equal("fudd@example.com"))
+	g.Expect(patched.Name.GivenName).To(gomega.Equal("Elmer"))
+	g.Expect(patched.ExternalID).To(gomega.Equal("x-1"))
+	// op not supported.
+	err = ScimPatch(r, []api.ScimOperation{{Op: "move"}}, &ScimUser{})
+	g.Expect(errors.Is(err, &BadRequestError{})).To(gomega.BeTrue())
+}
+
+func TestScimPatchGroup(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	m := &model.Role{Name: "architect"}
+	m.ID = 2
+	users := []model.User{
+		{Model: model.Model{ID: 1001}, Login: "elmer"},
+		{Model: model.Model{ID: 1002}, Login: "bugs"},
+	}
+	r := &ScimGroup{}
+	r.With(m, users)
+	// add.
+	patched := &ScimGroup{}
+	err := ScimPatch(
+		r,
+		[]api.ScimOperation{
+			{
+				Op:    api.ScimOpAdd,
+				Path:  "members",
+				Value: []any{map[string]any{"value": "1003"}},
+			},
+		},
+		patched)
+	g.Expect(err).To(gomega.BeNil())
+	ids, err := patched.MemberIds()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(ids).To(gomega.Equal([]uint{1001, 1002, 1003}))
+	// remove (filter).
+	patched = &ScimGroup{}
+	err = ScimPatch(
+		r,
+		[]api.ScimOperation{
+			{
+				Op:   api.ScimOpRemove,
+				Path: `members[value eq "1001"]`,
+			},
+		},
+		patched)
+	g.Expect(err).To(gomega.BeNil())
+	ids, err = patched.MemberIds()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(ids).To(gomega.Equal([]uint{1002}))
+	// remove (values).
+	patched = &ScimGroup{}
+	err = ScimPatch(
+		r,
+		[]api.ScimOperation{
+			{
+				Op:    api.ScimOpRemove,
+				Path:  "members",
+				Value: []any{map[string]any{"value": "1002"}},
+			},
+		},
+		patched)
+	g.Expect(err).To(gomega.BeNil())
+	ids, err = patched.MemberIds()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(ids).To(gomega.Equal([]uint{1001}))
+	// replace all.
+	patched = &ScimGroup{}
+	err = ScimPatch(
+		r,
+		[]api.ScimOperation{
+			{
+				Op:    api.ScimOpReplace,
+				Path:  "members",
+				Value: []any{},
+			},
+		},
+		patched)
+	g.Expect(err).To(gomega.BeNil())
+	ids, err = patched.MemberIds()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(ids).To(gomega.BeEmpty())
+}
+
+func TestLimiter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// budget.
+	b := settings.Budget{}
+	err := b.With("2/0/1")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(b.Rate).To(gomega.Equal(2.0))
+	g.Expect(b.Burst).To(gomega.Equal(2))
+	g.Expect(b.InFlight).To(gomega.Equal(1))
+	g.Expect(b.String()).To(gomega.Equal("2/2/1"))
+	err = b.With("0.5/4/0")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(b.Rate).To(gomega.Equal(0.5))
+	g.Expect(b.Burst).To(gomega.Equal(4))
+	for _, s := range []string{"", "1/2", "a/2/3", "1/-2/3", "1/2/3/4"} {
+		err = b.With(s)
+		g.Expect(err).ToNot(gomega.BeNil())
+	}
+
+	limiter := &Limiter{
+		Settings: &settings.Limit{
+			Enabled:        true,
+			User:           settings.Budget{Rate: 1, Burst: 2},
+			ServiceAccount: settings.Budget{InFlight: 1},
+			Task:           settings.Budget{},
+			Groups: map[string]settings.Budget{
+				"analyses":      {Rate: 1, Burst: 1},
+				"analyses:task": {InFlight: 2},
+			},
+		},
+	}
+
+	// rate: burst then limited.
+	for range 2 {
+		release, err := limiter.Acquire("u1", auth.SubjectUser, "tags")
+		g.Expect(err).To(gomega.BeNil())
+		release()
+	}
+	_, err = limiter.Acquire("u1", auth.SubjectUser, "applications")
+	limitErr := &TooManyRequests{}
+	g.Expect(errors.As(err, &limitErr)).To(gomega.BeTrue())
+	g.Expect(limitErr.Reason).To(gomega.ContainSubstring(LimitRate))
+	g.Expect(limitErr.RetryAfter).To(gomega.BeNumerically(">", 0))
+	g.Expect(limitErr.RetryAfter).To(gomega.BeNumerically("<=", time.Second))
+	// separate subjects.
+	release, err := limiter.Acquire("u2", auth.SubjectUser, "tags")
+	g.Expect(err).To(gomega.BeNil())
+	release()
+	// group budget.
+	release, err = limiter.Acquire("u1", auth.SubjectUser, "analyses")
+	g.Expect(err).To(gomega.BeNil())
+	release()
+	_, err = limiter.Acquire("u1", auth.SubjectUser, "analyses")
+	g.Expect(errors.Is(err, &TooManyRequests{})).To(gomega.BeTrue())
+
+	// in-flight.
+	release, err = limiter.Acquire("sa", auth.SubjectServiceAccount, "tags")
+	g.Expect(err).To(gomega.BeNil())
+	_, err = limiter.Acquire("sa", auth.SubjectServiceAccount, "tags")
+	g.Expect(errors.As(err, &limitErr)).To(gomega.BeTrue())
+	g.Expect(limitErr.Reason).To(gomega.ContainSubstring(LimitInFlight))
+	release()
+	release() // released once.
+	release, err = limiter.Acquire("sa", auth.SubjectServiceAccount, "tags")
+	g.Expect(err).To(gomega.BeNil())
+	release()
+
+	// charged: rate but not in-flight.
+	release, err = limiter.Acquire("sa", auth.SubjectServiceAccount, "tags")
+	g.Expect(err).To(gomega.BeNil())
+	err = limiter.Charge("sa", auth.SubjectServiceAccount, "tags")
+	g.Expect(err).To(gomega.BeNil())
+	release()
+	err = limiter.Charge("u3", auth.SubjectUser, "tags")
+	g.Expect(err).To(gomega.BeNil())
+	err = limiter.Charge("u3", auth.SubjectUser, "tags")
+	g.Expect(err).To(gomega.BeNil())
+	err = limiter.Charge("u3", auth.SubjectUser, "tags")
+	g.Expect(errors.As(err, &limitErr)).To(gomega.BeTrue())
+	g.Expect(limitErr.Reason).To(gomega.ContainSubstring(LimitRate))
+
+	// group budget by kind.
+	var releases []func()
+	for range 2 {
+		release, err = limiter.Acquire("task/1", auth.SubjectTask, "analyses")
+		g.Expect(err).To(gomega.BeNil())
+		releases = append(releases, release)
+	}
+	_, err = limiter.Acquire("task/1", auth.SubjectTask, "analyses")
+	g.Expect(errors.Is(err, &TooManyRequests{})).To(gomega.BeTrue())
+	release, err = limiter.Acquire("task/2", auth.SubjectTask, "analyses")
+	g.Expect(err).To(gomega.BeNil())
+	releases = append(releases, release)
+	for _, release := range releases {
+		release()
+	}
+	// unlimited (not released).
+	for range 100 {
+		release, err = limiter.Acquire("task/1", auth.SubjectTask, "tags")
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	// prune: in-flight not pruned.
+	limiter.pruned = time.Time{}
+	limiter.prune(time.Now().Add(LimitIdle * 2))
+	g.Expect(limiter.limits).To(gomega.HaveLen(1))
+}
+
+func TestLimitedResponse(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	saved := Limits
+	t.Cleanup(func() {
+		Limits = saved
+	})
+	Limits = &Limiter{
+		Settings: &settings.Limit{
+			Enabled: true,
+			User:    settings.Budget{Rate: 0.5, Burst: 1},
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Render())
+	router.Use(ErrorHandler())
+	router.GET(
+		"/limited",
+		func(ctx *gin.Context) {
+			rtx := RichContext(ctx)
+			rtx.Subject = "test"
+			rtx.SubjectKind = auth.SubjectUser
+			release := limited(ctx, "test")
+			if ctx.IsAborted() {
+				return
+			}
+			defer release()
+			rtx.Status(http.StatusNoContent)
+		})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/limited", nil))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/limited", nil))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusTooManyRequests))
+	g.Expect(w.Header().Get(RetryAfter)).To(gomega.Equal("2"))
+	g.Expect(w.Body.String()).To(gomega.ContainSubstring("exceeded"))
+}
+
+func TestLimitedBatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	saved := Limits
+	t.Cleanup(func() {
+		Limits = saved
+	})
+	Limits = &Limiter{
+		Settings: &settings.Limit{
+			Enabled: true,
+			User:    settings.Budget{Rate: 0.1, Burst: 1, InFlight: 1},
+			Groups: map[string]settings.Budget{
+				"tasks.report": {Rate: 0.1, Burst: 2, InFlight: 1},
+			},
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Render())
+	router.Use(ErrorHandler())
+	subject := func(ctx *gin.Context) {
+		rtx := RichContext(ctx)
+		rtx.Subject = "test"
+		rtx.SubjectKind = auth.SubjectUser
+	}
+	group := func(name string) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			release := limited(ctx, name)
+			if ctx.IsAborted() {
+				return
+			}
+			defer release()
+			ctx.Next()
+		}
+	}
+	router.PUT(
+		api.TaskReportRoute,
+		subject,
+		group("tasks.report"),
+		func(ctx *gin.Context) {
+			RichContext(ctx).Status(http.StatusNoContent)
+		})
+	h := BatchHandler{engine: router}
+	router.POST(api.BatchRoute, subject, group("batch"), h.Create)
+
+	// batch of report operations.
+	batch := &Batch{Mode: api.BatchItem}
+	for range 4 {
+		batch.Operations = append(
+			batch.Operations,
+			BatchOperation{
+				Method: http.MethodPut,
+				Path:   "/tasks/1/report",
+			})
+	}
+	b, _ := json.Marshal(batch)
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, api.BatchRoute, bytes.NewReader(b))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, request)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	batch = &Batch{}
+	err := json.Unmarshal(w.Body.Bytes(), batch)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(batch.Committed).To(gomega.BeFalse())
+	var status []int
+	for _, op := range batch.Operations {
+		status = append(status, op.Result.Status)
+	}
+	g.Expect(status).To(gomega.Equal([]int{
+		http.StatusNoContent,
+		http.StatusNoContent,
+		http.StatusTooManyRequests,
+		http.StatusTooManyRequests,
+	}))
+
+	// the group rate is shared with requests.
+	w = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPut, "/tasks/1/report", nil)
+	router.ServeHTTP(w, request)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusTooManyRequests))
+}
@@ -35,10 +35,12 @@ type ApplicationHandler struct {
 // AddRoutes adds routes.
 func (h ApplicationHandler) AddRoutes(e *gin.Engine) {
 	routeGroup := e.Group("/")
+	routeGroup.Use(Required("applications"))
+	routeGroup.POST(api.ApplicationsRoute, Idempotent, Transaction, h.Create)
+	routeGroup = e.Group("/")
 	routeGroup.Use(Required("applications"), Transaction)
 	routeGroup.GET(api.ApplicationsRoute, h.List)
 	routeGroup.GET(api.ApplicationsRoute+"/", h.List)
-	routeGroup.POST(api.ApplicationsRoute, h.Create)
 	routeGroup.GET(api.ApplicationRoute, h.Get)
 	routeGroup.PUT(api.ApplicationRoute, h.Update)
 	routeGroup.DELETE(api.ApplicationsRoute, h.DeleteList)
@@ -78,11 +80,17 @@ func (h ApplicationHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.Use(Required("applications.assessments"), Transaction)
 	routeGroup.GET(api.AppAssessmentsRoute, h.AssessmentList)
 	routeGroup.POST(api.AppAssessmentsRoute, h.AssessmentCreate)
+	// Membership
+	routeGroup = e.Group("/")
+	routeGroup.Use(Required("applications"))
+	routeGroup.GET(api.AppMembershipRoute, h.Membership)
 }
 
 // Get godoc
 // @summary Get an application by ID.
 // @description Get an application by ID.
+// @description fields: (sparse fieldset) Example: fields=id,name,owner,tags
+// @description expand: (see: list)
 // @tags applications
 // @produce json
 // @success 200 {object} api.Application
@@ -97,6 +105,17 @@ func (h ApplicationHandler) Get(ctx *gin.Context) {
 		_ = ctx.Error(result.Error)
 		return
 	}
+	err := h.expand(ctx, func() *gorm.DB {
+		q := h.DB(ctx)
+		q = q.Model(&model.Application{})
+		q = q.Select("ID")
+		q = q.Where("ID", id)
+		return q
+	})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	tagMap, err := h.tagMap(ctx, []uint{id})
 	if err != nil {
 		_ = ctx.Error(err)
@@ -145,6 +164,23 @@ func (h ApplicationHandler) Get(ctx *gin.Context) {
 // @description - platform.id
 // @description - repository.url
 // @description - repository.path
+// @description - facts.(key).(path)
+// @description - coordinates.(path)
+// @description - risk
+// @description - confidence
+// @description - score
+// @description sort: id, name, risk, confidence, score. Example: sort=desc:score
+// @description Sorting by id and name is done by the database. The risk, confidence
+// @description and score are computed from the assessments; filtering and sorting on
+// @description them is applied before pagination and the X-Total header is set.
+// @description Restricted to the applications permitted by constrained scopes.
+// @description fields: (sparse fieldset) Example: fields=id,name,owner,tags
+// @description expand:
+// @description - owner
+// @description - contributors
+// @description - businessService
+// @description - migrationWave
+// @description - platform
 // @tags applications
 // @produce json
 // @success 200 {object} []api.Application
@@ -179,12 +215,58 @@ func (h ApplicationHandler) List(ctx *gin.Context) {
 			{Field: "platform.id", Kind: qf.LITERAL},
 			{Field: "repository.url", Kind: qf.STRING},
 			{Field: "repository.path", Kind: qf.STRING},
+			{Field: "facts", Kind: qf.JSON},
+			{Field: "coordinates", Kind: qf.JSON},
+			{Field: "risk", Kind: qf.STRING},
+			{Field: "confidence", Kind: qf.LITERAL},
+			{Field: "score", Kind: qf.LITERAL},
 		})
 	if err != nil {
 		_ = ctx.Error(err)
 		return
 	}
+	ranking := Ranking{}
+	err = ranking.With(ctx, &filter)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	filter = filter.Renamed("platform.id", "PlatformId")
+	page := Page{}
+	page.With(ctx)
+	var ranked []uint
+	pageIds := func() *gorm.DB {
+		q := h.appIds(ctx, filter)
+		q = ranking.Sorted(q, "")
+		q = q.Order("ID")
+		q = page.Paginated(q)
+		return q
+	}
+	if ranking.Ranked() {
+		var total int
+		ranked, total, err = h.ranked(ctx, filter, &ranking, page, appResolver, tagMap)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		err = h.WithCount(ctx, int64(total))
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		pageIds = func() *gorm.DB {
+			q := h.DB(ctx)
+			q = q.Model(&model.Application{})
+			q = q.Select("ID")
+			q = q.Where("ID IN ?", ranked)
+			return q
+		}
+	}
+	err = h.expand(ctx, pageIds)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 
 	type M struct {
 		*model.Application
@@ -205,6 +287,7 @@ func (h ApplicationHandler) List(ctx *gin.Context) {
 		AssessmentId         uint
 		AssessmentSections   []byte
 		AssessmentThresholds []byte
+		AssessmentScoring    []byte
 		ManifestId           uint
 		QuestionnaireId      uint
 		AnalysisId           uint
@@ -230,6 +313,7 @@ func (h ApplicationHandler) List(ctx *gin.Context) {
 		"at.ID              AssessmentId",
 		"at.Sections        AssessmentSections",
 		"at.Thresholds     AssessmentThresholds",
+		"at.Scoring        AssessmentScoring",
 		"mf.ID              ManifestId",
 		"at.QuestionnaireID QuestionnaireId",
 		"an.ID              AnalysisId",
@@ -248,12 +332,17 @@ func (h ApplicationHandler) List(ctx *gin.Context) {
 	db = db.Joins("LEFT JOIN Assessment at ON at.ApplicationID = a.ID")
 	db = db.Joins("LEFT JOIN Manifest mf ON mf.ApplicationID = a.ID")
 	db = db.Joins("LEFT JOIN Analysis an ON an.ApplicationID = a.ID")
-	db = db.Where("a.ID IN (?)", h.appIds(ctx, filter))
-	db = db.Order("a.ID")
-	page := Page{}
-	page.With(ctx)
 	cursor := Cursor{}
-	cursor.With(db, page)
+	if ranking.Ranked() {
+		db = db.Where("a.ID IN ?", ranked)
+		db = db.Order("a.ID")
+		cursor.With(db, Page{})
+	} else {
+		db = db.Where("a.ID IN (?)", h.appIds(ctx, filter))
+		db = ranking.Sorted(db, "a")
+		db = db.Order("a.ID")
+		cursor.With(db, page)
+	}
 	builder := func(batch []any) (out any, err error) {
 		app := &model.Application{}
 		idMap := make(IdentityMap)
@@ -307,6 +396,7 @@ func (h ApplicationHandler) List(ctx *gin.Context) {
 				ref.QuestionnaireID = m.QuestionnaireId
 				_ = json.Unmarshal(m.AssessmentSections, &ref.Sections)
 				_ = json.Unmarshal(m.AssessmentThresholds, &ref.Thresholds)
+				_ = json.Unmarshal(m.AssessmentScoring, &ref.Scoring)
 				assessments[m.AssessmentId] = ref
 			}
 			if m.ManifestId > 0 {
@@ -346,18 +436,47 @@ func (h ApplicationHandler) List(ctx *gin.Context) {
 		return
 	}
 	iter := NewIterator(&M{}, &cursor, builder)
-	h.Respond(ctx, http.StatusOK, iter)
+	if !ranking.Ranked() {
+		h.Respond(ctx, http.StatusOK, iter)
+		return
+	}
+	defer iter.Close()
+	built := make(map[uint]Application)
+	for {
+		next, object := iter.Next()
+		if iter.Error != nil {
+			_ = ctx.Error(iter.Error)
+			return
+		}
+		if !next {
+			break
+		}
+		r := object.(Application)
+		built[r.ID] = r
+	}
+	resources := []Application{}
+	for _, id := range ranked {
+		r, found := built[id]
+		if found {
+			resources = append(resources, r)
+		}
+	}
+	h.Respond(ctx, http.StatusOK, resources)
 }
 
 // Create godoc
 // @summary Create an application.
 // @description Create an application.
+// @description Supports the Idempotency-Key header. A retry with the same key
+// @description replays the original response.
+// @description Must be permitted by constrained scopes.
 // @tags applications
 // @accept json
 // @produce json
 // @success 201 {object} api.Application
 // @router /applications [post]
 // @param application body api.Application true "Application data"
+// @param Idempotency-Key header string false "Idempotency key"
 func (h ApplicationHandler) Create(ctx *gin.Context) {
 	r := &Application{}
 	err := h.Bind(ctx, r)
@@ -366,6 +485,12 @@ func (h ApplicationHandler) Create(ctx *gin.Context) {
 		return
 	}
 	m := r.Model()
+	constraint := AppConstraint{ctx: ctx}
+	err = constraint.Permit(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
 	result := h.DB(ctx).Omit(clause.Associations).Create(m)
 	if result.Error != nil {
@@ -461,6 +586,7 @@ func (h ApplicationHandler) Delete(ctx *gin.Context) {
 // DeleteList godoc
 // @summary Delete a applications.
 // @description Delete applications.
+// @description Applications not permitted by constrained scopes are not deleted.
 // @tags applications
 // @success 204
 // @router /applications [delete]
@@ -472,7 +598,9 @@ func (h ApplicationHandler) DeleteList(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
-	err = h.DB(ctx).Delete(
+	constraint := AppConstraint{ctx: ctx}
+	db := constraint.Where(h.DB(ctx), "ID")
+	err = db.Delete(
 		&model.Application{},
 		"id IN ?",
 		ids).Error
@@ -487,6 +615,7 @@ func (h ApplicationHandler) DeleteList(ctx *gin.Context) {
 // Update godoc
 // @summary Update an application.
 // @description Update an application.
+// @description Must be permitted by constrained scopes.
 // @tags applications
 // @accept json
 // @success 204
@@ -513,6 +642,12 @@ func (h ApplicationHandler) Update(ctx *gin.Context) {
 	m = r.Model()
 	m.Tags = nil
 	m.ID = id
+	constraint := AppConstraint{ctx: ctx}
+	err = constraint.Permit(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m.UpdateUser = h.BaseHandler.CurrentUser(ctx)
 	db = h.DB(ctx).Model(m)
 	db = db.Omit(clause.Associations, "BucketID")
@@ -1218,6 +1353,11 @@ func (h ApplicationHandler) AssessmentCreate(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	err = r.Validate()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	r.Application = &resource.Ref{ID: id}
 	r.Archetype = nil
 	q := &model.Questionnaire{}
@@ -1230,6 +1370,8 @@ func (h ApplicationHandler) AssessmentCreate(ctx *gin.Context) {
 	m := r.Model()
 	m.Thresholds = q.Thresholds
 	m.RiskMessages = q.RiskMessages
+	m.Scoring = q.Scoring
+	m.QuestionnaireVersion = q.Version
 	m.CreateUser = h.CurrentUser(ctx)
 	// if sections aren't empty that indicates that this assessment is being
 	// created "as-is" and should not have its sections populated or autofilled.
@@ -1242,6 +1384,11 @@ func (h ApplicationHandler) AssessmentCreate(ctx *gin.Context) {
 			return
 		}
 		assessment.PrepareForApplication(resolver, application, m)
+		_, err = assessment.AutoAnswerForApplication(h.DB(ctx), application.ID, m)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
 		newAssessment = true
 	}
 	result = h.DB(ctx).Omit(clause.Associations).Create(m)
@@ -1267,6 +1414,51 @@ func (h ApplicationHandler) AssessmentCreate(ctx *gin.Context) {
 	h.Respond(ctx, http.StatusCreated, r)
 }
 
+// Membership godoc
+// @summary Explain the archetype membership of an application.
+// @description Explain the archetype membership of an application.
+// @description Lists each archetype with whether the criteria is matched:
+// @description - missing: criteria tags not assigned to the application.
+// @description - predicates: the evaluated selector predicates.
+// @description - superseded: more specific (matched) archetypes.
+// @description The application is a member of the most specific matched archetypes.
+// @tags applications
+// @produce json
+// @success 200 {object} []api.Membership
+// @router /applications/{id}/membership [get]
+// @param id path int true "Application ID"
+func (h ApplicationHandler) Membership(ctx *gin.Context) {
+	m := &model.Application{}
+	id := h.pk(ctx)
+	db := h.DB(ctx).Preload("Tags")
+	db = db.Omit("Analyses")
+	result := db.First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	memberResolver, err := assessment.NewMembershipResolver(h.DB(ctx))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	app := assessment.Application{}
+	app.With(m)
+	memberships, err := memberResolver.Explain(app)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	resources := []Membership{}
+	for i := range memberships {
+		r := Membership{}
+		r.With(&memberships[i])
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
 // tagMap returns a map of AppTag indexed by application id.
 // This is a performance and memory optimization.
 func (h *ApplicationHandler) tagMap(
@@ -1303,6 +1495,63 @@ func (h *ApplicationHandler) tagMap(
 	return
 }
 
+// ranked returns the ids of the applications (page) ranked by
+// the computed (risk, confidence and score) fields and the total
+// number matched. Only the computed fields are resolved.
+func (h *ApplicationHandler) ranked(
+	ctx *gin.Context,
+	filter qf.Filter,
+	ranking *Ranking,
+	page Page,
+	resolver *assessment.ApplicationResolver,
+	tagMap TagMap) (ids []uint, total int, err error) {
+	var list []model.Application
+	db := h.DB(ctx)
+	db = db.Select("ID", "Name")
+	db = db.Preload("Assessments")
+	db = db.Where("ID IN (?)", h.appIds(ctx, filter))
+	db = db.Order("ID")
+	err = db.Find(&list).Error
+	if err != nil {
+		return
+	}
+	values := []Ranked{}
+	for i := range list {
+		m := &list[i]
+		tagMap.Set(m)
+		v := Ranked{
+			ID:   m.ID,
+			Name: m.Name,
+			Risk: assessment.RiskUnassessed,
+		}
+		assessed := false
+		assessed, err = resolver.Assessed(m)
+		if err != nil {
+			return
+		}
+		if assessed {
+			v.Confidence, err = resolver.Confidence(m)
+			if err != nil {
+				return
+			}
+			v.Risk, err = resolver.Risk(m)
+			if err != nil {
+				return
+			}
+			v.Score, err = resolver.Score(m)
+			if err != nil {
+				return
+			}
+		}
+		values = append(values, v)
+	}
+	index, total := ranking.Apply(values, page)
+	for _, i := range index {
+		ids = append(ids, values[i].ID)
+	}
+	return
+}
+
 // idMap returns a loaded IdentityMap.
 func (h *ApplicationHandler) idMap(ctx *gin.Context, appId uint) (mp IdentityMap, err error) {
 	mp = make(IdentityMap)
@@ -1387,7 +1636,27 @@ func (h *ApplicationHandler) appIds(ctx *gin.Context, f qf.Filter) (q *gorm.DB)
 	q = q.Model(&model.Application{})
 	q = q.Select("ID")
 	q = f.Where(q)
+	constraint := AppConstraint{ctx: ctx}
+	q = constraint.Where(q, "ID")
 	filter := f
+	coordinates := filter.Resource("coordinates")
+	q = coordinates.JsonWhere(q, "Coordinates")
+	facts := filter.Resource("facts")
+	for _, fact := range facts.List() {
+		fq := h.DB(ctx)
+		fq = fq.Model(&model.Fact{})
+		fq = fq.Select("ApplicationID")
+		key := fact.Resource()
+		if key == "" {
+			key = fact.Name()
+			fact = fact.As("")
+		} else {
+			fact = fact.As(fact.Name())
+		}
+		fq = fq.Where("Key", key)
+		fq = fact.JsonWhere(fq, "Value")
+		q = q.Where("ID IN (?)", fq)
+	}
 	repository := filter.Resource("repository")
 	if repository.Empty() {
 		return
@@ -1412,6 +1681,96 @@ func (h *ApplicationHandler) appIds(ctx *gin.Context, f qf.Filter) (q *gorm.DB)
 	return
 }
 
+// expand eager loads the related resources to be expanded (expand=).
+// The appIds returns a query selecting the IDs of the applications
+// being rendered (the page). The related resources are fetched (one query) for
+// each expanded relationship.
+func (h *ApplicationHandler) expand(ctx *gin.Context, appIds func() *gorm.DB) (err error) {
+	expanded, err := h.Expanded(
+		ctx,
+		"owner",
+		"contributors",
+		"businessService",
+		"migrationWave",
+		"platform")
+	if err != nil {
+		return
+	}
+	for _, name := range expanded {
+		db := h.preLoad(h.DB(ctx), clause.Associations)
+		q := h.DB(ctx)
+		switch name {
+		case "owner", "contributors":
+			if name == "owner" {
+				q = q.Model(&model.Application{})
+				q = q.Select("OwnerID")
+				q = q.Where("ID IN (?)", appIds())
+			} else {
+				q = q.Table("ApplicationContributors")
+				q = q.Select("StakeholderID")
+				q = q.Where("ApplicationID IN (?)", appIds())
+			}
+			var list []model.Stakeholder
+			err = db.Find(&list, "ID IN (?)", q).Error
+			if err != nil {
+				return
+			}
+			for i := range list {
+				m := &list[i]
+				r := Stakeholder{}
+				r.With(m)
+				h.Expand(ctx, name, m.ID, r)
+			}
+		case "businessService":
+			q = q.Model(&model.Application{})
+			q = q.Select("BusinessServiceID")
+			q = q.Where("ID IN (?)", appIds())
+			var list []model.BusinessService
+			err = db.Find(&list, "ID IN (?)", q).Error
+			if err != nil {
+				return
+			}
+			for i := range list {
+				m := &list[i]
+				r := BusinessService{}
+				r.With(m)
+				h.Expand(ctx, name, m.ID, r)
+			}
+		case "migrationWave":
+			q = q.Model(&model.Application{})
+			q = q.Select("MigrationWaveID")
+			q = q.Where("ID IN (?)", appIds())
+			var list []model.MigrationWave
+			err = db.Find(&list, "ID IN (?)", q).Error
+			if err != nil {
+				return
+			}
+			for i := range list {
+				m := &list[i]
+				r := MigrationWave{}
+				r.With(m)
+				h.Expand(ctx, name, m.ID, r)
+			}
+		case "platform":
+			q = q.Model(&model.Application{})
+			q = q.Select("PlatformID")
+			q = q.Where("ID IN (?)", appIds())
+			var list []model.Platform
+			err = db.Find(&list, "ID IN (?)", q).Error
+			if err != nil {
+				return
+			}
+			for i := range list {
+				m := &list[i]
+				r := Platform{}
+				r.With(m)
+				h.Expand(ctx, name, m.ID, r)
+			}
+		}
+	}
+	return
+}
+
 // Application REST resource.
 type Application = resource.Application
 
@@ -1439,6 +1798,9 @@ type IdentityRef = resource.IdentityRef
 //	Example: 'analysis:"
 type FactKey = resource.FactKey
 
+// Membership REST resource.
+type Membership = resource.Membership
+
 // Stakeholders REST subresource.
 type Stakeholders struct {
 	Owner        *Ref  `json:"owner"`
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	qf "github.com/konveyor/tackle2-hub/internal/api/filter"
 	"github.com/konveyor/tackle2-hub/internal/api/resource"
 	"github.com/konveyor/tackle2-hub/internal/assessment"
 	"github.com/konveyor/tackle2-hub/internal/metrics"
@@ -85,11 +88,32 @@ func (h ArchetypeHandler) Get(ctx *gin.Context) {
 // List godoc
 // @summary List all archetypes.
 // @description List all archetypes.
+// @description filters:
+// @description - risk
+// @description - confidence
+// @description - score
+// @description sort: id, name, risk, confidence, score. Example: sort=desc:score
 // @tags archetypes
 // @produce json
 // @success 200 {object} []api.Archetype
 // @router /archetypes [get]
 func (h ArchetypeHandler) List(ctx *gin.Context) {
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "risk", Kind: qf.STRING},
+			{Field: "confidence", Kind: qf.LITERAL},
+			{Field: "score", Kind: qf.LITERAL},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	ranking := Ranking{}
+	err = ranking.With(ctx, &filter)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	var list []model.Archetype
 	db := h.DB(ctx)
 	db = db.Preload(clause.Associations)
@@ -98,6 +122,7 @@ func (h ArchetypeHandler) List(ctx *gin.Context) {
 	db = db.Preload("Profiles.Generators", func(db *gorm.DB) *gorm.DB {
 		return db.Order("`Index`")
 	})
+	db = ranking.Sorted(db, "")
 	result := db.Find(&list)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
@@ -130,6 +155,33 @@ func (h ArchetypeHandler) List(ctx *gin.Context) {
 		}
 		resources = append(resources, r)
 	}
+	if ranking.Ranked() {
+		var values []Ranked
+		for _, r := range resources {
+			values = append(
+				values,
+				Ranked{
+					ID:         r.ID,
+					Name:       r.Name,
+					Risk:       r.Risk,
+					Confidence: r.Confidence,
+					Score:      r.Score,
+				})
+		}
+		page := Page{}
+		page.With(ctx)
+		ranked := []Archetype{}
+		index, total := ranking.Apply(values, page)
+		for _, i := range index {
+			ranked = append(ranked, resources[i])
+		}
+		resources = ranked
+		err = h.WithCount(ctx, int64(total))
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
 
 	h.Respond(ctx, http.StatusOK, resources)
 }
@@ -137,6 +189,14 @@ func (h ArchetypeHandler) List(ctx *gin.Context) {
 // Create godoc
 // @summary Create an archetype.
 // @description Create an archetype.
+// @description The selector is an (optional) criteria expression matched in addition to the
+// @description criteria tags. Predicates: tag:<category>[=<name>], fact:<key>[=<value>],
+// @description platform:kind=<kind>, business:<name>, and (latest analysis) label:<label>,
+// @description rule:[<ruleset>/]<rule>, insights:<category>[<op><count>], dependency:<name>[=<version>]
+// @description combined using: && (and), || (or), ! (not) and parentheses.
+// @description Names, keys and values may contain wildcards (*).
+// @description The (optional) parent archetype is inherited: tags (union), stakeholders
+// @description (when none defined), target profiles (by name) and assessments (by questionnaire).
 // @tags archetypes
 // @accept json
 // @produce json
@@ -151,6 +211,11 @@ func (h ArchetypeHandler) Create(ctx *gin.Context) {
 		return
 	}
 	m := r.Model()
+	err = h.validate(ctx, m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m.CreateUser = h.CurrentUser(ctx)
 	result := h.DB(ctx).Omit(clause.Associations).Create(m)
 	if result.Error != nil {
@@ -248,6 +313,14 @@ func (h ArchetypeHandler) Delete(ctx *gin.Context) {
 // Update godoc
 // @summary Update an archetype.
 // @description Update an archetype.
+// @description The selector is an (optional) criteria expression matched in addition to the
+// @description criteria tags. Predicates: tag:<category>[=<name>], fact:<key>[=<value>],
+// @description platform:kind=<kind>, business:<name>, and (latest analysis) label:<label>,
+// @description rule:[<ruleset>/]<rule>, insights:<category>[<op><count>], dependency:<name>[=<version>]
+// @description combined using: && (and), || (or), ! (not) and parentheses.
+// @description Names, keys and values may contain wildcards (*).
+// @description The (optional) parent archetype is inherited: tags (union), stakeholders
+// @description (when none defined), target profiles (by name) and assessments (by questionnaire).
 // @tags archetypes
 // @accept json
 // @success 204
@@ -264,6 +337,11 @@ func (h ArchetypeHandler) Update(ctx *gin.Context) {
 	}
 	m := r.Model()
 	m.ID = id
+	err = h.validate(ctx, m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m.UpdateUser = h.CurrentUser(ctx)
 	db := h.DB(ctx).Model(m)
 	db = db.Omit(clause.Associations)
@@ -368,6 +446,11 @@ func (h ArchetypeHandler) AssessmentCreate(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	err = r.Validate()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	r.Archetype = &resource.Ref{ID: id}
 	r.Application = nil
 	q := &model.Questionnaire{}
@@ -379,6 +462,8 @@ func (h ArchetypeHandler) AssessmentCreate(ctx *gin.Context) {
 	m := r.Model()
 	m.Thresholds = q.Thresholds
 	m.RiskMessages = q.RiskMessages
+	m.Scoring = q.Scoring
+	m.QuestionnaireVersion = q.Version
 	m.CreateUser = h.CurrentUser(ctx)
 	// if sections aren't empty that indicates that this assessment is being
 	// created "as-is" and should not have its sections populated or autofilled.
@@ -467,6 +552,45 @@ func (h ArchetypeHandler) updateGenerators(ctx *gin.Context, m *model.Archetype)
 	return
 }
 
+// validate the criteria selector and parent.
+func (h ArchetypeHandler) validate(ctx *gin.Context, m *model.Archetype) (err error) {
+	criteria := assessment.Criteria{}
+	err = criteria.Validate(m.Selector)
+	if err != nil {
+		err = &BadRequestError{Reason: err.Error()}
+		return
+	}
+	err = h.validateParent(ctx, m)
+	return
+}
+
+// validateParent ensures the parent exists and
+// the hierarchy does not contain a cycle.
+func (h ArchetypeHandler) validateParent(ctx *gin.Context, m *model.Archetype) (err error) {
+	seen := map[uint]bool{m.ID: m.ID != 0}
+	parentID := m.ParentID
+	for parentID != nil {
+		if seen[*parentID] {
+			err = &BadRequestError{Reason: "archetype hierarchy cannot contain a cycle."}
+			return
+		}
+		parent := &model.Archetype{}
+		db := h.DB(ctx).Select("ID", "ParentID")
+		err = db.First(parent, *parentID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = &BadRequestError{
+					Reason: fmt.Sprintf("parent archetype (id=%d) not found.", *parentID),
+				}
+			}
+			return
+		}
+		seen[parent.ID] = true
+		parentID = parent.ParentID
+	}
+	return
+}
+
 // TargetProfile REST resource.
 type TargetProfile = resource.TargetProfile
 
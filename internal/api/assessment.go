@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/konveyor/tackle2-hub/internal/api/resource"
+	"github.com/konveyor/tackle2-hub/internal/assessment"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/shared/api"
 	"gorm.io/gorm/clause"
@@ -24,6 +25,7 @@ func (h AssessmentHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.GET(api.AssessmentRoute, h.Get)
 	routeGroup.PUT(api.AssessmentRoute, h.Update)
 	routeGroup.DELETE(api.AssessmentRoute, h.Delete)
+	routeGroup.POST(api.AssessmentUpgradeRoute, h.Upgrade)
 }
 
 // Get godoc
@@ -52,6 +54,7 @@ func (h AssessmentHandler) Get(ctx *gin.Context) {
 // List godoc
 // @summary List all assessments.
 // @description List all assessments.
+// @description Restricted to the applications permitted by constrained scopes.
 // @tags assessments
 // @produce json
 // @success 200 {object} []api.Assessment
@@ -59,6 +62,8 @@ func (h AssessmentHandler) Get(ctx *gin.Context) {
 func (h AssessmentHandler) List(ctx *gin.Context) {
 	var list []model.Assessment
 	db := h.preLoad(h.DB(ctx), clause.Associations)
+	constraint := AppConstraint{ctx: ctx}
+	db = constraint.Where(db, "ApplicationID")
 	result := db.Find(&list)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
@@ -101,6 +106,7 @@ func (h AssessmentHandler) Delete(ctx *gin.Context) {
 // Update godoc
 // @summary Update an assessment.
 // @description Update an assessment.
+// @description The answer (auto-answer) conditions are validated.
 // @tags assessments
 // @accept json
 // @success 204
@@ -115,11 +121,16 @@ func (h AssessmentHandler) Update(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	err = r.Validate()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m := r.Model()
 	m.ID = id
 	m.UpdateUser = h.CurrentUser(ctx)
 	db := h.DB(ctx).Model(m)
-	db = db.Omit(clause.Associations, "Thresholds", "RiskMessages")
+	db = db.Omit(clause.Associations, "Thresholds", "RiskMessages", "Scoring", "QuestionnaireVersion")
 	result := db.Save(m)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
@@ -139,5 +150,93 @@ func (h AssessmentHandler) Update(ctx *gin.Context) {
 	h.Status(ctx, http.StatusNoContent)
 }
 
+// Upgrade godoc
+// @summary Upgrade an assessment to the latest questionnaire version.
+// @description Upgrade an assessment to the latest questionnaire version.
+// @description Answers are carried forward for questions matched by (stable) ID
+// @description or by text. The report lists the answers carried forward, the answers
+// @description dropped and the questions needing to be (re)answered.
+// @description When dryRun=true, the assessment is not updated.
+// @tags assessments
+// @accept json
+// @produce json
+// @success 200 {object} api.AssessmentUpgrade
+// @router /assessments/{id}/upgrade [post]
+// @param id path int true "Assessment ID"
+// @param upgrade body api.AssessmentUpgrade true "Upgrade options"
+func (h AssessmentHandler) Upgrade(ctx *gin.Context) {
+	id := h.pk(ctx)
+	r := &AssessmentUpgrade{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := &model.Assessment{}
+	db := h.preLoad(
+		h.DB(ctx),
+		clause.Associations,
+		"Application.Tags",
+		"Archetype.Tags",
+		"Archetype.CriteriaTags")
+	result := db.First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	version := &model.QuestionnaireVersion{}
+	db = h.DB(ctx).Where("QuestionnaireID", m.QuestionnaireID)
+	db = db.Order("Version DESC")
+	result = db.First(version)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	dryRun := r.DryRun
+	r = &AssessmentUpgrade{DryRun: dryRun}
+	if m.QuestionnaireVersion == version.Version {
+		r.From = version.Version
+		r.To = version.Version
+		h.Respond(ctx, http.StatusOK, r)
+		return
+	}
+	tagResolver, err := assessment.NewTagResolver(h.DB(ctx))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	prepared := &model.Assessment{Sections: version.Sections}
+	switch {
+	case m.Application != nil:
+		assessment.PrepareForApplication(tagResolver, m.Application, prepared)
+		_, err = assessment.AutoAnswerForApplication(h.DB(ctx), m.Application.ID, prepared)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	case m.Archetype != nil:
+		assessment.PrepareForArchetype(tagResolver, m.Archetype, prepared)
+	}
+	a := assessment.Assessment{}
+	a.With(m)
+	report := a.Upgrade(version, prepared.Sections)
+	r.With(&report)
+	if !dryRun {
+		m.UpdateUser = h.CurrentUser(ctx)
+		db = h.DB(ctx).Model(m)
+		db = db.Select("Sections", "Thresholds", "RiskMessages", "Scoring", "QuestionnaireVersion", "UpdateUser")
+		result = db.Updates(m)
+		if result.Error != nil {
+			_ = ctx.Error(result.Error)
+			return
+		}
+	}
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
 // Assessment REST resource.
 type Assessment = resource.Assessment
+
+// AssessmentUpgrade REST resource.
+type AssessmentUpgrade = resource.AssessmentUpgrade
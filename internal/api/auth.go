@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	qf "github.com/konveyor/tackle2-hub/internal/api/filter"
 	"github.com/konveyor/tackle2-hub/internal/api/resource"
 	"github.com/konveyor/tackle2-hub/internal/auth"
 	"github.com/konveyor/tackle2-hub/internal/auth/cache"
@@ -53,6 +54,8 @@ func (h AuthHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.GET(api.UserRoute, h.UserGet)
 	routeGroup.PUT(api.UserRoute, h.UserUpdate)
 	routeGroup.DELETE(api.UserRoute, h.UserDelete)
+	routeGroup.DELETE(api.UserMfaRoute, h.UserMfaDelete)
+	routeGroup.DELETE(api.UserLockRoute, h.UserUnlock)
 	// SA routes.
 	routeGroup = e.Group("/")
 	routeGroup.Use(Required("serviceaccounts"), Transaction)
@@ -96,10 +99,21 @@ func (h AuthHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.GET(api.AuthTokenRoute, h.TokenGet)
 	routeGroup.POST(api.AuthTokenRevokeRoute, h.TokenRevoke)
 	routeGroup.DELETE(api.AuthTokenRoute, h.TokenDelete)
+	// Event routes
+	routeGroup = e.Group("/")
+	routeGroup.Use(Required("auth.events"))
+	routeGroup.GET(api.AuthEventsRoute, h.EventList)
+	routeGroup.GET(api.AuthEventsRoute+"/", h.EventList)
 	// self route
 	routeGroup = e.Group("/")
 	routeGroup.Use(Authenticate())
 	routeGroup.GET(api.AuthSelfGetRoute, h.GetSelf)
+	// MFA (self) routes
+	routeGroup = e.Group("/")
+	routeGroup.Use(Authenticate())
+	routeGroup.POST(api.AuthMfaRoute, h.MfaEnroll)
+	routeGroup.POST(api.AuthMfaConfirmRoute, h.MfaConfirm)
+	routeGroup.DELETE(api.AuthMfaRoute, h.MfaDelete)
 }
 
 //
@@ -509,6 +523,13 @@ func (h AuthHandler) UserCreate(ctx *gin.Context) {
 	m := r.Model()
 	m.Subject = uuid.New().String()
 	m.CreateUser = h.CurrentUser(ctx)
+	m.Password = ""
+	passwords := auth.Password{DB: h.DB(ctx)}
+	err = passwords.Set(m, r.Password)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	_, err = secret.Encode(m)
 	if err != nil {
 		_ = ctx.Error(err)
@@ -576,7 +597,24 @@ func (h AuthHandler) UserUpdate(ctx *gin.Context) {
 	}
 	updated := r.Model()
 	updated.ID = id
+	updated.ExternalId = current.ExternalId
+	updated.Totp = current.Totp
+	updated.TotpEnabled = current.TotpEnabled
+	updated.RecoveryCodes = current.RecoveryCodes
+	updated.PasswordHistory = current.PasswordHistory
+	updated.PasswordChanged = current.PasswordChanged
+	updated.FailedLogins = current.FailedLogins
+	updated.LockedUntil = current.LockedUntil
 	updated.UpdateUser = h.CurrentUser(ctx)
+	if r.Password != SecretMask && r.Password != current.Password {
+		updated.Password = current.Password
+		passwords := auth.Password{DB: h.DB(ctx)}
+		err = passwords.Set(updated, r.Password)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
 	db := h.DB(ctx).Model(updated)
 	db = db.Omit(clause.Associations)
 	fields, err := secret.Encode(updated)
@@ -1356,6 +1394,253 @@ func (h AuthHandler) TokenRevoke(ctx *gin.Context) {
 	h.Status(ctx, http.StatusNoContent)
 }
 
+//
+// MFA handlers
+//
+
+// MfaEnroll godoc
+// @summary Begin MFA enrollment.
+// @description Begin TOTP enrollment of the current user.
+// @description Returns the secret and the otpauth URI to be added to
+// @description an authenticator app. The enrollment is not enabled until
+// @description confirmed using a code generated by the app.
+// @tags auth
+// @produce json
+// @success 201 {object} api.MfaEnrollment
+// @router /auth/mfa [post]
+func (h AuthHandler) MfaEnroll(ctx *gin.Context) {
+	user, err := h.currentUser(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	mfa := auth.Mfa{DB: h.DB(ctx), Cache: auth.Idp().Cache()}
+	key, err := mfa.Enroll(user.ID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := MfaEnrollment{
+		Secret: key,
+		URI:    auth.TotpURI(user.Login, key),
+	}
+
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// MfaConfirm godoc
+// @summary Confirm MFA enrollment.
+// @description Confirm TOTP enrollment of the current user.
+// @description Returns the recovery codes. Each may be used (once)
+// @description in place of a TOTP code. They are not returned again.
+// @tags auth
+// @accept json
+// @produce json
+// @success 200 {object} api.MfaEnrollment
+// @router /auth/mfa/confirm [post]
+// @param code body api.MfaCode true "TOTP code"
+func (h AuthHandler) MfaConfirm(ctx *gin.Context) {
+	r := &MfaCode{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	user, err := h.currentUser(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	mfa := auth.Mfa{DB: h.DB(ctx), Cache: auth.Idp().Cache()}
+	codes, err := mfa.Confirm(user.ID, r.Code)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	enrollment := MfaEnrollment{
+		RecoveryCodes: codes,
+	}
+
+	h.Respond(ctx, http.StatusOK, enrollment)
+}
+
+// MfaDelete godoc
+// @summary Remove MFA enrollment.
+// @description Remove the TOTP enrollment of the current user.
+// @description A current TOTP code or (unused) recovery code is required
+// @description when enrolled. A pending enrollment is removed without a code.
+// @tags auth
+// @accept json
+// @success 204
+// @router /auth/mfa [delete]
+// @param code body api.MfaCode false "TOTP or recovery code"
+func (h AuthHandler) MfaDelete(ctx *gin.Context) {
+	user, err := h.currentUser(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := &MfaCode{}
+	if ctx.Request.ContentLength > 0 {
+		err = h.Bind(ctx, r)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
+	mfa := auth.Mfa{DB: h.DB(ctx), Cache: auth.Idp().Cache()}
+	err = mfa.Remove(user.ID, r.Code)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// UserMfaDelete godoc
+// @summary Reset user MFA enrollment.
+// @description Remove the TOTP enrollment of a user.
+// @description Used when the authenticator or recovery codes are lost.
+// @tags users
+// @success 204
+// @router /users/{id}/mfa [delete]
+// @param id path int true "User ID"
+func (h AuthHandler) UserMfaDelete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	if !h.Admin(ctx) {
+		_ = ctx.Error(&Forbidden{
+			Reason: "Must be admin",
+		})
+		return
+	}
+	mfa := auth.Mfa{DB: h.DB(ctx), Cache: auth.Idp().Cache()}
+	err := mfa.Reset(id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// UserUnlock godoc
+// @summary Unlock a user.
+// @description Unlock a user locked after failed authentication attempts.
+// @description The failed attempts are reset.
+// @tags users
+// @success 204
+// @router /users/{id}/lock [delete]
+// @param id path int true "User ID"
+func (h AuthHandler) UserUnlock(ctx *gin.Context) {
+	id := h.pk(ctx)
+	if !h.Admin(ctx) {
+		_ = ctx.Error(&Forbidden{
+			Reason: "Must be admin",
+		})
+		return
+	}
+	lockout := auth.Lockout{DB: h.DB(ctx), Cache: auth.Idp().Cache()}
+	err := lockout.Unlock(id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// EventList godoc
+// @summary List authentication events.
+// @description List authentication (audit) events.
+// @description Filters:
+// @description - id
+// @description - createTime
+// @description - subject
+// @description - login
+// @description - method (password|ldap|idp|device)
+// @description - source
+// @description - succeeded
+// @tags auth
+// @produce json
+// @success 200 {object} []api.AuthEvent
+// @router /auth/events [get]
+func (h AuthHandler) EventList(ctx *gin.Context) {
+	resources := []AuthEvent{}
+	// filter
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "id", Kind: qf.LITERAL},
+			{Field: "createTime", Kind: qf.STRING},
+			{Field: "subject", Kind: qf.STRING},
+			{Field: "login", Kind: qf.STRING},
+			{Field: "method", Kind: qf.STRING},
+			{Field: "source", Kind: qf.STRING},
+			{Field: "succeeded", Kind: qf.LITERAL},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// sort
+	sort := Sort{}
+	err = sort.With(ctx, &model.AuthEvent{})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Fetch
+	db := h.DB(ctx)
+	db = db.Model(&model.AuthEvent{})
+	db = sort.Sorted(db)
+	db = filter.Where(db)
+	var m model.AuthEvent
+	var list []model.AuthEvent
+	page := Page{}
+	page.With(ctx)
+	cursor := Cursor{}
+	cursor.With(db, page)
+	defer func() {
+		cursor.Close()
+	}()
+	for cursor.Next(&m) {
+		if cursor.Error != nil {
+			_ = ctx.Error(cursor.Error)
+			return
+		}
+		list = append(list, m)
+	}
+	err = h.WithCount(ctx, cursor.Count())
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	for i := range list {
+		m := &list[i]
+		r := AuthEvent{}
+		r.With(m)
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// currentUser returns the (builtin) user for the current subject.
+func (h AuthHandler) currentUser(ctx *gin.Context) (user *auth.User, err error) {
+	subject, err := auth.Idp().Cache().FindSubject(h.CurrentSubject(ctx))
+	if err != nil {
+		return
+	}
+	if !subject.IsUser() {
+		err = &BadRequestError{
+			Reason: "MFA is supported for (builtin) users only.",
+		}
+		return
+	}
+	user = subject.User
+	return
+}
+
 // Login OIDC login.
 func (h AuthHandler) Login(ctx *gin.Context) {
 	authReqID := ctx.Query(auth.AuthRequestId)
@@ -1366,7 +1651,7 @@ func (h AuthHandler) Login(ctx *gin.Context) {
 			})
 		return
 	}
-	err := auth.Idp().Login(ctx.Writer, ctx.Request, authReqID)
+	err := auth.Idp().Login(ctx, authReqID)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -1434,6 +1719,7 @@ func (h AuthHandler) OIDC() func(*gin.Context) {
 
 		idpHandler := p.IdpHandler()
 		dagHandler := p.DagHandler()
+		tokenHandler := p.TokenHandler()
 
 		path := ctx.Param("path")
 		switch path {
@@ -1457,6 +1743,10 @@ func (h AuthHandler) OIDC() func(*gin.Context) {
 			dagHandler.OIDCAuth().Login(ctx)
 		case api.DeviceCbRoute:
 			dagHandler.OIDCAuth().Callback(ctx)
+		case api.IntrospectRoute:
+			tokenHandler.Introspect(ctx)
+		case api.RevokeRoute:
+			tokenHandler.Revoke(ctx)
 		default:
 			baseHandler := p.Handler()
 			http.StripPrefix(api.OIDCRoutes, baseHandler).ServeHTTP(ctx.Writer, ctx.Request)
@@ -1486,7 +1776,10 @@ type Role = resource.Role
 type Scope = resource.Scope
 type Grant = resource.Grant
 type Token = resource.Token
+type AuthEvent = resource.AuthEvent
 type PAT api.PAT
+type MfaEnrollment = api.MfaEnrollment
+type MfaCode = api.MfaCode
 
 // AuthSelf REST resource.
 type AuthSelf struct {
@@ -1522,37 +1815,71 @@ func Authenticate() func(ctx *gin.Context) {
 		}
 		rtx.User = result.User
 		rtx.Subject = result.Subject
+		rtx.SubjectKind = result.Kind
+		rtx.Task = result.Task
 		rtx.Scope.Granted = result.Scopes
 	}
 }
 
 // Required authenticates the user and enforces that
 // the user has been granted the required scope.
+// Constrained scopes are enforced using Constrain().
+// The API (request) limits are enforced for the route group (resource).
 func Required(resource string) func(*gin.Context) {
 	auth.Domain().Register(resource)
 	return func(ctx *gin.Context) {
-		Authenticate()(ctx)
+		require(ctx, resource)
 		if ctx.IsAborted() {
 			return
 		}
-		rtx := RichContext(ctx)
-		rtx.Scope.Required = auth.Scope{
-			Method:   ctx.Request.Method,
-			Resource: resource,
+		release := limited(ctx, resource)
+		if ctx.IsAborted() {
+			return
 		}
-		for _, granted := range rtx.Scope.Granted {
-			matched := granted.Match(resource, ctx.Request.Method)
-			if matched {
-				return
-			}
+		defer release()
+		ctx.Next()
+	}
+}
+
+// require authenticates the user and enforces that
+// the user has been granted the (registered) scope.
+func require(ctx *gin.Context, resource string) {
+	Authenticate()(ctx)
+	if ctx.IsAborted() {
+		return
+	}
+	rtx := RichContext(ctx)
+	rtx.Scope.Required = auth.Scope{
+		Method:   ctx.Request.Method,
+		Resource: resource,
+	}
+	rtx.Scope.Constraints = nil
+	var constraints []auth.Constraint
+	for _, granted := range rtx.Scope.Granted {
+		matched := granted.Match(resource, ctx.Request.Method)
+		if !matched {
+			continue
 		}
-		wanted := auth.Scope{
-			Resource: resource,
-			Method:   ctx.Request.Method,
+		if !granted.Constrained() {
+			return
 		}
-		_ = ctx.Error(&Forbidden{
-			Reason: "Required scope not granted: " + wanted.String(),
-		})
-		ctx.Abort()
+		constraints = append(constraints, granted.Constraint)
+	}
+	if len(constraints) > 0 {
+		rtx.Scope.Constraints = constraints
+		err := Constrain(ctx, resource)
+		if err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+		}
+		return
+	}
+	wanted := auth.Scope{
+		Resource: resource,
+		Method:   ctx.Request.Method,
 	}
+	_ = ctx.Error(&Forbidden{
+		Reason: "Required scope not granted: " + wanted.String(),
+	})
+	ctx.Abort()
 }
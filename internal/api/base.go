@@ -15,6 +15,7 @@ import (
 	"github.com/jortel/go-utils/logr"
 	"github.com/konveyor/tackle2-hub/internal/api/association"
 	"github.com/konveyor/tackle2-hub/internal/api/jsd"
+	"github.com/konveyor/tackle2-hub/internal/api/resource"
 	"github.com/konveyor/tackle2-hub/internal/api/sort"
 	"github.com/konveyor/tackle2-hub/internal/auth"
 	"github.com/konveyor/tackle2-hub/internal/secret"
@@ -93,6 +94,37 @@ func (h *BaseHandler) preLoad(db *gorm.DB, fields ...string) (tx *gorm.DB) {
 	return
 }
 
+// Expanded returns the relationship expansions requested (expand=)
+// and validated against the supported (json) field names.
+func (h *BaseHandler) Expanded(ctx *gin.Context, supported ...string) (expanded []string, err error) {
+	shape := Shape{}
+	shape.With(ctx.QueryArray(DirectoryExpand)...)
+	for _, name := range shape.Fields {
+		found := false
+		for _, s := range supported {
+			if s == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			err = &BadRequestError{
+				Reason: fmt.Sprintf("expand: '%s' not supported.", name),
+			}
+			return
+		}
+		expanded = append(expanded, name)
+	}
+	return
+}
+
+// Expand adds an expanded related resource.
+// The related resource replaces the Ref (field) in the rendered resource.
+func (h *BaseHandler) Expand(ctx *gin.Context, field string, id uint, related any) {
+	rtx := RichContext(ctx)
+	rtx.Shape.Expand(field, id, related)
+}
+
 // pk returns the PK (ID) parameter.
 func (h *BaseHandler) pk(ctx *gin.Context) (id uint) {
 	s := ctx.Param(ID)
@@ -341,6 +373,9 @@ func (p *Page) Paginated(in *gorm.DB) (out *gorm.DB) {
 // Sort provides sorting.
 type Sort = sort.Sort
 
+// Shape used to shape rendered resources.
+type Shape = resource.Shape
+
 // Decoder binding decoder.
 type Decoder interface {
 	Decode(r any) (err error)
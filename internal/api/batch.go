@@ -2,22 +2,53 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/internal/api/resource"
 	"github.com/konveyor/tackle2-hub/shared/api"
+	"gorm.io/gorm"
 )
 
-// BatchHandler handles batch resource creation routes.
+// BatchRef matches references to the (body) of the result
+// of a previous operation. Format: ${index.field.field}.
+var BatchRef = regexp.MustCompile(`\$\{(\d+)((\.[^.}]+)+)}`)
+
+// errBatchRollback signals that the batch transaction must be rolled back.
+var errBatchRollback = errors.New("batch rolled back")
+
+// batchKey request context key used to pass the batch transaction.
+type batchKey struct{}
+
+// batchCommittedKey request context key used to pass the functions
+// to be called after the batch transaction is committed.
+type batchCommittedKey struct{}
+
+// batchLimitedKey request context key used to mark operations
+// dispatched by a batch. The operations are charged against the
+// (rate) limits but are not in-flight requests.
+type batchLimitedKey struct{}
+
+// BatchHandler handles batch routes.
 type BatchHandler struct {
 	BaseHandler
+	// engine used to dispatch operations.
+	engine *gin.Engine
 }
 
 // AddRoutes adds routes.
 func (h BatchHandler) AddRoutes(e *gin.Engine) {
+	h.engine = e
 	routeGroup := e.Group("/")
+	routeGroup.POST(api.BatchRoute, Required("batch"), h.Create)
 	routeGroup.POST(api.BatchTicketsRoute, Required("tickets"), Transaction, h.TicketsCreate)
 	routeGroup.POST(api.BatchTagsRoute, Required("tags"), Transaction, h.TagsCreate)
 }
@@ -80,3 +111,347 @@ func (h BatchHandler) create(ctx *gin.Context, create gin.HandlerFunc) {
 		_ = ctx.Error(bErr)
 	}
 }
+
+// Create godoc
+// @summary Execute a batch of operations.
+// @description Execute a batch of operations (method, path, body) across resources.
+// @description Each operation is dispatched as a request and authorized (scopes)
+// @description the same as when requested individually.
+// @description Modes:
+// @description - atomic: (default) operations are executed in a single DB transaction.
+// @description   Execution stops on the first failed operation and the transaction is
+// @description   rolled back. Operations not executed are reported with status=424.
+// @description - item: each operation is executed (and committed) independently.
+// @description A (string) value in the path or body may reference the body of a previous
+// @description operation result using ${index.field}. Eg: ${0.id}.
+// @description When the entire string is a reference, the referenced value (type) is used.
+// @description Side effects outside the DB (Eg: files, buckets) are not rolled back.
+// @tags batch
+// @accept json
+// @produce json
+// @success 200 {object} api.Batch
+// @router /batch [post]
+// @param batch body api.Batch true "Batch data"
+func (h BatchHandler) Create(ctx *gin.Context) {
+	r := &Batch{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	switch r.Mode {
+	case "":
+		r.Mode = api.BatchAtomic
+	case api.BatchAtomic,
+		api.BatchItem:
+	default:
+		err = &BadRequestError{
+			Reason: "mode must be: (atomic|item).",
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	if r.Mode == api.BatchItem {
+		r.Committed = true
+		for i := range r.Operations {
+			result := h.execute(ctx, nil, nil, r.Operations, i)
+			if result.Status >= http.StatusBadRequest {
+				r.Committed = false
+			}
+		}
+		h.Respond(ctx, http.StatusOK, r)
+		return
+	}
+	committed := []func(){}
+	err = h.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		for i := range r.Operations {
+			result := h.execute(ctx, tx, &committed, r.Operations, i)
+			if result.Status >= http.StatusBadRequest {
+				err = errBatchRollback
+				return
+			}
+		}
+		return
+	})
+	switch {
+	case err == nil:
+		r.Committed = true
+		for _, fn := range committed {
+			fn()
+		}
+	case errors.Is(err, errBatchRollback):
+		for i := range r.Operations {
+			op := &r.Operations[i]
+			if op.Result == nil {
+				op.Result = &BatchResult{
+					Status: http.StatusFailedDependency,
+					Body: Map{
+						"error": "Not executed.",
+					},
+				}
+			}
+		}
+	default:
+		_ = ctx.Error(err)
+		return
+	}
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// execute dispatches the operation (by index) and sets the result.
+// The transaction (tx) is used by the operation when specified and the
+// functions to be called after it is committed are added to committed.
+func (h BatchHandler) execute(
+	ctx *gin.Context,
+	tx *gorm.DB,
+	committed *[]func(),
+	operations []BatchOperation,
+	index int) (result *BatchResult) {
+	op := &operations[index]
+	result = &BatchResult{}
+	op.Result = result
+	failed := func(status int, err error) {
+		result.Status = status
+		result.Body = Map{
+			"error": err.Error(),
+		}
+	}
+	request, err := h.request(ctx, operations, index)
+	if err != nil {
+		failed(http.StatusBadRequest, err)
+		return
+	}
+	rctx := context.WithValue(request.Context(), batchLimitedKey{}, true)
+	if tx != nil {
+		rctx = context.WithValue(rctx, batchKey{}, tx)
+		rctx = context.WithValue(rctx, batchCommittedKey{}, committed)
+	}
+	request = request.WithContext(rctx)
+	writer := &batchWriter{header: http.Header{}}
+	h.engine.ServeHTTP(writer, request)
+	result.Status = writer.status
+	if writer.body.Len() > 0 {
+		var body any
+		d := json.NewDecoder(&writer.body)
+		d.UseNumber()
+		err = d.Decode(&body)
+		if err != nil {
+			failed(http.StatusInternalServerError, err)
+			return
+		}
+		result.Body = body
+	}
+	return
+}
+
+// request builds the request for the operation (by index).
+// References to the results of previous operations are resolved.
+func (h BatchHandler) request(ctx *gin.Context, operations []BatchOperation, index int) (request *http.Request, err error) {
+	op := &operations[index]
+	method := strings.ToUpper(op.Method)
+	switch method {
+	case http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete:
+	default:
+		err = &BadRequestError{
+			Reason: "method: " + op.Method + " not supported.",
+		}
+		return
+	}
+	path, err := h.resolve(op.Path, operations, index)
+	if err != nil {
+		return
+	}
+	p, _ := path.(string)
+	if !strings.HasPrefix(p, "/") {
+		err = &BadRequestError{
+			Reason: "path: " + op.Path + " must be absolute.",
+		}
+		return
+	}
+	if p == api.BatchRoute || strings.HasPrefix(p, api.BatchRoute+"/") ||
+		strings.HasPrefix(p, api.BatchRoute+"?") {
+		err = &BadRequestError{
+			Reason: "path: " + op.Path + " not supported.",
+		}
+		return
+	}
+	var reader io.Reader = http.NoBody
+	if op.Body != nil {
+		var body any
+		body, err = h.resolve(op.Body, operations, index)
+		if err != nil {
+			return
+		}
+		var b []byte
+		b, err = json.Marshal(body)
+		if err != nil {
+			return
+		}
+		reader = bytes.NewReader(b)
+	}
+	request, err = http.NewRequestWithContext(
+		ctx.Request.Context(),
+		method,
+		p,
+		reader)
+	if err != nil {
+		err = &BadRequestError{Reason: err.Error()}
+		return
+	}
+	request.Host = ctx.Request.Host
+	request.RemoteAddr = ctx.Request.RemoteAddr
+	request.Header.Set(Authorization, ctx.GetHeader(Authorization))
+	request.Header.Set(ContentType, api.MIMEJSON)
+	request.Header.Set(Accept, api.MIMEJSON)
+	return
+}
+
+// resolve returns the value with references to the results
+// of previous operations replaced.
+func (h BatchHandler) resolve(in any, operations []BatchOperation, index int) (out any, err error) {
+	out = in
+	switch v := in.(type) {
+	case string:
+		matched := BatchRef.FindStringSubmatch(v)
+		if matched != nil && matched[0] == v {
+			out, err = h.lookup(matched, operations, index)
+			return
+		}
+		out = BatchRef.ReplaceAllStringFunc(
+			v,
+			func(ref string) (s string) {
+				if err != nil {
+					return
+				}
+				var value any
+				value, err = h.lookup(BatchRef.FindStringSubmatch(ref), operations, index)
+				if err != nil {
+					return
+				}
+				s = fmt.Sprint(value)
+				return
+			})
+	case map[string]any:
+		mp := make(map[string]any)
+		for k, x := range v {
+			mp[k], err = h.resolve(x, operations, index)
+			if err != nil {
+				return
+			}
+		}
+		out = mp
+	case []any:
+		list := make([]any, len(v))
+		for i, x := range v {
+			list[i], err = h.resolve(x, operations, index)
+			if err != nil {
+				return
+			}
+		}
+		out = list
+	}
+	return
+}
+
+// lookup returns the value referenced in the result of a previous operation.
+func (h BatchHandler) lookup(matched []string, operations []BatchOperation, index int) (value any, err error) {
+	notFound := func() {
+		err = &BadRequestError{
+			Reason: "reference: " + matched[0] + " not found.",
+		}
+	}
+	n, err := strconv.Atoi(matched[1])
+	if err != nil || n >= index {
+		notFound()
+		return
+	}
+	result := operations[n].Result
+	if result == nil || result.Status >= http.StatusBadRequest {
+		notFound()
+		return
+	}
+	value = result.Body
+	for _, field := range strings.Split(matched[2][1:], ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			x, found := v[field]
+			if !found {
+				notFound()
+				return
+			}
+			value = x
+		case []any:
+			i, nErr := strconv.Atoi(field)
+			if nErr != nil || i < 0 || i >= len(v) {
+				notFound()
+				return
+			}
+			value = v[i]
+		default:
+			notFound()
+			return
+		}
+	}
+	return
+}
+
+// BatchDB returns the DB to be used by the request.
+// Operations dispatched by an atomic batch use the
+// batch transaction.
+func BatchDB(ctx *gin.Context, db *gorm.DB) (out *gorm.DB) {
+	out = db
+	tx, found := ctx.Request.Context().Value(batchKey{}).(*gorm.DB)
+	if found {
+		out = tx
+	}
+	return
+}
+
+// BatchCommitted returns the functions to be called after the
+// batch transaction is committed. Nil when not in a batch transaction.
+func BatchCommitted(ctx *gin.Context) (committed *[]func()) {
+	committed, _ = ctx.Request.Context().Value(batchCommittedKey{}).(*[]func())
+	return
+}
+
+// batchWriter records the response of a batch operation.
+type batchWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header returns the header.
+func (w *batchWriter) Header() (h http.Header) {
+	h = w.header
+	return
+}
+
+// Write the body.
+func (w *batchWriter) Write(b []byte) (n int, err error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err = w.body.Write(b)
+	return
+}
+
+// WriteHeader writes the status.
+func (w *batchWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Batch REST resource.
+type Batch = resource.Batch
+
+// BatchOperation REST nested resource.
+type BatchOperation = resource.BatchOperation
+
+// BatchResult REST nested resource.
+type BatchResult = resource.BatchResult
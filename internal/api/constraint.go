@@ -0,0 +1,252 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/internal/auth"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"gorm.io/gorm"
+)
+
+// constrainedRoutes collection routes (method and path) on which
+// the handlers apply the constraints.
+var constrainedRoutes = map[string]bool{
+	"GET " + api.ApplicationsRoute:       true,
+	"GET " + api.ApplicationsRoute + "/": true,
+	"POST " + api.ApplicationsRoute:      true,
+	"DELETE " + api.ApplicationsRoute:    true,
+	"GET " + api.AnalysesRoute:           true,
+	"GET " + api.AssessmentsRoute:        true,
+	"GET " + api.AssessmentsRoute + "/":  true,
+}
+
+// Constrain enforces the constraints of the (constrained) scopes granted
+// for the resource. Constraints restrict access to applications and the
+// resources they own.
+// Instance routes: the (owning) application must be permitted.
+// Collection routes: must be supported (constrained) by the handler.
+func Constrain(ctx *gin.Context, resource string) (err error) {
+	db := RichContext(ctx).DB
+	route := ctx.FullPath()
+	var appId *uint
+	switch resource {
+	case "analyses":
+		switch route {
+		case api.AnalysisRoute,
+			api.AnalysisArchiveRoute,
+			api.AnalysisInsightsRoute:
+			m := &model.Analysis{}
+			err = db.Select("ApplicationID").First(m, ctx.Param(ID)).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					err = nil
+				}
+				return
+			}
+			appId = &m.ApplicationID
+		}
+	case "assessments":
+		switch route {
+		case api.AssessmentRoute,
+			api.AssessmentUpgradeRoute:
+			m := &model.Assessment{}
+			err = db.Select("ApplicationID").First(m, ctx.Param(ID)).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					err = nil
+				}
+				return
+			}
+			if m.ApplicationID == nil {
+				err = &Forbidden{
+					Reason: "Constrained scope: not an application assessment.",
+				}
+				return
+			}
+			appId = m.ApplicationID
+		}
+	default:
+		if strings.HasPrefix(route, api.ApplicationRoute) {
+			n, pErr := strconv.ParseUint(ctx.Param(ID), 10, 0)
+			if pErr != nil {
+				err = &BadRequestError{Reason: "id must be an integer"}
+				return
+			}
+			id := uint(n)
+			appId = &id
+		}
+	}
+	if appId != nil {
+		constraint := AppConstraint{ctx: ctx}
+		err = constraint.Permitted(*appId)
+		return
+	}
+	if !constrainedRoutes[ctx.Request.Method+" "+route] {
+		err = &Forbidden{
+			Reason: "Constrained scope not supported by route: " + route,
+		}
+	}
+	return
+}
+
+// AppConstraint applies the constraints of the (constrained) scopes
+// granted for the request to applications.
+type AppConstraint struct {
+	ctx *gin.Context
+}
+
+// Constrained returns true when the request is constrained.
+func (r *AppConstraint) Constrained() (b bool) {
+	b = len(RichContext(r.ctx).Scope.Constraints) > 0
+	return
+}
+
+// Where returns the query restricted to permitted applications.
+// The column is the (application) ID column.
+func (r *AppConstraint) Where(db *gorm.DB, column string) (q *gorm.DB) {
+	q = db
+	if !r.Constrained() {
+		return
+	}
+	q = q.Where(column+" IN (?)", r.appIds())
+	return
+}
+
+// Permitted returns Forbidden when the (existing) application
+// is not permitted.
+func (r *AppConstraint) Permitted(id uint) (err error) {
+	if !r.Constrained() {
+		return
+	}
+	db := RichContext(r.ctx).DB
+	var found []uint
+	q := db.Model(&model.Application{})
+	q = q.Where("ID", id)
+	err = q.Pluck("ID", &found).Error
+	if err != nil || len(found) == 0 {
+		return
+	}
+	found = nil
+	q = db.Model(&model.Application{})
+	q = q.Where("ID", id)
+	q = q.Where("ID IN (?)", r.appIds())
+	err = q.Pluck("ID", &found).Error
+	if err != nil {
+		return
+	}
+	if len(found) == 0 {
+		err = r.forbidden()
+	}
+	return
+}
+
+// Permit returns Forbidden when the application (model) is not permitted.
+// Used to constrain created and updated applications.
+func (r *AppConstraint) Permit(m *model.Application) (err error) {
+	if !r.Constrained() {
+		return
+	}
+	me, err := r.me()
+	if err != nil {
+		return
+	}
+	isMe := func(id uint) (b bool) {
+		for _, n := range me {
+			if n == id {
+				b = true
+				break
+			}
+		}
+		return
+	}
+	for _, c := range RichContext(r.ctx).Scope.Constraints {
+		switch c.Field {
+		case auth.ConstraintBusinessService:
+			if m.BusinessServiceID == nil {
+				continue
+			}
+			ids, _ := c.IDs()
+			for _, id := range ids {
+				if id == *m.BusinessServiceID {
+					return
+				}
+			}
+		case auth.ConstraintOwner:
+			if m.OwnerID != nil && isMe(*m.OwnerID) {
+				return
+			}
+		case auth.ConstraintContributor:
+			for _, contributor := range m.Contributors {
+				if isMe(contributor.ID) {
+					return
+				}
+			}
+		}
+	}
+	err = r.forbidden()
+	return
+}
+
+// appIds returns a query selecting the IDs of permitted applications.
+// The constraints are OR'ed.
+func (r *AppConstraint) appIds() (q *gorm.DB) {
+	rtx := RichContext(r.ctx)
+	db := rtx.DB
+	var clauses []string
+	var args []any
+	for _, c := range rtx.Scope.Constraints {
+		switch c.Field {
+		case auth.ConstraintBusinessService:
+			ids, _ := c.IDs()
+			clauses = append(clauses, "BusinessServiceID IN (?)")
+			args = append(args, ids)
+		case auth.ConstraintOwner:
+			clauses = append(clauses, "OwnerID IN (?)")
+			args = append(args, r.stakeholders())
+		case auth.ConstraintContributor:
+			cq := db.Table("ApplicationContributors")
+			cq = cq.Select("ApplicationID")
+			cq = cq.Where("StakeholderID IN (?)", r.stakeholders())
+			clauses = append(clauses, "ID IN (?)")
+			args = append(args, cq)
+		}
+	}
+	q = db.Model(&model.Application{})
+	q = q.Select("ID")
+	q = q.Where(strings.Join(clauses, " OR "), args...)
+	return
+}
+
+// stakeholders returns a query selecting the IDs of the
+// stakeholders representing the user (me).
+func (r *AppConstraint) stakeholders() (q *gorm.DB) {
+	rtx := RichContext(r.ctx)
+	db := rtx.DB
+	q = db.Model(&model.Stakeholder{})
+	q = q.Select("ID")
+	q = q.Where("Email <> ''")
+	q = q.Where(
+		"Email IN (?) OR Email IN (?)",
+		db.Model(&model.User{}).Select("Email").Where("Subject", rtx.Subject),
+		db.Model(&model.IdpIdentity{}).Select("Email").Where("Subject", rtx.Subject))
+	return
+}
+
+// me returns the IDs of the stakeholders representing the user.
+func (r *AppConstraint) me() (ids []uint, err error) {
+	err = r.stakeholders().Pluck("ID", &ids).Error
+	return
+}
+
+// forbidden returns a Forbidden error.
+func (r *AppConstraint) forbidden() (err error) {
+	rtx := RichContext(r.ctx)
+	err = &Forbidden{
+		Reason: "Constrained scope not satisfied: " + rtx.Scope.Required.String(),
+	}
+	return
+}
@@ -2,17 +2,21 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"reflect"
 	"strings"
 	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
+	"github.com/konveyor/tackle2-hub/internal/api/resource"
 	"github.com/konveyor/tackle2-hub/internal/auth"
 	"github.com/konveyor/tackle2-hub/internal/heap"
+	"github.com/konveyor/tackle2-hub/internal/secret"
 	tasking "github.com/konveyor/tackle2-hub/internal/task"
 	"gopkg.in/yaml.v2"
 	"gorm.io/gorm"
@@ -37,17 +41,33 @@ type Context struct {
 	Subject string
 	// User
 	User string
+	// Subject kind: user|serviceaccount|task.
+	SubjectKind string
+	// Task to which the (task) token is bound.
+	Task uint
 	// Scope
 	Scope struct {
 		Required auth.Scope
 		Granted  []auth.Scope
+		// Constraints of the (constrained) granted scopes
+		// matching the required scope.
+		Constraints []auth.Constraint
 	}
 	// k8s Client
 	Client client.Client
 	// Response
 	Response Response
+	// Shape of the rendered resources.
+	Shape resource.Shape
 	// Task manager.
 	TaskManager *tasking.Manager
+	// Import manager.
+	ImportManager ImportWaker
+	// Secret (key) rotator.
+	SecretRotator *secret.Rotator
+	// committed functions called after the
+	// (request) transaction is committed.
+	committed *[]func()
 }
 
 // Attach to gin context.
@@ -82,6 +102,17 @@ func (r *Context) Respond(status int, body any) {
 	}
 }
 
+// AfterCommit calls the function after the (request) transaction
+// has been committed. Not called when the transaction is rolled back.
+// Called immediately when not in a transaction.
+func (r *Context) AfterCommit(fn func()) {
+	if r.committed == nil {
+		fn()
+		return
+	}
+	*r.committed = append(*r.committed, fn)
+}
+
 // RichContext returns a rich context attached to the gin context.
 func RichContext(ctx *gin.Context) (rtx *Context) {
 	key := "RichContext"
@@ -97,6 +128,9 @@ func RichContext(ctx *gin.Context) (rtx *Context) {
 }
 
 // Transaction handler.
+// The functions registered using AfterCommit are called after the
+// transaction is committed. Within a (atomic) batch, they are called
+// after the batch transaction is committed.
 func Transaction(ctx *gin.Context) {
 	switch ctx.Request.Method {
 	case http.MethodPost,
@@ -104,6 +138,14 @@ func Transaction(ctx *gin.Context) {
 		http.MethodPatch,
 		http.MethodDelete:
 		rtx := RichContext(ctx)
+		committed := BatchCommitted(ctx)
+		if committed == nil {
+			committed = &[]func(){}
+		}
+		rtx.committed = committed
+		defer func() {
+			rtx.committed = nil
+		}()
 		err := rtx.DB.Transaction(func(tx *gorm.DB) (err error) {
 			db := rtx.DB
 			rtx.DB = tx
@@ -119,6 +161,12 @@ func Transaction(ctx *gin.Context) {
 		})
 		if err != nil {
 			_ = ctx.Error(err)
+			return
+		}
+		if BatchCommitted(ctx) == nil {
+			for _, fn := range *committed {
+				fn()
+			}
 		}
 	}
 }
@@ -135,8 +183,10 @@ type Renderer struct{}
 // Render renders the response based on the Accept: header.
 // Opinionated towards json.
 func (r Renderer) Render(ctx *gin.Context) {
-	ctx.Next()
 	rtx := RichContext(ctx)
+	rtx.Shape = resource.Shape{}
+	rtx.Shape.With(ctx.QueryArray(Fields)...)
+	ctx.Next()
 	body := rtx.Response.Body
 	if body == nil {
 		ctx.Status(rtx.Response.Status)
@@ -156,11 +206,16 @@ func (r Renderer) Render(ctx *gin.Context) {
 		case reflect.Slice:
 			r.renderSlice(ctx, bv)
 		default:
+			shaped, err := rtx.Shape.Shaped(body)
+			if err != nil {
+				_ = ctx.Error(err)
+				return
+			}
 			ctx.Negotiate(
 				rtx.Response.Status,
 				gin.Negotiate{
 					Offered: BindMIMEs,
-					Data:    body})
+					Data:    shaped})
 		}
 	}
 }
@@ -181,6 +236,7 @@ func (r Renderer) renderIterator(ctx *gin.Context, iter Iterator) {
 		_ = ctx.Error(err)
 		return
 	}
+	shape := RichContext(ctx).Shape
 	encoder.beginList()
 	for i := 0; ; i++ {
 		next, object := iter.Next()
@@ -191,6 +247,11 @@ func (r Renderer) renderIterator(ctx *gin.Context, iter Iterator) {
 			_ = ctx.Error(iter.Error)
 			return
 		}
+		object, err = shape.Shaped(object)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
 		encoder.writeItem(0, i, object)
 	}
 	encoder.endList()
@@ -199,6 +260,7 @@ func (r Renderer) renderIterator(ctx *gin.Context, iter Iterator) {
 		_ = ctx.Error(err)
 		return
 	}
+	r.tabular(ctx)
 	ctx.File(file.Name())
 }
 
@@ -218,10 +280,15 @@ func (r Renderer) renderSlice(ctx *gin.Context, bv reflect.Value) {
 		_ = ctx.Error(err)
 		return
 	}
+	shape := RichContext(ctx).Shape
 	encoder.beginList()
 	for i := 0; i < bv.Len(); i++ {
 		v := bv.Index(i)
-		object := v.Interface()
+		object, err := shape.Shaped(v.Interface())
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
 		encoder.writeItem(0, i, object)
 	}
 	encoder.endList()
@@ -230,9 +297,27 @@ func (r Renderer) renderSlice(ctx *gin.Context, bv reflect.Value) {
 		_ = ctx.Error(err)
 		return
 	}
+	r.tabular(ctx)
 	ctx.File(file.Name())
 }
 
+// tabular sets the headers for tabular (csv|xlsx) rendering.
+// The spreadsheet is rendered as an attachment named for the
+// last segment of the path.
+func (r Renderer) tabular(ctx *gin.Context) {
+	accepted := ctx.NegotiateFormat(RenderMIMEs...)
+	switch accepted {
+	case MIMECSV:
+		ctx.Header(ContentType, MIMECSV)
+	case MIMEXLSX:
+		ctx.Header(ContentType, MIMEXLSX)
+		name := path.Base(ctx.Request.URL.Path) + ".xlsx"
+		ctx.Header(
+			"Content-Disposition",
+			fmt.Sprintf("attachment; filename=\"%s\"", name))
+	}
+}
+
 // NewIterator returns an iterator.
 func NewIterator(m any, cursor *Cursor, builder Builder) (iter Iterator) {
 	iter = Iterator{
@@ -344,7 +429,7 @@ func (r *Iterator) id(m any) (id uint) {
 
 // NewEncoder returns an Encoder.
 func NewEncoder(ctx *gin.Context, output io.Writer) (encoder Encoder, err error) {
-	accepted := ctx.NegotiateFormat(BindMIMEs...)
+	accepted := ctx.NegotiateFormat(RenderMIMEs...)
 	switch accepted {
 	case "",
 		binding.MIMEPOSTForm,
@@ -352,6 +437,10 @@ func NewEncoder(ctx *gin.Context, output io.Writer) (encoder Encoder, err error)
 		encoder = &jsonEncoder{output: output}
 	case binding.MIMEYAML:
 		encoder = &yamlEncoder{output: output}
+	case MIMECSV:
+		encoder = &csvEncoder{output: output}
+	case MIMEXLSX:
+		encoder = &xlsxEncoder{output: output}
 	default:
 		err = &BadRequestError{Reason: "MIME not supported."}
 	}
@@ -3,8 +3,11 @@ package api
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -12,8 +15,10 @@ import (
 	"github.com/konveyor/tackle2-hub/internal/api/resource"
 	"github.com/konveyor/tackle2-hub/internal/api/sort"
 	"github.com/konveyor/tackle2-hub/internal/auth"
+	"github.com/konveyor/tackle2-hub/internal/identity"
 	"github.com/konveyor/tackle2-hub/internal/jsd"
 	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/internal/secret"
 	tasking "github.com/konveyor/tackle2-hub/internal/task"
 	"github.com/konveyor/tackle2-hub/shared/command"
 	"github.com/mattn/go-sqlite3"
@@ -103,6 +108,42 @@ func (r *TrackerError) Is(err error) (matched bool) {
 	return
 }
 
+// IdempotencyError reports an idempotency key that cannot be
+// used for the request.
+type IdempotencyError struct {
+	Key    string
+	Reason string
+	// InProgress the original request is still in progress.
+	InProgress bool
+}
+
+func (r *IdempotencyError) Error() string {
+	return fmt.Sprintf("Idempotency-Key '%s': %s", r.Key, r.Reason)
+}
+
+func (r *IdempotencyError) Is(err error) (matched bool) {
+	var target *IdempotencyError
+	matched = errors.As(err, &target)
+	return
+}
+
+// TooManyRequests reports API (request) limit exceeded errors.
+type TooManyRequests struct {
+	Reason string
+	// RetryAfter the duration after which the request may be retried.
+	RetryAfter time.Duration
+}
+
+func (r *TooManyRequests) Error() string {
+	return r.Reason
+}
+
+func (r *TooManyRequests) Is(err error) (matched bool) {
+	var target *TooManyRequests
+	matched = errors.As(err, &target)
+	return
+}
+
 // ErrorHandler handles error conditions from lower handlers.
 func ErrorHandler() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -121,6 +162,7 @@ func ErrorHandler() gin.HandlerFunc {
 			errors.Is(err, &resource.ValidationError{}) ||
 			errors.Is(err, &filter.Error{}) ||
 			errors.Is(err, &sort.SortError{}) ||
+			errors.Is(err, &ScimFilterError{}) ||
 			errors.Is(err, validator.ValidationErrors{}) {
 			rtx.Respond(
 				http.StatusBadRequest,
@@ -132,6 +174,7 @@ func ErrorHandler() gin.HandlerFunc {
 
 		if errors.Is(err, gorm.ErrRecordNotFound) ||
 			errors.Is(err, &NotFound{}) ||
+			errors.Is(err, &secret.KeyNotFound{}) ||
 			errors.Is(err, &auth.NotFound{}) ||
 			errors.Is(err, &jsd.NotFound{}) {
 			if ctx.Request.Method == http.MethodDelete {
@@ -155,7 +198,10 @@ func ErrorHandler() gin.HandlerFunc {
 			return
 		}
 
-		if errors.Is(err, model.DependencyCyclicError{}) {
+		if errors.Is(err, model.DependencyCyclicError{}) ||
+			errors.Is(err, &secret.KeyInUse{}) ||
+			errors.Is(err, &secret.RotationInProgress{}) ||
+			errors.Is(err, &identity.NotResolved{}) {
 			rtx.Respond(
 				http.StatusConflict,
 				gin.H{
@@ -201,6 +247,33 @@ func ErrorHandler() gin.HandlerFunc {
 			return
 		}
 
+		tErr := &TooManyRequests{}
+		if errors.As(err, &tErr) {
+			retryAfter := math.Ceil(tErr.RetryAfter.Seconds())
+			retryAfter = max(retryAfter, 1)
+			ctx.Header(RetryAfter, strconv.Itoa(int(retryAfter)))
+			rtx.Respond(
+				http.StatusTooManyRequests,
+				gin.H{
+					"error": err.Error(),
+				})
+			return
+		}
+
+		iErr := &IdempotencyError{}
+		if errors.As(err, &iErr) {
+			status := http.StatusUnprocessableEntity
+			if iErr.InProgress {
+				status = http.StatusConflict
+			}
+			rtx.Respond(
+				status,
+				gin.H{
+					"error": err.Error(),
+				})
+			return
+		}
+
 		sqliteErr := &sqlite3.Error{}
 		if errors.As(err, sqliteErr) {
 			switch sqliteErr.ExtendedCode {
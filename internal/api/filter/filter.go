@@ -1,6 +1,8 @@
 package filter
 
 import (
+	"fmt"
+	"path"
 	"strconv"
 	"strings"
 
@@ -13,6 +15,14 @@ const (
 	QueryParam = api.Filter
 )
 
+// Assert kinds.
+const (
+	// JSON document field.
+	// Predicates are expressed as: field.path
+	// Example: facts.java.version>=11
+	JSON = 0x10
+)
+
 // New filter.
 func New(ctx *gin.Context, assertions []Assert) (f Filter, err error) {
 	p := Parser{}
@@ -48,9 +58,27 @@ func (f *Filter) Validate(assertions []Assert) (err error) {
 		}
 		return
 	}
+	findJson := func(name string) (assert *Assert, found bool) {
+		name = strings.ToLower(name)
+		for i := range assertions {
+			assert = &assertions[i]
+			if assert.Kind != JSON {
+				continue
+			}
+			prefix := strings.ToLower(assert.Field) + "."
+			if strings.HasPrefix(name, prefix) {
+				found = true
+				break
+			}
+		}
+		return
+	}
 	for _, p := range f.predicates {
 		name := p.Field.Value
 		v, found := find(name)
+		if !found {
+			v, found = findJson(name)
+		}
 		if !found {
 			err = Errorf("'%s' not supported.", name)
 			return
@@ -103,6 +131,14 @@ func (f *Filter) Resource(r string) (filter Filter) {
 	return
 }
 
+// List returns all fields.
+func (f *Filter) List() (fields []Field) {
+	for _, p := range f.predicates {
+		fields = append(fields, Field{p})
+	}
+	return
+}
+
 // Where applies (root) fields to the where clause.
 func (f *Filter) Where(in *gorm.DB, selector ...string) (out *gorm.DB) {
 	out = in
@@ -116,6 +152,19 @@ func (f *Filter) Where(in *gorm.DB, selector ...string) (out *gorm.DB) {
 	return
 }
 
+// JsonWhere applies fields to the where clause as paths
+// within the JSON document stored in the specified column.
+// The filter is expected to be scoped to the document.
+// See: Resource().
+func (f *Filter) JsonWhere(in *gorm.DB, column string) (out *gorm.DB) {
+	out = in
+	for _, p := range f.predicates {
+		field := Field{p}
+		out = field.JsonWhere(out, column)
+	}
+	return
+}
+
 // With return filter with selected predicates.
 func (f *Filter) With(selector ...string) (out Filter) {
 	fs := FieldSelector(selector)
@@ -315,6 +364,210 @@ func (f *Field) SQL() (s string, vList []any) {
 	return
 }
 
+// JsonWhere updates the where clause.
+// The field name is a path within the JSON document stored
+// in the specified column.
+func (f *Field) JsonWhere(in *gorm.DB, column string) (out *gorm.DB) {
+	out = in
+	sql, values := f.JsonSQL(column)
+	if sql == "" {
+		return
+	}
+	out = in.Where(sql, values...)
+	return
+}
+
+// JsonSQL builds SQL for a path within the JSON document stored
+// in the specified column. The comparison is type-aware: the value
+// determines the (json) type matched.
+//   - numbers match integer and real.
+//   - true|false match boolean.
+//   - null matches null.
+//   - everything else (including quoted strings) match text.
+//
+// Returns statement and values (for ?).
+func (f *Field) JsonSQL(column string) (s string, vList []any) {
+	path := f.JsonPath()
+	switch len(f.Value) {
+	case 0:
+	case 1:
+		s, vList = f.jsonClause(column, path, f.Value[0])
+	default:
+		var clauses []string
+		for _, fx := range f.Expand() {
+			sql, values := fx.jsonClause(column, path, fx.Value[0])
+			vList = append(vList, values...)
+			clauses = append(clauses, sql)
+		}
+		switch {
+		case f.Value.Operator(AND),
+			f.Operator.Value == string(NOT)+string(EQ):
+			s = "(" + strings.Join(clauses, " AND ") + ")"
+		default:
+			s = "(" + strings.Join(clauses, " OR ") + ")"
+		}
+	}
+	return
+}
+
+// Match returns true when the (in-memory) value matches the predicate.
+// Used for values not stored in the DB (computed). Numeric values are
+// compared numerically; everything else is compared as strings.
+func (f *Field) Match(v any) (matched bool) {
+	switch len(f.Value) {
+	case 0:
+	case 1:
+		matched = f.match(v, f.Value[0])
+	default:
+		if f.Value.Operator(AND) {
+			// not supported.
+			break
+		}
+		for _, fx := range f.Expand() {
+			switch f.Operator.Value {
+			case string(NOT) + string(EQ):
+				fx.Operator.Value = string(EQ)
+				if fx.match(v, fx.Value[0]) {
+					return
+				}
+				matched = true
+			default:
+				if fx.match(v, fx.Value[0]) {
+					matched = true
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
+// match returns true when the value matches a single token.
+func (f *Field) match(v any, t Token) (matched bool) {
+	n := 0
+	switch x := v.(type) {
+	case int:
+		v = float64(x)
+	case int64:
+		v = float64(x)
+	case uint:
+		v = float64(x)
+	}
+	switch x := v.(type) {
+	case float64:
+		wanted, err := strconv.ParseFloat(t.Value, 64)
+		if err != nil || t.Kind == STRING {
+			return
+		}
+		switch {
+		case x < wanted:
+			n = -1
+		case x > wanted:
+			n = 1
+		}
+	default:
+		s := fmt.Sprint(x)
+		if f.Operator.Value == string(LIKE) {
+			pattern := strings.ToLower(t.Value)
+			matched, _ = path.Match(pattern, strings.ToLower(s))
+			return
+		}
+		n = strings.Compare(s, t.Value)
+	}
+	switch f.operator() {
+	case "=":
+		matched = n == 0
+	case "!=":
+		matched = n != 0
+	case "<":
+		matched = n < 0
+	case ">":
+		matched = n > 0
+	case "<=":
+		matched = n <= 0
+	case ">=":
+		matched = n >= 0
+	}
+	return
+}
+
+// JsonPath returns the field name as a (sqlite) JSON path.
+// Each segment is quoted; numeric segments are array indexes.
+// Segments containing (") are rejected by Validate().
+// The (.) separator is escaped when preceded by (\).
+// Example: java.version => $."java"."version"
+func (f *Field) JsonPath() (path string) {
+	path = "$"
+	var segments []string
+	var bfr []byte
+	s := f.Field.Value
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch ch {
+		case ESCAPE:
+			if i+1 < len(s) && s[i+1] == '.' {
+				bfr = append(bfr, '.')
+				i++
+			} else {
+				bfr = append(bfr, ch)
+			}
+		case '.':
+			segments = append(segments, string(bfr))
+			bfr = nil
+		default:
+			bfr = append(bfr, ch)
+		}
+	}
+	if len(bfr) > 0 {
+		segments = append(segments, string(bfr))
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		_, err := strconv.Atoi(segment)
+		if err == nil {
+			path += "[" + segment + "]"
+		} else {
+			path += ".\"" + segment + "\""
+		}
+	}
+	return
+}
+
+// jsonClause returns a type-aware clause for a single value.
+func (f *Field) jsonClause(column, path string, t Token) (s string, vList []any) {
+	extract := "json_extract(" + column + ",?)"
+	kind := "json_type(" + column + ",?)"
+	operator := f.operator()
+	if f.Operator.Value == string(LIKE) {
+		v := strings.Replace(t.Value, "*", "%", -1)
+		s = "(" + kind + " = 'text' AND " + extract + " LIKE ?)"
+		vList = append(vList, path, path, v)
+		return
+	}
+	v := JsonValue(t)
+	switch v.(type) {
+	case nil:
+		switch operator {
+		case "!=":
+			s = "(" + kind + " != 'null')"
+		default:
+			s = "(" + kind + " = 'null')"
+		}
+		vList = append(vList, path)
+		return
+	case int, float64:
+		s = "(" + kind + " IN ('integer','real') AND " + extract + " " + operator + " ?)"
+	case bool:
+		s = "(" + kind + " IN ('true','false') AND " + extract + " " + operator + " ?)"
+	default:
+		s = "(" + kind + " = 'text' AND " + extract + " " + operator + " ?)"
+	}
+	vList = append(vList, path, path, v)
+	return
+}
+
 // Expand flattens a multi-value field and returns a Field for each value.
 func (f *Field) Expand() (expanded []Field) {
 	for _, v := range f.Value.ByKind(LITERAL, STRING) {
@@ -379,6 +632,15 @@ type Assert struct {
 func (r *Assert) assert(p *Predicate) (err error) {
 	name := p.Field.Value
 	switch r.Kind {
+	case JSON:
+		if strings.EqualFold(name, r.Field) {
+			err = Errorf("'%s' requires a path. Example: %s.name", name, name)
+			return
+		}
+		if strings.Contains(name, "\"") {
+			err = Errorf("'%s' path cannot contain (\").", name)
+			return
+		}
 	case LITERAL:
 		switch p.Operator.Value {
 		case string(LIKE):
@@ -415,3 +677,34 @@ func AsValue(t Token) (object any) {
 	}
 	return
 }
+
+// JsonValue returns the real value used to match a JSON document value.
+// Quoted strings are always strings.
+func JsonValue(t Token) (object any) {
+	v := t.Value
+	object = v
+	switch t.Kind {
+	case LITERAL:
+		if v == "null" {
+			object = nil
+			break
+		}
+		n, err := strconv.Atoi(v)
+		if err == nil {
+			object = n
+			break
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			object = f
+			break
+		}
+		b, err := strconv.ParseBool(v)
+		if err == nil {
+			object = b
+			break
+		}
+	default:
+	}
+	return
+}
@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/onsi/gomega"
@@ -372,3 +373,136 @@ func TestFilterWith(t *testing.T) {
 	g.Expect(hasAge).To(gomega.BeTrue())
 	g.Expect(hasCat).To(gomega.BeFalse())
 }
+
+func TestJson(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	p := Parser{}
+	filter, err := p.Filter("facts.java.version>=11,coordinates.content.namespace=payments")
+	g.Expect(err).To(gomega.BeNil())
+	err = filter.Validate(
+		[]Assert{
+			{Field: "name", Kind: STRING},
+			{Field: "facts", Kind: JSON},
+			{Field: "coordinates", Kind: JSON},
+		})
+	g.Expect(err).To(gomega.BeNil())
+
+	facts := filter.Resource("facts")
+	fields := facts.List()
+	g.Expect(len(fields)).To(gomega.Equal(1))
+	f := fields[0]
+	g.Expect(f.Resource()).To(gomega.Equal("java"))
+	g.Expect(f.JsonPath()).To(gomega.Equal(`$."java"."version"`))
+	f = f.As(f.Name())
+	g.Expect(f.JsonPath()).To(gomega.Equal(`$."version"`))
+	sql, values := f.JsonSQL("Value")
+	g.Expect(sql).To(gomega.Equal(
+		"(json_type(Value,?) IN ('integer','real') AND json_extract(Value,?) >= ?)"))
+	g.Expect(values).To(gomega.Equal([]any{`$."version"`, `$."version"`, 11}))
+
+	coordinates := filter.Resource("coordinates")
+	f, found := coordinates.Field("content.namespace")
+	g.Expect(found).To(gomega.BeTrue())
+	sql, values = f.JsonSQL("Coordinates")
+	g.Expect(sql).To(gomega.Equal(
+		"(json_type(Coordinates,?) = 'text' AND json_extract(Coordinates,?) = ?)"))
+	g.Expect(values).To(gomega.Equal(
+		[]any{`$."content"."namespace"`, `$."content"."namespace"`, "payments"}))
+
+	filter, err = p.Filter("content.ports.0=8080,content.a\\.b='8080'")
+	g.Expect(err).To(gomega.BeNil())
+	content := filter.Resource("content")
+	fields = content.List()
+	g.Expect(len(fields)).To(gomega.Equal(2))
+	g.Expect(fields[0].JsonPath()).To(gomega.Equal(`$."ports"[0]`))
+	g.Expect(fields[1].JsonPath()).To(gomega.Equal(`$."a.b"`))
+	sql, values = fields[1].JsonSQL("Content")
+	g.Expect(sql).To(gomega.Equal(
+		"(json_type(Content,?) = 'text' AND json_extract(Content,?) = ?)"))
+	g.Expect(values[2]).To(gomega.Equal("8080"))
+
+	filter, err = p.Filter("facts.spring.boot~1.*,facts.spring.enabled=true,facts.x!=(1|2)")
+	g.Expect(err).To(gomega.BeNil())
+	facts = filter.Resource("facts")
+	fields = facts.List()
+	sql, values = fields[0].JsonSQL("Value")
+	g.Expect(sql).To(gomega.Equal(
+		"(json_type(Value,?) = 'text' AND json_extract(Value,?) LIKE ?)"))
+	g.Expect(values[2]).To(gomega.Equal("1.%"))
+	sql, values = fields[1].JsonSQL("Value")
+	g.Expect(sql).To(gomega.Equal(
+		"(json_type(Value,?) IN ('true','false') AND json_extract(Value,?) = ?)"))
+	g.Expect(values[2]).To(gomega.Equal(true))
+	sql, values = fields[2].JsonSQL("Value")
+	g.Expect(sql).To(gomega.Equal(
+		"((json_type(Value,?) IN ('integer','real') AND json_extract(Value,?) != ?)" +
+			" AND (json_type(Value,?) IN ('integer','real') AND json_extract(Value,?) != ?))"))
+	g.Expect(len(values)).To(gomega.Equal(6))
+
+	// AND list.
+	filter, err = p.Filter("facts.x=(1,2)")
+	g.Expect(err).To(gomega.BeNil())
+	err = filter.Validate([]Assert{{Field: "facts", Kind: JSON, And: true}})
+	g.Expect(err).To(gomega.BeNil())
+	facts = filter.Resource("facts")
+	fields = facts.List()
+	sql, values = fields[0].JsonSQL("Value")
+	g.Expect(sql).To(gomega.Equal(
+		"((json_type(Value,?) IN ('integer','real') AND json_extract(Value,?) = ?)" +
+			" AND (json_type(Value,?) IN ('integer','real') AND json_extract(Value,?) = ?))"))
+	g.Expect(len(values)).To(gomega.Equal(6))
+
+	// quote in path.
+	filter, err = p.Filter(`facts.a"b=1`)
+	if err == nil {
+		err = filter.Validate([]Assert{{Field: "facts", Kind: JSON}})
+	}
+	g.Expect(errors.Is(err, &Error{})).To(gomega.BeTrue())
+
+	filter, err = p.Filter("facts:10")
+	g.Expect(err).To(gomega.BeNil())
+	err = filter.Validate(
+		[]Assert{
+			{Field: "facts", Kind: JSON},
+		})
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	filter, err = p.Filter("other.java:10")
+	g.Expect(err).To(gomega.BeNil())
+	err = filter.Validate(
+		[]Assert{
+			{Field: "facts", Kind: JSON},
+		})
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestMatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	p := Parser{}
+	cases := []struct {
+		filter  string
+		value   any
+		matched bool
+	}{
+		{"score>=80", 80.0, true},
+		{"score>=80", 79.5, false},
+		{"score<50", 12, true},
+		{"score!=50", 50.0, false},
+		{"score:'50'", 50.0, false},
+		{"risk=red", "red", true},
+		{"risk!=red", "green", true},
+		{"risk=(red|yellow)", "yellow", true},
+		{"risk=(red|yellow)", "green", false},
+		{"risk!=(red|yellow)", "green", true},
+		{"risk!=(red|yellow)", "red", false},
+		{"risk~GR*", "green", true},
+	}
+	for _, c := range cases {
+		filter, err := p.Filter(c.filter)
+		g.Expect(err).To(gomega.BeNil(), c.filter)
+		f := filter.List()[0]
+		g.Expect(f.Match(c.value)).To(gomega.Equal(c.matched), c.filter)
+	}
+}
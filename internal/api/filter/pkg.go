@@ -29,6 +29,15 @@ operator:
 Notes:
 - The OR `|` operator may only be used inside a list `()`.
 
+JSON document fields:
+
+Fields (declared by the endpoint) containing JSON documents may be
+filtered using: field.path where the path is relative to the document.
+Numeric path segments are array indexes. The comparison is type-aware
+and determined by the value: numbers match (json) numbers; true|false
+match booleans; null matches null; everything else matches strings.
+Quoted values always match strings.
+
 
 Examples:
 ?filter=name:elmer
@@ -43,4 +52,7 @@ Examples:
 ?filter=category:mandatory,effort:20  // category=mandatory AND effort=20
 ?filter=category:mandatory|effort:10  // category=mandatory OR effort=10
 ?filter=tag.id:(1,2)                  // tag.id 1 AND 2.
+?filter=facts.java.version>=11        // fact (java) value.version >= 11
+?filter=coordinates.content.namespace=payments
+?filter=content.ports.0=8080          // json_extract(Content, '$.ports[0]') = 8080
 */
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyLimit the max length of an idempotency key.
+const IdempotencyKeyLimit = 255
+
+// Idempotent handler.
+// A POST request with an Idempotency-Key header is recorded with
+// the request fingerprint (digest) and the (successful) response.
+// A retry (with the same key) replays the recorded response.
+// A key reused with a different request is rejected.
+// Keys are scoped to the auth subject and expire based on
+// Settings.Hub.Idempotency.TTL.
+// Must precede Transaction so the key (claim) is committed and
+// visible to a retry while the original request is in progress.
+func Idempotent(ctx *gin.Context) {
+	key := ctx.GetHeader(IdempotencyKey)
+	if key == "" || ctx.Request.Method != http.MethodPost {
+		return
+	}
+	if len(key) > IdempotencyKeyLimit {
+		_ = ctx.Error(&BadRequestError{
+			Reason: IdempotencyKey + " exceeds max length.",
+		})
+		ctx.Abort()
+		return
+	}
+	rtx := RichContext(ctx)
+	digest, err := idempotencyDigest(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+	defer func() {
+		if ctx.Request.Body != nil {
+			_ = ctx.Request.Body.Close()
+		}
+	}()
+	db := rtx.DB
+	m := &model.Idempotency{}
+	err = db.First(
+		m,
+		map[string]any{
+			"Key":     key,
+			"Subject": rtx.Subject,
+		}).Error
+	switch {
+	case err == nil:
+		if time.Now().After(m.Expiration) {
+			err = db.Delete(m).Error
+			if err != nil {
+				_ = ctx.Error(err)
+				ctx.Abort()
+				return
+			}
+			break
+		}
+		if m.Digest != digest {
+			_ = ctx.Error(&IdempotencyError{
+				Key:    key,
+				Reason: "reused with a different request.",
+			})
+			ctx.Abort()
+			return
+		}
+		if m.Status == 0 {
+			_ = ctx.Error(&IdempotencyError{
+				Key:        key,
+				Reason:     "original request in progress.",
+				InProgress: true,
+			})
+			ctx.Abort()
+			return
+		}
+		var body any
+		if len(m.Body) > 0 {
+			err = json.Unmarshal(m.Body, &body)
+			if err != nil {
+				_ = ctx.Error(err)
+				ctx.Abort()
+				return
+			}
+		}
+		ctx.Header(Replayed, "true")
+		rtx.Respond(m.Status, body)
+		ctx.Abort()
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	default:
+		_ = ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+	m = &model.Idempotency{
+		Key:        key,
+		Subject:    rtx.Subject,
+		Method:     ctx.Request.Method,
+		Path:       ctx.Request.URL.Path,
+		Digest:     digest,
+		Expiration: time.Now().Add(Settings.Hub.Idempotency.TTL),
+	}
+	m.CreateUser = rtx.User
+	err = db.Create(m).Error
+	if err != nil {
+		_ = ctx.Error(&IdempotencyError{
+			Key:        key,
+			Reason:     "original request in progress.",
+			InProgress: true,
+		})
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+	status := rtx.Response.Status
+	if len(ctx.Errors) > 0 || status < 200 || status > 299 {
+		err = db.Delete(m).Error
+		if err != nil {
+			log.Error(err, "")
+		}
+		return
+	}
+	if rtx.Response.Body != nil {
+		m.Body, err = json.Marshal(rtx.Response.Body)
+		if err != nil {
+			log.Error(err, "")
+			return
+		}
+	}
+	m.Status = status
+	err = db.Save(m).Error
+	if err != nil {
+		log.Error(err, "")
+	}
+}
+
+// idempotencyDigest returns the request fingerprint.
+// The body is spooled to a temporary file which is
+// used as the request body.
+// Multipart bodies are fingerprinted by part so the
+// (random) boundary is not considered.
+func idempotencyDigest(ctx *gin.Context) (digest string, err error) {
+	h := sha256.New()
+	mediaType, params, _ := mime.ParseMediaType(ctx.GetHeader(ContentType))
+	_, _ = io.WriteString(h, ctx.Request.Method)
+	_, _ = io.WriteString(h, ctx.Request.URL.RequestURI())
+	_, _ = io.WriteString(h, mediaType)
+	body := ctx.Request.Body
+	if body == nil || body == http.NoBody {
+		digest = hex.EncodeToString(h.Sum(nil))
+		return
+	}
+	f, err := os.CreateTemp("", "idempotency-*")
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	spool := &spooled{File: f}
+	defer func() {
+		if err != nil {
+			_ = spool.Close()
+		}
+	}()
+	boundary := params["boundary"]
+	if strings.HasPrefix(mediaType, "multipart/") && boundary != "" {
+		_, err = io.Copy(f, body)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		err = idempotencyParts(h, multipart.NewReader(f, boundary))
+		if err != nil {
+			return
+		}
+	} else {
+		_, err = io.Copy(f, io.TeeReader(body, h))
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	_ = body.Close()
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	ctx.Request.Body = spool
+	digest = hex.EncodeToString(h.Sum(nil))
+	return
+}
+
+// idempotencyParts writes the multipart parts to the hash.
+func idempotencyParts(h hash.Hash, reader *multipart.Reader) (err error) {
+	for {
+		var part *multipart.Part
+		part, err = reader.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			} else {
+				err = &BadRequestError{Reason: err.Error()}
+			}
+			return
+		}
+		_, _ = io.WriteString(h, part.FormName())
+		_, _ = io.WriteString(h, part.FileName())
+		_, _ = io.WriteString(h, part.Header.Get(ContentType))
+		_, err = io.Copy(h, part)
+		_ = part.Close()
+		if err != nil {
+			err = &BadRequestError{Reason: err.Error()}
+			return
+		}
+	}
+}
+
+// spooled request body.
+// The file is deleted when closed.
+type spooled struct {
+	*os.File
+}
+
+// Close and delete the file.
+func (r *spooled) Close() (err error) {
+	err = r.File.Close()
+	_ = os.Remove(r.File.Name())
+	return
+}
@@ -3,10 +3,13 @@ package api
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	qf "github.com/konveyor/tackle2-hub/internal/api/filter"
 	"github.com/konveyor/tackle2-hub/internal/api/resource"
+	"github.com/konveyor/tackle2-hub/internal/identity"
+	"github.com/konveyor/tackle2-hub/internal/identity/verify"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/internal/secret"
 	"github.com/konveyor/tackle2-hub/internal/trigger"
@@ -32,6 +35,7 @@ func (h IdentityHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.GET(api.IdentityRoute, h.Get)
 	routeGroup.PUT(api.IdentityRoute, Transaction, h.Update)
 	routeGroup.DELETE(api.IdentityRoute, h.Delete)
+	routeGroup.POST(api.IdentityVerifyRoute, h.Verify)
 	//
 	routeGroup.GET(api.AppIdentitiesRoute, h.AppList)
 }
@@ -44,6 +48,7 @@ func (h IdentityHandler) AddRoutes(e *gin.Engine) {
 // @success 200 {object} Identity
 // @router /identities/{id} [get]
 // @param id path int true "Identity ID"
+// @description Identities backed by a (k8s) secret are resolved when decrypted.
 // @Param decrypted query bool false "Decrypt fields"
 func (h IdentityHandler) Get(ctx *gin.Context) {
 	id := h.pk(ctx)
@@ -54,7 +59,7 @@ func (h IdentityHandler) Get(ctx *gin.Context) {
 		return
 	}
 	r := Identity{}
-	err := h.Decrypt(ctx, m)
+	err := h.decrypt(ctx, m)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -71,6 +76,7 @@ func (h IdentityHandler) Get(ctx *gin.Context) {
 // @description - kind
 // @description - name
 // @description - application.id
+// @description Identities backed by a (k8s) secret are resolved when decrypted.
 // @tags identities
 // @produce json
 // @success 200 {object} []Identity
@@ -112,7 +118,7 @@ func (h IdentityHandler) List(ctx *gin.Context) {
 	for i := range list {
 		m := &list[i]
 		r := Identity{}
-		err := h.Decrypt(ctx, m)
+		err := h.decrypt(ctx, m)
 		if err != nil {
 			_ = ctx.Error(err)
 			return
@@ -130,6 +136,7 @@ func (h IdentityHandler) List(ctx *gin.Context) {
 // @description Filter by:
 // @description - role - applied to direct.
 // @description - kind - applied to indirect.
+// @description Identities backed by a (k8s) secret are resolved when decrypted.
 // @tags identities
 // @produce json
 // @success 200 {object} []Identity
@@ -161,7 +168,7 @@ func (h IdentityHandler) AppList(ctx *gin.Context) {
 	for i := range direct {
 		m := &direct[i]
 		r := Identity{}
-		err := h.Decrypt(ctx, m)
+		err := h.decrypt(ctx, m)
 		if err != nil {
 			_ = ctx.Error(err)
 			return
@@ -176,6 +183,9 @@ func (h IdentityHandler) AppList(ctx *gin.Context) {
 // Create godoc
 // @summary Create an identity.
 // @description Create an identity.
+// @description Identities backed by a (k8s) secret must not include
+// @description the user, password, key or settings.
+// @description The secret must be labeled: konveyor.io/identity.
 // @tags identities
 // @accept json
 // @produce json
@@ -189,6 +199,11 @@ func (h IdentityHandler) Create(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	err = h.validSecret(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	if r.Default {
 		defId, err := h.getDefault(ctx, r.Kind)
 		if err != nil {
@@ -263,6 +278,10 @@ func (h IdentityHandler) Delete(ctx *gin.Context) {
 // Update godoc
 // @summary Update an identity.
 // @description Update an identity.
+// @description The verification is cleared.
+// @description Identities backed by a (k8s) secret must not include
+// @description the user, password, key or settings.
+// @description The secret must be labeled: konveyor.io/identity.
 // @tags identities
 // @accept json
 // @success 204
@@ -277,6 +296,11 @@ func (h IdentityHandler) Update(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	err = h.validSecret(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	if r.Default {
 		defId, err := h.getDefault(ctx, r.Kind)
 		if err != nil {
@@ -324,6 +348,136 @@ func (h IdentityHandler) Update(ctx *gin.Context) {
 	h.Status(ctx, http.StatusNoContent)
 }
 
+// decrypt the identity when requested.
+// Identities backed by a (k8s) secret are resolved.
+func (h IdentityHandler) decrypt(ctx *gin.Context, m *model.Identity) (err error) {
+	err = h.Decrypt(ctx, m)
+	if err != nil {
+		return
+	}
+	requested, _ := strconv.ParseBool(ctx.Query(Decrypted))
+	if !requested || m.Secret == nil {
+		return
+	}
+	rtx := RichContext(ctx)
+	resolver := identity.Resolver{Client: rtx.Client}
+	err = resolver.Resolve(m)
+	return
+}
+
+// validSecret ensures identities backed by a (k8s) secret
+// do not include the values provided by the secret and
+// that the secret is labeled for use by identities.
+func (h IdentityHandler) validSecret(ctx *gin.Context, r *Identity) (err error) {
+	if r.Secret == nil {
+		return
+	}
+	if r.User != "" ||
+		r.Password != "" ||
+		r.Key != "" ||
+		r.Settings != "" {
+		err = &BadRequestError{
+			Reason: "user, password, key and settings provided by the secret.",
+		}
+		return
+	}
+	rtx := RichContext(ctx)
+	resolver := identity.Resolver{Client: rtx.Client}
+	err = resolver.Validate(r.Secret.Name)
+	if errors.Is(err, &identity.NotLabeled{}) {
+		err = &BadRequestError{
+			Reason: err.Error(),
+		}
+	}
+	return
+}
+
+// Verify godoc
+// @summary Verify an identity.
+// @description Verify the credentials against the target:
+// @description - source: the application repository is accessed (git ls-remote|svn info)
+// @description with the proxies applied.
+// @description - maven: the settings.xml is parsed.
+// @description Other kinds cannot be verified (400).
+// @description The verification may be scoped to an application referencing the
+// @description identity (400 when not referenced). When the application is not
+// @description specified, the first application (with a repository) referencing
+// @description the identity is used.
+// @description The verification is recorded on the identity. Failed verification
+// @description is reported with succeeded=false.
+// @tags identities
+// @accept json
+// @produce json
+// @success 200 {object} IdentityVerification
+// @router /identities/{id}/verify [post]
+// @param id path int true "Identity ID"
+// @param verification body IdentityVerification false "Verification (application) scope"
+func (h IdentityHandler) Verify(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Identity{}
+	err := h.DB(ctx).First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := IdentityVerification{}
+	if ctx.Request.ContentLength > 0 {
+		err = h.Bind(ctx, &r)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
+	var app *model.Application
+	if r.Application > 0 {
+		app = &model.Application{}
+		err = h.DB(ctx).First(app, r.Application).Error
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		var n int64
+		db := h.DB(ctx).Model(&model.ApplicationIdentity{})
+		db = db.Where("ApplicationID", app.ID)
+		db = db.Where("IdentityID", id)
+		err = db.Count(&n).Error
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		if n == 0 {
+			err = &BadRequestError{
+				Reason: "identity not referenced by the application.",
+			}
+			_ = ctx.Error(err)
+			return
+		}
+	}
+	verifier := verify.Verifier{
+		DB:     h.DB(ctx),
+		Client: RichContext(ctx).Client,
+	}
+	v, err := verifier.Verify(m, app)
+	if err != nil {
+		if errors.Is(err, &verify.NotSupported{}) {
+			err = &BadRequestError{Reason: err.Error()}
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	verified := &model.Identity{Verification: &v}
+	verified.ID = id
+	db := h.DB(ctx).Model(verified)
+	err = db.Select("Verification").Updates(verified).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r.With(&v)
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
 // ids return identity IDs (query) based on the filter.
 func (h IdentityHandler) ids(ctx *gin.Context, f qf.Filter) (q *gorm.DB) {
 	q = h.DB(ctx)
@@ -358,3 +512,4 @@ func (h IdentityHandler) getDefault(ctx *gin.Context, kind string) (id uint, err
 
 // Identity REST resource.
 type Identity = resource.Identity
+type IdentityVerification = resource.IdentityVerification
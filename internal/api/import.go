@@ -2,7 +2,6 @@ package api
 
 import (
 	"bytes"
-	"encoding/csv"
 	"io"
 	"net/http"
 	"strconv"
@@ -11,6 +10,8 @@ import (
 	"github.com/konveyor/tackle2-hub/internal/api/resource"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/shared/api"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Record types
@@ -23,6 +24,32 @@ const (
 	ExpectedFieldCount = 17
 )
 
+// Import modes.
+const (
+	ImportCreate = api.ImportCreate
+	ImportUpdate = api.ImportUpdate
+	ImportUpsert = api.ImportUpsert
+)
+
+// Import tag modes.
+const (
+	ImportTagAdd     = api.ImportTagAdd
+	ImportTagReplace = api.ImportTagReplace
+)
+
+// Import (application) matching.
+const (
+	ImportMatchName       = api.ImportMatchName
+	ImportMatchRepository = api.ImportMatchRepository
+)
+
+// ImportWaker wakes the import manager.
+type ImportWaker interface {
+	// Wake notifies the manager that imports are ready to
+	// be processed.
+	Wake()
+}
+
 // ImportHandler handles import routes.
 type ImportHandler struct {
 	BaseHandler
@@ -42,6 +69,7 @@ func (h ImportHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.DELETE(api.ImportRoute, h.DeleteImport)
 	routeGroup.GET(api.DownloadRoute, h.DownloadCSV)
 	routeGroup.POST(api.UploadRoute, h.UploadCSV)
+	routeGroup.POST(api.CommitRoute, h.CommitSummary)
 }
 
 // GetImport godoc
@@ -133,7 +161,9 @@ func (h ImportHandler) GetSummary(ctx *gin.Context) {
 		_ = ctx.Error(result.Error)
 		return
 	}
-	h.Respond(ctx, http.StatusOK, m)
+	r := ImportSummary{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
 }
 
 // ListSummaries godoc
@@ -180,12 +210,47 @@ func (h ImportHandler) DeleteSummary(ctx *gin.Context) {
 }
 
 // UploadCSV godoc
-// @summary Upload a CSV containing applications and dependencies to import.
-// @description Upload a CSV containing applications and dependencies to import.
+// @summary Upload a file containing applications and dependencies to import.
+// @description Upload a file containing applications and dependencies to import.
+// @description Form fields:
+// @description - format: (csv|xlsx|json|yaml) default: based on the file name extension.
+// @description   Documents (json|yaml) contain a list of objects.
+// @description - profile: The import profile (ID) used to map columns (or keys) onto import fields.
+// @description   Without a profile, tables (csv|xlsx) must use the legacy template and
+// @description   documents (json|yaml) are mapped by field name.
+// @description - mode: (create|update|upsert) default: create.
+// @description   - create: applications are created.
+// @description   - update: existing applications are updated.
+// @description   - upsert: existing applications are updated; others are created.
+// @description - matchBy: (name|repository) default: name.
+// @description   Existing applications are matched by name or repository URL.
+// @description - tagMode: (add|replace) default: add.
+// @description   - add: tags are added to the application.
+// @description   - replace: tags (not assigned by analysis) are replaced.
+// @description     Rows without tags are ignored.
+// @description When updating, empty columns are ignored. Field changes are reported in
+// @description the import (changes) and the summary.
+// @description - dryRun: (true|false) default: false.
+// @description   Rows are validated against the inventory but nothing is created or updated.
+// @description   The per-row validation report is included in the summary (report).
+// @description   The import is applied using: POST /importsummaries/{id}/commit.
+// @description - atomic: (true|false) default: false.
+// @description   All-or-nothing. When any row fails, every entity created or updated
+// @description   by the import is rolled back.
 // @tags imports
 // @success 201 {object} api.ImportSummary
 // @produce json
 // @router /importsummaries/upload [post]
+// @param file formData file true "CSV, XLSX, JSON or YAML file"
+// @param fileName formData string true "File name"
+// @param createEntities formData bool false "Create entities"
+// @param mode formData string false "Mode"
+// @param matchBy formData string false "Match by"
+// @param tagMode formData string false "Tag mode"
+// @param dryRun formData bool false "Dry run"
+// @param atomic formData bool false "All-or-nothing"
+// @param format formData string false "Format"
+// @param profile formData int false "Import Profile ID"
 func (h ImportHandler) UploadCSV(ctx *gin.Context) {
 	fileName, ok := ctx.GetPostForm("fileName")
 	if !ok {
@@ -212,72 +277,296 @@ func (h ImportHandler) UploadCSV(ctx *gin.Context) {
 	if err != nil {
 		createEntities = true
 	}
+	mode := ctx.DefaultPostForm("mode", api.ImportCreate)
+	switch mode {
+	case api.ImportCreate,
+		api.ImportUpdate,
+		api.ImportUpsert:
+	default:
+		err = &BadRequestError{
+			Reason: "mode must be: (create|update|upsert).",
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	matchBy := ctx.DefaultPostForm("matchBy", api.ImportMatchName)
+	switch matchBy {
+	case api.ImportMatchName,
+		api.ImportMatchRepository:
+	default:
+		err = &BadRequestError{
+			Reason: "matchBy must be: (name|repository).",
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	tagMode := ctx.DefaultPostForm("tagMode", api.ImportTagAdd)
+	switch tagMode {
+	case api.ImportTagAdd,
+		api.ImportTagReplace:
+	default:
+		err = &BadRequestError{
+			Reason: "tagMode must be: (add|replace).",
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	dryRun, err := strconv.ParseBool(ctx.DefaultPostForm("dryRun", "false"))
+	if err != nil {
+		err = &BadRequestError{
+			Reason: "dryRun must be: (true|false).",
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	atomic, err := strconv.ParseBool(ctx.DefaultPostForm("atomic", "false"))
+	if err != nil {
+		err = &BadRequestError{
+			Reason: "atomic must be: (true|false).",
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	name := fileName
+	if name == "" {
+		name = file.Filename
+	}
+	format, err := ImportFormat(ctx.PostForm("format"), name)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var profile *model.ImportProfile
+	profileId := ctx.PostForm("profile")
+	if profileId != "" {
+		profile = &model.ImportProfile{}
+		err = h.DB(ctx).First(profile, profileId).Error
+		if err != nil {
+			err = &BadRequestError{
+				Reason: "profile: " + err.Error(),
+			}
+			_ = ctx.Error(err)
+			return
+		}
+	}
+	table := ImportTable{}
+	err = table.Read(format, buf.Bytes())
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	imports, err := h.imports(fileName, format, profile, &table)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m := model.ImportSummary{
+		DryRun:         dryRun,
+		Atomic:         atomic,
 		Filename:       fileName,
+		Format:         format,
 		ImportStatus:   resource.InProgress,
 		Content:        buf.Bytes(),
 		CreateEntities: createEntities,
+		Mode:           mode,
+		MatchBy:        matchBy,
+		TagMode:        tagMode,
+	}
+	if profile != nil {
+		m.ProfileID = &profile.ID
+		m.Profile = profile
 	}
 	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
-	result := h.DB(ctx).Create(&m)
-	if result.Error != nil {
-		_ = ctx.Error(result.Error)
+	err = h.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		err = tx.Omit(clause.Associations).Create(&m).Error
+		if err != nil {
+			return
+		}
+		for i := range imports {
+			imp := &imports[i]
+			imp.ImportSummaryID = m.ID
+			err = tx.Create(imp).Error
+			if err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		_ = ctx.Error(err)
 		return
 	}
-	_, err = fileReader.Seek(0, 0)
+	h.wake(ctx)
+
+	summary := ImportSummary{}
+	summary.With(&m)
+	h.Respond(ctx, http.StatusCreated, summary)
+}
+
+// CommitSummary godoc
+// @summary Commit a dry-run import.
+// @description Commit a dry-run import. The previewed (valid) rows are applied (imported)
+// @description using the planned action (create|update). A row fails when the inventory
+// @description has changed since the dry-run such that the planned action no longer applies.
+// @description Invalid rows are not imported. The dry-run must be completed and an
+// @description (all-or-nothing) dry-run must not contain invalid rows.
+// @tags imports
+// @produce json
+// @success 200 {object} api.ImportSummary
+// @router /importsummaries/{id}/commit [post]
+// @param id path int true "ImportSummary ID"
+func (h ImportHandler) CommitSummary(ctx *gin.Context) {
+	m := &model.ImportSummary{}
+	id := ctx.Param(ID)
+	db := h.preLoad(h.DB(ctx), "Imports")
+	err := db.First(m, id).Error
 	if err != nil {
-		h.Status(ctx, http.StatusInternalServerError)
+		_ = ctx.Error(err)
+		return
+	}
+	r := ImportSummary{}
+	r.With(m)
+	if !m.DryRun {
+		err = &BadRequestError{
+			Reason: "not a dry-run.",
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	if r.ImportStatus != resource.Completed {
+		err = &BadRequestError{
+			Reason: "dry-run not completed.",
+		}
+		_ = ctx.Error(err)
+		return
 	}
-	csvReader := csv.NewReader(fileReader)
-	csvReader.TrimLeadingSpace = true
-	// skip the header
-	_, err = csvReader.Read()
+	if m.Atomic {
+		for i := range m.Imports {
+			if !m.Imports[i].IsValid {
+				err = &BadRequestError{
+					Reason: "(all-or-nothing) dry-run contains invalid rows.",
+				}
+				_ = ctx.Error(err)
+				return
+			}
+		}
+	}
+	err = h.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		m.DryRun = false
+		m.UpdateUser = h.CurrentUser(ctx)
+		err = tx.Omit(clause.Associations).Save(m).Error
+		if err != nil {
+			return
+		}
+		db := tx.Model(&model.Import{})
+		db = db.Where("ImportSummaryID = ?", m.ID)
+		db = db.Where("IsValid", true)
+		err = db.Updates(
+			map[string]any{
+				"Processed":    false,
+				"IsValid":      false,
+				"ErrorMessage": "",
+				"Changes":      nil,
+				"Issues":       nil,
+			}).Error
+		return
+	})
 	if err != nil {
-		h.Status(ctx, http.StatusBadRequest)
+		_ = ctx.Error(err)
+		return
+	}
+	h.wake(ctx)
+	m = &model.ImportSummary{}
+	err = db.First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
 	}
+	r = ImportSummary{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
 
-	for {
-		row, err := csvReader.Read()
-		if err != nil {
-			if err == io.EOF {
-				break
-			} else {
-				h.Status(ctx, http.StatusBadRequest)
+// imports builds the imports from the table.
+// Tables (csv|xlsx) without a profile use the (positional)
+// legacy template. Documents (json|yaml) without a profile
+// map object keys by field name.
+func (h ImportHandler) imports(
+	fileName string,
+	format string,
+	profile *model.ImportProfile,
+	table *ImportTable) (imports []model.Import, err error) {
+	//
+	if profile == nil {
+		switch format {
+		case api.ImportJSON,
+			api.ImportYAML:
+			mapping := ImportMapping{}
+			err = mapping.Identity(table.Header)
+			if err != nil {
+				return
 			}
+			profile = mapping.Profile
 		}
+	}
+	mapping := ImportMapping{Profile: profile}
+	for _, row := range table.Rows {
 		var imp model.Import
-		switch row[0] {
-		case RecordTypeApplication:
-			// Check row format - length, expecting 17 fields + tags
-			if len(row) < ExpectedFieldCount {
-				h.Respond(ctx, http.StatusBadRequest, gin.H{"errorMessage": "Invalid Application Import CSV format."})
+		if profile != nil {
+			imp = mapping.Import(table.Header, row.Cells)
+		} else {
+			imp, err = h.legacyImport(fileName, row.Cells)
+			if err != nil {
 				return
 			}
-			imp = h.applicationFromRow(fileName, row)
-		case RecordTypeDependency:
-			imp = h.dependencyFromRow(fileName, row)
-		default:
-			imp = model.Import{
-				Filename:    fileName,
-				RecordType1: row[0],
+		}
+		imp.Filename = fileName
+		imp.Row = row.Number
+		imports = append(imports, imp)
+	}
+	return
+}
+
+// legacyImport builds an import from a (legacy template) row.
+func (h ImportHandler) legacyImport(fileName string, row []string) (imp model.Import, err error) {
+	switch row[0] {
+	case RecordTypeApplication:
+		// Check row format - length, expecting 17 fields + tags
+		if len(row) < ExpectedFieldCount {
+			err = &BadRequestError{
+				Reason: "Invalid Application Import CSV format.",
 			}
+			return
 		}
-		imp.ImportSummary = m
-		result := h.DB(ctx).Create(&imp)
-		if result.Error != nil {
-			_ = ctx.Error(result.Error)
+		imp = h.applicationFromRow(fileName, row)
+	case RecordTypeDependency:
+		if len(row) < 7 {
+			err = &BadRequestError{
+				Reason: "Invalid Dependency Import CSV format.",
+			}
 			return
 		}
+		imp = h.dependencyFromRow(fileName, row)
+	default:
+		imp = model.Import{
+			Filename:    fileName,
+			RecordType1: row[0],
+		}
 	}
+	return
+}
 
-	summary := ImportSummary{}
-	summary.With(&m)
-	h.Respond(ctx, http.StatusCreated, summary)
+// wake the import manager.
+func (h ImportHandler) wake(ctx *gin.Context) {
+	rtx := RichContext(ctx)
+	if rtx.ImportManager != nil {
+		rtx.ImportManager.Wake()
+	}
 }
 
 // DownloadCSV godoc
-// @summary Export the source CSV for a particular import summary.
-// @description Export the source CSV for a particular import summary.
+// @summary Export the source file for a particular import summary.
+// @description Export the source file (CSV, XLSX, JSON or YAML) for a particular import summary.
 // @tags imports
 // @produce text/csv
 // @success 200 file csv
@@ -291,8 +580,17 @@ func (h ImportHandler) DownloadCSV(ctx *gin.Context) {
 		_ = ctx.Error(result.Error)
 		return
 	}
+	mime := MIMECSV
+	switch m.Format {
+	case api.ImportXLSX:
+		mime = MIMEXLSX
+	case api.ImportJSON:
+		mime = api.MIMEJSON
+	case api.ImportYAML:
+		mime = api.MIMEYAML
+	}
 	h.Attachment(ctx, m.Filename)
-	ctx.Data(http.StatusOK, "text/csv", m.Content)
+	ctx.Data(http.StatusOK, mime, m.Content)
 }
 
 // CSV upload supports two types of records in the same file: application imports, and dependencies.
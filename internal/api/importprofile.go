@@ -0,0 +1,350 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/internal/api/resource"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"gorm.io/gorm/clause"
+)
+
+// Import field (mapping) prefixes.
+const (
+	// FieldTag maps tags (by category).
+	// Example: tag:Language
+	FieldTag = "tag:"
+	// FieldFact maps application facts (by key).
+	// Example: fact:cmdb.id
+	FieldFact = "fact:"
+)
+
+// ImportFields import fields (mapping targets).
+var ImportFields = map[string]func(m *model.Import, v string){
+	"recordType":          func(m *model.Import, v string) { m.RecordType1 = v },
+	"applicationName":     func(m *model.Import, v string) { m.ApplicationName = v },
+	"description":         func(m *model.Import, v string) { m.Description = v },
+	"comments":            func(m *model.Import, v string) { m.Comments = v },
+	"businessService":     func(m *model.Import, v string) { m.BusinessService = v },
+	"dependency":          func(m *model.Import, v string) { m.Dependency = v },
+	"dependencyDirection": func(m *model.Import, v string) { m.DependencyDirection = v },
+	"binaryGroup":         func(m *model.Import, v string) { m.BinaryGroup = v },
+	"binaryArtifact":      func(m *model.Import, v string) { m.BinaryArtifact = v },
+	"binaryVersion":       func(m *model.Import, v string) { m.BinaryVersion = v },
+	"binaryPackaging":     func(m *model.Import, v string) { m.BinaryPackaging = v },
+	"repositoryKind":      func(m *model.Import, v string) { m.RepositoryKind = v },
+	"repositoryUrl":       func(m *model.Import, v string) { m.RepositoryURL = v },
+	"repositoryBranch":    func(m *model.Import, v string) { m.RepositoryBranch = v },
+	"repositoryPath":      func(m *model.Import, v string) { m.RepositoryPath = v },
+	"owner":               func(m *model.Import, v string) { m.Owner = v },
+	"contributors":        func(m *model.Import, v string) { m.Contributors = v },
+}
+
+// ImportProfileHandler handles import profile routes.
+type ImportProfileHandler struct {
+	BaseHandler
+}
+
+// AddRoutes adds routes.
+func (h ImportProfileHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("importprofiles"))
+	routeGroup.GET(api.ImportProfilesRoute, h.List)
+	routeGroup.GET(api.ImportProfilesRoute+"/", h.List)
+	routeGroup.POST(api.ImportProfilesRoute, h.Create)
+	routeGroup.GET(api.ImportProfileRoute, h.Get)
+	routeGroup.PUT(api.ImportProfileRoute, h.Update)
+	routeGroup.DELETE(api.ImportProfileRoute, h.Delete)
+	routeGroup.GET(api.ImportTemplateRoute, h.Template)
+}
+
+// Get godoc
+// @summary Get an import profile by ID.
+// @description Get an import profile by ID.
+// @tags importprofiles
+// @produce json
+// @success 200 {object} api.ImportProfile
+// @router /importprofiles/{id} [get]
+// @param id path int true "Import Profile ID"
+func (h ImportProfileHandler) Get(ctx *gin.Context) {
+	m := &model.ImportProfile{}
+	id := h.pk(ctx)
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := ImportProfile{}
+	r.With(m)
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List all import profiles.
+// @description List all import profiles.
+// @tags importprofiles
+// @produce json
+// @success 200 {object} []api.ImportProfile
+// @router /importprofiles [get]
+func (h ImportProfileHandler) List(ctx *gin.Context) {
+	var list []model.ImportProfile
+	result := h.DB(ctx).Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []ImportProfile{}
+	for i := range list {
+		r := ImportProfile{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// Create godoc
+// @summary Create an import profile.
+// @description Create an import profile.
+// @description Each column maps a source column (by name) onto an import field:
+// @description recordType, applicationName, description, comments, businessService,
+// @description dependency, dependencyDirection, binaryGroup, binaryArtifact, binaryVersion,
+// @description binaryPackaging, repositoryKind, repositoryUrl, repositoryBranch, repositoryPath,
+// @description owner, contributors.
+// @description - tag:<category> maps (comma separated) tags in the category.
+// @description - fact:<key> maps an application fact.
+// @description The recordType is used when the record type is not mapped. Default: 1.
+// @tags importprofiles
+// @accept json
+// @produce json
+// @success 201 {object} api.ImportProfile
+// @router /importprofiles [post]
+// @param import_profile body api.ImportProfile true "Import Profile data"
+func (h ImportProfileHandler) Create(ctx *gin.Context) {
+	r := &ImportProfile{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	err = h.validate(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
+	result := h.DB(ctx).Create(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r.With(m)
+
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// Delete godoc
+// @summary Delete an import profile.
+// @description Delete an import profile.
+// @tags importprofiles
+// @success 204
+// @router /importprofiles/{id} [delete]
+// @param id path int true "Import Profile ID"
+func (h ImportProfileHandler) Delete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.ImportProfile{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	result = h.DB(ctx).Delete(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// Update godoc
+// @summary Update an import profile.
+// @description Update an import profile.
+// @tags importprofiles
+// @accept json
+// @success 204
+// @router /importprofiles/{id} [put]
+// @param id path int true "Import Profile ID"
+// @param import_profile body api.ImportProfile true "Import Profile data"
+func (h ImportProfileHandler) Update(ctx *gin.Context) {
+	id := h.pk(ctx)
+	r := &ImportProfile{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	err = h.validate(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m.ID = id
+	m.UpdateUser = h.BaseHandler.CurrentUser(ctx)
+	db := h.DB(ctx).Model(m)
+	db = db.Omit(clause.Associations)
+	result := db.Save(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// Template godoc
+// @summary Download the (CSV) import template for an import profile.
+// @description Download the (CSV) import template for an import profile.
+// @description The template contains the header row (mapped column names).
+// @tags importprofiles
+// @produce text/csv
+// @success 200 file csv
+// @router /importprofiles/{id}/template [get]
+// @param id path int true "Import Profile ID"
+func (h ImportProfileHandler) Template(ctx *gin.Context) {
+	m := &model.ImportProfile{}
+	id := h.pk(ctx)
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	header := []string{}
+	for _, c := range m.Columns {
+		header = append(header, c.Name)
+	}
+	bfr := &bytes.Buffer{}
+	writer := csv.NewWriter(bfr)
+	err := writer.Write(header)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	writer.Flush()
+	h.Attachment(ctx, m.Name+".csv")
+	ctx.Data(http.StatusOK, MIMECSV, bfr.Bytes())
+}
+
+// validate the profile columns.
+func (h ImportProfileHandler) validate(m *model.ImportProfile) (err error) {
+	mapping := ImportMapping{Profile: m}
+	err = mapping.Validate()
+	return
+}
+
+// ImportMapping maps (tabular) rows onto imports.
+type ImportMapping struct {
+	Profile *model.ImportProfile
+	// index of header columns (lower case).
+	index map[string]int
+}
+
+// Identity returns a mapping of (known) fields by name.
+// Used for documents (json|yaml) imported without a profile.
+func (r *ImportMapping) Identity(header []string) (err error) {
+	r.Profile = &model.ImportProfile{}
+	for _, name := range header {
+		r.Profile.Columns = append(
+			r.Profile.Columns,
+			model.ImportColumn{
+				Name:  name,
+				Field: name,
+			})
+	}
+	err = r.Validate()
+	return
+}
+
+// Validate the mapped fields.
+func (r *ImportMapping) Validate() (err error) {
+	for _, c := range r.Profile.Columns {
+		_, found := ImportFields[c.Field]
+		if found {
+			continue
+		}
+		for _, prefix := range []string{FieldTag, FieldFact} {
+			if strings.HasPrefix(c.Field, prefix) && len(c.Field) > len(prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			err = &BadRequestError{
+				Reason: fmt.Sprintf(
+					"column '%s': field '%s' not supported.",
+					c.Name,
+					c.Field),
+			}
+			return
+		}
+	}
+	return
+}
+
+// Import builds an import from the row.
+func (r *ImportMapping) Import(header []string, cells []string) (m model.Import) {
+	if r.index == nil {
+		r.index = make(map[string]int)
+		for i, name := range header {
+			r.index[strings.ToLower(name)] = i
+		}
+	}
+	m.RecordType1 = r.Profile.RecordType
+	if m.RecordType1 == "" {
+		m.RecordType1 = RecordTypeApplication
+	}
+	for _, c := range r.Profile.Columns {
+		i, found := r.index[strings.ToLower(strings.TrimSpace(c.Name))]
+		if !found || i >= len(cells) {
+			continue
+		}
+		v := cells[i]
+		if v == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(c.Field, FieldTag):
+			category := c.Field[len(FieldTag):]
+			for _, name := range strings.Split(v, ",") {
+				m.ImportTags = append(
+					m.ImportTags,
+					model.ImportTag{
+						Category: category,
+						Name:     strings.TrimSpace(name),
+					})
+			}
+		case strings.HasPrefix(c.Field, FieldFact):
+			if m.Facts == nil {
+				m.Facts = make(map[string]string)
+			}
+			m.Facts[c.Field[len(FieldFact):]] = v
+		default:
+			fn, found := ImportFields[c.Field]
+			if found {
+				fn(&m, v)
+			}
+		}
+	}
+	return
+}
+
+// ImportProfile REST resource.
+type ImportProfile = resource.ImportProfile
@@ -0,0 +1,355 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"sigs.k8s.io/yaml"
+)
+
+// ImportTable tabular import content.
+// Documents (json|yaml) are a list of objects. The header
+// contains the (sorted) object keys.
+type ImportTable struct {
+	Header []string
+	Rows   []ImportTableRow
+}
+
+// ImportTableRow a (non-empty) row.
+type ImportTableRow struct {
+	// Number the row (line) number in the source.
+	Number int
+	// Cells values (padded to the header length).
+	Cells []string
+}
+
+// ImportFormat returns the import format.
+// The (explicit) format is validated. Otherwise, the format is
+// determined by the file name extension. Defaults to csv.
+func ImportFormat(format, fileName string) (matched string, err error) {
+	if format != "" {
+		switch format {
+		case api.ImportCSV,
+			api.ImportXLSX,
+			api.ImportJSON,
+			api.ImportYAML:
+			matched = format
+		default:
+			err = &BadRequestError{
+				Reason: "format must be: (csv|xlsx|json|yaml).",
+			}
+		}
+		return
+	}
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".xlsx":
+		matched = api.ImportXLSX
+	case ".json":
+		matched = api.ImportJSON
+	case ".yaml", ".yml":
+		matched = api.ImportYAML
+	default:
+		matched = api.ImportCSV
+	}
+	return
+}
+
+// Read the content based on format.
+func (r *ImportTable) Read(format string, content []byte) (err error) {
+	switch format {
+	case api.ImportXLSX:
+		err = r.readXLSX(content)
+	case api.ImportJSON:
+		err = r.readJSON(content)
+	case api.ImportYAML:
+		var b []byte
+		b, err = yaml.YAMLToJSON(content)
+		if err != nil {
+			err = &BadRequestError{Reason: err.Error()}
+			return
+		}
+		err = r.readJSON(b)
+	default:
+		err = r.readCSV(content)
+	}
+	return
+}
+
+// readCSV reads csv content.
+func (r *ImportTable) readCSV(content []byte) (err error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	for {
+		var cells []string
+		cells, err = reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			err = &BadRequestError{Reason: err.Error()}
+			return
+		}
+		line, _ := reader.FieldPos(0)
+		r.add(line, cells)
+	}
+	return
+}
+
+// readJSON reads a json document (list of objects).
+func (r *ImportTable) readJSON(content []byte) (err error) {
+	var objects []map[string]any
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.UseNumber()
+	err = d.Decode(&objects)
+	if err != nil {
+		err = &BadRequestError{
+			Reason: "document must be a list of objects: " + err.Error(),
+		}
+		return
+	}
+	keys := make(map[string]bool)
+	for _, object := range objects {
+		for k := range object {
+			keys[k] = true
+		}
+	}
+	header := []string{}
+	for k := range keys {
+		header = append(header, k)
+	}
+	sort.Strings(header)
+	r.Header = header
+	for i, object := range objects {
+		cells := make([]string, len(header))
+		for j, k := range header {
+			cells[j] = r.string(object[k])
+		}
+		r.Rows = append(
+			r.Rows,
+			ImportTableRow{
+				Number: i + 1,
+				Cells:  cells,
+			})
+	}
+	return
+}
+
+// readXLSX reads the first sheet of an (office open xml) spreadsheet.
+func (r *ImportTable) readXLSX(content []byte) (err error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		err = &BadRequestError{Reason: err.Error()}
+		return
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+	var sst struct {
+		Items []xlsxText `xml:"si"`
+	}
+	err = r.xml(files, "xl/sharedStrings.xml", &sst)
+	if err != nil {
+		return
+	}
+	sheet := "xl/worksheets/sheet1.xml"
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	err = r.xml(files, "xl/workbook.xml", &workbook)
+	if err != nil {
+		return
+	}
+	err = r.xml(files, "xl/_rels/workbook.xml.rels", &rels)
+	if err != nil {
+		return
+	}
+	if len(workbook.Sheets) > 0 {
+		for _, rel := range rels.Items {
+			if rel.ID != workbook.Sheets[0].ID {
+				continue
+			}
+			if strings.HasPrefix(rel.Target, "/") {
+				sheet = strings.TrimPrefix(rel.Target, "/")
+			} else {
+				sheet = path.Join("xl", rel.Target)
+			}
+			break
+		}
+	}
+	var worksheet struct {
+		Rows []struct {
+			Number int `xml:"r,attr"`
+			Cells  []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if _, found := files[sheet]; !found {
+		err = &BadRequestError{Reason: "spreadsheet has no (first) sheet."}
+		return
+	}
+	err = r.xml(files, sheet, &worksheet)
+	if err != nil {
+		return
+	}
+	number := 0
+	for _, row := range worksheet.Rows {
+		number++
+		if row.Number > 0 {
+			number = row.Number
+		}
+		var cells []string
+		for _, cell := range row.Cells {
+			column := len(cells)
+			if cell.Ref != "" {
+				column = r.column(cell.Ref)
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+			switch cell.Type {
+			case "s":
+				n, nErr := strconv.Atoi(cell.Value)
+				if nErr == nil && n >= 0 && n < len(sst.Items) {
+					cells[column] = sst.Items[n].String()
+				}
+			case "inlineStr":
+				cells[column] = cell.Inline.String()
+			case "b":
+				cells[column] = strconv.FormatBool(cell.Value == "1")
+			default:
+				cells[column] = cell.Value
+			}
+		}
+		r.add(number, cells)
+	}
+	return
+}
+
+// add a row. The first row is the header.
+// Empty rows are ignored.
+func (r *ImportTable) add(number int, cells []string) {
+	empty := true
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+		if cells[i] != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return
+	}
+	if r.Header == nil {
+		r.Header = cells
+		return
+	}
+	for len(cells) < len(r.Header) {
+		cells = append(cells, "")
+	}
+	r.Rows = append(
+		r.Rows,
+		ImportTableRow{
+			Number: number,
+			Cells:  cells,
+		})
+}
+
+// column returns the (0-based) column index for a cell reference.
+// Example: column("AB12") returns 27.
+func (r *ImportTable) column(ref string) (n int) {
+	for _, ch := range strings.ToUpper(ref) {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		n = n*26 + int(ch-'A'+1)
+	}
+	n--
+	if n < 0 {
+		n = 0
+	}
+	return
+}
+
+// string returns the string representation of a document value.
+// Lists are joined with commas.
+func (r *ImportTable) string(v any) (s string) {
+	switch x := v.(type) {
+	case nil:
+	case string:
+		s = x
+	case []any:
+		var list []string
+		for _, v := range x {
+			list = append(list, r.string(v))
+		}
+		s = strings.Join(list, ",")
+	case map[string]any:
+		b, _ := json.Marshal(x)
+		s = string(b)
+	default:
+		s = fmt.Sprint(x)
+	}
+	return
+}
+
+// xml decodes a (optional) xml part.
+func (r *ImportTable) xml(files map[string]*zip.File, name string, object any) (err error) {
+	f, found := files[name]
+	if !found {
+		return
+	}
+	reader, err := f.Open()
+	if err != nil {
+		err = &BadRequestError{Reason: err.Error()}
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	err = xml.NewDecoder(reader).Decode(object)
+	if err != nil {
+		err = &BadRequestError{Reason: err.Error()}
+	}
+	return
+}
+
+// xlsxText (shared or inline) rich text.
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String returns the text.
+func (t *xlsxText) String() (s string) {
+	s = t.Text
+	for _, run := range t.Runs {
+		s += run.Text
+	}
+	return
+}
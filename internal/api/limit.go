@@ -0,0 +1,270 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/internal/auth"
+	"github.com/konveyor/tackle2-hub/internal/metrics"
+	"github.com/konveyor/tackle2-hub/shared/settings"
+)
+
+// Limit groups and reasons.
+const (
+	// LimitDefault the (default) group shared by route groups
+	// without a configured budget.
+	LimitDefault = "default"
+	// LimitRate the request rate exceeded.
+	LimitRate = "rate"
+	// LimitInFlight the concurrent requests exceeded.
+	LimitInFlight = "inflight"
+)
+
+// LimitIdle the duration after which idle subjects are pruned.
+const LimitIdle = 10 * time.Minute
+
+// Limits the API (request) limiter.
+var Limits = &Limiter{
+	Settings: &Settings.Hub.Limit,
+}
+
+// limited enforces the API (request) limits for the route group.
+// Limits are keyed by the auth subject. Task tokens are keyed by
+// task because they share the (addon) service account subject.
+// Operations dispatched by a batch are charged against the rate
+// of the group but do not use an in-flight request because the
+// batch request is in-flight.
+// Returns a function used to release the (in-flight) request.
+func limited(ctx *gin.Context, group string) (release func()) {
+	release = func() {}
+	if !Limits.Settings.Enabled {
+		return
+	}
+	rtx := RichContext(ctx)
+	kind := rtx.SubjectKind
+	key := rtx.Subject
+	switch {
+	case kind == auth.SubjectTask:
+		key = "task/" + strconv.FormatUint(uint64(rtx.Task), 10)
+	case key == "":
+		key = "ip/" + ctx.ClientIP()
+	}
+	if kind == "" {
+		kind = auth.SubjectUser
+	}
+	_, batched := ctx.Request.Context().Value(batchLimitedKey{}).(bool)
+	if batched {
+		err := Limits.Charge(key, kind, group)
+		if err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+		}
+		return
+	}
+	acquired, err := Limits.Acquire(key, kind, group)
+	if err != nil {
+		_ = ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+	release = acquired
+	return
+}
+
+// Limiter enforces API (request) limits.
+// Each subject has a token-bucket (rate) and in-flight (concurrency)
+// budget based on the kind of subject: user|serviceaccount|task.
+// Route groups with a configured budget are limited separately. All
+// other route groups share the (default) budget for the subject kind.
+type Limiter struct {
+	// Settings the limit settings.
+	Settings *settings.Limit
+	// mutex
+	mutex sync.Mutex
+	// limits by subject (key) and group.
+	limits map[string]*limit
+	// pruned the last time idle limits were pruned.
+	pruned time.Time
+}
+
+// Acquire a request for the subject (key) in the route group.
+// Returns a function used to release the (in-flight) request,
+// or TooManyRequests when the budget is exceeded.
+func (r *Limiter) Acquire(key, kind, group string) (release func(), err error) {
+	budget, group := r.budget(kind, group)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	m := r.find(key, group, budget, now)
+	reason, wait := m.acquire(budget, now)
+	if reason != "" {
+		err = r.rejected(kind, group, reason, wait)
+		return
+	}
+	metrics.LimitAdmitted.WithLabelValues(kind, group).Inc()
+	metrics.LimitInFlight.WithLabelValues(kind, group).Inc()
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			m.release(time.Now())
+			metrics.LimitInFlight.WithLabelValues(kind, group).Dec()
+		})
+	}
+	return
+}
+
+// Charge a request for the subject (key) in the route group
+// against the rate only. No (in-flight) request is acquired.
+// Returns TooManyRequests when the rate is exceeded.
+func (r *Limiter) Charge(key, kind, group string) (err error) {
+	budget, group := r.budget(kind, group)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	m := r.find(key, group, budget, now)
+	reason, wait := m.charge(budget, now)
+	if reason != "" {
+		err = r.rejected(kind, group, reason, wait)
+		return
+	}
+	metrics.LimitAdmitted.WithLabelValues(kind, group).Inc()
+	return
+}
+
+// find returns the limit for the subject (key) in the route group.
+// Created (with a full bucket) when not found.
+// Must be called with the mutex held.
+func (r *Limiter) find(key, group string, budget settings.Budget, now time.Time) (m *limit) {
+	r.prune(now)
+	id := key + "|" + group
+	m, found := r.limits[id]
+	if !found {
+		m = &limit{
+			tokens:  float64(budget.Burst),
+			updated: now,
+		}
+		r.limits[id] = m
+		metrics.LimitSubjects.Set(float64(len(r.limits)))
+	}
+	return
+}
+
+// rejected returns the error for a rejected request.
+func (r *Limiter) rejected(kind, group, reason string, wait time.Duration) (err error) {
+	metrics.LimitRejected.WithLabelValues(kind, group, reason).Inc()
+	err = &TooManyRequests{
+		Reason: fmt.Sprintf(
+			"Limit (%s) exceeded for group: '%s'.",
+			reason,
+			group),
+		RetryAfter: wait,
+	}
+	return
+}
+
+// budget returns the budget for the subject kind in the route group.
+// Group budgets are matched by: <group>:<kind> then <group>.
+// Returns the default budget (and group) when not matched.
+func (r *Limiter) budget(kind, group string) (b settings.Budget, matched string) {
+	var found bool
+	for _, k := range []string{group + ":" + kind, group} {
+		b, found = r.Settings.Groups[k]
+		if found {
+			matched = group
+			return
+		}
+	}
+	matched = LimitDefault
+	switch kind {
+	case auth.SubjectTask:
+		b = r.Settings.Task
+	case auth.SubjectServiceAccount:
+		b = r.Settings.ServiceAccount
+	default:
+		b = r.Settings.User
+	}
+	return
+}
+
+// prune deletes idle limits.
+// Must be called with the mutex held.
+func (r *Limiter) prune(now time.Time) {
+	if r.limits == nil {
+		r.limits = make(map[string]*limit)
+	}
+	if now.Sub(r.pruned) < time.Minute {
+		return
+	}
+	r.pruned = now
+	for id, m := range r.limits {
+		if m.idle(now) {
+			delete(r.limits, id)
+		}
+	}
+	metrics.LimitSubjects.Set(float64(len(r.limits)))
+}
+
+// limit the state of a subject (in a group).
+type limit struct {
+	// tokens available in the bucket.
+	tokens float64
+	// updated the last time tokens were added.
+	updated time.Time
+	// inFlight the number of concurrent requests.
+	inFlight int
+	// used the last time a request was made or released.
+	used time.Time
+}
+
+// acquire a request.
+// Returns the reason and suggested wait when not acquired.
+func (m *limit) acquire(b settings.Budget, now time.Time) (reason string, wait time.Duration) {
+	m.used = now
+	if b.InFlight > 0 && m.inFlight >= b.InFlight {
+		reason = LimitInFlight
+		wait = time.Second
+		return
+	}
+	reason, wait = m.charge(b, now)
+	if reason != "" {
+		return
+	}
+	m.inFlight++
+	return
+}
+
+// charge a request against the rate.
+// Returns the reason and suggested wait when not charged.
+func (m *limit) charge(b settings.Budget, now time.Time) (reason string, wait time.Duration) {
+	m.used = now
+	if b.Rate > 0 {
+		elapsed := now.Sub(m.updated).Seconds()
+		m.tokens = min(float64(b.Burst), m.tokens+elapsed*b.Rate)
+		m.updated = now
+		if m.tokens < 1 {
+			reason = LimitRate
+			wait = time.Duration((1 - m.tokens) / b.Rate * float64(time.Second))
+			return
+		}
+		m.tokens--
+	}
+	return
+}
+
+// release a request.
+func (m *limit) release(now time.Time) {
+	m.inFlight--
+	m.used = now
+}
+
+// idle returns true when no requests are in-flight
+// and no request has been made within LimitIdle.
+func (m *limit) idle(now time.Time) (idle bool) {
+	idle = m.inFlight == 0 && now.Sub(m.used) > LimitIdle
+	return
+}
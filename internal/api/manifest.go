@@ -79,6 +79,7 @@ func (h ManifestHandler) Get(ctx *gin.Context) {
 // @description List all manifests.
 // @description filters:
 // @description   - application.id
+// @description   - content.(path)
 // @tags manifests
 // @produce json
 // @success 200 {object} []Manifest
@@ -91,17 +92,20 @@ func (h ManifestHandler) List(ctx *gin.Context) {
 	filter, err := qf.New(ctx,
 		[]qf.Assert{
 			{Field: "application.id", Kind: qf.LITERAL},
+			{Field: "content", Kind: qf.JSON},
 		})
 	if err != nil {
 		_ = ctx.Error(err)
 		return
 	}
 	filter = filter.Renamed("application.id", "applicationid")
+	content := filter.Resource("content")
 	// Fetch.
 	var list []model.Manifest
 	db := h.DB(ctx)
 	db = db.Preload(clause.Associations)
 	db = filter.Where(db)
+	db = content.JsonWhere(db, "Content")
 	err = db.Find(&list).Error
 	if err != nil {
 		_ = ctx.Error(err)
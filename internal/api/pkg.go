@@ -34,6 +34,7 @@ const (
 	Wildcard  = api.Wildcard
 	FileField = api.FileField
 	Decrypted = api.Decrypted
+	Fields    = api.Fields
 )
 
 // Scopes
@@ -43,22 +44,30 @@ const (
 
 // Headers
 const (
-	Accept        = api.Accept
-	Authorization = api.Authorization
-	ContentType   = api.ContentType
-	Directory     = api.Directory
-	Total         = api.Total
+	Accept         = api.Accept
+	Authorization  = api.Authorization
+	ContentType    = api.ContentType
+	Directory      = api.Directory
+	Total          = api.Total
+	IdempotencyKey = api.IdempotencyKey
+	Replayed       = api.Replayed
+	RetryAfter     = api.RetryAfter
 )
 
 // MIME Types.
 const (
 	MIMEOCTETSTREAM = api.MIMEOCTETSTREAM
+	MIMECSV         = api.MIMECSV
+	MIMEXLSX        = api.MIMEXLSX
 	TAR             = api.TAR
 )
 
 // BindMIMEs supported binding MIME types.
 var BindMIMEs = []string{api.MIMEJSON, api.MIMEYAML}
 
+// RenderMIMEs supported (list) rendering MIME types.
+var RenderMIMEs = []string{api.MIMEJSON, api.MIMEYAML, api.MIMECSV, api.MIMEXLSX}
+
 // Header Values
 const (
 	DirectoryExpand = api.DirectoryExpand
@@ -89,6 +98,7 @@ func All() []Handler {
 		&DependencyHandler{},
 		&GeneratorHandler{},
 		&ImportHandler{},
+		&ImportProfileHandler{},
 		&JobFunctionHandler{},
 		&IdentityHandler{},
 		&PlatformHandler{},
@@ -97,6 +107,8 @@ func All() []Handler {
 		&ReviewHandler{},
 		&RuleSetHandler{},
 		&SchemaHandler{},
+		&ScimHandler{},
+		&SecretHandler{},
 		&SettingHandler{},
 		&ServiceHandler{},
 		&StakeholderHandler{},
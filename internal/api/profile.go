@@ -21,6 +21,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // AnalysisProfileHandler handles application Profile resource routes.
@@ -76,7 +77,7 @@ func (h AnalysisProfileHandler) Get(ctx *gin.Context) {
 // @param id path int true "Profile ID"
 func (h AnalysisProfileHandler) GetBundle(ctx *gin.Context) {
 	id := h.pk(ctx)
-	bundle := ApBundle{}
+	bundle := ApBundle{Client: RichContext(ctx).Client}
 	tmpDir, err := bundle.Build(h.DB(ctx), id)
 	if err != nil {
 		_ = ctx.Error(err)
@@ -257,7 +258,7 @@ func (h AnalysisProfileHandler) AppProfileList(ctx *gin.Context) {
 		return
 	}
 	for _, archetype := range archetypes {
-		for _, p := range archetype.Profiles {
+		for _, p := range archetype.AllProfiles() {
 			if p.AnalysisProfileID != nil {
 				ids = append(ids, *p.AnalysisProfileID)
 			}
@@ -289,6 +290,7 @@ type AnalysisProfile = resource.AnalysisProfile
 
 // ApBundle defines and builds the application bundle.
 type ApBundle struct {
+	Client  k8s.Client
 	db      *gorm.DB
 	tmpDir  string
 	ruleDir string
@@ -495,7 +497,7 @@ func (b *ApBundle) addRepository(rootDir string, repository *model.Repository) (
 		URL:    repository.URL,
 		Branch: repository.Branch,
 	}
-	mirror := scm.GetMirror(b.db, remote)
+	mirror := scm.GetMirror(b.db, b.Client, remote)
 	err = mirror.CopyTo(repository.Path, rootDir)
 	if err != nil {
 		return
@@ -25,6 +25,8 @@ func (h QuestionnaireHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.GET(api.QuestionnaireRoute, h.Get)
 	routeGroup.PUT(api.QuestionnaireRoute, h.Update)
 	routeGroup.DELETE(api.QuestionnaireRoute, h.Delete)
+	routeGroup.GET(api.QuestionnaireVersionsRoute, h.VersionList)
+	routeGroup.GET(api.QuestionnaireVersionRoute, h.VersionGet)
 }
 
 // Get godoc
@@ -142,6 +144,8 @@ func (h QuestionnaireHandler) Delete(ctx *gin.Context) {
 // @description Update a questionnaire. If the Questionnaire
 // @description is builtin, only its "required" field can be changed
 // @description and all other fields will be ignored.
+// @description A new version is recorded when the content is changed.
+// @description Existing assessments are not changed (see: /assessments/{id}/upgrade).
 // @tags questionnaires
 // @accept json
 // @success 204
@@ -191,5 +195,71 @@ func (h QuestionnaireHandler) Update(ctx *gin.Context) {
 	h.Status(ctx, http.StatusNoContent)
 }
 
+// VersionList godoc
+// @summary List the versions of a questionnaire.
+// @description List the (immutable) versions of a questionnaire.
+// @description A version is recorded each time the sections, thresholds
+// @description or risk messages are changed.
+// @tags questionnaires
+// @produce json
+// @success 200 {object} []api.QuestionnaireVersion
+// @router /questionnaires/{id}/versions [get]
+// @param id path int true "Questionnaire ID"
+func (h QuestionnaireHandler) VersionList(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Questionnaire{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	var list []model.QuestionnaireVersion
+	db := h.preLoad(h.DB(ctx), clause.Associations)
+	db = db.Where("QuestionnaireID", id)
+	db = db.Order("Version")
+	result = db.Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []QuestionnaireVersion{}
+	for i := range list {
+		r := QuestionnaireVersion{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// VersionGet godoc
+// @summary Get a questionnaire version.
+// @description Get a questionnaire version.
+// @tags questionnaires
+// @produce json
+// @success 200 {object} api.QuestionnaireVersion
+// @router /questionnaires/{id}/versions/{version} [get]
+// @param id path int true "Questionnaire ID"
+// @param version path int true "Version"
+func (h QuestionnaireHandler) VersionGet(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.QuestionnaireVersion{}
+	db := h.preLoad(h.DB(ctx), clause.Associations)
+	db = db.Where("QuestionnaireID", id)
+	db = db.Where("Version", ctx.Param(ID2))
+	result := db.First(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := QuestionnaireVersion{}
+	r.With(m)
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
 // Questionnaire REST resource.
 type Questionnaire = resource.Questionnaire
+
+// QuestionnaireVersion REST resource.
+type QuestionnaireVersion = resource.QuestionnaireVersion
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	qf "github.com/konveyor/tackle2-hub/internal/api/filter"
+	"github.com/konveyor/tackle2-hub/internal/assessment"
+	"gorm.io/gorm"
+)
+
+// RankedFields the (computed) fields supported by the ranking.
+var RankedFields = []string{
+	"risk",
+	"confidence",
+	"score",
+}
+
+// Ranked values used to rank resources.
+type Ranked struct {
+	ID         uint
+	Name       string
+	Risk       string
+	Confidence int
+	Score      float64
+}
+
+// Ranking provides filtering and sorting of resources by values
+// derived from assessments (risk, confidence and score). The values
+// are computed (not stored) so the ranking is applied after the
+// resources have been built.
+type Ranking struct {
+	fields []qf.Field
+	sort   Sort
+}
+
+// With context.
+// The ranked fields are removed from the filter.
+func (r *Ranking) With(ctx *gin.Context, filter *qf.Filter) (err error) {
+	for _, name := range RankedFields {
+		r.fields = append(r.fields, filter.Fields(name)...)
+		filter.Delete(name)
+	}
+	err = r.sort.With(ctx, &Ranked{})
+	return
+}
+
+// Ranked returns true when filtering or sorting on the computed
+// fields has been requested. Sorting only by id and name is done
+// by the DB (see: Sorted).
+func (r *Ranking) Ranked() (b bool) {
+	if len(r.fields) > 0 {
+		b = true
+		return
+	}
+	for _, clause := range r.sort.Clauses() {
+		if slices.Contains(RankedFields, clause.Name()) {
+			b = true
+			break
+		}
+	}
+	return
+}
+
+// Sorted returns the DB sorted by the (id and name) sort clauses.
+// The alias is the (optional) table alias.
+func (r *Ranking) Sorted(in *gorm.DB, alias string) (out *gorm.DB) {
+	out = in
+	if alias != "" {
+		alias += "."
+	}
+	for _, clause := range r.sort.Clauses() {
+		column := ""
+		switch clause.Name() {
+		case "id":
+			column = alias + "ID"
+		case "name":
+			column = alias + "Name COLLATE NOCASE"
+		default:
+			continue
+		}
+		if clause.Descending() {
+			column += " DESC"
+		}
+		out = out.Order(column)
+	}
+	return
+}
+
+// Apply returns the indexes of the matched values in (sorted) order
+// and the total number matched.
+// The page is applied after the values have been filtered and sorted.
+func (r *Ranking) Apply(values []Ranked, page Page) (index []int, total int) {
+	for i := range values {
+		if r.match(&values[i]) {
+			index = append(index, i)
+		}
+	}
+	total = len(index)
+	clauses := r.sort.Clauses()
+	slices.SortStableFunc(index, func(a, b int) (n int) {
+		for _, clause := range clauses {
+			n = r.compare(&values[a], &values[b], clause.Name())
+			if clause.Descending() {
+				n = -n
+			}
+			if n != 0 {
+				break
+			}
+		}
+		return
+	})
+	if page.Offset > 0 {
+		index = index[min(page.Offset, len(index)):]
+	}
+	if page.Limit > 0 {
+		index = index[:min(page.Limit, len(index))]
+	}
+	return
+}
+
+// match returns true when the values match the filter.
+func (r *Ranking) match(v *Ranked) (matched bool) {
+	for _, f := range r.fields {
+		switch strings.ToLower(f.Name()) {
+		case "risk":
+			matched = f.Match(v.Risk)
+		case "confidence":
+			matched = f.Match(v.Confidence)
+		case "score":
+			matched = f.Match(v.Score)
+		}
+		if !matched {
+			return
+		}
+	}
+	matched = true
+	return
+}
+
+// compare the named field.
+func (r *Ranking) compare(a, b *Ranked, name string) (n int) {
+	switch name {
+	case "id":
+		n = cmp.Compare(a.ID, b.ID)
+	case "name":
+		n = cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case "risk":
+		n = cmp.Compare(r.riskOrder(a.Risk), r.riskOrder(b.Risk))
+	case "confidence":
+		n = cmp.Compare(a.Confidence, b.Confidence)
+	case "score":
+		n = cmp.Compare(a.Score, b.Score)
+	}
+	return
+}
+
+// riskOrder returns the order of the risk (lowest first).
+func (r *Ranking) riskOrder(risk string) (n int) {
+	switch risk {
+	case assessment.RiskGreen:
+		n = 1
+	case assessment.RiskYellow:
+		n = 2
+	case assessment.RiskUnknown:
+		n = 3
+	case assessment.RiskRed:
+		n = 4
+	}
+	return
+}
@@ -135,6 +135,10 @@ func (r *Application) WithResolver(m *model.Application, resolver *assessment.Ap
 		if err != nil {
 			return
 		}
+		r.Score, err = resolver.Score(m)
+		if err != nil {
+			return
+		}
 	}
 	return
 }
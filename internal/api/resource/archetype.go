@@ -50,6 +50,8 @@ func (r *Archetype) With(m *model.Archetype) {
 	r.Name = m.Name
 	r.Description = m.Description
 	r.Comments = m.Comments
+	r.Selector = m.Selector
+	r.Parent = refPtr(m.ParentID, m.Parent)
 	r.Tags = []TagRef{}
 	for _, t := range m.Tags {
 		r.Tags = append(r.Tags, TagRef{ID: t.ID, Name: t.Name, Source: "", Virtual: false})
@@ -89,6 +91,7 @@ func (r *Archetype) WithResolver(resolver *assessment.ArchetypeResolver) (err er
 	if r.Assessed {
 		r.Risk = resolver.Risk()
 		r.Confidence = resolver.Confidence()
+		r.Score = resolver.Score()
 	}
 	apps, err := resolver.Applications()
 	for i := range apps {
@@ -97,15 +100,47 @@ func (r *Archetype) WithResolver(resolver *assessment.ArchetypeResolver) (err er
 	for _, t := range resolver.AssessmentTags() {
 		r.Tags = append(r.Tags, TagRef{ID: t.ID, Name: t.Name, Source: SourceAssessment, Virtual: true})
 	}
+	r.withInherited(resolver.Archetype())
 	return
 }
 
+// withInherited updates the resource with resources inherited from ancestors.
+func (r *Archetype) withInherited(archetype assessment.Archetype) {
+	if len(archetype.Ancestors) == 0 {
+		return
+	}
+	inherited := &api.ArchetypeInherited{}
+	for _, m := range archetype.Ancestors {
+		inherited.Ancestors = append(inherited.Ancestors, ref(m.ID, m))
+	}
+	for _, t := range archetype.Inherited.Tags {
+		r.Tags = append(r.Tags, TagRef{ID: t.ID, Name: t.Name, Source: SourceArchetype, Virtual: true})
+	}
+	for _, s := range archetype.Inherited.Stakeholders {
+		inherited.Stakeholders = append(inherited.Stakeholders, ref(s.ID, &s))
+	}
+	for _, g := range archetype.Inherited.StakeholderGroups {
+		inherited.StakeholderGroups = append(inherited.StakeholderGroups, ref(g.ID, &g))
+	}
+	for _, a := range archetype.Inherited.Assessments {
+		inherited.Assessments = append(inherited.Assessments, ref(a.ID, a.Assessment))
+	}
+	for _, p := range archetype.Inherited.Profiles {
+		pr := TargetProfile{}
+		pr.With(&p)
+		inherited.Profiles = append(inherited.Profiles, api.TargetProfile(pr))
+	}
+	r.Inherited = inherited
+}
+
 // Model builds a model from the resource.
 func (r *Archetype) Model() (m *model.Archetype) {
 	m = &model.Archetype{
 		Name:        r.Name,
 		Description: r.Description,
 		Comments:    r.Comments,
+		Selector:    r.Selector,
+		ParentID:    idPtr(r.Parent),
 	}
 	m.ID = r.ID
 	for _, ref := range r.Tags {
@@ -157,3 +192,31 @@ func (r *Archetype) Model() (m *model.Archetype) {
 
 	return
 }
+
+// Membership REST resource.
+type Membership api.Membership
+
+// With updates the resource with the explained membership.
+func (r *Membership) With(m *assessment.Membership) {
+	r.Archetype = ref(m.Archetype.ID, m.Archetype.Archetype)
+	r.Matched = m.Matched
+	r.Member = m.Member
+	r.Selector = m.Archetype.Selector
+	for _, t := range m.Missing {
+		r.Missing = append(r.Missing, ref(t.ID, &t))
+	}
+	for _, e := range m.Evaluated {
+		r.Predicates = append(
+			r.Predicates,
+			api.MembershipPredicate{
+				Predicate: e.Predicate,
+				Matched:   e.Matched,
+			})
+	}
+	for _, a := range m.Superseded {
+		r.Superseded = append(r.Superseded, ref(a.ID, a.Archetype))
+	}
+	if m.Error != nil {
+		r.Error = m.Error.Error()
+	}
+}
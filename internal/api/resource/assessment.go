@@ -1,6 +1,8 @@
 package resource
 
 import (
+	"fmt"
+
 	"github.com/konveyor/tackle2-hub/internal/assessment"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/shared/api"
@@ -22,6 +24,7 @@ type Assessment api.Assessment
 func (r *Assessment) With(m *model.Assessment) {
 	baseWith(&r.Resource, &m.Model)
 	r.Questionnaire = ref(m.QuestionnaireID, &m.Questionnaire)
+	r.QuestionnaireVersion = m.QuestionnaireVersion
 	r.Archetype = refPtr(m.ArchetypeID, m.Archetype)
 	r.Application = refPtr(m.ApplicationID, m.Application)
 	r.Stakeholders = []Ref{}
@@ -43,12 +46,19 @@ func (r *Assessment) With(m *model.Assessment) {
 	r.Required = a.Questionnaire.Required
 	r.Risk = a.Risk()
 	r.Confidence = a.Confidence()
+	r.Score = a.Scored().Score
 	r.RiskMessages = api.RiskMessages(a.RiskMessages)
 	r.Thresholds = api.Thresholds(a.Thresholds)
+	r.Scoring = api.Scoring(a.Scoring)
 	r.Sections = []api.Section{}
+	hidden := a.Hidden()
 	for _, s := range a.Sections {
 		sect := Section{}
 		sect.With(&s)
+		for i := range sect.Questions {
+			q := &sect.Questions[i]
+			q.Hidden = hidden[assessment.QuestionKey{Section: s.Order, Question: q.Order}]
+		}
 		r.Sections = append(r.Sections, api.Section(sect))
 	}
 	r.Status = a.Status()
@@ -86,11 +96,34 @@ func (r *Assessment) Model() (m *model.Assessment) {
 	return
 }
 
+// Validate the answer (auto-answer) conditions.
+func (r *Assessment) Validate() (err error) {
+	for i, section := range r.Sections {
+		for j, question := range section.Questions {
+			for k, answer := range question.Answers {
+				if answer.AutoAnswerWhen == "" {
+					continue
+				}
+				criteria := &assessment.Criteria{}
+				err = criteria.Validate(answer.AutoAnswerWhen)
+				if err != nil {
+					err = &ValidationError{
+						fmt.Sprintf("answer %d (%s) in question %d (%s) in section %d (%s) has invalid auto-answer condition: %s", k, answer.Text, j, question.Text, i, section.Name, err.Error()),
+					}
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
 // Question REST resource.
 type Question api.Question
 
 // With updates the resource with the model.
 func (r *Question) With(m *model.Question) {
+	r.ID = m.ID
 	r.Order = m.Order
 	r.Text = m.Text
 	r.Explanation = m.Explanation
@@ -102,6 +135,10 @@ func (r *Question) With(m *model.Question) {
 	for _, t := range m.ExcludeFor {
 		r.ExcludeFor = append(r.ExcludeFor, api.CategorizedTag(t))
 	}
+	for _, ref := range m.ShowWhen {
+		r.ShowWhen = append(r.ShowWhen, api.AnswerRef(ref))
+	}
+	r.Weight = m.Weight
 	r.Answers = []api.Answer{}
 	for _, a := range m.Answers {
 		answer := Answer{}
@@ -113,9 +150,11 @@ func (r *Question) With(m *model.Question) {
 // Model builds a model.
 func (r *Question) Model() (m *model.Question) {
 	m = &model.Question{
+		ID:          r.ID,
 		Order:       r.Order,
 		Text:        r.Text,
 		Explanation: r.Explanation,
+		Weight:      r.Weight,
 	}
 	for _, t := range r.IncludeFor {
 		m.IncludeFor = append(m.IncludeFor, model.CategorizedTag(t))
@@ -123,6 +162,9 @@ func (r *Question) Model() (m *model.Question) {
 	for _, t := range r.ExcludeFor {
 		m.ExcludeFor = append(m.ExcludeFor, model.CategorizedTag(t))
 	}
+	for _, ref := range r.ShowWhen {
+		m.ShowWhen = append(m.ShowWhen, model.AnswerRef(ref))
+	}
 	for _, a := range r.Answers {
 		answer := Answer(a)
 		m.Answers = append(m.Answers, *answer.Model())
@@ -140,8 +182,11 @@ func (r *Answer) With(m *model.Answer) {
 	r.Risk = m.Risk
 	r.Rationale = m.Rationale
 	r.Mitigation = m.Mitigation
+	r.Score = m.Score
 	r.Selected = m.Selected
 	r.AutoAnswered = m.AutoAnswered
+	r.AutoAnswerWhen = m.AutoAnswerWhen
+	r.AutoAnswerReason = m.AutoAnswerReason
 	r.ApplyTags = []api.CategorizedTag{}
 	for _, t := range m.ApplyTags {
 		r.ApplyTags = append(r.ApplyTags, api.CategorizedTag(t))
@@ -155,13 +200,16 @@ func (r *Answer) With(m *model.Answer) {
 // Model builds a model.
 func (r *Answer) Model() (m *model.Answer) {
 	m = &model.Answer{
-		Order:        r.Order,
-		Text:         r.Text,
-		Risk:         r.Risk,
-		Rationale:    r.Rationale,
-		Mitigation:   r.Mitigation,
-		Selected:     r.Selected,
-		AutoAnswered: r.AutoAnswered,
+		Order:            r.Order,
+		Text:             r.Text,
+		Risk:             r.Risk,
+		Rationale:        r.Rationale,
+		Mitigation:       r.Mitigation,
+		Score:            r.Score,
+		Selected:         r.Selected,
+		AutoAnswered:     r.AutoAnswered,
+		AutoAnswerWhen:   r.AutoAnswerWhen,
+		AutoAnswerReason: r.AutoAnswerReason,
 	}
 	for _, t := range r.ApplyTags {
 		m.ApplyTags = append(m.ApplyTags, model.CategorizedTag(t))
@@ -180,6 +228,7 @@ func (r *Section) With(m *model.Section) {
 	r.Order = m.Order
 	r.Name = m.Name
 	r.Comment = m.Comment
+	r.Weight = m.Weight
 	r.Questions = []api.Question{}
 	for _, q := range m.Questions {
 		question := Question{}
@@ -194,6 +243,7 @@ func (r *Section) Model() (m *model.Section) {
 		Order:   r.Order,
 		Name:    r.Name,
 		Comment: r.Comment,
+		Weight:  r.Weight,
 	}
 	for _, q := range r.Questions {
 		question := Question(q)
@@ -201,3 +251,23 @@ func (r *Section) Model() (m *model.Section) {
 	}
 	return
 }
+
+// AssessmentUpgrade REST resource.
+type AssessmentUpgrade api.AssessmentUpgrade
+
+// With updates the resource with the report.
+func (r *AssessmentUpgrade) With(m *assessment.UpgradeReport) {
+	r.From = m.From
+	r.To = m.To
+	r.Carried = r.questions(m.Carried)
+	r.Dropped = r.questions(m.Dropped)
+	r.Reanswer = r.questions(m.Reanswer)
+}
+
+// questions returns the reported questions.
+func (r *AssessmentUpgrade) questions(list []assessment.UpgradedQuestion) (questions []api.UpgradedQuestion) {
+	for _, q := range list {
+		questions = append(questions, api.UpgradedQuestion(q))
+	}
+	return
+}
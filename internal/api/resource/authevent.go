@@ -0,0 +1,20 @@
+package resource
+
+import (
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// AuthEvent REST resource.
+type AuthEvent api.AuthEvent
+
+// With converts model to REST resource.
+func (r *AuthEvent) With(m *model.AuthEvent) {
+	baseWith(&r.Resource, &m.Model)
+	r.Subject = m.Subject
+	r.Login = m.Login
+	r.Method = m.Method
+	r.Source = m.Source
+	r.Succeeded = m.Succeeded
+	r.Reason = m.Reason
+}
@@ -18,3 +18,12 @@ type RestAPI = api.RestAPI
 
 // Service REST resource.
 type Service = api.Service
+
+// Batch REST resource.
+type Batch = api.Batch
+
+// BatchOperation REST nested resource.
+type BatchOperation = api.BatchOperation
+
+// BatchResult REST nested resource.
+type BatchResult = api.BatchResult
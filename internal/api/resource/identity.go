@@ -19,6 +19,24 @@ func (r *Identity) With(m *model.Identity) {
 	r.Password = m.Password
 	r.Key = m.Key
 	r.Settings = m.Settings
+	if m.Secret != nil {
+		r.Secret = &api.IdentitySecret{
+			Name:     m.Secret.Name,
+			User:     m.Secret.User,
+			Password: m.Secret.Password,
+			Key:      m.Secret.Key,
+			Settings: m.Secret.Settings,
+		}
+	} else {
+		r.Secret = nil
+	}
+	if m.Verification != nil {
+		v := &IdentityVerification{}
+		v.With(m.Verification)
+		r.Verification = (*api.IdentityVerification)(v)
+	} else {
+		r.Verification = nil
+	}
 }
 
 // Model builds a model.
@@ -33,7 +51,28 @@ func (r *Identity) Model() (m *model.Identity) {
 		Key:         r.Key,
 		Settings:    r.Settings,
 	}
+	if r.Secret != nil {
+		m.Secret = &model.IdentitySecret{
+			Name:     r.Secret.Name,
+			User:     r.Secret.User,
+			Password: r.Secret.Password,
+			Key:      r.Secret.Key,
+			Settings: r.Secret.Settings,
+		}
+	}
 	m.ID = r.ID
 
 	return
 }
+
+// IdentityVerification REST resource.
+type IdentityVerification api.IdentityVerification
+
+// With updates the resource with the model.
+func (r *IdentityVerification) With(m *model.IdentityVerification) {
+	r.Verified = m.Verified
+	r.Succeeded = m.Succeeded
+	r.Application = m.Application
+	r.Target = m.Target
+	r.Error = m.Error
+}
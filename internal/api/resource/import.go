@@ -20,6 +20,13 @@ func (r *ImportSummary) With(m *model.ImportSummary) {
 	r.Filename = m.Filename
 	r.ImportTime = m.CreateTime
 	r.CreateEntities = m.CreateEntities
+	r.Mode = m.Mode
+	r.TagMode = m.TagMode
+	r.MatchBy = m.MatchBy
+	r.DryRun = m.DryRun
+	r.Atomic = m.Atomic
+	r.Format = m.Format
+	r.Profile = refPtr(m.ProfileID, m.Profile)
 	for _, imp := range m.Imports {
 		if imp.Processed {
 			if imp.IsValid {
@@ -28,6 +35,30 @@ func (r *ImportSummary) With(m *model.ImportSummary) {
 				r.InvalidCount++
 			}
 		}
+		for _, change := range imp.Changes {
+			r.Changes = append(
+				r.Changes,
+				api.ImportChange{
+					Import:      imp.ID,
+					Application: imp.ApplicationName,
+					Field:       change.Field,
+					Before:      change.Before,
+					After:       change.After,
+				})
+		}
+		if m.DryRun && imp.Processed {
+			r.Report = append(
+				r.Report,
+				api.ImportRow{
+					Import:      imp.ID,
+					Row:         imp.Row,
+					RecordType:  imp.RecordType1,
+					Application: imp.ApplicationName,
+					Action:      imp.Action,
+					Valid:       imp.IsValid,
+					Issues:      imp.Issues,
+				})
+		}
 	}
 	if len(m.Imports) == r.ValidCount+r.InvalidCount {
 		r.ImportStatus = Completed
@@ -35,3 +66,42 @@ func (r *ImportSummary) With(m *model.ImportSummary) {
 		r.ImportStatus = InProgress
 	}
 }
+
+// ImportProfile REST resource.
+type ImportProfile api.ImportProfile
+
+// With updates the resource with the model.
+func (r *ImportProfile) With(m *model.ImportProfile) {
+	baseWith(&r.Resource, &m.Model)
+	r.Name = m.Name
+	r.Description = m.Description
+	r.RecordType = m.RecordType
+	r.Columns = []api.ImportColumn{}
+	for _, c := range m.Columns {
+		r.Columns = append(
+			r.Columns,
+			api.ImportColumn{
+				Name:  c.Name,
+				Field: c.Field,
+			})
+	}
+}
+
+// Model builds a model.
+func (r *ImportProfile) Model() (m *model.ImportProfile) {
+	m = &model.ImportProfile{
+		Name:        r.Name,
+		Description: r.Description,
+		RecordType:  r.RecordType,
+	}
+	m.ID = r.ID
+	for _, c := range r.Columns {
+		m.Columns = append(
+			m.Columns,
+			model.ImportColumn{
+				Name:  c.Name,
+				Field: c.Field,
+			})
+	}
+	return
+}
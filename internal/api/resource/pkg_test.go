@@ -2,6 +2,7 @@ package resource
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -674,6 +675,34 @@ func TestAssessment_Model_NilPointers(t *testing.T) {
 	g.Expect(m.ArchetypeID).To(gomega.BeNil())
 }
 
+// TestAssessment_Validate tests Assessment.Validate() with answer conditions
+func TestAssessment_Validate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	r := &Assessment{
+		Sections: []api.Section{
+			{
+				Name: "Section",
+				Questions: []api.Question{
+					{
+						Text: "Question",
+						Answers: []api.Answer{
+							{Text: "Yes", AutoAnswerWhen: "rule:jms-* && !tag:Language=Java"},
+							{Text: "No"},
+						},
+					},
+				},
+			},
+		},
+	}
+	g.Expect(r.Validate()).To(gomega.Succeed())
+
+	r.Sections[0].Questions[0].Answers[1].AutoAnswerWhen = "unknown:jms"
+	err := r.Validate()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(errors.Is(err, &ValidationError{})).To(gomega.BeTrue())
+}
+
 // TestReview_With tests the Review.With() method
 func TestReview_With(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
@@ -1845,6 +1874,7 @@ func TestAnalysisProfile_Model(t *testing.T) {
 func TestArchetype_With(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
 
+	parentID := uint(2)
 	m := &model.Archetype{
 		Model: model.Model{
 			ID:         1,
@@ -1853,6 +1883,9 @@ func TestArchetype_With(t *testing.T) {
 		Name:        "Test Archetype",
 		Description: "Test description",
 		Comments:    "Some comments",
+		Selector:    "tag:Language=Java",
+		ParentID:    &parentID,
+		Parent:      &model.Archetype{Name: "Parent"},
 		Tags: []model.Tag{
 			{Model: model.Model{ID: 10}, Name: "tag1"},
 		},
@@ -1874,6 +1907,8 @@ func TestArchetype_With(t *testing.T) {
 	g.Expect(r.Name).To(gomega.Equal("Test Archetype"))
 	g.Expect(r.Description).To(gomega.Equal("Test description"))
 	g.Expect(r.Comments).To(gomega.Equal("Some comments"))
+	g.Expect(r.Selector).To(gomega.Equal("tag:Language=Java"))
+	g.Expect(r.Parent).To(gomega.Equal(&Ref{ID: 2, Name: "Parent"}))
 	g.Expect(len(r.Tags)).To(gomega.Equal(1))
 	g.Expect(r.Tags[0].ID).To(gomega.Equal(uint(10)))
 	g.Expect(len(r.Criteria)).To(gomega.Equal(1))
@@ -1891,6 +1926,8 @@ func TestArchetype_Model(t *testing.T) {
 		Name:        "Test Archetype",
 		Description: "Test description",
 		Comments:    "Some comments",
+		Selector:    "tag:Language=Java",
+		Parent:      &Ref{ID: 2},
 		Tags: []TagRef{
 			{ID: 10, Name: "tag1"},
 		},
@@ -1911,6 +1948,8 @@ func TestArchetype_Model(t *testing.T) {
 	g.Expect(m.Name).To(gomega.Equal("Test Archetype"))
 	g.Expect(m.Description).To(gomega.Equal("Test description"))
 	g.Expect(m.Comments).To(gomega.Equal("Some comments"))
+	g.Expect(m.Selector).To(gomega.Equal("tag:Language=Java"))
+	g.Expect(*m.ParentID).To(gomega.Equal(uint(2)))
 	g.Expect(len(m.Tags)).To(gomega.Equal(1))
 	g.Expect(m.Tags[0].ID).To(gomega.Equal(uint(10)))
 }
@@ -2879,7 +2918,9 @@ func TestExtension_With_WithProbes(t *testing.T) {
 func TestQuestion_With(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
 
+	score := 75.0
 	m := &model.Question{
+		ID:          "platform",
 		Order:       1,
 		Text:        "What is your platform?",
 		Explanation: "Select your target platform",
@@ -2889,8 +2930,12 @@ func TestQuestion_With(t *testing.T) {
 		ExcludeFor: []model.CategorizedTag{
 			{Category: "Runtime", Tag: "Legacy"},
 		},
+		ShowWhen: []model.AnswerRef{
+			{Section: 1, Question: 2, Answer: 1},
+		},
+		Weight: 2,
 		Answers: []model.Answer{
-			{Order: 1, Text: "Cloud", Risk: "green"},
+			{Order: 1, Text: "Cloud", Risk: "green", Score: &score},
 			{Order: 2, Text: "On-Premise", Risk: "yellow"},
 		},
 	}
@@ -2898,6 +2943,7 @@ func TestQuestion_With(t *testing.T) {
 	r := &Question{}
 	r.With(m)
 
+	g.Expect(r.ID).To(gomega.Equal("platform"))
 	g.Expect(r.Order).To(gomega.Equal(uint(1)))
 	g.Expect(r.Text).To(gomega.Equal("What is your platform?"))
 	g.Expect(r.Explanation).To(gomega.Equal("Select your target platform"))
@@ -2906,7 +2952,11 @@ func TestQuestion_With(t *testing.T) {
 	g.Expect(r.IncludeFor[0].Tag).To(gomega.Equal("Java"))
 	g.Expect(len(r.ExcludeFor)).To(gomega.Equal(1))
 	g.Expect(r.ExcludeFor[0].Category).To(gomega.Equal("Runtime"))
+	g.Expect(r.ShowWhen).To(gomega.Equal([]api.AnswerRef{{Section: 1, Question: 2, Answer: 1}}))
+	g.Expect(r.Weight).To(gomega.Equal(2.0))
 	g.Expect(len(r.Answers)).To(gomega.Equal(2))
+	g.Expect(*r.Answers[0].Score).To(gomega.Equal(75.0))
+	g.Expect(r.Answers[1].Score).To(gomega.BeNil())
 	g.Expect(r.Answers[0].Text).To(gomega.Equal("Cloud"))
 	g.Expect(r.Answers[1].Text).To(gomega.Equal("On-Premise"))
 }
@@ -2925,6 +2975,10 @@ func TestQuestion_Model(t *testing.T) {
 		ExcludeFor: []api.CategorizedTag{
 			{Category: "Runtime", Tag: "Legacy"},
 		},
+		ShowWhen: []api.AnswerRef{
+			{Section: 1, Question: 2, Answer: 1},
+		},
+		Weight: 0.5,
 		Answers: []api.Answer{
 			{Order: 1, Text: "Cloud", Risk: "green"},
 			{Order: 2, Text: "On-Premise", Risk: "yellow"},
@@ -2939,6 +2993,8 @@ func TestQuestion_Model(t *testing.T) {
 	g.Expect(len(m.IncludeFor)).To(gomega.Equal(1))
 	g.Expect(m.IncludeFor[0].Category).To(gomega.Equal("Language"))
 	g.Expect(len(m.ExcludeFor)).To(gomega.Equal(1))
+	g.Expect(len(m.ShowWhen)).To(gomega.Equal(1))
+	g.Expect(m.Weight).To(gomega.Equal(0.5))
 	g.Expect(len(m.Answers)).To(gomega.Equal(2))
 	g.Expect(m.Answers[0].Text).To(gomega.Equal("Cloud"))
 }
@@ -2961,6 +3017,8 @@ func TestAnswer_With(t *testing.T) {
 		AutoAnswerFor: []model.CategorizedTag{
 			{Category: "Container", Tag: "Docker"},
 		},
+		AutoAnswerWhen:   "label:konveyor.io/target=cloud-readiness",
+		AutoAnswerReason: "Matched: label:konveyor.io/target=cloud-readiness",
 	}
 
 	r := &Answer{}
@@ -2978,6 +3036,8 @@ func TestAnswer_With(t *testing.T) {
 	g.Expect(r.ApplyTags[0].Tag).To(gomega.Equal("K8s"))
 	g.Expect(len(r.AutoAnswerFor)).To(gomega.Equal(1))
 	g.Expect(r.AutoAnswerFor[0].Category).To(gomega.Equal("Container"))
+	g.Expect(r.AutoAnswerWhen).To(gomega.Equal("label:konveyor.io/target=cloud-readiness"))
+	g.Expect(r.AutoAnswerReason).To(gomega.Equal("Matched: label:konveyor.io/target=cloud-readiness"))
 }
 
 // TestAnswer_Model tests the Answer.Model() method
@@ -2998,6 +3058,7 @@ func TestAnswer_Model(t *testing.T) {
 		AutoAnswerFor: []api.CategorizedTag{
 			{Category: "Container", Tag: "Docker"},
 		},
+		AutoAnswerWhen: "rule:jms-*",
 	}
 
 	m := r.Model()
@@ -3012,6 +3073,7 @@ func TestAnswer_Model(t *testing.T) {
 	g.Expect(len(m.ApplyTags)).To(gomega.Equal(1))
 	g.Expect(m.ApplyTags[0].Category).To(gomega.Equal("Platform"))
 	g.Expect(len(m.AutoAnswerFor)).To(gomega.Equal(1))
+	g.Expect(m.AutoAnswerWhen).To(gomega.Equal("rule:jms-*"))
 }
 
 // TestSection_With tests the Section.With() method
@@ -3897,3 +3959,59 @@ func TestToken_With_IdpClient(t *testing.T) {
 	g.Expect(r.IdpClient).ToNot(gomega.BeNil())
 	g.Expect(r.IdpClient.ID).To(gomega.Equal(uint(10)))
 }
+
+func TestShape_Shaped(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	r := Application{}
+	r.ID = 1
+	r.Name = "app"
+	r.Effort = 10
+	r.Owner = &Ref{ID: 2, Name: "owner"}
+	r.Contributors = []Ref{{ID: 2, Name: "owner"}, {ID: 3, Name: "other"}}
+	r.MigrationWave = &Ref{ID: 4}
+
+	// Not shaped.
+	shape := Shape{}
+	g.Expect(shape.Empty()).To(gomega.BeTrue())
+	shaped, err := shape.Shaped(r)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(shaped).To(gomega.Equal(r))
+
+	// Sparse fieldset.
+	shape = Shape{}
+	shape.With("id,name", " owner ")
+	g.Expect(shape.Fields).To(gomega.Equal([]string{"id", "name", "owner"}))
+	shaped, err = shape.Shaped(r)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(shaped).To(gomega.Equal(Map{
+		"id":    uint(1),
+		"name":  "app",
+		"owner": &Ref{ID: 2, Name: "owner"},
+	}))
+
+	// Expanded.
+	owner := Stakeholder{Name: "owner", Email: "owner@acme.com"}
+	owner.ID = 2
+	shape = Shape{}
+	shape.With("id,owner,contributors,migrationWave")
+	shape.Expand("owner", 2, owner)
+	shape.Expand("contributors", 2, owner)
+	shaped, err = shape.Shaped(r)
+	g.Expect(err).To(gomega.BeNil())
+	mp := shaped.(Map)
+	g.Expect(mp["owner"]).To(gomega.Equal(owner))
+	g.Expect(mp["contributors"]).To(gomega.Equal([]any{
+		owner,
+		Ref{ID: 3, Name: "other"},
+	}))
+	g.Expect(mp["migrationWave"]).To(gomega.Equal(&Ref{ID: 4}))
+	b, err := json.Marshal(shaped)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(b)).To(gomega.ContainSubstring(`"email":"owner@acme.com"`))
+
+	// Not an object.
+	shaped, err = shape.Shaped([]string{"a"})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(shaped).To(gomega.Equal([]string{"a"}))
+}
@@ -3,6 +3,7 @@ package resource
 import (
 	"fmt"
 
+	"github.com/konveyor/tackle2-hub/internal/assessment"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/shared/api"
 )
@@ -16,6 +17,7 @@ func (r *Questionnaire) With(m *model.Questionnaire) {
 	r.Name = m.Name
 	r.Description = m.Description
 	r.Required = m.Required
+	r.Version = m.Version
 	r.Builtin = m.Builtin()
 	r.Sections = []api.Section{}
 	for _, s := range m.Sections {
@@ -25,6 +27,7 @@ func (r *Questionnaire) With(m *model.Questionnaire) {
 	}
 	r.Thresholds = api.Thresholds(m.Thresholds)
 	r.RiskMessages = api.RiskMessages(m.RiskMessages)
+	r.Scoring = api.Scoring(m.Scoring)
 }
 
 // Model builds a model.
@@ -41,14 +44,35 @@ func (r *Questionnaire) Model() (m *model.Questionnaire) {
 	}
 	m.Thresholds = model.Thresholds(r.Thresholds)
 	m.RiskMessages = model.RiskMessages(r.RiskMessages)
+	m.Scoring = model.Scoring(r.Scoring)
 
 	return
 }
 
+// QuestionnaireVersion REST resource.
+type QuestionnaireVersion api.QuestionnaireVersion
+
+// With updates the resource with the model.
+func (r *QuestionnaireVersion) With(m *model.QuestionnaireVersion) {
+	baseWith(&r.Resource, &m.Model)
+	r.Questionnaire = ref(m.QuestionnaireID, &m.Questionnaire)
+	r.Version = m.Version
+	r.Sections = []api.Section{}
+	for _, s := range m.Sections {
+		sect := Section{}
+		sect.With(&s)
+		r.Sections = append(r.Sections, api.Section(sect))
+	}
+	r.Thresholds = api.Thresholds(m.Thresholds)
+	r.RiskMessages = api.RiskMessages(m.RiskMessages)
+	r.Scoring = api.Scoring(m.Scoring)
+}
+
 // Validate performs additional validation on the questionnaire beyond binding tags.
 func (r *Questionnaire) Validate() error {
 	// Validate sections have unique order values
 	sectionOrders := make(map[uint]bool)
+	questionIds := make(map[string]bool)
 	for i, section := range r.Sections {
 		// Check for duplicate section order
 		if sectionOrders[section.Order] {
@@ -58,6 +82,13 @@ func (r *Questionnaire) Validate() error {
 		}
 		sectionOrders[section.Order] = true
 
+		// Validate section weight
+		if section.Weight < 0 {
+			return &ValidationError{
+				fmt.Sprintf("section %d (%s) has negative weight", i, section.Name),
+			}
+		}
+
 		// Validate each section has at least one question
 		if len(section.Questions) == 0 {
 			return &ValidationError{
@@ -76,6 +107,16 @@ func (r *Questionnaire) Validate() error {
 			}
 			questionOrders[question.Order] = true
 
+			// Check for duplicate (stable) question ID
+			if question.ID != "" {
+				if questionIds[question.ID] {
+					return &ValidationError{
+						fmt.Sprintf("duplicate question id '%s' found in section %d (%s)", question.ID, i, section.Name),
+					}
+				}
+				questionIds[question.ID] = true
+			}
+
 			// Validate question text is not empty
 			if question.Text == "" {
 				return &ValidationError{
@@ -83,6 +124,13 @@ func (r *Questionnaire) Validate() error {
 				}
 			}
 
+			// Validate question weight
+			if question.Weight < 0 {
+				return &ValidationError{
+					fmt.Sprintf("question %d (%s) in section %d (%s) has negative weight", j, question.Text, i, section.Name),
+				}
+			}
+
 			// Validate each question has at least one answer
 			if len(question.Answers) == 0 {
 				return &ValidationError{
@@ -115,10 +163,35 @@ func (r *Questionnaire) Validate() error {
 						fmt.Sprintf("answer %d (%s) in question %d (%s) has invalid risk level '%s', must be one of: red, yellow, green, unknown", k, answer.Text, j, question.Text, answer.Risk),
 					}
 				}
+
+				// Validate auto-answer condition
+				if answer.AutoAnswerWhen != "" {
+					criteria := &assessment.Criteria{}
+					err := criteria.Validate(answer.AutoAnswerWhen)
+					if err != nil {
+						return &ValidationError{
+							fmt.Sprintf("answer %d (%s) in question %d (%s) has invalid auto-answer condition: %s", k, answer.Text, j, question.Text, err.Error()),
+						}
+					}
+				}
 			}
 		}
 	}
 
+	// Validate question (answer) conditions
+	err := r.validateConditions()
+	if err != nil {
+		return err
+	}
+
+	// Validate scoring formula
+	if r.Scoring.Formula != "" {
+		err = assessment.Formula(r.Scoring.Formula).Validate()
+		if err != nil {
+			return &ValidationError{err.Error()}
+		}
+	}
+
 	// Validate threshold values
 	if r.Thresholds.Red == 0 && r.Thresholds.Yellow == 0 && r.Thresholds.Unknown == 0 {
 		return &ValidationError{
@@ -136,3 +209,76 @@ func (r *Questionnaire) Validate() error {
 
 	return nil
 }
+
+// validateConditions validates the question (answer) conditions.
+// The referenced answers must exist and the conditions must not be circular.
+func (r *Questionnaire) validateConditions() error {
+	type key struct {
+		section  uint
+		question uint
+	}
+	answers := make(map[key]map[uint]bool)
+	for _, section := range r.Sections {
+		for _, question := range section.Questions {
+			orders := make(map[uint]bool)
+			for _, answer := range question.Answers {
+				orders[answer.Order] = true
+			}
+			answers[key{section.Order, question.Order}] = orders
+		}
+	}
+	depends := make(map[key][]key)
+	for _, section := range r.Sections {
+		for _, question := range section.Questions {
+			k := key{section.Order, question.Order}
+			for _, ref := range question.ShowWhen {
+				refKey := key{ref.Section, ref.Question}
+				orders, found := answers[refKey]
+				if !found || !orders[ref.Answer] {
+					return &ValidationError{
+						fmt.Sprintf(
+							"question %d (%s) in section %d (%s) references answer %d in question %d of section %d which does not exist",
+							question.Order, question.Text, section.Order, section.Name, ref.Answer, ref.Question, ref.Section),
+					}
+				}
+				depends[k] = append(depends[k], refKey)
+			}
+		}
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[key]int)
+	var visit func(k key) bool
+	visit = func(k key) (circular bool) {
+		switch state[k] {
+		case visiting:
+			circular = true
+			return
+		case visited:
+			return
+		}
+		state[k] = visiting
+		for _, ref := range depends[k] {
+			if visit(ref) {
+				circular = true
+				return
+			}
+		}
+		state[k] = visited
+		return
+	}
+	for _, section := range r.Sections {
+		for _, question := range section.Questions {
+			if visit(key{section.Order, question.Order}) {
+				return &ValidationError{
+					fmt.Sprintf(
+						"question %d (%s) in section %d (%s) has circular conditions",
+						question.Order, question.Text, section.Order, section.Name),
+				}
+			}
+		}
+	}
+	return nil
+}
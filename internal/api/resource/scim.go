@@ -0,0 +1,136 @@
+package resource
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// ScimUser REST resource.
+type ScimUser api.ScimUser
+
+// With converts model to REST resource.
+func (r *ScimUser) With(m *model.User) {
+	r.Schemas = []string{api.ScimSchemaUser}
+	r.ID = strconv.Itoa(int(m.ID))
+	r.ExternalID = m.ExternalId
+	r.UserName = m.Login
+	r.DisplayName = m.Name
+	r.Name = nil
+	if m.Name != "" {
+		r.Name = &api.ScimName{Formatted: m.Name}
+	}
+	r.Emails = nil
+	if m.Email != "" {
+		r.Emails = []api.ScimValue{
+			{
+				Value:   m.Email,
+				Type:    "work",
+				Primary: true,
+			},
+		}
+	}
+	r.Password = ""
+	active := !m.Disabled
+	r.Active = &active
+	r.Groups = []api.ScimMember{}
+	for _, role := range m.Roles {
+		r.Groups = append(
+			r.Groups,
+			api.ScimMember{
+				Value:   strconv.Itoa(int(role.ID)),
+				Display: role.Name,
+			})
+	}
+	r.Meta = &api.ScimMeta{
+		ResourceType: api.ScimResourceUser,
+		Created:      m.CreateTime,
+		LastModified: m.UpdateTime,
+	}
+}
+
+// Model converts REST resource to model.
+// The (read-only) groups are ignored.
+func (r *ScimUser) Model() (m *model.User) {
+	m = &model.User{
+		Login:      r.UserName,
+		ExternalId: r.ExternalID,
+		Name:       r.DisplayName,
+		Password:   r.Password,
+		Email:      r.email(),
+	}
+	if m.Name == "" && r.Name != nil {
+		m.Name = r.Name.Formatted
+		if m.Name == "" {
+			m.Name = strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+		}
+	}
+	if r.Active != nil {
+		m.Disabled = !*r.Active
+	}
+	return
+}
+
+// email returns the primary (or first) email.
+func (r *ScimUser) email() (email string) {
+	for _, v := range r.Emails {
+		if v.Primary {
+			email = v.Value
+			return
+		}
+	}
+	if len(r.Emails) > 0 {
+		email = r.Emails[0].Value
+	}
+	return
+}
+
+// ScimGroup REST resource.
+type ScimGroup api.ScimGroup
+
+// With converts model to REST resource.
+// The members are the users with the role.
+func (r *ScimGroup) With(m *model.Role, members []model.User) {
+	r.Schemas = []string{api.ScimSchemaGroup}
+	r.ID = strconv.Itoa(int(m.ID))
+	r.DisplayName = m.Name
+	r.Members = []api.ScimMember{}
+	for _, user := range members {
+		r.Members = append(
+			r.Members,
+			api.ScimMember{
+				Value:   strconv.Itoa(int(user.ID)),
+				Display: user.Login,
+			})
+	}
+	r.Meta = &api.ScimMeta{
+		ResourceType: api.ScimResourceGroup,
+		Created:      m.CreateTime,
+		LastModified: m.UpdateTime,
+	}
+}
+
+// Model converts REST resource to model.
+func (r *ScimGroup) Model() (m *model.Role) {
+	m = &model.Role{
+		Name: r.DisplayName,
+	}
+	return
+}
+
+// MemberIds returns the IDs of member users.
+func (r *ScimGroup) MemberIds() (ids []uint, err error) {
+	for _, member := range r.Members {
+		n, pErr := strconv.ParseUint(member.Value, 10, 0)
+		if pErr != nil {
+			err = &ValidationError{
+				Reason: "member: '" + member.Value + "' not valid.",
+			}
+			return
+		}
+		ids = append(ids, uint(n))
+	}
+	return
+}
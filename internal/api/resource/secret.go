@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"github.com/konveyor/tackle2-hub/internal/secret"
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// SecretKey REST resource.
+type SecretKey = api.SecretKey
+
+// SecretRotation REST resource.
+type SecretRotation api.SecretRotation
+
+// With updates the resource with the rotation.
+func (r *SecretRotation) With(m *secret.Rotation) {
+	r.State = m.State
+	r.KeyId = m.KeyId
+	if !m.Started.IsZero() {
+		started := m.Started
+		r.Started = &started
+	}
+	if !m.Terminated.IsZero() {
+		terminated := m.Terminated
+		r.Terminated = &terminated
+	}
+	r.Kinds = nil
+	for _, kind := range m.Kinds {
+		r.Kinds = append(
+			r.Kinds,
+			api.SecretRotatedKind{
+				Kind:      kind.Kind,
+				Total:     kind.Total,
+				Processed: kind.Processed,
+				Rotated:   kind.Rotated,
+				Failed:    kind.Failed,
+				Skipped:   kind.Skipped,
+			})
+	}
+	r.Error = m.Error
+}
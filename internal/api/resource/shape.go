@@ -0,0 +1,197 @@
+package resource
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Shape used to shape rendered resources.
+// Supports sparse fieldsets and expanded relationships.
+type Shape struct {
+	// Fields (json) names to be included.
+	// Empty includes all fields.
+	Fields []string
+	// Expanded related resources.
+	// Keyed by (json) field name and ID.
+	Expanded map[string]map[uint]any
+}
+
+// With parses the (comma separated) fields.
+func (r *Shape) With(fields ...string) {
+	for _, s := range fields {
+		for _, name := range strings.Split(s, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				r.Fields = append(r.Fields, name)
+			}
+		}
+	}
+}
+
+// Expand adds a related resource.
+// The field is the (json) name of the Ref (or []Ref) field
+// to be replaced by the related resource.
+func (r *Shape) Expand(field string, id uint, related any) {
+	if r.Expanded == nil {
+		r.Expanded = make(map[string]map[uint]any)
+	}
+	mp, found := r.Expanded[field]
+	if !found {
+		mp = make(map[uint]any)
+		r.Expanded[field] = mp
+	}
+	mp[id] = related
+}
+
+// Empty returns true when no shaping requested.
+func (r *Shape) Empty() (b bool) {
+	b = len(r.Fields) == 0 && len(r.Expanded) == 0
+	return
+}
+
+// Shaped returns the shaped resource.
+// The resource is returned unchanged when no shaping is
+// requested or the resource is not an object (struct).
+// The (json) fields are selected by reflection so the field
+// values are rendered by the encoder as usual.
+func (r *Shape) Shaped(object any) (shaped any, err error) {
+	shaped = object
+	if r.Empty() {
+		return
+	}
+	v := reflect.ValueOf(object)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	mp := Map{}
+	r.fields(v, mp, false)
+	shaped = mp
+	return
+}
+
+// fields adds the wanted (json) fields of the struct to the map.
+// Fields promoted from embedded structs do not replace fields
+// already added.
+func (r *Shape) fields(v reflect.Value, mp Map, promoted bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		fv := v.Field(i)
+		tag := ft.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if ft.Anonymous && name == "" {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				r.fields(fv, mp, true)
+				continue
+			}
+		}
+		if !ft.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = ft.Name
+		}
+		if !r.wanted(name) {
+			continue
+		}
+		if strings.Contains(options, "omitempty") && r.empty(fv) {
+			continue
+		}
+		if _, found := mp[name]; found && promoted {
+			continue
+		}
+		mp[name] = r.expanded(name, fv)
+	}
+}
+
+// wanted returns true when the (json) field is included.
+func (r *Shape) wanted(name string) (b bool) {
+	if len(r.Fields) == 0 {
+		b = true
+		return
+	}
+	for _, f := range r.Fields {
+		if f == name {
+			b = true
+			break
+		}
+	}
+	return
+}
+
+// expanded returns the field value with the Ref (or []Ref)
+// replaced by the related resources.
+// The value is returned when not expanded.
+func (r *Shape) expanded(name string, v reflect.Value) (object any) {
+	object = v.Interface()
+	related, found := r.Expanded[name]
+	if !found {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = r.related(v.Index(i), related)
+		}
+		object = list
+	default:
+		object = r.related(v, related)
+	}
+	return
+}
+
+// related returns the related resource for the ref.
+// The ref is returned when not found.
+func (r *Shape) related(ref reflect.Value, related map[uint]any) (object any) {
+	object = ref.Interface()
+	for ref.Kind() == reflect.Ptr {
+		if ref.IsNil() {
+			return
+		}
+		ref = ref.Elem()
+	}
+	if ref.Kind() != reflect.Struct {
+		return
+	}
+	id := ref.FieldByName("ID")
+	switch id.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		m, found := related[uint(id.Uint())]
+		if found {
+			object = m
+		}
+	}
+	return
+}
+
+// empty returns true when the value is empty as defined
+// by the json (omitempty) option.
+func (r *Shape) empty(v reflect.Value) (b bool) {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		b = v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		b = v.IsZero()
+	}
+	return
+}
@@ -1,6 +1,8 @@
 package resource
 
 import (
+	"time"
+
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/shared/api"
 )
@@ -15,12 +17,15 @@ type User api.User
 // With converts model to REST resource.
 func (r *User) With(m *model.User) {
 	baseWith(&r.Resource, &m.Model)
+	r.Locked = m.LockedUntil != nil && m.LockedUntil.After(time.Now())
 	m = mustRedact(m)
 	r.Subject = m.Subject
 	r.Login = m.Login
 	r.Name = m.Name
 	r.Password = m.Password
 	r.Email = m.Email
+	r.Disabled = m.Disabled
+	r.Mfa = m.TotpEnabled
 	r.Roles = []Ref{}
 	for _, role := range m.Roles {
 		r.Roles = append(r.Roles, Ref{ID: role.ID, Name: role.Name})
@@ -39,6 +44,7 @@ func (r *User) Model() (m *model.User) {
 		Name:     r.Name,
 		Password: r.Password,
 		Email:    r.Email,
+		Disabled: r.Disabled,
 	}
 	m.ID = r.ID
 	for _, ref := range r.Roles {
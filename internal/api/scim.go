@@ -0,0 +1,1124 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/konveyor/tackle2-hub/internal/api/resource"
+	"github.com/konveyor/tackle2-hub/internal/auth"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/internal/secret"
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"gorm.io/gorm"
+)
+
+// ScimHandler handles SCIM (2.0) provisioning routes.
+// SCIM users are hub users; SCIM groups are hub roles.
+// The (auth) cache is updated after the transaction is committed.
+type ScimHandler struct {
+	BaseHandler
+}
+
+// AddRoutes adds routes.
+func (h ScimHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(h.scimError, ErrorHandler(), Required("scim"), Transaction)
+	routeGroup.GET(api.ScimServiceProviderConfigRoute, h.ServiceProviderConfig)
+	routeGroup.GET(api.ScimUsersRoute, h.UserList)
+	routeGroup.POST(api.ScimUsersRoute, h.UserCreate)
+	routeGroup.GET(api.ScimUserRoute, h.UserGet)
+	routeGroup.PUT(api.ScimUserRoute, h.UserUpdate)
+	routeGroup.PATCH(api.ScimUserRoute, h.UserPatch)
+	routeGroup.DELETE(api.ScimUserRoute, h.UserDelete)
+	routeGroup.GET(api.ScimGroupsRoute, h.GroupList)
+	routeGroup.POST(api.ScimGroupsRoute, h.GroupCreate)
+	routeGroup.GET(api.ScimGroupRoute, h.GroupGet)
+	routeGroup.PUT(api.ScimGroupRoute, h.GroupUpdate)
+	routeGroup.PATCH(api.ScimGroupRoute, h.GroupPatch)
+	routeGroup.DELETE(api.ScimGroupRoute, h.GroupDelete)
+}
+
+// ServiceProviderConfig godoc
+// @summary Get the SCIM service provider configuration.
+// @description Get the SCIM service provider configuration.
+// @tags scim
+// @produce json
+// @success 200 {object} api.ScimServiceProviderConfig
+// @router /scim/v2/ServiceProviderConfig [get]
+func (h ScimHandler) ServiceProviderConfig(ctx *gin.Context) {
+	r := api.ScimServiceProviderConfig{
+		Schemas: []string{api.ScimSchemaSpConfig},
+		Patch:   api.ScimSupported{Supported: true},
+		Filter:  api.ScimSupported{Supported: true},
+	}
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// UserList godoc
+// @summary List SCIM users.
+// @description List SCIM users.
+// @description Filter: `attribute eq "value"` where attribute is: id, userName, externalId,
+// @description displayName or emails.
+// @tags scim
+// @produce json
+// @success 200 {object} api.ScimList
+// @router /scim/v2/Users [get]
+// @param filter query string false "Filter"
+// @param startIndex query int false "Start index (1-based)"
+// @param count query int false "Count"
+func (h ScimHandler) UserList(ctx *gin.Context) {
+	db := h.DB(ctx).Model(&model.User{})
+	db, err := h.filter(ctx, db, map[string]string{
+		"id":           "ID",
+		"username":     "Login",
+		"externalid":   "ExternalId",
+		"displayname":  "Name",
+		"emails":       "Email",
+		"emails.value": "Email",
+	})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	list := api.ScimList{}
+	db, err = h.page(ctx, db, &list)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var users []model.User
+	db = db.Preload("Roles")
+	err = db.Order("ID").Find(&users).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	for i := range users {
+		r := &ScimUser{}
+		r.With(&users[i])
+		list.Resources = append(list.Resources, r)
+	}
+	list.ItemsPerPage = len(list.Resources)
+	h.Respond(ctx, http.StatusOK, list)
+}
+
+// UserGet godoc
+// @summary Get a SCIM user by ID.
+// @description Get a SCIM user by ID.
+// @tags scim
+// @produce json
+// @success 200 {object} api.ScimUser
+// @router /scim/v2/Users/{id} [get]
+// @param id path int true "User ID"
+func (h ScimHandler) UserGet(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.User{}
+	db := h.DB(ctx).Preload("Roles")
+	err := db.First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := &ScimUser{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// UserCreate godoc
+// @summary Create a SCIM user.
+// @description Create a SCIM user.
+// @description When the password is not specified, a random password is
+// @description generated and the user must authenticate using the IdP.
+// @tags scim
+// @accept json
+// @produce json
+// @success 201 {object} api.ScimUser
+// @router /scim/v2/Users [post]
+// @param user body api.ScimUser true "User data"
+func (h ScimHandler) UserCreate(ctx *gin.Context) {
+	r := &ScimUser{}
+	err := h.bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if r.UserName == "" {
+		_ = ctx.Error(&BadRequestError{Reason: "userName required."})
+		return
+	}
+	m := r.Model()
+	m.Subject = uuid.New().String()
+	m.CreateUser = h.CurrentUser(ctx)
+	if m.Password != "" {
+		password := m.Password
+		m.Password = ""
+		passwords := auth.Password{DB: h.DB(ctx)}
+		err = passwords.Set(m, password)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	} else {
+		m.Password = uuid.New().String()
+	}
+	_, err = secret.Encode(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = h.DB(ctx).Omit("Roles", "Tokens").Create(m).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = h.DB(ctx).Preload("Roles").First(m).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	RichContext(ctx).AfterCommit(func() {
+		auth.Idp().Cache().UserSaved(m)
+	})
+
+	r.With(m)
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// UserUpdate godoc
+// @summary Replace a SCIM user.
+// @description Replace a SCIM user.
+// @description The password is unchanged when not specified.
+// @description Deactivated users cannot authenticate and their tokens are revoked.
+// @tags scim
+// @accept json
+// @produce json
+// @success 200 {object} api.ScimUser
+// @router /scim/v2/Users/{id} [put]
+// @param id path int true "User ID"
+// @param user body api.ScimUser true "User data"
+func (h ScimHandler) UserUpdate(ctx *gin.Context) {
+	id := h.pk(ctx)
+	r := &ScimUser{}
+	err := h.bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.userUpdate(ctx, id, r)
+}
+
+// UserPatch godoc
+// @summary Patch a SCIM user.
+// @description Patch a SCIM user.
+// @description Supports add, replace and remove operations.
+// @description Deactivated users cannot authenticate and their tokens are revoked.
+// @tags scim
+// @accept json
+// @produce json
+// @success 200 {object} api.ScimUser
+// @router /scim/v2/Users/{id} [patch]
+// @param id path int true "User ID"
+// @param patch body api.ScimPatch true "Patch operations"
+func (h ScimHandler) UserPatch(ctx *gin.Context) {
+	id := h.pk(ctx)
+	patch := &api.ScimPatch{}
+	err := h.bind(ctx, patch)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := &model.User{}
+	err = h.DB(ctx).Preload("Roles").First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := &ScimUser{}
+	r.With(m)
+	patched := &ScimUser{}
+	err = ScimPatch(r, patch.Operations, patched)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.userUpdate(ctx, id, patched)
+}
+
+// UserDelete godoc
+// @summary Delete a SCIM user.
+// @description Delete (deprovision) a SCIM user.
+// @description The user tokens are revoked.
+// @tags scim
+// @success 204
+// @router /scim/v2/Users/{id} [delete]
+// @param id path int true "User ID"
+func (h ScimHandler) UserDelete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	if id < auth.LastId {
+		_ = ctx.Error(&BadRequestError{
+			Reason: "id reserved",
+		})
+		return
+	}
+	m := &model.User{}
+	err := h.DB(ctx).First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = h.revoke(ctx, id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = h.DB(ctx).Delete(m).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	RichContext(ctx).AfterCommit(func() {
+		auth.Idp().Cache().UserDeleted(id)
+	})
+
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// GroupList godoc
+// @summary List SCIM groups.
+// @description List SCIM groups (roles).
+// @description Filter: `attribute eq "value"` where attribute is: id or displayName.
+// @tags scim
+// @produce json
+// @success 200 {object} api.ScimList
+// @router /scim/v2/Groups [get]
+// @param filter query string false "Filter"
+// @param startIndex query int false "Start index (1-based)"
+// @param count query int false "Count"
+func (h ScimHandler) GroupList(ctx *gin.Context) {
+	db := h.DB(ctx).Model(&model.Role{})
+	db, err := h.filter(ctx, db, map[string]string{
+		"id":          "ID",
+		"displayname": "Name",
+	})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	list := api.ScimList{}
+	db, err = h.page(ctx, db, &list)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var roles []model.Role
+	err = db.Order("ID").Find(&roles).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	for i := range roles {
+		m := &roles[i]
+		members, err := h.members(ctx, m.ID)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		r := &ScimGroup{}
+		r.With(m, members)
+		list.Resources = append(list.Resources, r)
+	}
+	list.ItemsPerPage = len(list.Resources)
+	h.Respond(ctx, http.StatusOK, list)
+}
+
+// GroupGet godoc
+// @summary Get a SCIM group by ID.
+// @description Get a SCIM group (role) by ID.
+// @tags scim
+// @produce json
+// @success 200 {object} api.ScimGroup
+// @router /scim/v2/Groups/{id} [get]
+// @param id path int true "Role ID"
+func (h ScimHandler) GroupGet(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Role{}
+	err := h.DB(ctx).First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	members, err := h.members(ctx, id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := &ScimGroup{}
+	r.With(m, members)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// GroupCreate godoc
+// @summary Create a SCIM group.
+// @description Create a SCIM group.
+// @description Creates a role (without scopes) and assigns it to the members.
+// @tags scim
+// @accept json
+// @produce json
+// @success 201 {object} api.ScimGroup
+// @router /scim/v2/Groups [post]
+// @param group body api.ScimGroup true "Group data"
+func (h ScimHandler) GroupCreate(ctx *gin.Context) {
+	r := &ScimGroup{}
+	err := h.bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if r.DisplayName == "" {
+		_ = ctx.Error(&BadRequestError{Reason: "displayName required."})
+		return
+	}
+	ids, err := r.MemberIds()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	m.CreateUser = h.CurrentUser(ctx)
+	err = h.DB(ctx).Create(m).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	RichContext(ctx).AfterCommit(func() {
+		auth.Idp().Cache().RoleSaved(m)
+	})
+
+	err = h.setMembers(ctx, m, ids)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.groupRespond(ctx, http.StatusCreated, m.ID)
+}
+
+// GroupUpdate godoc
+// @summary Replace a SCIM group.
+// @description Replace a SCIM group (role) name and members.
+// @description Reserved (seeded) roles cannot be renamed.
+// @tags scim
+// @accept json
+// @produce json
+// @success 200 {object} api.ScimGroup
+// @router /scim/v2/Groups/{id} [put]
+// @param id path int true "Role ID"
+// @param group body api.ScimGroup true "Group data"
+func (h ScimHandler) GroupUpdate(ctx *gin.Context) {
+	id := h.pk(ctx)
+	r := &ScimGroup{}
+	err := h.bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.groupUpdate(ctx, id, r)
+}
+
+// GroupPatch godoc
+// @summary Patch a SCIM group.
+// @description Patch a SCIM group (role).
+// @description Supports add, replace and remove operations. Members
+// @description are removed using: `members[value eq "id"]`.
+// @tags scim
+// @accept json
+// @produce json
+// @success 200 {object} api.ScimGroup
+// @router /scim/v2/Groups/{id} [patch]
+// @param id path int true "Role ID"
+// @param patch body api.ScimPatch true "Patch operations"
+func (h ScimHandler) GroupPatch(ctx *gin.Context) {
+	id := h.pk(ctx)
+	patch := &api.ScimPatch{}
+	err := h.bind(ctx, patch)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := &model.Role{}
+	err = h.DB(ctx).First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	members, err := h.members(ctx, id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := &ScimGroup{}
+	r.With(m, members)
+	patched := &ScimGroup{}
+	err = ScimPatch(r, patch.Operations, patched)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.groupUpdate(ctx, id, patched)
+}
+
+// GroupDelete godoc
+// @summary Delete a SCIM group.
+// @description Delete a SCIM group (role).
+// @tags scim
+// @success 204
+// @router /scim/v2/Groups/{id} [delete]
+// @param id path int true "Role ID"
+func (h ScimHandler) GroupDelete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	if id < auth.LastId {
+		_ = ctx.Error(&BadRequestError{
+			Reason: "id reserved",
+		})
+		return
+	}
+	m := &model.Role{}
+	err := h.DB(ctx).First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = h.DB(ctx).Delete(m).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	RichContext(ctx).AfterCommit(func() {
+		auth.Idp().Cache().RoleDeleted(id)
+	})
+
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// userUpdate updates the user.
+// Deactivated users have their tokens revoked.
+func (h ScimHandler) userUpdate(ctx *gin.Context, id uint, r *ScimUser) {
+	if id < auth.LastId {
+		_ = ctx.Error(&BadRequestError{
+			Reason: "id reserved",
+		})
+		return
+	}
+	current := &model.User{}
+	err := h.DB(ctx).First(current, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if r.UserName != "" && r.UserName != current.Login {
+		_ = ctx.Error(&BadRequestError{Reason: "userName is immutable."})
+		return
+	}
+	updated := r.Model()
+	updated.ID = id
+	updated.Login = current.Login
+	updated.Subject = current.Subject
+	updated.UpdateUser = h.CurrentUser(ctx)
+	fields := []string{"ExternalId", "Name", "Email", "Disabled", "UpdateUser"}
+	if updated.Password != "" {
+		password := updated.Password
+		updated.Password = current.Password
+		updated.PasswordHistory = current.PasswordHistory
+		passwords := auth.Password{DB: h.DB(ctx)}
+		err = passwords.Set(updated, password)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		_, err = secret.Encode(updated)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		fields = append(fields, "Password", "PasswordHistory", "PasswordChanged")
+	}
+	err = h.DB(ctx).Model(updated).Select(fields).Updates(updated).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if updated.Disabled && !current.Disabled {
+		err = h.revoke(ctx, id)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
+	m := &model.User{}
+	err = h.DB(ctx).Preload("Roles").First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	RichContext(ctx).AfterCommit(func() {
+		auth.Idp().Cache().UserSaved(m)
+	})
+
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// groupUpdate updates the role name and members.
+func (h ScimHandler) groupUpdate(ctx *gin.Context, id uint, r *ScimGroup) {
+	ids, err := r.MemberIds()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := &model.Role{}
+	err = h.DB(ctx).First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if r.DisplayName != "" && r.DisplayName != m.Name {
+		if id < auth.LastId {
+			_ = ctx.Error(&BadRequestError{
+				Reason: "id reserved: cannot be renamed.",
+			})
+			return
+		}
+		m.Name = r.DisplayName
+		m.UpdateUser = h.CurrentUser(ctx)
+		err = h.DB(ctx).Model(m).Select("Name", "UpdateUser").Updates(m).Error
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+
+		RichContext(ctx).AfterCommit(func() {
+			auth.Idp().Cache().RoleSaved(m)
+		})
+	}
+	err = h.setMembers(ctx, m, ids)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.groupRespond(ctx, http.StatusOK, id)
+}
+
+// groupRespond responds with the group.
+func (h ScimHandler) groupRespond(ctx *gin.Context, status int, id uint) {
+	m := &model.Role{}
+	err := h.DB(ctx).First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	members, err := h.members(ctx, id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := &ScimGroup{}
+	r.With(m, members)
+	h.Respond(ctx, status, r)
+}
+
+// members returns the users with the role.
+func (h ScimHandler) members(ctx *gin.Context, roleId uint) (members []model.User, err error) {
+	db := h.DB(ctx).Joins("JOIN UserRole j ON j.UserID = User.ID")
+	db = db.Where("j.RoleID", roleId)
+	err = db.Order("User.ID").Find(&members).Error
+	return
+}
+
+// setMembers assigns the role to (only) the specified users.
+func (h ScimHandler) setMembers(ctx *gin.Context, role *model.Role, ids []uint) (err error) {
+	current, err := h.members(ctx, role.ID)
+	if err != nil {
+		return
+	}
+	wanted := make(map[uint]bool)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	var changed []uint
+	for i := range current {
+		user := &current[i]
+		if wanted[user.ID] {
+			delete(wanted, user.ID)
+			continue
+		}
+		err = h.DB(ctx).Model(user).Association("Roles").Delete(role)
+		if err != nil {
+			return
+		}
+		changed = append(changed, user.ID)
+	}
+	for id := range wanted {
+		user := &model.User{}
+		err = h.DB(ctx).First(user, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = &BadRequestError{
+					Reason: "member: user (id=" + strconv.Itoa(int(id)) + ") not found.",
+				}
+			}
+			return
+		}
+		err = h.DB(ctx).Model(user).Association("Roles").Append(role)
+		if err != nil {
+			return
+		}
+		changed = append(changed, user.ID)
+	}
+	for _, id := range changed {
+		user := &model.User{}
+		err = h.DB(ctx).Preload("Roles").First(user, id).Error
+		if err != nil {
+			return
+		}
+		RichContext(ctx).AfterCommit(func() {
+			auth.Idp().Cache().UserSaved(user)
+		})
+	}
+	return
+}
+
+// revoke revokes the user tokens using the provider after commit.
+// Tokens deleted (cascade) with the user are not found and ignored.
+func (h ScimHandler) revoke(ctx *gin.Context, userId uint) (err error) {
+	var ids []uint
+	db := h.DB(ctx).Model(&model.Token{})
+	db = db.Where("UserID", userId)
+	err = db.Pluck("ID", &ids).Error
+	if err != nil {
+		return
+	}
+	RichContext(ctx).AfterCommit(func() {
+		for _, id := range ids {
+			err := auth.Idp().Revoke(id)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				Log.Error(err, "Revoke failed.", "user", userId, "token", id)
+			}
+		}
+	})
+	return
+}
+
+// filter applies the SCIM filter.
+// The attributes maps (lower-case) attribute names to columns.
+func (h ScimHandler) filter(ctx *gin.Context, db *gorm.DB, attributes map[string]string) (q *gorm.DB, err error) {
+	q = db
+	s := ctx.Query(api.ScimParamFilter)
+	if s == "" {
+		return
+	}
+	f, err := ScimFilterWith(s)
+	if err != nil {
+		return
+	}
+	column, found := attributes[strings.ToLower(f.Attribute)]
+	if !found {
+		err = &ScimFilterError{
+			Filter: s,
+			Reason: "attribute not supported.",
+		}
+		return
+	}
+	q = q.Where(column, f.Value)
+	return
+}
+
+// page applies the SCIM pagination and sets the list
+// total and start index.
+func (h ScimHandler) page(ctx *gin.Context, db *gorm.DB, list *api.ScimList) (q *gorm.DB, err error) {
+	q = db
+	list.Schemas = []string{api.ScimSchemaList}
+	list.Resources = []any{}
+	list.StartIndex = 1
+	s := ctx.Query(api.ScimParamStartIndex)
+	if s != "" {
+		list.StartIndex, _ = strconv.Atoi(s)
+		if list.StartIndex < 1 {
+			list.StartIndex = 1
+		}
+	}
+	var total int64
+	err = db.Session(&gorm.Session{}).Count(&total).Error
+	if err != nil {
+		return
+	}
+	list.TotalResults = int(total)
+	q = q.Offset(list.StartIndex - 1)
+	s = ctx.Query(api.ScimParamCount)
+	if s != "" {
+		count, _ := strconv.Atoi(s)
+		if count < 0 {
+			count = 0
+		}
+		q = q.Limit(count)
+	}
+	return
+}
+
+// bind the request body.
+// Unknown attributes (extensions) are ignored.
+func (h ScimHandler) bind(ctx *gin.Context, r any) (err error) {
+	if ctx.Request.Body == nil {
+		err = &BadRequestError{Reason: "body required."}
+		return
+	}
+	d := json.NewDecoder(ctx.Request.Body)
+	err = d.Decode(r)
+	if err != nil {
+		err = &BadRequestError{Reason: err.Error()}
+		return
+	}
+	return
+}
+
+// scimError negotiates the SCIM media type and renders
+// errors as SCIM errors.
+func (h ScimHandler) scimError(ctx *gin.Context) {
+	if h.Accepted(ctx, api.MIMESCIM) {
+		ctx.Request.Header.Set(Accept, api.MIMEJSON)
+	}
+	ctx.Next()
+	if len(ctx.Errors) == 0 {
+		return
+	}
+	err := ctx.Errors[0]
+	ctx.Errors = nil
+	rtx := RichContext(ctx)
+	status := rtx.Response.Status
+	if status < http.StatusBadRequest {
+		return
+	}
+	r := api.ScimError{
+		Schemas: []string{api.ScimSchemaError},
+		Status:  strconv.Itoa(status),
+		Detail:  err.Error(),
+	}
+	switch status {
+	case http.StatusBadRequest:
+		r.ScimType = "invalidValue"
+		if errors.Is(err, &ScimFilterError{}) {
+			r.ScimType = "invalidFilter"
+		}
+	case http.StatusConflict:
+		r.ScimType = "uniqueness"
+	}
+	rtx.Respond(status, r)
+}
+
+// ScimFilter a (simple) SCIM filter: `attribute eq "value"`.
+type ScimFilter struct {
+	Attribute string
+	Value     string
+}
+
+// ScimFilterWith parses a SCIM filter.
+func ScimFilterWith(s string) (f ScimFilter, err error) {
+	pattern := regexp.MustCompile(`(?i)^\s*([\w.:$]+)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+	matched := pattern.FindStringSubmatch(s)
+	if matched == nil {
+		err = &ScimFilterError{
+			Filter: s,
+			Reason: "only: attribute eq \"value\" supported.",
+		}
+		return
+	}
+	f.Attribute = matched[1]
+	f.Value, err = strconv.Unquote(matched[2])
+	if err != nil {
+		err = &ScimFilterError{
+			Filter: s,
+			Reason: err.Error(),
+		}
+		return
+	}
+	return
+}
+
+// ScimPatch applies the patch operations to the resource (in)
+// and returns the result (out).
+// Paths: attribute, attribute.sub-attribute and the value filter:
+// attribute[sub-attribute eq "value"](.sub-attribute).
+func ScimPatch(in any, ops []api.ScimOperation, out any) (err error) {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return
+	}
+	doc := make(map[string]any)
+	err = json.Unmarshal(b, &doc)
+	if err != nil {
+		return
+	}
+	for _, op := range ops {
+		p := scimPatcher{op: op}
+		err = p.apply(doc)
+		if err != nil {
+			return
+		}
+	}
+	if v, cast := doc["active"].(string); cast {
+		doc["active"], err = strconv.ParseBool(v)
+		if err != nil {
+			err = &BadRequestError{Reason: "active: " + err.Error()}
+			return
+		}
+	}
+	b, err = json.Marshal(doc)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, out)
+	if err != nil {
+		err = &BadRequestError{Reason: err.Error()}
+		return
+	}
+	return
+}
+
+// scimPatcher applies a patch operation.
+type scimPatcher struct {
+	op api.ScimOperation
+}
+
+// apply the operation to the document.
+func (p *scimPatcher) apply(doc map[string]any) (err error) {
+	op := strings.ToLower(p.op.Op)
+	switch op {
+	case api.ScimOpAdd,
+		api.ScimOpReplace,
+		api.ScimOpRemove:
+	default:
+		err = &BadRequestError{Reason: "op: '" + p.op.Op + "' not supported."}
+		return
+	}
+	path := p.op.Path
+	if strings.HasPrefix(path, "urn:") {
+		path = path[strings.LastIndex(path, ":")+1:]
+	}
+	if path == "" {
+		if op == api.ScimOpRemove {
+			err = &BadRequestError{Reason: "remove: path required."}
+			return
+		}
+		values, cast := p.op.Value.(map[string]any)
+		if !cast {
+			err = &BadRequestError{Reason: "value: object expected."}
+			return
+		}
+		for name, v := range values {
+			p.set(op, doc, name, v)
+		}
+		return
+	}
+	pattern := regexp.MustCompile(`^([\w$]+)\[(.+)\](?:\.([\w$]+))?$`)
+	matched := pattern.FindStringSubmatch(path)
+	if matched != nil {
+		var f ScimFilter
+		f, err = ScimFilterWith(matched[2])
+		if err != nil {
+			return
+		}
+		p.filtered(op, doc, matched[1], f, matched[3])
+		return
+	}
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) == 2 {
+		key := p.key(doc, parts[0])
+		child, cast := doc[key].(map[string]any)
+		if !cast {
+			if op == api.ScimOpRemove {
+				return
+			}
+			child = make(map[string]any)
+			doc[key] = child
+		}
+		doc = child
+		path = parts[1]
+	}
+	if op == api.ScimOpRemove {
+		p.remove(doc, path)
+		return
+	}
+	p.set(op, doc, path, p.op.Value)
+	return
+}
+
+// set the attribute.
+// Multi-valued attributes are appended by add.
+func (p *scimPatcher) set(op string, doc map[string]any, name string, v any) {
+	key := p.key(doc, name)
+	if op == api.ScimOpAdd {
+		if list, cast := doc[key].([]any); cast {
+			switch added := v.(type) {
+			case []any:
+				doc[key] = append(list, added...)
+			default:
+				doc[key] = append(list, added)
+			}
+			return
+		}
+	}
+	doc[key] = v
+}
+
+// remove the attribute.
+// When values are specified, only matching elements of a
+// multi-valued attribute are removed.
+func (p *scimPatcher) remove(doc map[string]any, name string) {
+	key := p.key(doc, name)
+	list, cast := doc[key].([]any)
+	removed, hasValues := p.op.Value.([]any)
+	if !cast || !hasValues {
+		delete(doc, key)
+		return
+	}
+	kept := []any{}
+	for _, element := range list {
+		matched := false
+		for _, r := range removed {
+			if p.sameValue(element, r) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			kept = append(kept, element)
+		}
+	}
+	doc[key] = kept
+}
+
+// filtered applies the operation to the elements of the multi-valued
+// attribute matching the filter. When replacing and no elements
+// are matched, an element is added.
+func (p *scimPatcher) filtered(op string, doc map[string]any, name string, f ScimFilter, sub string) {
+	key := p.key(doc, name)
+	list, _ := doc[key].([]any)
+	updated := []any{}
+	found := false
+	for _, element := range list {
+		object, cast := element.(map[string]any)
+		if !cast || !p.match(object, f) {
+			updated = append(updated, element)
+			continue
+		}
+		found = true
+		switch op {
+		case api.ScimOpRemove:
+			if sub != "" {
+				delete(object, p.key(object, sub))
+				updated = append(updated, object)
+			}
+		default:
+			if sub != "" {
+				object[p.key(object, sub)] = p.op.Value
+			} else if v, cast := p.op.Value.(map[string]any); cast {
+				object = v
+			}
+			updated = append(updated, object)
+		}
+	}
+	if !found && op != api.ScimOpRemove {
+		object := map[string]any{f.Attribute: f.Value}
+		if sub != "" {
+			object[sub] = p.op.Value
+		} else if v, cast := p.op.Value.(map[string]any); cast {
+			for k, v := range v {
+				object[k] = v
+			}
+		}
+		updated = append(updated, object)
+	}
+	doc[key] = updated
+}
+
+// match returns true when the object matches the filter.
+func (p *scimPatcher) match(object map[string]any, f ScimFilter) (matched bool) {
+	v, found := object[p.key(object, f.Attribute)]
+	if found {
+		matched = p.format(v) == f.Value
+	}
+	return
+}
+
+// sameValue returns true when the elements have the same value.
+func (p *scimPatcher) sameValue(a, b any) (matched bool) {
+	aObject, cast := a.(map[string]any)
+	if !cast {
+		return
+	}
+	bObject, cast := b.(map[string]any)
+	if !cast {
+		return
+	}
+	v, found := bObject["value"]
+	if found {
+		matched = p.match(aObject, ScimFilter{Attribute: "value", Value: p.format(v)})
+	}
+	return
+}
+
+// format returns the string representation of a value.
+func (p *scimPatcher) format(v any) (s string) {
+	switch x := v.(type) {
+	case string:
+		s = x
+	default:
+		b, _ := json.Marshal(x)
+		s = string(b)
+	}
+	return
+}
+
+// key returns the (existing) document key matching the
+// attribute name. Attribute names are case-insensitive.
+func (p *scimPatcher) key(doc map[string]any, name string) (key string) {
+	key = name
+	for k := range doc {
+		if strings.EqualFold(k, name) {
+			key = k
+			break
+		}
+	}
+	return
+}
+
+// ScimFilterError reports a SCIM filter not supported.
+type ScimFilterError struct {
+	Filter string
+	Reason string
+}
+
+func (e *ScimFilterError) Error() string {
+	return "filter: '" + e.Filter + "' not valid: " + e.Reason
+}
+
+func (e *ScimFilterError) Is(err error) (matched bool) {
+	var target *ScimFilterError
+	matched = errors.As(err, &target)
+	return
+}
+
+// ScimUser REST resource.
+type ScimUser = resource.ScimUser
+
+// ScimGroup REST resource.
+type ScimGroup = resource.ScimGroup
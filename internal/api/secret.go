@@ -0,0 +1,122 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/internal/api/resource"
+	"github.com/konveyor/tackle2-hub/internal/secret"
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// SecretHandler handles encryption key routes.
+type SecretHandler struct {
+	BaseHandler
+}
+
+// AddRoutes adds routes.
+func (h SecretHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("secrets"))
+	routeGroup.GET(api.SecretKeysRoute, h.KeyList)
+	routeGroup.DELETE(api.SecretKeyRoute, h.KeyRetire)
+	routeGroup.GET(api.SecretRotationRoute, h.RotationGet)
+	routeGroup.POST(api.SecretRotationRoute, h.RotationStart)
+}
+
+// KeyList godoc
+// @summary List encryption keys.
+// @description List encryption keys.
+// @description Reports the number of encrypted fields referencing each key.
+// @description Secrets encrypted without a key ID reference the `legacy` key.
+// @tags secrets
+// @produce json
+// @success 200 {object} []api.SecretKey
+// @router /secrets/keys [get]
+func (h SecretHandler) KeyList(ctx *gin.Context) {
+	rotator := RichContext(ctx).SecretRotator
+	refs, err := rotator.References()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	keyring := secret.NewKeyring()
+	resources := []SecretKey{}
+	for _, id := range keyring.Ids() {
+		r := SecretKey{
+			ID:         id,
+			Current:    id == keyring.Current,
+			References: refs[id],
+		}
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// KeyRetire godoc
+// @summary Retire an encryption key.
+// @description Retire an encryption key.
+// @description The current key and keys referenced by encrypted fields
+// @description cannot be retired (409). Retired keys are persisted, can no longer
+// @description be used to decrypt and should be removed from ENCRYPTION_KEYS.
+// @tags secrets
+// @success 204
+// @router /secrets/keys/{key} [delete]
+// @param key path string true "Key ID"
+func (h SecretHandler) KeyRetire(ctx *gin.Context) {
+	id := ctx.Param(Key)
+	rotator := RichContext(ctx).SecretRotator
+	err := rotator.Retire(id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// RotationGet godoc
+// @summary Get the key rotation progress.
+// @description Get the key rotation progress.
+// @tags secrets
+// @produce json
+// @success 200 {object} api.SecretRotation
+// @router /secrets/rotation [get]
+func (h SecretHandler) RotationGet(ctx *gin.Context) {
+	rotator := RichContext(ctx).SecretRotator
+	m := rotator.Status()
+	r := SecretRotation{}
+	r.With(&m)
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// RotationStart godoc
+// @summary Start a key rotation.
+// @description Start a key rotation.
+// @description Secret fields not encrypted using the current key (ENCRYPTION_KEY_ID)
+// @description are re-encrypted (in batches) in the background.
+// @description The progress is reported by GET /secrets/rotation.
+// @tags secrets
+// @produce json
+// @success 202 {object} api.SecretRotation
+// @router /secrets/rotation [post]
+func (h SecretHandler) RotationStart(ctx *gin.Context) {
+	rotator := RichContext(ctx).SecretRotator
+	m, err := rotator.Start()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := SecretRotation{}
+	r.With(&m)
+
+	h.Respond(ctx, http.StatusAccepted, r)
+}
+
+// SecretKey REST resource.
+type SecretKey = resource.SecretKey
+
+// SecretRotation REST resource.
+type SecretRotation = resource.SecretRotation
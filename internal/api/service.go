@@ -6,9 +6,13 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/konveyor/tackle2-hub/internal/api/resource"
+	"github.com/konveyor/tackle2-hub/internal/auth"
 	"github.com/konveyor/tackle2-hub/shared/api"
 )
 
@@ -18,16 +22,26 @@ var serviceRoutes = map[string]string{
 	"kai":       os.Getenv("KAI_URL"),
 }
 
+const (
+	// ServiceProbeTimeout service health check timeout.
+	ServiceProbeTimeout = 5 * time.Second
+)
+
 // ServiceHandler handles service routes.
 type ServiceHandler struct {
 	BaseHandler
 }
 
 // AddRoutes adds routes.
+// Each service is a (registered) scope resource: services.<name>.
 func (h ServiceHandler) AddRoutes(e *gin.Engine) {
+	for name := range serviceRoutes {
+		auth.Domain().Register(h.resource(name))
+	}
 	routeGroup := e.Group("/")
-	routeGroup.Use(Authenticate())
+	routeGroup.Use(Required("services"))
 	routeGroup.GET(api.ServicesRoute, h.List)
+	routeGroup = e.Group("/")
 	routeGroup.Any(api.ServiceRoute, h.Forward)
 	routeGroup.Any(api.ServiceNestedRoute, h.Forward)
 }
@@ -35,21 +49,37 @@ func (h ServiceHandler) AddRoutes(e *gin.Engine) {
 // List godoc
 // @summary List named service routes.
 // @description List named service routes.
+// @description Services are health checked and the status reported:
+// @description Ready|Unavailable|NotConfigured.
 // @tags services
 // @produce json
-// @success 200 {object} api.Service
+// @success 200 {object} []api.Service
 // @router /services [get]
 func (h ServiceHandler) List(ctx *gin.Context) {
-	var r []Service
-	for name, route := range serviceRoutes {
-		service := Service{Name: name, Route: route}
-		r = append(r, service)
+	var names []string
+	for name := range serviceRoutes {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	r := make([]Service, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r[i] = h.probe(name)
+		}()
+	}
+	wg.Wait()
 
 	h.Respond(ctx, http.StatusOK, r)
 }
 
 // Forward provides RBAC and forwards request to the service.
+// The caller must be granted the service scope: services.<name>.
+// The inbound Authorization header is replaced with a (short-lived)
+// token minted for the service. The token carries the caller subject,
+// user and scopes.
 func (h ServiceHandler) Forward(ctx *gin.Context) {
 	path := ctx.Param(Wildcard)
 	name := ctx.Param(Name)
@@ -59,6 +89,15 @@ func (h ServiceHandler) Forward(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	require(ctx, h.resource(name))
+	if ctx.IsAborted() {
+		return
+	}
+	release := limited(ctx, h.resource(name))
+	if ctx.IsAborted() {
+		return
+	}
+	defer release()
 	if route == "" {
 		err := fmt.Errorf("route for: '%s' not defined", name)
 		_ = ctx.Error(err)
@@ -70,17 +109,33 @@ func (h ServiceHandler) Forward(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	rtx := RichContext(ctx)
+	caller := auth.Result{
+		Authenticated: true,
+		Subject:       rtx.Subject,
+		User:          rtx.User,
+		Scopes:        rtx.Scope.Granted,
+	}
+	token, err := auth.Idp().ServiceToken(ctx.Request, name, caller)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	proxy := httputil.ReverseProxy{
 		Director: func(req *http.Request) {
 			req.URL.Scheme = u.Scheme
 			req.URL.Host = u.Host
 			req.URL.Path = path
+			req.Header.Del(Authorization)
+			req.Header.Set(Authorization, "Bearer "+token)
 			Log.Info(
 				"Routing (service)",
 				"path",
 				ctx.Request.URL.Path,
 				"route",
-				req.URL.String())
+				req.URL.String(),
+				"user",
+				rtx.User)
 		},
 		FlushInterval: -1, // Flush immediately for SSE/streaming (MCP, LLM responses)
 	}
@@ -88,5 +143,37 @@ func (h ServiceHandler) Forward(ctx *gin.Context) {
 	proxy.ServeHTTP(ctx.Writer, ctx.Request)
 }
 
+// probe returns the service with the (health) status.
+// The service is ready when the route responds (status < 500).
+func (h ServiceHandler) probe(name string) (r Service) {
+	r.Name = name
+	r.Route = serviceRoutes[name]
+	if r.Route == "" {
+		r.Status = api.ServiceNotConfigured
+		return
+	}
+	client := http.Client{Timeout: ServiceProbeTimeout}
+	response, err := client.Get(r.Route)
+	if err != nil {
+		r.Status = api.ServiceUnavailable
+		r.Reason = err.Error()
+		return
+	}
+	_ = response.Body.Close()
+	if response.StatusCode >= http.StatusInternalServerError {
+		r.Status = api.ServiceUnavailable
+		r.Reason = response.Status
+		return
+	}
+	r.Status = api.ServiceReady
+	return
+}
+
+// resource returns the scope resource for the service.
+func (h ServiceHandler) resource(name string) (resource string) {
+	resource = "services." + name
+	return
+}
+
 // Service REST resource.
 type Service = resource.Service
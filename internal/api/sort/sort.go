@@ -14,6 +14,18 @@ type Clause struct {
 	name      string
 }
 
+// Name returns the (resolved) field name.
+func (r *Clause) Name() (name string) {
+	name = r.name
+	return
+}
+
+// Descending returns true when sorted in descending order.
+func (r *Clause) Descending() (b bool) {
+	b = r.direction == "DESC"
+	return
+}
+
 // Sort provides sorting.
 type Sort struct {
 	fields  map[string]any
@@ -91,6 +103,12 @@ func (r *Sort) Sorted(in *gorm.DB) (out *gorm.DB) {
 	return
 }
 
+// Clauses returns the sort clauses.
+func (r *Sort) Clauses() (clauses []Clause) {
+	clauses = r.clauses
+	return
+}
+
 // init allocate maps.
 func (r *Sort) init() {
 	if r.fields == nil {
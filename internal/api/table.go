@@ -0,0 +1,558 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxColumnDepth the maximum depth of nested
+	// objects flattened into columns.
+	MaxColumnDepth = 3
+	// ColumnSeparator separates nested column names.
+	ColumnSeparator = "."
+	// ValueSeparator separates values collected from lists.
+	ValueSeparator = ", "
+)
+
+// Table flattens (streamed) objects into rows.
+// The columns are determined by the first object. When the
+// object is a struct, the columns are determined by the type
+// so that nil (nested) refs are rendered as empty cells.
+// Nested objects are flattened using dot notation. Example: owner.name.
+// Lists of objects are flattened into a cell for each nested field
+// containing the joined values. Example: contributors.name = "a, b".
+type Table struct {
+	Columns []string
+}
+
+// Header returns the header (column names).
+func (r *Table) Header() (header []any) {
+	for _, c := range r.Columns {
+		header = append(header, c)
+	}
+	return
+}
+
+// Row returns the (flattened) cells for the object.
+// Cells are either: string or json.Number.
+func (r *Table) Row(object any) (row []any, err error) {
+	if r.Columns == nil {
+		r.Columns = r.columns(object)
+	}
+	b, err := json.Marshal(object)
+	if err != nil {
+		return
+	}
+	var document any
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err = d.Decode(&document)
+	if err != nil {
+		return
+	}
+	for _, column := range r.Columns {
+		var path []string
+		if column != "" {
+			path = strings.Split(column, ColumnSeparator)
+		}
+		values := r.valueAt(document, path)
+		row = append(row, r.cell(values))
+	}
+	return
+}
+
+// columns returns the flattened columns for the object.
+func (r *Table) columns(object any) (columns []string) {
+	t := reflect.TypeOf(object)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) {
+		r.typeColumns(&columns, "", t, 0)
+		return
+	}
+	b, err := json.Marshal(object)
+	if err != nil {
+		return
+	}
+	var document any
+	err = json.Unmarshal(b, &document)
+	if err != nil {
+		return
+	}
+	r.documentColumns(&columns, "", document, 0)
+	return
+}
+
+// typeColumns appends the flattened columns for the type.
+func (r *Table) typeColumns(columns *[]string, prefix string, t reflect.Type, depth int) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if depth >= MaxColumnDepth || t == reflect.TypeOf(time.Time{}) {
+			*columns = append(*columns, prefix)
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := f.Name
+			tag := f.Tag.Get("json")
+			if tag != "" {
+				name = strings.Split(tag, ",")[0]
+			}
+			if name == "-" {
+				continue
+			}
+			if f.Anonymous && (tag == "" || name == "") {
+				r.typeColumns(columns, prefix, f.Type, depth)
+				continue
+			}
+			r.typeColumns(columns, r.join(prefix, name), f.Type, depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		elem := t.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Struct && elem != reflect.TypeOf(time.Time{}) {
+			r.typeColumns(columns, prefix, elem, depth)
+			return
+		}
+		*columns = append(*columns, prefix)
+	default:
+		*columns = append(*columns, prefix)
+	}
+}
+
+// documentColumns appends the flattened columns for a (json) document.
+func (r *Table) documentColumns(columns *[]string, prefix string, document any, depth int) {
+	switch d := document.(type) {
+	case map[string]any:
+		if depth >= MaxColumnDepth || len(d) == 0 {
+			*columns = append(*columns, prefix)
+			return
+		}
+		var keys []string
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			r.documentColumns(columns, r.join(prefix, k), d[k], depth+1)
+		}
+	case []any:
+		if len(d) > 0 {
+			if _, isMap := d[0].(map[string]any); isMap {
+				r.documentColumns(columns, prefix, d[0], depth)
+				return
+			}
+		}
+		*columns = append(*columns, prefix)
+	default:
+		*columns = append(*columns, prefix)
+	}
+}
+
+// valueAt returns the values found at the path.
+// Values within lists are collected.
+func (r *Table) valueAt(document any, path []string) (values []any) {
+	if len(path) == 0 {
+		if document != nil {
+			values = append(values, document)
+		}
+		return
+	}
+	switch d := document.(type) {
+	case map[string]any:
+		values = r.valueAt(d[path[0]], path[1:])
+	case []any:
+		for _, v := range d {
+			values = append(values, r.valueAt(v, path)...)
+		}
+	}
+	return
+}
+
+// cell returns the cell for the values.
+func (r *Table) cell(values []any) (cell any) {
+	if len(values) == 1 {
+		if n, isNumber := values[0].(json.Number); isNumber {
+			cell = n
+			return
+		}
+	}
+	var parts []string
+	for _, v := range values {
+		switch x := v.(type) {
+		case string:
+			parts = append(parts, x)
+		case json.Number:
+			parts = append(parts, x.String())
+		case bool:
+			parts = append(parts, strconv.FormatBool(x))
+		case []any:
+			scalar := true
+			var list []string
+			for _, item := range x {
+				switch s := item.(type) {
+				case string:
+					list = append(list, s)
+				case json.Number:
+					list = append(list, s.String())
+				case bool:
+					list = append(list, strconv.FormatBool(s))
+				default:
+					scalar = false
+				}
+			}
+			if scalar {
+				parts = append(parts, strings.Join(list, ValueSeparator))
+				break
+			}
+			b, _ := json.Marshal(x)
+			parts = append(parts, string(b))
+		default:
+			b, _ := json.Marshal(x)
+			parts = append(parts, string(b))
+		}
+	}
+	cell = strings.Join(parts, ValueSeparator)
+	return
+}
+
+// join returns the joined column name.
+func (r *Table) join(prefix, name string) (joined string) {
+	joined = name
+	if prefix != "" {
+		joined = prefix + ColumnSeparator + name
+	}
+	return
+}
+
+// errTabular reports an encoder method not supported by tabular encoders.
+var errTabular = &BadRequestError{Reason: "MIME (tabular) supports lists only."}
+
+// csvEncoder streamed CSV encoder.
+// Only lists (of objects) are supported.
+type csvEncoder struct {
+	output io.Writer
+	writer *csv.Writer
+	table  Table
+	errors []error
+}
+
+func (r *csvEncoder) begin() Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *csvEncoder) end() Encoder {
+	return r
+}
+
+func (r *csvEncoder) write(s string) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *csvEncoder) writeStr(s string) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *csvEncoder) field(name string) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *csvEncoder) node(name string, value any) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *csvEncoder) beginList() Encoder {
+	if r.writer == nil {
+		r.writer = csv.NewWriter(r.output)
+	}
+	return r
+}
+
+func (r *csvEncoder) endList() Encoder {
+	if r.writer == nil {
+		return r
+	}
+	r.writer.Flush()
+	r.record(r.writer.Error())
+	return r
+}
+
+func (r *csvEncoder) writeItem(batch, index int, object any) Encoder {
+	r.beginList()
+	header := r.table.Columns == nil
+	row, err := r.table.Row(object)
+	if err != nil {
+		r.record(err)
+		return r
+	}
+	if header {
+		r.writeRow(r.table.Header())
+	}
+	r.writeRow(row)
+	return r
+}
+
+func (r *csvEncoder) encode(object any) Encoder {
+	r.beginList()
+	r.writeItem(0, 0, object)
+	r.endList()
+	return r
+}
+
+func (r *csvEncoder) embed(object any) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+// writeRow writes a row.
+func (r *csvEncoder) writeRow(row []any) {
+	var record []string
+	for _, cell := range row {
+		switch v := cell.(type) {
+		case json.Number:
+			record = append(record, v.String())
+		case string:
+			record = append(record, v)
+		}
+	}
+	err := r.writer.Write(record)
+	r.record(err)
+}
+
+// record appends errors.
+func (r *csvEncoder) record(err error) {
+	if err != nil {
+		r.errors = append(r.errors, err)
+	}
+}
+
+// error returns the first error encountered.
+func (r *csvEncoder) error() (err error) {
+	if len(r.errors) > 0 {
+		err = r.errors[0]
+	}
+	return
+}
+
+// xlsxEncoder streamed (office open xml) spreadsheet encoder.
+// Only lists (of objects) are supported. The workbook contains
+// a single sheet. Rows are written (streamed) as inline strings
+// and numbers.
+type xlsxEncoder struct {
+	output io.Writer
+	writer *zip.Writer
+	sheet  io.Writer
+	table  Table
+	rows   int
+	errors []error
+}
+
+func (r *xlsxEncoder) begin() Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *xlsxEncoder) end() Encoder {
+	return r
+}
+
+func (r *xlsxEncoder) write(s string) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *xlsxEncoder) writeStr(s string) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *xlsxEncoder) field(name string) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *xlsxEncoder) node(name string, value any) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+func (r *xlsxEncoder) beginList() Encoder {
+	if r.writer != nil {
+		return r
+	}
+	r.writer = zip.NewWriter(r.output)
+	parts := []struct {
+		path    string
+		content string
+	}{
+		{
+			path: "[Content_Types].xml",
+			content: xml.Header +
+				`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+				`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+				`<Default Extension="xml" ContentType="application/xml"/>` +
+				`<Override PartName="/xl/workbook.xml" ` +
+				`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+				`<Override PartName="/xl/worksheets/sheet1.xml" ` +
+				`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+				`</Types>`,
+		},
+		{
+			path: "_rels/.rels",
+			content: xml.Header +
+				`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+				`<Relationship Id="rId1" ` +
+				`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+				`Target="xl/workbook.xml"/>` +
+				`</Relationships>`,
+		},
+		{
+			path: "xl/workbook.xml",
+			content: xml.Header +
+				`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+				`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+				`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+				`</workbook>`,
+		},
+		{
+			path: "xl/_rels/workbook.xml.rels",
+			content: xml.Header +
+				`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+				`<Relationship Id="rId1" ` +
+				`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" ` +
+				`Target="worksheets/sheet1.xml"/>` +
+				`</Relationships>`,
+		},
+	}
+	for _, part := range parts {
+		w, err := r.writer.Create(part.path)
+		if err != nil {
+			r.record(err)
+			return r
+		}
+		_, err = io.WriteString(w, part.content)
+		if err != nil {
+			r.record(err)
+			return r
+		}
+	}
+	w, err := r.writer.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		r.record(err)
+		return r
+	}
+	r.sheet = w
+	r.writeSheet(
+		xml.Header +
+			`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<sheetData>`)
+	return r
+}
+
+func (r *xlsxEncoder) endList() Encoder {
+	if r.writer == nil || r.sheet == nil {
+		return r
+	}
+	r.writeSheet(`</sheetData></worksheet>`)
+	err := r.writer.Close()
+	r.record(err)
+	r.sheet = nil
+	return r
+}
+
+func (r *xlsxEncoder) writeItem(batch, index int, object any) Encoder {
+	r.beginList()
+	header := r.table.Columns == nil
+	row, err := r.table.Row(object)
+	if err != nil {
+		r.record(err)
+		return r
+	}
+	if header {
+		r.writeRow(r.table.Header())
+	}
+	r.writeRow(row)
+	return r
+}
+
+func (r *xlsxEncoder) encode(object any) Encoder {
+	r.beginList()
+	r.writeItem(0, 0, object)
+	r.endList()
+	return r
+}
+
+func (r *xlsxEncoder) embed(object any) Encoder {
+	r.record(errTabular)
+	return r
+}
+
+// writeRow writes a row.
+func (r *xlsxEncoder) writeRow(row []any) {
+	if r.sheet == nil {
+		return
+	}
+	r.rows++
+	bfr := &bytes.Buffer{}
+	bfr.WriteString(`<row r="` + strconv.Itoa(r.rows) + `">`)
+	for _, cell := range row {
+		switch v := cell.(type) {
+		case json.Number:
+			bfr.WriteString(`<c><v>` + v.String() + `</v></c>`)
+		case string:
+			bfr.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			_ = xml.EscapeText(bfr, []byte(v))
+			bfr.WriteString(`</t></is></c>`)
+		}
+	}
+	bfr.WriteString(`</row>`)
+	r.writeSheet(bfr.String())
+}
+
+// writeSheet writes to the sheet.
+func (r *xlsxEncoder) writeSheet(s string) {
+	if r.sheet == nil {
+		return
+	}
+	_, err := io.WriteString(r.sheet, s)
+	r.record(err)
+}
+
+// record appends errors.
+func (r *xlsxEncoder) record(err error) {
+	if err != nil {
+		r.errors = append(r.errors, err)
+	}
+}
+
+// error returns the first error encountered.
+func (r *xlsxEncoder) error() (err error) {
+	if len(r.errors) > 0 {
+		err = r.errors[0]
+	}
+	return
+}
@@ -30,7 +30,7 @@ func (h TaskHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.Use(Required("tasks"))
 	routeGroup.GET(api.TasksRoute, h.List)
 	routeGroup.GET(api.TasksRoute+"/", h.List)
-	routeGroup.POST(api.TasksRoute, h.Create)
+	routeGroup.POST(api.TasksRoute, Idempotent, h.Create)
 	routeGroup.GET(api.TaskRoute, h.Get)
 	routeGroup.PUT(api.TaskRoute, h.Update)
 	routeGroup.PATCH(api.TaskRoute, Transaction, h.Update)
@@ -363,12 +363,15 @@ func (h TaskHandler) Dashboard(ctx *gin.Context) {
 // @description Create a task.
 // @description Note: The priority will be adjusted as needed
 // @description to ensure the priority higher than system reserved (0-9).
+// @description Supports the Idempotency-Key header. A retry with the same key
+// @description replays the original response.
 // @tags tasks
 // @accept json
 // @produce json
 // @success 201 {object} api.Task
 // @router /tasks [post]
 // @param task body api.Task true "Task data"
+// @param Idempotency-Key header string false "Idempotency key"
 func (h TaskHandler) Create(ctx *gin.Context) {
 	r := &Task{}
 	err := h.Bind(ctx, r)
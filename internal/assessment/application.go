@@ -64,7 +64,7 @@ func (r *ApplicationResolver) ArchetypeTags(app *model.Application) (tags []mode
 
 	seenTags := make(map[uint]bool)
 	for _, a := range archetypes {
-		for _, t := range a.Tags {
+		for _, t := range a.AllTags() {
 			if _, found := seenTags[t.ID]; !found {
 				seenTags[t.ID] = true
 				tags = append(tags, t)
@@ -126,23 +126,9 @@ func (r *ApplicationResolver) AssessmentTags(app *model.Application) (tags []mod
 
 // Risk returns the overall risk level for the application based on its or its archetypes' assessments.
 func (r *ApplicationResolver) Risk(app *model.Application) (risk string, err error) {
-	var assessments []Assessment
-	requiredAssessments := r.RequiredAssessments(app)
-	if len(requiredAssessments) > 0 {
-		assessments = requiredAssessments
-	} else {
-		archetypes, aErr := r.Archetypes(app)
-		if aErr != nil {
-			err = aErr
-			return
-		}
-		for _, a := range archetypes {
-			for _, assessment := range a.Assessments {
-				if r.questionnaireResolver.Required(assessment.QuestionnaireID) {
-					assessments = append(assessments, assessment)
-				}
-			}
-		}
+	assessments, err := r.assessments(app)
+	if err != nil {
+		return
 	}
 	risk = Risk(assessments)
 	return
@@ -150,28 +136,24 @@ func (r *ApplicationResolver) Risk(app *model.Application) (risk string, err err
 
 // Confidence returns the application's overall assessment confidence score.
 func (r *ApplicationResolver) Confidence(app *model.Application) (confidence int, err error) {
-	var assessments []Assessment
-	requiredAssessments := r.RequiredAssessments(app)
-	if len(requiredAssessments) > 0 {
-		assessments = requiredAssessments
-	} else {
-		archetypes, aErr := r.Archetypes(app)
-		if aErr != nil {
-			err = aErr
-			return
-		}
-		for _, a := range archetypes {
-			for _, assessment := range a.Assessments {
-				if r.questionnaireResolver.Required(assessment.QuestionnaireID) {
-					assessments = append(assessments, assessment)
-				}
-			}
-		}
+	assessments, err := r.assessments(app)
+	if err != nil {
+		return
 	}
 	confidence = Confidence(assessments)
 	return
 }
 
+// Score returns the application's overall assessment score.
+func (r *ApplicationResolver) Score(app *model.Application) (score float64, err error) {
+	assessments, err := r.assessments(app)
+	if err != nil {
+		return
+	}
+	score = Score(assessments)
+	return
+}
+
 // Assessed returns whether the application has been fully assessed.
 func (r *ApplicationResolver) Assessed(app *model.Application) (assessed bool, err error) {
 	// if the application has any of its own assessments, only consider them for
@@ -195,3 +177,25 @@ func (r *ApplicationResolver) Assessed(app *model.Application) (assessed bool, e
 	assessed = assessedCount > 0 && assessedCount == len(archetypes)
 	return
 }
+
+// assessments returns the (required) assessments used to determine the application's
+// risk, confidence and score. The archetypes' assessments are used when the
+// application does not have any of its own.
+func (r *ApplicationResolver) assessments(app *model.Application) (assessments []Assessment, err error) {
+	assessments = r.RequiredAssessments(app)
+	if len(assessments) > 0 {
+		return
+	}
+	archetypes, err := r.Archetypes(app)
+	if err != nil {
+		return
+	}
+	for _, a := range archetypes {
+		for _, assessment := range a.Assessments {
+			if r.questionnaireResolver.Required(assessment.QuestionnaireID) {
+				assessments = append(assessments, assessment)
+			}
+		}
+	}
+	return
+}
@@ -5,9 +5,23 @@ import (
 )
 
 // Archetype represents an Archetype with its assessments.
+// The assessments include those inherited from ancestors.
 type Archetype struct {
 	*model.Archetype
 	Assessments []Assessment
+	// Ancestors (parent first).
+	Ancestors []*model.Archetype
+	// Inherited from ancestors.
+	Inherited Inherited
+}
+
+// Inherited resources.
+type Inherited struct {
+	Tags              []model.Tag
+	Stakeholders      []model.Stakeholder
+	StakeholderGroups []model.StakeholderGroup
+	Profiles          []model.TargetProfile
+	Assessments       []Assessment
 }
 
 // With updates the Archetype with the db model and deserializes its assessments.
@@ -21,6 +35,78 @@ func (r *Archetype) With(m *model.Archetype) {
 	}
 }
 
+// Inherit resources from the ancestors (parent first).
+// A nearer archetype overrides:
+//   - tags: union.
+//   - stakeholders and groups: (all) when defined.
+//   - profiles: by name.
+//   - assessments: by questionnaire.
+func (r *Archetype) Inherit(ancestors []*model.Archetype) {
+	r.Ancestors = ancestors
+	r.Inherited = Inherited{}
+	tags := make(map[uint]bool)
+	for _, t := range r.Tags {
+		tags[t.ID] = true
+	}
+	profiles := make(map[string]bool)
+	for _, p := range r.Profiles {
+		profiles[p.Name] = true
+	}
+	questionnaires := make(map[uint]bool)
+	for _, a := range r.Assessments {
+		questionnaires[a.QuestionnaireID] = true
+	}
+	hasStakeholders := len(r.Stakeholders) > 0 || len(r.StakeholderGroups) > 0
+	for _, m := range ancestors {
+		for _, t := range m.Tags {
+			if !tags[t.ID] {
+				tags[t.ID] = true
+				r.Inherited.Tags = append(r.Inherited.Tags, t)
+			}
+		}
+		if !hasStakeholders && (len(m.Stakeholders) > 0 || len(m.StakeholderGroups) > 0) {
+			hasStakeholders = true
+			r.Inherited.Stakeholders = m.Stakeholders
+			r.Inherited.StakeholderGroups = m.StakeholderGroups
+		}
+		for _, p := range m.Profiles {
+			if !profiles[p.Name] {
+				profiles[p.Name] = true
+				r.Inherited.Profiles = append(r.Inherited.Profiles, p)
+			}
+		}
+		var inherited []uint
+		for i := range m.Assessments {
+			a := &m.Assessments[i]
+			if questionnaires[a.QuestionnaireID] {
+				continue
+			}
+			inherited = append(inherited, a.QuestionnaireID)
+			assessment := Assessment{}
+			assessment.With(a)
+			r.Inherited.Assessments = append(r.Inherited.Assessments, assessment)
+			r.Assessments = append(r.Assessments, assessment)
+		}
+		for _, id := range inherited {
+			questionnaires[id] = true
+		}
+	}
+}
+
+// AllTags returns the archetype tags including inherited.
+func (r *Archetype) AllTags() (tags []model.Tag) {
+	tags = append(tags, r.Tags...)
+	tags = append(tags, r.Inherited.Tags...)
+	return
+}
+
+// AllProfiles returns the target profiles including inherited.
+func (r *Archetype) AllProfiles() (profiles []model.TargetProfile) {
+	profiles = append(profiles, r.Profiles...)
+	profiles = append(profiles, r.Inherited.Profiles...)
+	return
+}
+
 // NewArchetypeResolver creates a new ArchetypeResolver.
 func NewArchetypeResolver(
 	m *model.Archetype,
@@ -34,6 +120,9 @@ func NewArchetypeResolver(
 	}
 	archetype := Archetype{}
 	archetype.With(m)
+	if membership != nil {
+		archetype.Inherit(membership.Ancestors(m))
+	}
 	a.archetype = archetype
 	return
 }
@@ -88,6 +177,12 @@ func (r *ArchetypeResolver) Confidence() (confidence int) {
 	return
 }
 
+// Score returns the archetype's overall assessment score.
+func (r *ArchetypeResolver) Score() (score float64) {
+	score = Score(r.RequiredAssessments())
+	return
+}
+
 // Assessed returns whether the archetype has been fully assessed.
 func (r *ArchetypeResolver) Assessed() (assessed bool) {
 	if r.questionnaire == nil {
@@ -97,6 +192,12 @@ func (r *ArchetypeResolver) Assessed() (assessed bool) {
 	return
 }
 
+// Archetype returns the archetype (with inherited resources).
+func (r *ArchetypeResolver) Archetype() (archetype Archetype) {
+	archetype = r.archetype
+	return
+}
+
 // Applications returns the archetype's member applications.
 func (r *ArchetypeResolver) Applications() (applications []Application, err error) {
 	if r.membership == nil {
@@ -0,0 +1,85 @@
+package assessment
+
+import (
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/onsi/gomega"
+)
+
+func TestArchetypeInherit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	tag := func(id uint) (m model.Tag) {
+		m.ID = id
+		return
+	}
+	assessment := func(id, questionnaireId uint) (m model.Assessment) {
+		m.ID = id
+		m.QuestionnaireID = questionnaireId
+		return
+	}
+	root := &model.Archetype{
+		Tags:         []model.Tag{tag(1), tag(2)},
+		Stakeholders: []model.Stakeholder{{Name: "Alice"}},
+		Profiles:     []model.TargetProfile{{Name: "A"}, {Name: "B"}},
+		Assessments:  []model.Assessment{assessment(1, 1), assessment(2, 2)},
+	}
+	root.ID = 1
+	parent := &model.Archetype{
+		ParentID:    &root.ID,
+		Tags:        []model.Tag{tag(3)},
+		Profiles:    []model.TargetProfile{{Name: "A"}},
+		Assessments: []model.Assessment{assessment(3, 1)},
+	}
+	parent.ID = 2
+	child := &model.Archetype{
+		ParentID: &parent.ID,
+		Tags:     []model.Tag{tag(2)},
+	}
+	child.ID = 3
+	resolver := &MembershipResolver{
+		archetypeMap: map[uint]*model.Archetype{
+			root.ID:   root,
+			parent.ID: parent,
+			child.ID:  child,
+		},
+	}
+
+	a := Archetype{}
+	a.With(child)
+	a.Inherit(resolver.Ancestors(child))
+	g.Expect(a.Ancestors).To(gomega.Equal([]*model.Archetype{parent, root}))
+	// tags (union).
+	g.Expect(len(a.AllTags())).To(gomega.Equal(3))
+	g.Expect(len(a.Inherited.Tags)).To(gomega.Equal(2))
+	// stakeholders (nearest defined).
+	g.Expect(len(a.Inherited.Stakeholders)).To(gomega.Equal(1))
+	// profiles (by name).
+	profiles := a.AllProfiles()
+	g.Expect(len(profiles)).To(gomega.Equal(2))
+	g.Expect(profiles[0].ArchetypeID).To(gomega.Equal(uint(0)))
+	// assessments (by questionnaire).
+	var ids []uint
+	for _, m := range a.Assessments {
+		ids = append(ids, m.ID)
+	}
+	g.Expect(ids).To(gomega.Equal([]uint{3, 2}))
+
+	// overridden.
+	child.Stakeholders = []model.Stakeholder{{Name: "Bob"}}
+	child.Assessments = []model.Assessment{assessment(4, 2)}
+	a = Archetype{}
+	a.With(child)
+	a.Inherit(resolver.Ancestors(child))
+	g.Expect(len(a.Inherited.Stakeholders)).To(gomega.Equal(0))
+	ids = nil
+	for _, m := range a.Assessments {
+		ids = append(ids, m.ID)
+	}
+	g.Expect(ids).To(gomega.Equal([]uint{4, 3}))
+
+	// cycle.
+	root.ParentID = &child.ID
+	g.Expect(len(resolver.Ancestors(child))).To(gomega.Equal(2))
+}
@@ -6,6 +6,12 @@ import (
 	"github.com/konveyor/tackle2-hub/internal/model"
 )
 
+// QuestionKey identifies a question by section and question order.
+type QuestionKey struct {
+	Section  uint
+	Question uint
+}
+
 // Assessment represents a deserialized Assessment.
 type Assessment struct {
 	*model.Assessment
@@ -29,8 +35,9 @@ func (r *Assessment) Status() string {
 
 // Complete returns whether all sections have been completed.
 func (r *Assessment) Complete() bool {
+	hidden := r.Hidden()
 	for _, s := range r.Sections {
-		if !r.sectionComplete(&s) {
+		if !r.sectionComplete(&s, hidden) {
 			return false
 		}
 	}
@@ -39,8 +46,9 @@ func (r *Assessment) Complete() bool {
 
 // Started returns whether any sections have been started.
 func (r *Assessment) Started() bool {
+	hidden := r.Hidden()
 	for _, s := range r.Sections {
-		if r.sectionStarted(&s) {
+		if r.sectionStarted(&s, hidden) {
 			return true
 		}
 	}
@@ -48,11 +56,25 @@ func (r *Assessment) Started() bool {
 }
 
 // Risk calculates the risk level (red, yellow, green, unknown) for the application.
+// When the questionnaire defines a scoring formula, the risk is determined
+// by the formula using the (weighted) scoring.
 func (r *Assessment) Risk() string {
+	if r.Scoring.Formula != "" {
+		scored := r.Scored()
+		if scored.Weight == 0 {
+			return RiskUnknown
+		}
+		risk, err := Formula(r.Scoring.Formula).Risk(scored)
+		if err != nil {
+			return RiskUnknown
+		}
+		return risk
+	}
 	var total uint
 	colors := make(map[string]uint)
+	hidden := r.Hidden()
 	for _, s := range r.Sections {
-		for _, risk := range r.sectionRisks(&s) {
+		for _, risk := range r.sectionRisks(&s, hidden) {
 			colors[risk]++
 			total++
 		}
@@ -72,13 +94,49 @@ func (r *Assessment) Risk() string {
 	return RiskGreen
 }
 
+// Scored returns the (weighted) scoring of the shown questions.
+// Each question is weighted by the section weight multiplied by the
+// question weight (default: 1). Answered questions are scored using the
+// answer score which defaults by risk. Questions answered with an unknown
+// risk (and no score) are not scored.
+func (r *Assessment) Scored() (scored Scored) {
+	scored.Risks = make(map[string]float64)
+	var total, sum float64
+	hidden := r.Hidden()
+	for _, s := range r.Sections {
+		for _, q := range s.Questions {
+			if hidden[QuestionKey{Section: s.Order, Question: q.Order}] {
+				continue
+			}
+			weight := r.weight(s.Weight) * r.weight(q.Weight)
+			total += weight
+			scored.Risks[r.questionRisk(&q)] += weight
+			n, found := r.questionScore(&q)
+			if found {
+				sum += n * weight
+				scored.Weight += weight
+			}
+		}
+	}
+	if total > 0 {
+		for risk, weight := range scored.Risks {
+			scored.Risks[risk] = weight / total * 100
+		}
+	}
+	if scored.Weight > 0 {
+		scored.Score = sum / scored.Weight
+	}
+	return
+}
+
 // Confidence calculates a confidence score based on the answers to an assessment's questions.
 // The algorithm is a reimplementation of the calculation done by Pathfinder.
 func (r *Assessment) Confidence() (score int) {
 	totalQuestions := 0
 	riskCounts := make(map[string]int)
+	hidden := r.Hidden()
 	for _, s := range r.Sections {
-		for _, risk := range r.sectionRisks(&s) {
+		for _, risk := range r.sectionRisks(&s, hidden) {
 			riskCounts[risk]++
 			totalQuestions++
 		}
@@ -111,6 +169,9 @@ func (r *Assessment) Confidence() (score int) {
 	return
 }
 
+// Prepare the sections by including, excluding or auto-answering
+// questions based on a set of tags. Answers to questions hidden by
+// (answer) conditions are cleared.
 func (r *Assessment) Prepare(tagResolver *TagResolver, tags Set) {
 	for i := range r.Sections {
 		s := &r.Sections[i]
@@ -118,15 +179,17 @@ func (r *Assessment) Prepare(tagResolver *TagResolver, tags Set) {
 		for _, q := range s.Questions {
 			for j := range q.Answers {
 				a := &q.Answers[j]
-				autoAnswerTags := NewSet()
+				reason := ""
 				for _, t := range a.AutoAnswerFor {
 					tag, found := tagResolver.Resolve(t.Category, t.Tag)
-					if found {
-						autoAnswerTags.Add(tag.ID)
+					if found && tags.Contains(tag.ID) {
+						reason = ReasonTagged + t.Category + "=" + t.Tag
+						break
 					}
 				}
-				if tags.Intersects(autoAnswerTags) {
+				if reason != "" {
 					a.AutoAnswered = true
+					a.AutoAnswerReason = reason
 					a.Selected = true
 					break
 				}
@@ -162,21 +225,98 @@ func (r *Assessment) Prepare(tagResolver *TagResolver, tags Set) {
 		}
 		s.Questions = includedQuestions
 	}
+	hidden := r.Hidden()
+	for i := range r.Sections {
+		s := &r.Sections[i]
+		for j := range s.Questions {
+			q := &s.Questions[j]
+			if !hidden[QuestionKey{Section: s.Order, Question: q.Order}] {
+				continue
+			}
+			for k := range q.Answers {
+				a := &q.Answers[k]
+				a.Selected = false
+				a.AutoAnswered = false
+			}
+		}
+	}
+	return
+}
+
+// Hidden returns the questions hidden by (answer) conditions.
+// A question with conditions is shown only when one of the referenced
+// answers is selected and the referenced question is shown.
+func (r *Assessment) Hidden() (hidden map[QuestionKey]bool) {
+	hidden = make(map[QuestionKey]bool)
+	questions := make(map[QuestionKey]*model.Question)
+	for i := range r.Sections {
+		s := &r.Sections[i]
+		for j := range s.Questions {
+			q := &s.Questions[j]
+			questions[QuestionKey{Section: s.Order, Question: q.Order}] = q
+		}
+	}
+	shown := make(map[QuestionKey]bool)
+	visiting := make(map[QuestionKey]bool)
+	var visible func(key QuestionKey) bool
+	visible = func(key QuestionKey) (matched bool) {
+		if v, found := shown[key]; found {
+			matched = v
+			return
+		}
+		q, found := questions[key]
+		if !found || visiting[key] {
+			return
+		}
+		if len(q.ShowWhen) == 0 {
+			matched = true
+			shown[key] = matched
+			return
+		}
+		visiting[key] = true
+		for _, ref := range q.ShowWhen {
+			refKey := QuestionKey{Section: ref.Section, Question: ref.Question}
+			if !visible(refKey) {
+				continue
+			}
+			for _, a := range questions[refKey].Answers {
+				if a.Order == ref.Answer && a.Selected {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				break
+			}
+		}
+		delete(visiting, key)
+		shown[key] = matched
+		return
+	}
+	for key := range questions {
+		if !visible(key) {
+			hidden[key] = true
+		}
+	}
 	return
 }
 
 func (r *Assessment) Tags() (tags []model.CategorizedTag) {
+	hidden := r.Hidden()
 	for _, s := range r.Sections {
-		for _, t := range r.sectionTags(&s) {
+		for _, t := range r.sectionTags(&s, hidden) {
 			tags = append(tags, t)
 		}
 	}
 	return
 }
 
-// Complete returns whether all questions in the section have been answered.
-func (r *Assessment) sectionComplete(s *model.Section) bool {
+// Complete returns whether all (shown) questions in the section have been answered.
+func (r *Assessment) sectionComplete(s *model.Section, hidden map[QuestionKey]bool) bool {
 	for _, q := range s.Questions {
+		if hidden[QuestionKey{Section: s.Order, Question: q.Order}] {
+			continue
+		}
 		if !r.questionAnswered(&q) {
 			return false
 		}
@@ -186,8 +326,11 @@ func (r *Assessment) sectionComplete(s *model.Section) bool {
 
 // Started returns whether any questions in the section have been answered.
 
-func (r *Assessment) sectionStarted(s *model.Section) bool {
+func (r *Assessment) sectionStarted(s *model.Section, hidden map[QuestionKey]bool) bool {
 	for _, q := range s.Questions {
+		if hidden[QuestionKey{Section: s.Order, Question: q.Order}] {
+			continue
+		}
 		if r.questionAnswered(&q) && !r.questionAutoAnswered(&q) {
 			return true
 		}
@@ -195,10 +338,13 @@ func (r *Assessment) sectionStarted(s *model.Section) bool {
 	return false
 }
 
-// Risks returns a slice of the risks of each of its questions.
-func (r *Assessment) sectionRisks(s *model.Section) []string {
+// Risks returns a slice of the risks of each of its (shown) questions.
+func (r *Assessment) sectionRisks(s *model.Section, hidden map[QuestionKey]bool) []string {
 	risks := []string{}
 	for _, q := range s.Questions {
+		if hidden[QuestionKey{Section: s.Order, Question: q.Order}] {
+			continue
+		}
 		risks = append(risks, r.questionRisk(&q))
 	}
 	return risks
@@ -206,8 +352,11 @@ func (r *Assessment) sectionRisks(s *model.Section) []string {
 
 // Tags returns all the tags that should be applied based on how
 // the questions in the section have been answered.
-func (r *Assessment) sectionTags(s *model.Section) (tags []model.CategorizedTag) {
+func (r *Assessment) sectionTags(s *model.Section, hidden map[QuestionKey]bool) (tags []model.CategorizedTag) {
 	for _, q := range s.Questions {
+		if hidden[QuestionKey{Section: s.Order, Question: q.Order}] {
+			continue
+		}
 		tags = append(tags, r.questionTags(&q)...)
 	}
 	return
@@ -223,6 +372,43 @@ func (r *Assessment) questionRisk(q *model.Question) string {
 	return RiskUnknown
 }
 
+// Score returns the score for the question based on how it has been answered.
+// The answer score defaults by risk. Returns false when not scored.
+func (r *Assessment) questionScore(q *model.Question) (score float64, found bool) {
+	for _, a := range q.Answers {
+		if !a.Selected {
+			continue
+		}
+		if a.Score != nil {
+			score = *a.Score
+			found = true
+			return
+		}
+		found = true
+		switch a.Risk {
+		case RiskRed:
+			score = ScoreRed
+		case RiskYellow:
+			score = ScoreYellow
+		case RiskGreen:
+			score = ScoreGreen
+		default:
+			found = false
+		}
+		return
+	}
+	return
+}
+
+// weight returns the weight (default: 1).
+func (r *Assessment) weight(n float64) (weight float64) {
+	weight = n
+	if weight == 0 {
+		weight = 1
+	}
+	return
+}
+
 // Answered returns whether the question has had an answer selected.
 func (r *Assessment) questionAnswered(q *model.Question) bool {
 	for _, a := range q.Answers {
@@ -213,3 +213,68 @@ func TestAssessmentComplete(t *testing.T) {
 	g.Expect(a.Complete()).To(gomega.BeTrue())
 	g.Expect(a.Status()).To(gomega.Equal(StatusComplete))
 }
+
+func TestAssessmentConditional(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	assessment := model.Assessment{}
+	assessment.Thresholds = model.Thresholds{Red: 1, Yellow: 1, Unknown: 1}
+	assessment.Sections = []model.Section{
+		{
+			Order: 1,
+			Questions: []model.Question{
+				{
+					Order: 1,
+					Text:  "Uses EJB?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes", Risk: RiskGreen},
+						{Order: 2, Text: "No", Risk: RiskGreen, Selected: true},
+					},
+				},
+				{
+					Order: 2,
+					Text:  "Which EJB version?",
+					ShowWhen: []model.AnswerRef{
+						{Section: 1, Question: 1, Answer: 1},
+					},
+					Answers: []model.Answer{
+						{Order: 1, Text: "2.x", Risk: RiskRed, Selected: true},
+						{Order: 2, Text: "3.x", Risk: RiskGreen},
+					},
+				},
+				{
+					Order: 3,
+					Text:  "Uses EJB 2.x entity beans?",
+					ShowWhen: []model.AnswerRef{
+						{Section: 1, Question: 2, Answer: 1},
+					},
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes", Risk: RiskRed},
+					},
+				},
+			},
+		},
+	}
+
+	a := Assessment{}
+	a.With(&assessment)
+	// hidden (transitive).
+	hidden := a.Hidden()
+	g.Expect(hidden).To(gomega.Equal(map[QuestionKey]bool{
+		{Section: 1, Question: 2}: true,
+		{Section: 1, Question: 3}: true,
+	}))
+	g.Expect(a.Complete()).To(gomega.BeTrue())
+	g.Expect(a.Risk()).To(gomega.Equal(RiskGreen))
+	g.Expect(a.Confidence()).To(gomega.Equal(100))
+	// shown.
+	a.Sections[0].Questions[0].Answers[0].Selected = true
+	a.Sections[0].Questions[0].Answers[1].Selected = false
+	g.Expect(len(a.Hidden())).To(gomega.Equal(0))
+	g.Expect(a.Complete()).To(gomega.BeFalse())
+	g.Expect(a.Risk()).To(gomega.Equal(RiskRed))
+	// prepare clears hidden answers.
+	a.Sections[0].Questions[0].Answers[0].Selected = false
+	a.Prepare(&TagResolver{}, NewSet())
+	g.Expect(a.Sections[0].Questions[1].Answers[0].Selected).To(gomega.BeFalse())
+}
@@ -0,0 +1,126 @@
+package assessment
+
+import (
+	"strings"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+)
+
+// Auto-answer reason prefixes.
+const (
+	ReasonTagged  = "Tagged: "
+	ReasonMatched = "Matched: "
+)
+
+// AutoAnswer selects the answers with (auto-answer) conditions matched
+// by the subject (application). The first answer matched is selected and
+// the evaluated predicates are recorded as the reason. Questions answered
+// by the user or auto-answered by tags are not changed. Answers selected by
+// conditions no longer matched are cleared.
+// Returns true when answers have been changed.
+func (r *Assessment) AutoAnswer(subject *Subject) (changed bool, err error) {
+	criteria := &Criteria{Subject: subject}
+	for i := range r.Sections {
+		s := &r.Sections[i]
+		for j := range s.Questions {
+			q := &s.Questions[j]
+			if !r.autoAnswerable(q) {
+				continue
+			}
+			selected := -1
+			reason := ""
+			for k := range q.Answers {
+				a := &q.Answers[k]
+				if a.AutoAnswerWhen == "" {
+					continue
+				}
+				matched, mErr := criteria.Match(a.AutoAnswerWhen)
+				if mErr != nil {
+					err = mErr
+					return
+				}
+				if matched {
+					selected = k
+					reason = r.reason(criteria.Evaluated())
+					break
+				}
+			}
+			for k := range q.Answers {
+				a := &q.Answers[k]
+				answer := model.Answer{}
+				if k == selected {
+					answer.Selected = true
+					answer.AutoAnswered = true
+					answer.AutoAnswerReason = reason
+				}
+				if a.Selected != answer.Selected ||
+					a.AutoAnswered != answer.AutoAnswered ||
+					a.AutoAnswerReason != answer.AutoAnswerReason {
+					a.Selected = answer.Selected
+					a.AutoAnswered = answer.AutoAnswered
+					a.AutoAnswerReason = answer.AutoAnswerReason
+					changed = true
+				}
+			}
+		}
+	}
+	hidden := r.Hidden()
+	for i := range r.Sections {
+		s := &r.Sections[i]
+		for j := range s.Questions {
+			q := &s.Questions[j]
+			if !hidden[QuestionKey{Section: s.Order, Question: q.Order}] {
+				continue
+			}
+			for k := range q.Answers {
+				a := &q.Answers[k]
+				if a.Selected && a.AutoAnswered && a.AutoAnswerWhen != "" &&
+					!strings.HasPrefix(a.AutoAnswerReason, ReasonTagged) {
+					a.Selected = false
+					a.AutoAnswered = false
+					a.AutoAnswerReason = ""
+					changed = true
+				}
+			}
+		}
+	}
+	return
+}
+
+// autoAnswerable returns true when the question has answers with
+// conditions and has not been answered by the user or by tags.
+func (r *Assessment) autoAnswerable(q *model.Question) (b bool) {
+	for _, a := range q.Answers {
+		if !a.Selected {
+			continue
+		}
+		if !a.AutoAnswered || a.AutoAnswerWhen == "" {
+			return
+		}
+		if strings.HasPrefix(a.AutoAnswerReason, ReasonTagged) {
+			return
+		}
+	}
+	for _, a := range q.Answers {
+		if a.AutoAnswerWhen != "" {
+			b = true
+			break
+		}
+	}
+	return
+}
+
+// reason returns the auto-answer reason.
+// Lists the evaluated predicates; not matched predicates are negated.
+func (r *Assessment) reason(evaluated []Evaluated) (reason string) {
+	var predicates []string
+	for _, p := range evaluated {
+		if p.Matched {
+			predicates = append(predicates, p.Predicate)
+		} else {
+			predicates = append(predicates, "!"+p.Predicate)
+		}
+	}
+	reason = ReasonMatched + strings.Join(predicates, ", ")
+	return
+}
@@ -0,0 +1,99 @@
+package assessment
+
+import (
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/onsi/gomega"
+)
+
+func TestAssessmentAutoAnswer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	subject := &Subject{
+		Facts: []model.Fact{
+			{Key: "java.version", Value: "8"},
+		},
+		Insights: []model.Insight{
+			{RuleSet: "eap7", Rule: "jms-01", Category: "mandatory"},
+		},
+	}
+	assessment := model.Assessment{}
+	assessment.Sections = []model.Section{
+		{
+			Order: 1,
+			Questions: []model.Question{
+				{
+					Order: 1,
+					Text:  "Java version?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "8", AutoAnswerWhen: "fact:java.version=8"},
+						{Order: 2, Text: "11+", AutoAnswerWhen: "!fact:java.version=8"},
+					},
+				},
+				{
+					Order: 2,
+					Text:  "Uses JMS?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes", AutoAnswerWhen: "rule:jms-*"},
+						{Order: 2, Text: "No", Selected: true},
+					},
+				},
+				{
+					Order: 3,
+					Text:  "Uses log4j?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes", AutoAnswerWhen: "dependency:log4j"},
+						{
+							Order:            2,
+							Text:             "No",
+							Selected:         true,
+							AutoAnswered:     true,
+							AutoAnswerWhen:   "!dependency:log4j",
+							AutoAnswerReason: "Matched: !dependency:log4j",
+						},
+					},
+				},
+				{
+					Order: 4,
+					Text:  "Mandatory issues?",
+					Answers: []model.Answer{
+						{
+							Order:            1,
+							Text:             "Yes",
+							Selected:         true,
+							AutoAnswered:     true,
+							AutoAnswerWhen:   "insights:optional",
+							AutoAnswerReason: "Matched: insights:optional",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	a := Assessment{}
+	a.With(&assessment)
+	changed, err := a.AutoAnswer(subject)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(changed).To(gomega.BeTrue())
+	questions := a.Sections[0].Questions
+	// matched.
+	g.Expect(questions[0].Answers[0].Selected).To(gomega.BeTrue())
+	g.Expect(questions[0].Answers[0].AutoAnswered).To(gomega.BeTrue())
+	g.Expect(questions[0].Answers[0].AutoAnswerReason).To(gomega.Equal("Matched: fact:java.version=8"))
+	g.Expect(questions[0].Answers[1].Selected).To(gomega.BeFalse())
+	// answered by the user.
+	g.Expect(questions[1].Answers[0].Selected).To(gomega.BeFalse())
+	g.Expect(questions[1].Answers[1].Selected).To(gomega.BeTrue())
+	// unchanged.
+	g.Expect(questions[2].Answers[1].Selected).To(gomega.BeTrue())
+	// no longer matched.
+	g.Expect(questions[3].Answers[0].Selected).To(gomega.BeFalse())
+	g.Expect(questions[3].Answers[0].AutoAnswered).To(gomega.BeFalse())
+	g.Expect(questions[3].Answers[0].AutoAnswerReason).To(gomega.BeEmpty())
+
+	changed, err = a.AutoAnswer(subject)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(changed).To(gomega.BeFalse())
+}
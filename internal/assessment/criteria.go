@@ -0,0 +1,608 @@
+package assessment
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/internal/task"
+	"gorm.io/gorm"
+)
+
+// Criteria (selector) predicate kinds.
+const (
+	PredicateTag        = "tag"
+	PredicateFact       = "fact"
+	PredicatePlatform   = "platform"
+	PredicateBusiness   = "business"
+	PredicateLabel      = "label"
+	PredicateRule       = "rule"
+	PredicateInsights   = "insights"
+	PredicateDependency = "dependency"
+)
+
+var (
+	InsightsRegex = regexp.MustCompile(`^([^<>=]+)(>=|<=|=|>|<)(\d+)$`)
+	// CriteriaRegex matches criteria predicates.
+	// Unlike the task selector, values may not contain
+	// parentheses which are used for grouping.
+	CriteriaRegex = regexp.MustCompile(`(\w+):([^\s|&()]+)`)
+)
+
+// Subject the application evaluated by archetype criteria.
+type Subject struct {
+	ApplicationID   uint
+	Tags            []model.Tag
+	Facts           []model.Fact
+	Platform        string
+	BusinessService string
+	// reported by the latest analysis.
+	Labels       []string
+	Insights     []model.Insight
+	Dependencies []model.TechDependency
+	// lazy loading.
+	db             *gorm.DB
+	analysisLoaded bool
+}
+
+// Load the subject.
+// The latest analysis is loaded (lazily) when referenced.
+func (r *Subject) Load(db *gorm.DB, id uint) (err error) {
+	r.db = db.Session(&gorm.Session{})
+	m := &model.Application{}
+	db = r.db.Preload("Tags.Category")
+	db = db.Preload("Facts")
+	db = db.Preload("Platform")
+	db = db.Preload("BusinessService")
+	err = db.First(m, id).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	r.ApplicationID = m.ID
+	r.Tags = m.Tags
+	r.Facts = m.Facts
+	if m.Platform != nil {
+		r.Platform = m.Platform.Kind
+	}
+	if m.BusinessService != nil {
+		r.BusinessService = m.BusinessService.Name
+	}
+	return
+}
+
+// labels returns the labels reported by the latest analysis.
+func (r *Subject) labels() (labels []string, err error) {
+	err = r.analysis()
+	labels = r.Labels
+	return
+}
+
+// analysis loads the insights, dependencies and labels
+// reported by the latest analysis.
+func (r *Subject) analysis() (err error) {
+	if r.db == nil || r.analysisLoaded {
+		return
+	}
+	var list []model.Analysis
+	db := r.db.Select("ID")
+	db = db.Where("ApplicationID", r.ApplicationID)
+	db = db.Order("ID DESC")
+	err = db.Limit(1).Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	r.analysisLoaded = true
+	if len(list) == 0 {
+		return
+	}
+	analysisId := list[0].ID
+	db = r.db.Select("RuleSet", "Rule", "Category", "Labels")
+	err = db.Find(&r.Insights, "AnalysisID", analysisId).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	db = r.db.Select("Provider", "Name", "Version", "Labels")
+	err = db.Find(&r.Dependencies, "AnalysisID", analysisId).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	r.labeled()
+	return
+}
+
+// labeled sets the labels reported by the insights and dependencies.
+func (r *Subject) labeled() {
+	r.Labels = nil
+	seen := make(map[string]bool)
+	add := func(labels []string) {
+		for _, label := range labels {
+			if !seen[label] {
+				seen[label] = true
+				r.Labels = append(r.Labels, label)
+			}
+		}
+	}
+	for _, m := range r.Insights {
+		add(m.Labels)
+	}
+	for _, m := range r.Dependencies {
+		add(m.Labels)
+	}
+}
+
+// Subjects loads criteria subjects (for all applications) in bulk.
+// Only the subject fields referenced by the selectors are loaded.
+type Subjects struct {
+	// predicate kinds referenced by the selectors.
+	kinds map[string]bool
+}
+
+// With the selectors.
+func (r *Subjects) With(selectors ...string) {
+	if r.kinds == nil {
+		r.kinds = make(map[string]bool)
+	}
+	for _, selector := range selectors {
+		for _, m := range CriteriaRegex.FindAllStringSubmatch(selector, -1) {
+			r.kinds[m[1]] = true
+		}
+	}
+}
+
+// Load the subjects keyed by application ID.
+func (r *Subjects) Load(db *gorm.DB) (subjects map[uint]*Subject, err error) {
+	db = db.Session(&gorm.Session{})
+	subjects = make(map[uint]*Subject)
+	var ids []uint
+	err = db.Model(&model.Application{}).Pluck("ID", &ids).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, id := range ids {
+		subjects[id] = &Subject{
+			ApplicationID: id,
+			db:            db,
+		}
+	}
+	if r.kinds[PredicateTag] {
+		err = r.tags(db, subjects)
+		if err != nil {
+			return
+		}
+	}
+	if r.kinds[PredicateFact] {
+		err = r.facts(db, subjects)
+		if err != nil {
+			return
+		}
+	}
+	if r.kinds[PredicatePlatform] || r.kinds[PredicateBusiness] {
+		err = r.application(db, subjects)
+		if err != nil {
+			return
+		}
+	}
+	if r.kinds[PredicateLabel] ||
+		r.kinds[PredicateRule] ||
+		r.kinds[PredicateInsights] ||
+		r.kinds[PredicateDependency] {
+		err = r.analysis(db, subjects)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// tags loads the application tags.
+func (r *Subjects) tags(db *gorm.DB, subjects map[uint]*Subject) (err error) {
+	type M struct {
+		AppId        uint
+		TagId        uint
+		TagName      string
+		CategoryId   uint
+		CategoryName string
+	}
+	var list []M
+	db = db.Select(
+		"j.applicationId AppId",
+		"t.id            TagId",
+		"t.name          TagName",
+		"c.id            CategoryId",
+		"c.name          CategoryName")
+	db = db.Table("applicationTags j")
+	db = db.Joins("JOIN tag t ON t.id = j.tagId")
+	db = db.Joins("JOIN tagCategory c ON c.id = t.categoryId")
+	err = db.Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range list {
+		subject, found := subjects[m.AppId]
+		if !found {
+			continue
+		}
+		tag := model.Tag{}
+		tag.ID = m.TagId
+		tag.Name = m.TagName
+		tag.CategoryID = m.CategoryId
+		tag.Category.ID = m.CategoryId
+		tag.Category.Name = m.CategoryName
+		subject.Tags = append(subject.Tags, tag)
+	}
+	return
+}
+
+// facts loads the application facts.
+func (r *Subjects) facts(db *gorm.DB, subjects map[uint]*Subject) (err error) {
+	var list []model.Fact
+	err = db.Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range list {
+		subject, found := subjects[m.ApplicationID]
+		if found {
+			subject.Facts = append(subject.Facts, m)
+		}
+	}
+	return
+}
+
+// application loads the application platform (kind) and
+// business service (name).
+func (r *Subjects) application(db *gorm.DB, subjects map[uint]*Subject) (err error) {
+	type M struct {
+		AppId           uint
+		Platform        string
+		BusinessService string
+	}
+	var list []M
+	db = db.Select(
+		"a.id                  AppId",
+		"COALESCE(p.kind, '')  Platform",
+		"COALESCE(bs.name, '') BusinessService")
+	db = db.Table("application a")
+	db = db.Joins("LEFT JOIN platform p ON p.id = a.platformId")
+	db = db.Joins("LEFT JOIN businessService bs ON bs.id = a.businessServiceId")
+	err = db.Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range list {
+		subject, found := subjects[m.AppId]
+		if found {
+			subject.Platform = m.Platform
+			subject.BusinessService = m.BusinessService
+		}
+	}
+	return
+}
+
+// analysis loads the insights, dependencies and labels
+// reported by the latest analysis of each application.
+func (r *Subjects) analysis(db *gorm.DB, subjects map[uint]*Subject) (err error) {
+	type M struct {
+		ID            uint
+		ApplicationID uint
+	}
+	var latest []M
+	q := db.Model(&model.Analysis{})
+	q = q.Select("MAX(ID) ID", "ApplicationID")
+	q = q.Group("ApplicationID")
+	err = q.Find(&latest).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	appId := make(map[uint]uint)
+	for _, m := range latest {
+		appId[m.ID] = m.ApplicationID
+	}
+	ids := db.Model(&model.Analysis{})
+	ids = ids.Select("MAX(ID)")
+	ids = ids.Group("ApplicationID")
+	var insights []model.Insight
+	q = db.Select("AnalysisID", "RuleSet", "Rule", "Category", "Labels")
+	err = q.Find(&insights, "AnalysisID IN (?)", ids).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range insights {
+		subject, found := subjects[appId[m.AnalysisID]]
+		if found {
+			subject.Insights = append(subject.Insights, m)
+		}
+	}
+	var dependencies []model.TechDependency
+	q = db.Select("AnalysisID", "Provider", "Name", "Version", "Labels")
+	err = q.Find(&dependencies, "AnalysisID IN (?)", ids).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range dependencies {
+		subject, found := subjects[appId[m.AnalysisID]]
+		if found {
+			subject.Dependencies = append(subject.Dependencies, m)
+		}
+	}
+	for _, subject := range subjects {
+		subject.labeled()
+		subject.analysisLoaded = true
+	}
+	return
+}
+
+// Evaluated selector predicate.
+type Evaluated struct {
+	Predicate string
+	Matched   bool
+}
+
+// Criteria evaluates archetype criteria and answer (auto-answer)
+// condition (selector) expressions.
+// The expression grammar is the task selector grammar:
+// predicates combined with && (and), || (or), ! (not) and
+// parentheses. Predicates:
+//   - tag:<category>[=<name>] the application has a tag in the category.
+//   - fact:<key>[=<value>] the application has the fact.
+//   - platform:kind=<kind> the application platform kind.
+//   - business:<name> the application business service.
+//   - label:<label> reported by the latest analysis.
+//   - rule:[<ruleset>/]<rule> insight reported by the latest analysis.
+//   - insights:<category>[<op><count>] the number of insights in the category
+//     reported by the latest analysis. op: =, >, >=, <, <= (default: >0).
+//   - dependency:<name>[=<version>] reported by the latest analysis.
+//
+// Names, keys and values may contain wildcards (*).
+// Example: tag:Language=Java && !(fact:cmdb.tier=3 || label:konveyor.io/target=*)
+type Criteria struct {
+	Subject *Subject
+	// evaluated predicates.
+	evaluated []Evaluated
+	err       error
+}
+
+// Match evaluates the selector.
+func (r *Criteria) Match(selector string) (matched bool, err error) {
+	r.evaluated = nil
+	r.err = nil
+	predicates := map[string]task.Predicate{
+		PredicateTag:        &criteriaPredicate{criteria: r, kind: PredicateTag, fn: r.tag},
+		PredicateFact:       &criteriaPredicate{criteria: r, kind: PredicateFact, fn: r.fact},
+		PredicatePlatform:   &criteriaPredicate{criteria: r, kind: PredicatePlatform, fn: r.platform},
+		PredicateBusiness:   &criteriaPredicate{criteria: r, kind: PredicateBusiness, fn: r.business},
+		PredicateLabel:      &criteriaPredicate{criteria: r, kind: PredicateLabel, fn: r.label},
+		PredicateRule:       &criteriaPredicate{criteria: r, kind: PredicateRule, fn: r.rule},
+		PredicateInsights:   &criteriaPredicate{criteria: r, kind: PredicateInsights, fn: r.insights},
+		PredicateDependency: &criteriaPredicate{criteria: r, kind: PredicateDependency, fn: r.dependency},
+	}
+	matched, err = task.NewPredicateSelector(CriteriaRegex, predicates).Match(selector)
+	if err == nil && r.err != nil {
+		err = r.err
+		matched = false
+	}
+	return
+}
+
+// Validate the selector syntax and predicates.
+func (r *Criteria) Validate(selector string) (err error) {
+	if r.Subject == nil {
+		r.Subject = &Subject{}
+	}
+	_, err = r.Match(selector)
+	return
+}
+
+// Evaluated returns the evaluated predicates.
+func (r *Criteria) Evaluated() (evaluated []Evaluated) {
+	evaluated = r.evaluated
+	return
+}
+
+// tag predicate.
+func (r *Criteria) tag(ref string) (matched bool) {
+	category, name := r.split(ref)
+	for _, tag := range r.Subject.Tags {
+		if !r.glob(category, tag.Category.Name) {
+			continue
+		}
+		if name == "" || r.glob(name, tag.Name) {
+			matched = true
+			break
+		}
+	}
+	return
+}
+
+// fact predicate.
+func (r *Criteria) fact(ref string) (matched bool) {
+	key, value := r.split(ref)
+	for _, fact := range r.Subject.Facts {
+		if !r.glob(key, fact.Key) {
+			continue
+		}
+		if value == "" || r.glob(value, r.string(fact.Value)) {
+			matched = true
+			break
+		}
+	}
+	return
+}
+
+// platform predicate.
+func (r *Criteria) platform(ref string) (matched bool) {
+	key, value := r.split(ref)
+	switch key {
+	case "kind":
+		matched = r.Subject.Platform != "" && r.glob(value, r.Subject.Platform)
+	}
+	return
+}
+
+// business service predicate.
+func (r *Criteria) business(ref string) (matched bool) {
+	name := r.Subject.BusinessService
+	matched = name != "" && r.glob(ref, name)
+	return
+}
+
+// label predicate.
+func (r *Criteria) label(ref string) (matched bool) {
+	labels, err := r.Subject.labels()
+	if err != nil {
+		r.err = err
+		return
+	}
+	for _, label := range labels {
+		if r.glob(ref, label) {
+			matched = true
+			break
+		}
+	}
+	return
+}
+
+// rule predicate.
+func (r *Criteria) rule(ref string) (matched bool) {
+	err := r.Subject.analysis()
+	if err != nil {
+		r.err = err
+		return
+	}
+	for _, insight := range r.Subject.Insights {
+		if r.glob(ref, insight.Rule) || r.glob(ref, insight.RuleSet+"/"+insight.Rule) {
+			matched = true
+			break
+		}
+	}
+	return
+}
+
+// insights (count) predicate.
+func (r *Criteria) insights(ref string) (matched bool) {
+	err := r.Subject.analysis()
+	if err != nil {
+		r.err = err
+		return
+	}
+	category, operator, wanted := ref, ">", 0
+	m := InsightsRegex.FindStringSubmatch(ref)
+	if m != nil {
+		category, operator = m[1], m[2]
+		wanted, _ = strconv.Atoi(m[3])
+	}
+	n := 0
+	for _, insight := range r.Subject.Insights {
+		if r.glob(category, insight.Category) {
+			n++
+		}
+	}
+	switch operator {
+	case "=":
+		matched = n == wanted
+	case ">":
+		matched = n > wanted
+	case ">=":
+		matched = n >= wanted
+	case "<":
+		matched = n < wanted
+	case "<=":
+		matched = n <= wanted
+	}
+	return
+}
+
+// dependency predicate.
+func (r *Criteria) dependency(ref string) (matched bool) {
+	err := r.Subject.analysis()
+	if err != nil {
+		r.err = err
+		return
+	}
+	name, version := r.split(ref)
+	for _, dep := range r.Subject.Dependencies {
+		if !r.glob(name, dep.Name) {
+			continue
+		}
+		if version == "" || r.glob(version, dep.Version) {
+			matched = true
+			break
+		}
+	}
+	return
+}
+
+// split the ref into key and (optional) value.
+func (r *Criteria) split(ref string) (key, value string) {
+	part := strings.SplitN(ref, "=", 2)
+	key = part[0]
+	if len(part) > 1 {
+		value = part[1]
+	}
+	return
+}
+
+// glob returns true when the string matches the pattern.
+// The pattern may contain wildcards (*).
+func (r *Criteria) glob(pattern, s string) (matched bool) {
+	if !strings.Contains(pattern, "*") {
+		matched = pattern == s
+		return
+	}
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	matched, _ = regexp.MatchString("^"+expr+"$", s)
+	return
+}
+
+// string returns the string representation of a fact value.
+func (r *Criteria) string(v any) (s string) {
+	switch x := v.(type) {
+	case nil:
+	case string:
+		s = x
+	default:
+		s = fmt.Sprint(x)
+	}
+	return
+}
+
+// criteriaPredicate selector predicate.
+// Records the evaluated predicate.
+type criteriaPredicate struct {
+	criteria *Criteria
+	kind     string
+	fn       func(ref string) bool
+}
+
+// Match evaluates the predicate.
+func (r *criteriaPredicate) Match(ref string) (matched bool, err error) {
+	matched = r.fn(ref)
+	predicate := r.kind + ":" + ref
+	for _, evaluated := range r.criteria.evaluated {
+		if evaluated.Predicate == predicate {
+			return
+		}
+	}
+	r.criteria.evaluated = append(
+		r.criteria.evaluated,
+		Evaluated{
+			Predicate: predicate,
+			Matched:   matched,
+		})
+	return
+}
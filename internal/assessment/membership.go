@@ -9,9 +9,11 @@ import (
 
 // NewMembershipResolver builds a MembershipResolver.
 func NewMembershipResolver(db *gorm.DB) (m *MembershipResolver, err error) {
-	m = &MembershipResolver{}
+	m = &MembershipResolver{db: db}
 	m.tagSets = make(map[uint]Set)
+	m.archetypeMap = make(map[uint]*model.Archetype)
 	m.archetypeMembers = make(map[uint][]Application)
+	m.subjects = make(map[uint]*Subject)
 	err = m.cacheArchetypes(db)
 	if err != nil {
 		return
@@ -25,13 +27,34 @@ func NewMembershipResolver(db *gorm.DB) (m *MembershipResolver, err error) {
 
 // MembershipResolver resolves archetype membership.
 type MembershipResolver struct {
+	db               *gorm.DB
 	archetypes       []Archetype
+	archetypeMap     map[uint]*model.Archetype
 	tagSets          map[uint]Set
 	archetypeMembers map[uint][]Application
+	subjects         map[uint]*Subject
+	subjectsLoaded   bool
 	membersCached    bool
 	archetypesCached bool
 }
 
+// Membership explains the archetype membership of an application.
+type Membership struct {
+	Archetype Archetype
+	// Matched the criteria is matched.
+	Matched bool
+	// Member the archetype is (one of) the most specific matched.
+	Member bool
+	// Missing criteria tags.
+	Missing []model.Tag
+	// Evaluated selector predicates.
+	Evaluated []Evaluated
+	// Superseded by more specific (matched) archetypes.
+	Superseded []Archetype
+	// Error selector evaluation error.
+	Error error
+}
+
 // Applications returns the list of applications that are members of the given archetype.
 func (r *MembershipResolver) Applications(m Archetype) (applications []Application, err error) {
 	applications = r.archetypeMembers[m.ID]
@@ -41,44 +64,140 @@ func (r *MembershipResolver) Applications(m Archetype) (applications []Applicati
 // Archetypes returns the most specific archetypes.
 // Algorithm:
 // 1. Build a tag set for the application.
-// 2. Identify potential archetypes whose tags are all contained in the application's tags.
-// 3. Incrementally filter candidates to keep only the most specific archetypes:
-//   - Discard any candidate that is a subset of an existing archetype (broader match).
-//   - Replace any existing archetypes that are subsets of the candidate (narrower match).
+// 2. Identify potential archetypes whose criteria are matched:
+//   - The criteria tags are all contained in the application's tags.
+//   - The criteria selector (expression) is matched.
 //
+// 3. Keep only the most specific archetypes by discarding any matched
+// archetype when a narrower archetype is also matched.
 // 4. Record the application as a member of each resulting archetype.
 // This ensures that each application is mapped only to the narrowest matching archetypes.
 func (r *MembershipResolver) Archetypes(m Application) (archetypes []Archetype, err error) {
+	memberships, err := r.Explain(m)
+	if err != nil {
+		return
+	}
+	for _, membership := range memberships {
+		if membership.Member {
+			archetypes = append(archetypes, membership.Archetype)
+		}
+	}
+	for _, a := range archetypes {
+		r.archetypeMembers[a.ID] = append(r.archetypeMembers[a.ID], m)
+	}
+	return
+}
+
+// Explain returns the archetype membership of the application
+// for each archetype.
+// Selector evaluation errors are reported (not returned) and the
+// archetype is not matched.
+func (r *MembershipResolver) Explain(m Application) (memberships []Membership, err error) {
 	appTags := NewSet()
 	for _, t := range m.Tags {
 		appTags.Add(t.ID)
 	}
 	for _, a := range r.archetypes {
-		aTags := r.tagSets[a.ID]
-		if !appTags.Superset(aTags, false) {
-			continue
+		membership := Membership{Archetype: a}
+		for _, t := range a.CriteriaTags {
+			if !appTags.Contains(t.ID) {
+				membership.Missing = append(membership.Missing, t)
+			}
 		}
-		dominated := false
-		kept := archetypes[:0]
-		for _, existing := range archetypes {
-			existingTags := r.tagSets[existing.ID]
-			if aTags.Subset(existingTags, true) {
-				dominated = true
-				break
+		membership.Matched = len(membership.Missing) == 0
+		if a.Selector != "" {
+			subject, sErr := r.subject(m)
+			if sErr != nil {
+				err = sErr
+				return
 			}
-			if existingTags.Subset(aTags, true) {
+			criteria := Criteria{Subject: subject}
+			matched, mErr := criteria.Match(a.Selector)
+			if mErr != nil {
+				membership.Error = mErr
+			}
+			membership.Evaluated = criteria.Evaluated()
+			membership.Matched = membership.Matched && matched
+		}
+		memberships = append(memberships, membership)
+	}
+	for i := range memberships {
+		membership := &memberships[i]
+		if !membership.Matched {
+			continue
+		}
+		for _, other := range memberships {
+			if !other.Matched {
 				continue
 			}
-			kept = append(kept, existing)
+			if r.narrower(other.Archetype, membership.Archetype) {
+				membership.Superseded = append(membership.Superseded, other.Archetype)
+			}
+		}
+		membership.Member = len(membership.Superseded) == 0
+	}
+	return
+}
+
+// narrower returns true when archetype (a) is more specific than (b).
+// An archetype is more specific when its criteria tags are a strict superset.
+// When the criteria tags are the same, an archetype with a selector is more
+// specific than an archetype without.
+func (r *MembershipResolver) narrower(a, b Archetype) (narrower bool) {
+	aTags := r.tagSets[a.ID]
+	bTags := r.tagSets[b.ID]
+	if bTags.Subset(aTags, true) {
+		narrower = true
+		return
+	}
+	if bTags.Subset(aTags, false) && aTags.Subset(bTags, false) {
+		narrower = a.Selector != "" && b.Selector == ""
+	}
+	return
+}
+
+// Ancestors returns the ancestors of the archetype (parent first).
+// The walk stops when a cycle is detected.
+func (r *MembershipResolver) Ancestors(m *model.Archetype) (ancestors []*model.Archetype) {
+	seen := map[uint]bool{m.ID: true}
+	parentID := m.ParentID
+	for parentID != nil && !seen[*parentID] {
+		parent, found := r.archetypeMap[*parentID]
+		if !found {
+			break
+		}
+		seen[parent.ID] = true
+		ancestors = append(ancestors, parent)
+		parentID = parent.ParentID
+	}
+	return
+}
+
+// subject returns the (cached) criteria subject for the application.
+// The subjects (for all applications) are loaded in bulk when first
+// needed. Only the fields referenced by archetype selectors are loaded.
+func (r *MembershipResolver) subject(m Application) (subject *Subject, err error) {
+	if !r.subjectsLoaded {
+		subjects := Subjects{}
+		for _, a := range r.archetypes {
+			subjects.With(a.Selector)
 		}
-		archetypes = kept
-		if !dominated {
-			archetypes = append(archetypes, a)
+		r.subjects, err = subjects.Load(r.db)
+		if err != nil {
+			return
 		}
+		r.subjectsLoaded = true
 	}
-	for _, a := range archetypes {
-		r.archetypeMembers[a.ID] = append(r.archetypeMembers[a.ID], m)
+	subject, found := r.subjects[m.ID]
+	if found {
+		return
+	}
+	subject = &Subject{}
+	err = subject.Load(r.db, m.ID)
+	if err != nil {
+		return
 	}
+	r.subjects[m.ID] = subject
 	return
 }
 
@@ -91,6 +210,11 @@ func (r *MembershipResolver) cacheArchetypes(db *gorm.DB) (err error) {
 	db = db.Preload(clause.Associations)
 	db = db.Preload("Assessments.Stakeholders")
 	db = db.Preload("Assessments.StakeholderGroups")
+	db = db.Preload("Profiles.AnalysisProfile")
+	db = db.Preload("Profiles.Generators.Generator")
+	db = db.Preload("Profiles.Generators", func(db *gorm.DB) *gorm.DB {
+		return db.Order("`Index`")
+	})
 	result := db.Find(&list)
 	if result.Error != nil {
 		err = liberr.Wrap(err)
@@ -98,8 +222,14 @@ func (r *MembershipResolver) cacheArchetypes(db *gorm.DB) (err error) {
 	}
 
 	for i := range list {
+		m := &list[i]
+		r.archetypeMap[m.ID] = m
+	}
+	for i := range list {
+		m := &list[i]
 		a := Archetype{}
-		a.With(&list[i])
+		a.With(m)
+		a.Inherit(r.Ancestors(m))
 		r.archetypes = append(r.archetypes, a)
 		set := NewSet()
 		for _, t := range a.CriteriaTags {
@@ -124,14 +254,14 @@ func (r *MembershipResolver) cacheArchetypeMembers(db *gorm.DB) (err error) {
 		CategoryId uint
 	}
 	db = db.Select(
-		"a.id         AppId",
-		"a.name       AppName",
-		"t.id         TagId",
-		"t.name       TagName",
-		"t.categoryId CategoryId")
+		"a.id                      AppId",
+		"a.name                    AppName",
+		"COALESCE(t.id, 0)         TagId",
+		"COALESCE(t.name, '')      TagName",
+		"COALESCE(t.categoryId, 0) CategoryId")
 	db = db.Table("application a")
-	db = db.Joins("JOIN applicationTags j ON j.applicationId = a.id")
-	db = db.Joins("JOIN tag t ON t.id = j.tagId")
+	db = db.Joins("LEFT JOIN applicationTags j ON j.applicationId = a.id")
+	db = db.Joins("LEFT JOIN tag t ON t.id = j.tagId")
 	db = db.Order("a.id")
 	cursor, err := db.Rows()
 	if err != nil {
@@ -141,7 +271,10 @@ func (r *MembershipResolver) cacheArchetypeMembers(db *gorm.DB) (err error) {
 	defer func() {
 		_ = cursor.Close()
 	}()
-	application := model.Application{}
+	// The applications are resolved after the cursor is
+	// closed. Evaluating selectors may query the DB.
+	var applications []*model.Application
+	application := &model.Application{}
 	for cursor.Next() {
 		var m M
 		err = db.ScanRows(cursor, &m)
@@ -150,29 +283,27 @@ func (r *MembershipResolver) cacheArchetypeMembers(db *gorm.DB) (err error) {
 			return
 		}
 		if m.AppId != application.ID {
-			if application.ID > 0 {
-				appCopy := application
-				a := Application{Application: &appCopy}
-				_, err = r.Archetypes(a)
-				if err != nil {
-					return
-				}
-			}
+			application = &model.Application{}
 			application.ID = m.AppId
 			application.Name = m.AppName
-			application.Tags = nil
+			applications = append(applications, application)
+		}
+		if m.TagId == 0 {
+			continue
 		}
 		tag := model.Tag{}
 		tag.ID = m.TagId
 		tag.Name = m.TagName
 		tag.CategoryID = m.CategoryId
 		application.Tags = append(application.Tags, tag)
-
 	}
-	// Last
-	if application.ID > 0 {
-		appCopy := application
-		a := Application{Application: &appCopy}
+	err = cursor.Close()
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, application := range applications {
+		a := Application{Application: application}
 		_, err = r.Archetypes(a)
 		if err != nil {
 			return
@@ -0,0 +1,216 @@
+package assessment
+
+import (
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/internal/database"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/onsi/gomega"
+)
+
+func TestCriteria(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	language := model.TagCategory{Name: "Language"}
+	subject := &Subject{
+		Tags: []model.Tag{
+			{Name: "Java", Category: language},
+		},
+		Facts: []model.Fact{
+			{Key: "cmdb.tier", Value: float64(3)},
+		},
+		Platform:        "cloudfoundry",
+		BusinessService: "Retail",
+		Labels:          []string{"konveyor.io/target=quarkus"},
+		Insights: []model.Insight{
+			{RuleSet: "eap7", Rule: "jms-01", Category: "mandatory"},
+			{RuleSet: "eap7", Rule: "jndi-01", Category: "mandatory"},
+			{RuleSet: "cloud", Rule: "session-01", Category: "optional"},
+		},
+		Dependencies: []model.TechDependency{
+			{Name: "log4j", Version: "1.2.17"},
+		},
+	}
+	criteria := Criteria{Subject: subject}
+	cases := map[string]bool{
+		"tag:Language=Java":                             true,
+		"tag:Language":                                  true,
+		"tag:Lang*=J*":                                  true,
+		"tag:Language=Go":                               false,
+		"tag:Language=Java && !tag:Language=Go":         true,
+		"tag:Language=Go || fact:cmdb.tier=3":           true,
+		"fact:cmdb.tier":                                true,
+		"fact:cmdb.tier=2":                              false,
+		"platform:kind=cloudfoundry":                    true,
+		"platform:kind=kubernetes":                      false,
+		"business:Ret*":                                 true,
+		"label:konveyor.io/target=*":                    true,
+		"!(label:konveyor.io/target=eap || business:X)": true,
+		"rule:jms-01":                                   true,
+		"rule:eap7/*":                                   true,
+		"rule:ejb-*":                                    false,
+		"insights:mandatory":                            true,
+		"insights:mandatory>=2":                         true,
+		"insights:mandatory>2":                          false,
+		"insights:optional=1":                           true,
+		"insights:potential":                            false,
+		"dependency:log4j=1.*":                          true,
+		"dependency:log4j=2.*":                          false,
+		"dependency:slf4j":                              false,
+	}
+	for selector, expected := range cases {
+		matched, err := criteria.Match(selector)
+		g.Expect(err).To(gomega.BeNil(), selector)
+		g.Expect(matched).To(gomega.Equal(expected), selector)
+	}
+
+	_, err := criteria.Match("tag:Language=Go || fact:cmdb.tier=3")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(criteria.Evaluated()).To(gomega.Equal(
+		[]Evaluated{
+			{Predicate: "tag:Language=Go", Matched: false},
+			{Predicate: "fact:cmdb.tier=3", Matched: true},
+		}))
+
+	g.Expect(criteria.Validate("unknown:thing")).NotTo(gomega.BeNil())
+	g.Expect(criteria.Validate("tag:Language &&")).NotTo(gomega.BeNil())
+	g.Expect(criteria.Validate("")).To(gomega.BeNil())
+}
+
+func TestMembershipExplain(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	language := model.TagCategory{Name: "Language"}
+	java := model.Tag{Model: model.Model{ID: 1}, Name: "Java", Category: language}
+	linux := model.Tag{Model: model.Model{ID: 2}, Name: "Linux"}
+	archetype := func(id uint, selector string, tags ...model.Tag) (a Archetype) {
+		m := &model.Archetype{Selector: selector, CriteriaTags: tags}
+		m.ID = id
+		a.With(m)
+		return
+	}
+	resolver := &MembershipResolver{
+		tagSets:          make(map[uint]Set),
+		archetypeMembers: make(map[uint][]Application),
+		subjects:         make(map[uint]*Subject),
+		subjectsLoaded:   true,
+	}
+	resolver.archetypes = []Archetype{
+		archetype(1, ""),
+		archetype(2, "", java),
+		archetype(3, "tag:Language=Java", java),
+		archetype(4, "", java, linux),
+		archetype(5, "tag:Language=Go"),
+	}
+	for _, a := range resolver.archetypes {
+		set := NewSet()
+		for _, t := range a.CriteriaTags {
+			set.Add(t.ID)
+		}
+		resolver.tagSets[a.ID] = set
+	}
+	app := Application{Application: &model.Application{}}
+	app.ID = 1
+	app.Tags = []model.Tag{java}
+	resolver.subjects[app.ID] = &Subject{Tags: app.Tags}
+
+	memberships, err := resolver.Explain(app)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(memberships)).To(gomega.Equal(5))
+	// no criteria.
+	g.Expect(memberships[0].Matched).To(gomega.BeTrue())
+	g.Expect(memberships[0].Member).To(gomega.BeFalse())
+	// same tags without selector.
+	g.Expect(memberships[1].Matched).To(gomega.BeTrue())
+	g.Expect(memberships[1].Member).To(gomega.BeFalse())
+	g.Expect(memberships[1].Superseded[0].ID).To(gomega.Equal(uint(3)))
+	// most specific.
+	g.Expect(memberships[2].Matched).To(gomega.BeTrue())
+	g.Expect(memberships[2].Member).To(gomega.BeTrue())
+	// missing tag.
+	g.Expect(memberships[3].Matched).To(gomega.BeFalse())
+	g.Expect(memberships[3].Missing[0].ID).To(gomega.Equal(uint(2)))
+	// selector not matched.
+	g.Expect(memberships[4].Matched).To(gomega.BeFalse())
+	g.Expect(memberships[4].Evaluated[0].Matched).To(gomega.BeFalse())
+
+	archetypes, err := resolver.Archetypes(app)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(archetypes)).To(gomega.Equal(1))
+	g.Expect(archetypes[0].ID).To(gomega.Equal(uint(3)))
+	members, _ := resolver.Applications(archetypes[0])
+	g.Expect(len(members)).To(gomega.Equal(1))
+}
+
+func TestSubjects(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	db, err := database.OpenTest()
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(
+		&model.Application{},
+		&model.TagCategory{},
+		&model.Tag{},
+		&model.Fact{},
+		&model.Analysis{},
+		&model.Insight{},
+		&model.TechDependency{})
+	g.Expect(err).To(gomega.BeNil())
+	category := &model.TagCategory{Name: "Language"}
+	g.Expect(db.Create(category).Error).To(gomega.BeNil())
+	tag := &model.Tag{Name: "Java", CategoryID: category.ID}
+	g.Expect(db.Create(tag).Error).To(gomega.BeNil())
+	service := &model.BusinessService{Name: "Retail"}
+	g.Expect(db.Create(service).Error).To(gomega.BeNil())
+	app := &model.Application{
+		Name:              "A",
+		BusinessServiceID: &service.ID,
+		Tags:              []model.Tag{*tag},
+	}
+	g.Expect(db.Create(app).Error).To(gomega.BeNil())
+	other := &model.Application{Name: "B"}
+	g.Expect(db.Create(other).Error).To(gomega.BeNil())
+	fact := &model.Fact{ApplicationID: app.ID, Key: "tier", Source: "test", Value: "3"}
+	g.Expect(db.Create(fact).Error).To(gomega.BeNil())
+	for _, rule := range []string{"old", "new"} {
+		analysis := &model.Analysis{ApplicationID: app.ID}
+		g.Expect(db.Create(analysis).Error).To(gomega.BeNil())
+		insight := &model.Insight{
+			AnalysisID: analysis.ID,
+			RuleSet:    "rs",
+			Rule:       rule,
+			Category:   "mandatory",
+			Labels:     []string{"konveyor.io/target=" + rule},
+		}
+		g.Expect(db.Create(insight).Error).To(gomega.BeNil())
+	}
+
+	// Only referenced predicates loaded.
+	subjects := Subjects{}
+	subjects.With("tag:Language=Java", "!(business:Retail)")
+	loaded, err := subjects.Load(db)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(loaded)).To(gomega.Equal(2))
+	subject := loaded[app.ID]
+	g.Expect(len(subject.Tags)).To(gomega.Equal(1))
+	g.Expect(subject.Tags[0].Category.Name).To(gomega.Equal("Language"))
+	g.Expect(subject.BusinessService).To(gomega.Equal("Retail"))
+	g.Expect(subject.Facts).To(gomega.BeEmpty())
+	g.Expect(subject.analysisLoaded).To(gomega.BeFalse())
+	g.Expect(loaded[other.ID].Tags).To(gomega.BeEmpty())
+
+	// Latest analysis.
+	subjects = Subjects{}
+	subjects.With("fact:tier=3 && rule:new")
+	loaded, err = subjects.Load(db)
+	g.Expect(err).To(gomega.BeNil())
+	subject = loaded[app.ID]
+	g.Expect(len(subject.Facts)).To(gomega.Equal(1))
+	g.Expect(subject.analysisLoaded).To(gomega.BeTrue())
+	g.Expect(len(subject.Insights)).To(gomega.Equal(1))
+	g.Expect(subject.Labels).To(gomega.Equal([]string{"konveyor.io/target=new"}))
+	criteria := Criteria{Subject: subject}
+	matched, err := criteria.Match("fact:tier=3 && rule:new && !(rule:old)")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(matched).To(gomega.BeTrue())
+}
@@ -2,6 +2,7 @@ package assessment
 
 import (
 	"github.com/konveyor/tackle2-hub/internal/model"
+	"gorm.io/gorm"
 )
 
 // Assessment risk
@@ -88,6 +89,24 @@ func Confidence(assessments []Assessment) (confidence int) {
 	return
 }
 
+// Score returns the average score for a group of assessments.
+// Assessments without scored questions are not included.
+func Score(assessments []Assessment) (score float64) {
+	n := 0
+	for _, a := range assessments {
+		scored := a.Scored()
+		if scored.Weight == 0 {
+			continue
+		}
+		score += scored.Score
+		n++
+	}
+	if n > 0 {
+		score /= float64(n)
+	}
+	return
+}
+
 // PrepareForApplication prepares the sections of an assessment by including, excluding,
 // or auto-answering questions based on a set of tags.
 func PrepareForApplication(tagResolver *TagResolver, application *model.Application, assessment *model.Assessment) {
@@ -101,6 +120,20 @@ func PrepareForApplication(tagResolver *TagResolver, application *model.Applicat
 	return
 }
 
+// AutoAnswerForApplication auto-answers questions based on the application
+// facts and the latest analysis (answer conditions).
+func AutoAnswerForApplication(db *gorm.DB, id uint, assessment *model.Assessment) (changed bool, err error) {
+	subject := &Subject{}
+	err = subject.Load(db, id)
+	if err != nil {
+		return
+	}
+	a := Assessment{}
+	a.With(assessment)
+	changed, err = a.AutoAnswer(subject)
+	return
+}
+
 // PrepareForArchetype prepares the sections of an assessment by including, excluding,
 // or auto-answering questions based on a set of tags.
 func PrepareForArchetype(tagResolver *TagResolver, archetype *model.Archetype, assessment *model.Assessment) {
@@ -0,0 +1,116 @@
+package assessment
+
+import (
+	"fmt"
+
+	gv "github.com/PaesslerAG/gval"
+)
+
+// Default answer scores (by risk).
+const (
+	ScoreRed    = 0
+	ScoreYellow = 50
+	ScoreGreen  = 100
+)
+
+// Formula variables.
+const (
+	VarScore   = "score"
+	VarRed     = "red"
+	VarYellow  = "yellow"
+	VarGreen   = "green"
+	VarUnknown = "unknown"
+)
+
+// FormulaNotValid reports a formula that cannot be evaluated.
+type FormulaNotValid struct {
+	Formula string
+	Reason  string
+}
+
+func (e *FormulaNotValid) Error() string {
+	return fmt.Sprintf("scoring formula '%s' not valid: %s", e.Formula, e.Reason)
+}
+
+func (e *FormulaNotValid) Is(err error) (matched bool) {
+	_, matched = err.(*FormulaNotValid)
+	return
+}
+
+// Scored the (weighted) scoring of an assessment.
+type Scored struct {
+	// Score the weighted average (0-100) of the answer scores.
+	Score float64
+	// Weight the total weight of the scored questions.
+	// Zero when no questions have been scored.
+	Weight float64
+	// Risks the weighted percentage (0-100) of questions by risk.
+	Risks map[string]float64
+}
+
+// Vars returns the formula variables.
+func (r *Scored) Vars() (vars map[string]any) {
+	vars = map[string]any{
+		VarScore:   r.Score,
+		VarRed:     r.Risks[RiskRed],
+		VarYellow:  r.Risks[RiskYellow],
+		VarGreen:   r.Risks[RiskGreen],
+		VarUnknown: r.Risks[RiskUnknown],
+	}
+	return
+}
+
+// Formula maps the (weighted) scoring to a risk.
+// Example: score >= 80 ? "green" : score >= 50 ? "yellow" : "red"
+type Formula string
+
+// Risk evaluates the formula and returns the risk.
+func (r Formula) Risk(scored Scored) (risk string, err error) {
+	v, err := gv.Full().Evaluate(string(r), scored.Vars())
+	if err != nil {
+		err = &FormulaNotValid{
+			Formula: string(r),
+			Reason:  err.Error(),
+		}
+		return
+	}
+	if s, cast := v.(string); cast {
+		switch s {
+		case RiskRed, RiskYellow, RiskGreen, RiskUnknown:
+			risk = s
+			return
+		}
+	}
+	err = &FormulaNotValid{
+		Formula: string(r),
+		Reason: fmt.Sprintf(
+			"returned '%v', must be one of: red, yellow, green, unknown",
+			v),
+	}
+	return
+}
+
+// Validate the formula.
+// The formula is evaluated using each of the default answer scores.
+func (r Formula) Validate() (err error) {
+	_, err = gv.Full().NewEvaluable(string(r))
+	if err != nil {
+		err = &FormulaNotValid{
+			Formula: string(r),
+			Reason:  err.Error(),
+		}
+		return
+	}
+	for _, n := range []float64{ScoreRed, ScoreYellow, ScoreGreen} {
+		scored := Scored{
+			Score:  n,
+			Weight: 1,
+			Risks:  map[string]float64{RiskGreen: n},
+		}
+		_, err = r.Risk(scored)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
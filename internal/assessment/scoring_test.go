@@ -0,0 +1,92 @@
+package assessment
+
+import (
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/onsi/gomega"
+)
+
+func TestAssessmentScored(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	score := func(n float64) *float64 {
+		return &n
+	}
+	assessment := model.Assessment{}
+	assessment.Thresholds = model.Thresholds{Red: 1, Yellow: 1, Unknown: 1}
+	assessment.Sections = []model.Section{
+		{
+			Order:  1,
+			Weight: 2,
+			Questions: []model.Question{
+				{
+					Order:  1,
+					Weight: 3,
+					Answers: []model.Answer{
+						{Order: 1, Risk: RiskGreen, Selected: true},
+						{Order: 2, Risk: RiskRed},
+					},
+				},
+				{
+					Order: 2,
+					Answers: []model.Answer{
+						{Order: 1, Risk: RiskGreen},
+						{Order: 2, Risk: RiskRed, Score: score(20), Selected: true},
+					},
+				},
+			},
+		},
+		{
+			Order: 2,
+			Questions: []model.Question{
+				{
+					Order: 1,
+					Answers: []model.Answer{
+						{Order: 1, Risk: RiskUnknown, Selected: true},
+					},
+				},
+				{
+					Order: 2,
+					Answers: []model.Answer{
+						{Order: 1, Risk: RiskYellow},
+					},
+				},
+			},
+		},
+	}
+	a := Assessment{}
+	a.With(&assessment)
+	scored := a.Scored()
+	// (100*6 + 20*2) / 8.
+	g.Expect(scored.Weight).To(gomega.Equal(8.0))
+	g.Expect(scored.Score).To(gomega.Equal(80.0))
+	g.Expect(scored.Risks[RiskGreen]).To(gomega.Equal(60.0))
+	g.Expect(scored.Risks[RiskRed]).To(gomega.Equal(20.0))
+	g.Expect(scored.Risks[RiskUnknown]).To(gomega.Equal(20.0))
+	// thresholds.
+	g.Expect(a.Risk()).To(gomega.Equal(RiskRed))
+	// formula.
+	assessment.Scoring.Formula = `score >= 80 ? "green" : score >= 50 ? "yellow" : "red"`
+	g.Expect(a.Risk()).To(gomega.Equal(RiskGreen))
+	assessment.Scoring.Formula = `red > 10 ? "yellow" : "green"`
+	g.Expect(a.Risk()).To(gomega.Equal(RiskYellow))
+	assessment.Scoring.Formula = `score`
+	g.Expect(a.Risk()).To(gomega.Equal(RiskUnknown))
+	// aggregated.
+	g.Expect(Score([]Assessment{a, {Assessment: &model.Assessment{}}})).To(gomega.Equal(80.0))
+}
+
+func TestFormula(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	formula := Formula(`score >= 80 ? "green" : score >= 50 ? "yellow" : "red"`)
+	g.Expect(formula.Validate()).To(gomega.BeNil())
+	risk, err := formula.Risk(Scored{Score: 50})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(risk).To(gomega.Equal(RiskYellow))
+
+	g.Expect(Formula(`score >=`).Validate()).NotTo(gomega.BeNil())
+	g.Expect(Formula(`score > 50 ? "blue" : "red"`).Validate()).NotTo(gomega.BeNil())
+	g.Expect(Formula(`nothing > 1 ? "red" : "green"`).Validate()).NotTo(gomega.BeNil())
+}
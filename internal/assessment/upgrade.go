@@ -0,0 +1,216 @@
+package assessment
+
+import (
+	"strings"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+)
+
+// Upgrade reasons.
+const (
+	ReasonNew      = "Question added."
+	ReasonRemoved  = "Question removed."
+	ReasonAnswer   = "Answer no longer offered."
+	ReasonHidden   = "Question no longer shown."
+	ReasonExcluded = "Question no longer included."
+)
+
+// UpgradedQuestion a question reported by the upgrade.
+type UpgradedQuestion struct {
+	Section  uint
+	Question uint
+	ID       string
+	Text     string
+	Answer   string
+	Reason   string
+}
+
+// UpgradeReport reports how answers were carried forward.
+type UpgradeReport struct {
+	// From the previous questionnaire version.
+	From uint
+	// To the new questionnaire version.
+	To uint
+	// Carried answers (carried forward).
+	Carried []UpgradedQuestion
+	// Dropped answers (questions removed).
+	Dropped []UpgradedQuestion
+	// Reanswer questions needing to be (re)answered.
+	Reanswer []UpgradedQuestion
+}
+
+// Upgrade an assessment to a questionnaire version.
+// The prepared sections are built from the version (and prepared
+// for the application/archetype tags). Answers are carried forward
+// for questions matched by (stable) ID or by text and answers matched
+// by text.
+func (r *Assessment) Upgrade(version *model.QuestionnaireVersion, prepared []model.Section) (report UpgradeReport) {
+	report.From = r.QuestionnaireVersion
+	report.To = version.Version
+	type answered struct {
+		section  *model.Section
+		question *model.Question
+		answer   *model.Answer
+		matched  bool
+	}
+	byId := make(map[string]*answered)
+	byText := make(map[string]*answered)
+	var previous []*answered
+	hidden := r.Hidden()
+	for i := range r.Sections {
+		s := &r.Sections[i]
+		for j := range s.Questions {
+			q := &s.Questions[j]
+			if hidden[QuestionKey{Section: s.Order, Question: q.Order}] {
+				continue
+			}
+			for k := range q.Answers {
+				a := &q.Answers[k]
+				if !a.Selected || a.AutoAnswered {
+					continue
+				}
+				p := &answered{section: s, question: q, answer: a}
+				previous = append(previous, p)
+				if q.ID != "" {
+					byId[q.ID] = p
+				}
+				byText[r.normalized(q.Text)] = p
+				break
+			}
+		}
+	}
+	for i := range prepared {
+		s := &prepared[i]
+		for j := range s.Questions {
+			q := &s.Questions[j]
+			reported := UpgradedQuestion{
+				Section:  s.Order,
+				Question: q.Order,
+				ID:       q.ID,
+				Text:     q.Text,
+			}
+			var p *answered
+			if q.ID != "" {
+				p = byId[q.ID]
+			}
+			if p == nil {
+				p = byText[r.normalized(q.Text)]
+			}
+			if p == nil || p.matched {
+				if !r.questionAnswered(q) && !r.defined(r.Sections, q) {
+					reported.Reason = ReasonNew
+					report.Reanswer = append(report.Reanswer, reported)
+				}
+				continue
+			}
+			p.matched = true
+			reported.Answer = p.answer.Text
+			selected := false
+			for k := range q.Answers {
+				a := &q.Answers[k]
+				if r.normalized(a.Text) == r.normalized(p.answer.Text) {
+					selected = true
+					a.Selected = true
+					a.AutoAnswered = false
+				} else {
+					a.Selected = false
+					a.AutoAnswered = false
+				}
+			}
+			if selected {
+				report.Carried = append(report.Carried, reported)
+			} else {
+				reported.Reason = ReasonAnswer
+				report.Reanswer = append(report.Reanswer, reported)
+			}
+		}
+	}
+	for _, p := range previous {
+		if p.matched {
+			continue
+		}
+		reason := ReasonRemoved
+		if r.defined(version.Sections, p.question) {
+			reason = ReasonExcluded
+		}
+		report.Dropped = append(
+			report.Dropped,
+			UpgradedQuestion{
+				Section:  p.section.Order,
+				Question: p.question.Order,
+				ID:       p.question.ID,
+				Text:     p.question.Text,
+				Answer:   p.answer.Text,
+				Reason:   reason,
+			})
+	}
+	r.Sections = prepared
+	r.Thresholds = version.Thresholds
+	r.RiskMessages = version.RiskMessages
+	r.Scoring = version.Scoring
+	r.QuestionnaireVersion = version.Version
+	hidden = r.Hidden()
+	var reanswer []UpgradedQuestion
+	for _, q := range report.Reanswer {
+		if !hidden[QuestionKey{Section: q.Section, Question: q.Question}] {
+			reanswer = append(reanswer, q)
+		}
+	}
+	report.Reanswer = reanswer
+	var carried []UpgradedQuestion
+	for _, q := range report.Carried {
+		if !hidden[QuestionKey{Section: q.Section, Question: q.Question}] {
+			carried = append(carried, q)
+			continue
+		}
+		r.clear(q.Section, q.Question)
+		q.Reason = ReasonHidden
+		report.Dropped = append(report.Dropped, q)
+	}
+	report.Carried = carried
+	return
+}
+
+// clear the answers to the question.
+func (r *Assessment) clear(section, question uint) {
+	for i := range r.Sections {
+		s := &r.Sections[i]
+		if s.Order != section {
+			continue
+		}
+		for j := range s.Questions {
+			q := &s.Questions[j]
+			if q.Order != question {
+				continue
+			}
+			for k := range q.Answers {
+				a := &q.Answers[k]
+				a.Selected = false
+				a.AutoAnswered = false
+			}
+		}
+	}
+}
+
+// defined returns true when the question is defined by the sections.
+func (r *Assessment) defined(sections []model.Section, q *model.Question) (found bool) {
+	for _, s := range sections {
+		for _, q2 := range s.Questions {
+			if q.ID != "" && q.ID == q2.ID {
+				found = true
+				return
+			}
+			if r.normalized(q.Text) == r.normalized(q2.Text) {
+				found = true
+				return
+			}
+		}
+	}
+	return
+}
+
+// normalized returns the normalized text used for matching.
+func (r *Assessment) normalized(s string) (normalized string) {
+	normalized = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return
+}
@@ -0,0 +1,126 @@
+package assessment
+
+import (
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/onsi/gomega"
+)
+
+func TestAssessmentUpgrade(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	assessment := model.Assessment{QuestionnaireVersion: 1}
+	assessment.Sections = []model.Section{
+		{
+			Order: 1,
+			Questions: []model.Question{
+				{
+					Order: 1,
+					ID:    "platform",
+					Text:  "What is the platform?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Kubernetes", Selected: true},
+						{Order: 2, Text: "VM"},
+					},
+				},
+				{
+					Order: 2,
+					Text:  "Uses EJB?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes", Selected: true},
+						{Order: 2, Text: "No"},
+					},
+				},
+				{
+					Order: 3,
+					Text:  "Uses JMS?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes", Selected: true},
+					},
+				},
+				{
+					Order: 4,
+					Text:  "Uses JNDI?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes", Selected: true},
+						{Order: 2, Text: "No"},
+					},
+				},
+			},
+		},
+	}
+	version := &model.QuestionnaireVersion{
+		Version:    2,
+		Thresholds: model.Thresholds{Red: 10},
+	}
+	version.Sections = []model.Section{
+		{
+			Order: 1,
+			Questions: []model.Question{
+				{
+					Order: 1,
+					ID:    "platform",
+					Text:  "What is the target platform?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "VM"},
+						{Order: 2, Text: "kubernetes"},
+					},
+				},
+				{
+					Order: 2,
+					Text:  "uses  EJB?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes (2.x)"},
+						{Order: 2, Text: "Yes (3.x)"},
+						{Order: 3, Text: "No"},
+					},
+				},
+				{
+					Order: 3,
+					Text:  "Uses JPA?",
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes"},
+					},
+				},
+				{
+					Order: 4,
+					Text:  "Uses JNDI?",
+					ShowWhen: []model.AnswerRef{
+						{Section: 1, Question: 2, Answer: 1},
+					},
+					Answers: []model.Answer{
+						{Order: 1, Text: "Yes"},
+						{Order: 2, Text: "No"},
+					},
+				},
+			},
+		},
+	}
+
+	a := Assessment{}
+	a.With(&assessment)
+	report := a.Upgrade(version, version.Sections)
+	g.Expect(report.From).To(gomega.Equal(uint(1)))
+	g.Expect(report.To).To(gomega.Equal(uint(2)))
+	// matched by ID.
+	g.Expect(len(report.Carried)).To(gomega.Equal(1))
+	g.Expect(report.Carried[0].ID).To(gomega.Equal("platform"))
+	g.Expect(a.Sections[0].Questions[0].Answers[1].Selected).To(gomega.BeTrue())
+	// matched by text; answer not offered.
+	g.Expect(len(report.Reanswer)).To(gomega.Equal(2))
+	g.Expect(report.Reanswer[0].Question).To(gomega.Equal(uint(2)))
+	g.Expect(report.Reanswer[0].Reason).To(gomega.Equal(ReasonAnswer))
+	g.Expect(report.Reanswer[1].Question).To(gomega.Equal(uint(3)))
+	g.Expect(report.Reanswer[1].Reason).To(gomega.Equal(ReasonNew))
+	// removed and hidden.
+	g.Expect(len(report.Dropped)).To(gomega.Equal(2))
+	g.Expect(report.Dropped[0].Text).To(gomega.Equal("Uses JMS?"))
+	g.Expect(report.Dropped[0].Reason).To(gomega.Equal(ReasonRemoved))
+	g.Expect(report.Dropped[1].Text).To(gomega.Equal("Uses JNDI?"))
+	g.Expect(report.Dropped[1].Reason).To(gomega.Equal(ReasonHidden))
+	g.Expect(a.Sections[0].Questions[3].Answers[0].Selected).To(gomega.BeFalse())
+	// updated.
+	g.Expect(assessment.QuestionnaireVersion).To(gomega.Equal(uint(2)))
+	g.Expect(assessment.Thresholds.Red).To(gomega.Equal(uint(10)))
+}
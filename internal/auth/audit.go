@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"gorm.io/gorm"
+)
+
+// Authentication (audit) methods.
+const (
+	MethodPassword = "password"
+	MethodLdap     = "ldap"
+	MethodIdp      = "idp"
+	MethodDevice   = "device"
+)
+
+// AuthEvent authentication (audit) event.
+type AuthEvent = model.AuthEvent
+
+// Audit records authentication events.
+// Failure to record an event is logged and does not
+// affect authentication.
+type Audit struct {
+	DB *gorm.DB
+}
+
+// Succeeded records a successful authentication.
+func (r *Audit) Succeeded(ctx *gin.Context, method, login, subject string) {
+	r.record(
+		&AuthEvent{
+			Subject:   subject,
+			Login:     login,
+			Method:    method,
+			Source:    SourceIP(ctx),
+			Succeeded: true,
+		})
+}
+
+// Failed records a failed authentication.
+func (r *Audit) Failed(ctx *gin.Context, method, login string, err error) {
+	m := &AuthEvent{
+		Login:  login,
+		Method: method,
+		Source: SourceIP(ctx),
+	}
+	if err != nil {
+		m.Reason = err.Error()
+		notAuth := &NotAuthenticated{}
+		if errors.As(err, &notAuth) && notAuth.Reason != "" {
+			m.Reason = notAuth.Reason
+		}
+	}
+	r.record(m)
+}
+
+// record the event.
+func (r *Audit) record(m *AuthEvent) {
+	m.CreateUser = m.Login
+	err := r.DB.Create(m).Error
+	if err != nil {
+		Log.Error(err, "Auth event not recorded.")
+	}
+}
+
+// SourceIP returns the source (client) IP of the request.
+// The X-Forwarded-For address is used only when reported
+// by a trusted proxy.
+func SourceIP(ctx *gin.Context) (ip string) {
+	if ctx == nil || ctx.Request == nil {
+		return
+	}
+	ip = ctx.ClientIP()
+	return
+}
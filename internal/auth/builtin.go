@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/go-jose/go-jose/v4"
 	"github.com/golang-jwt/jwt/v5"
 	liberr "github.com/jortel/go-utils/error"
@@ -110,21 +111,25 @@ func NewBuiltin(db *gorm.DB, domain *Tenant) (builtin *Builtin, err error) {
 	builtin.dagHandler = &DagHandler{
 		storage: builtin.storage,
 	}
+	builtin.tokenHandler = &TokenHandler{
+		provider: builtin,
+	}
 	return
 }
 
 // Builtin provides OIDC authentication.
 type Builtin struct {
-	ready      sync.Once
-	db         *gorm.DB
-	domain     *Tenant
-	provider   op.OpenIDProvider
-	idpHandler *FedIdpHandler
-	dsHandler  *LdapHandler
-	dagHandler *DagHandler
-	cache      *Cache
-	storage    *Storage
-	keySet     KeySet
+	ready        sync.Once
+	db           *gorm.DB
+	domain       *Tenant
+	provider     op.OpenIDProvider
+	idpHandler   *FedIdpHandler
+	dsHandler    *LdapHandler
+	dagHandler   *DagHandler
+	tokenHandler *TokenHandler
+	cache        *Cache
+	storage      *Storage
+	keySet       KeySet
 }
 
 // Handler returns an http handler.
@@ -139,6 +144,12 @@ func (p *Builtin) DagHandler() (h *DagHandler) {
 	return
 }
 
+// TokenHandler returns the token introspection and revocation handler.
+func (p *Builtin) TokenHandler() (h *TokenHandler) {
+	h = p.tokenHandler
+	return
+}
+
 // IdpHandler returns the IdP federation handler.
 func (p *Builtin) IdpHandler() (h *FedIdpHandler) {
 	h = p.idpHandler
@@ -160,11 +171,8 @@ func (p *Builtin) Ready(r *http.Request) {
 }
 
 // Login handles the custom login page.
-func (p *Builtin) Login(
-	writer http.ResponseWriter,
-	request *http.Request,
-	authReqId string) (err error) {
-	err = p.storage.Login(writer, request, authReqId)
+func (p *Builtin) Login(ctx *gin.Context, authReqId string) (err error) {
+	err = p.storage.Login(ctx, authReqId)
 	return
 }
 
@@ -225,19 +233,24 @@ func (p *Builtin) Authenticate(req *Request) (jwToken *jwt.Token, err error) {
 	}()
 	if req.Token != "" {
 		jwToken, err = p.authToken(req)
+		if err == nil {
+			err = p.notServiceToken(jwToken)
+		}
 		return
 	}
 	if req.Login != "" {
-		for _, method := range []func(*Request) (*jwt.Token, error){
-			p.authUser,
-			p.authLdapUser,
-		} {
-			jwToken, err = method(req)
+		method := MethodPassword
+		jwToken, err = p.authUser(req)
+		if errors.Is(err, &NotFound{}) {
+			method = MethodLdap
+			jwToken, err = p.authLdapUser(req)
+		}
+		if err != nil {
 			if errors.Is(err, &NotFound{}) {
-				continue
-			} else {
-				break
+				method = MethodPassword
 			}
+			audit := Audit{DB: p.reqDB(req)}
+			audit.Failed(req.CTX, method, req.Login, err)
 		}
 		return
 	}
@@ -545,6 +558,8 @@ func (p *Builtin) authToken(req *Request) (jwToken *jwt.Token, err error) {
 }
 
 // authUser authenticates the user.
+// When MFA is required, the TOTP code is appended to the password.
+// Failed attempts are counted toward (progressive) lockout.
 func (p *Builtin) authUser(req *Request) (jwToken *jwt.Token, err error) {
 	login := req.Login
 	password := req.Password
@@ -552,11 +567,70 @@ func (p *Builtin) authUser(req *Request) (jwToken *jwt.Token, err error) {
 	if err != nil {
 		return
 	}
+	db := p.reqDB(req)
+	lockout := Lockout{DB: db, Cache: p.cache}
+	if lockout.Locked(user) {
+		err = &NotAuthenticated{
+			Reason: "user locked",
+			Token:  login,
+		}
+		return
+	}
+	mfa := Mfa{DB: db, Cache: p.cache}
+	mfaRequired, err := mfa.Required(user)
+	if err != nil {
+		return
+	}
+	code := ""
+	if mfaRequired {
+		if !user.TotpEnabled {
+			err = &NotAuthenticated{
+				Reason: "MFA enrollment required",
+				Token:  login,
+			}
+			return
+		}
+		n := max(0, len(password)-TotpDigits)
+		code = password[n:]
+		password = password[:n]
+	}
 	if !secret.MatchPassword(password, user.Password) {
 		err = &NotAuthenticated{
 			Reason: "invalid password",
 			Token:  login,
 		}
+		p.failed(&lockout, user)
+		return
+	}
+	if user.Disabled {
+		err = &NotAuthenticated{
+			Reason: "user disabled",
+			Token:  login,
+		}
+		return
+	}
+	if mfaRequired && !mfa.Match(user, code) {
+		err = &NotAuthenticated{
+			Reason: "invalid MFA code",
+			Token:  login,
+		}
+		p.failed(&lockout, user)
+		return
+	}
+	passwords := Password{DB: db}
+	policy, err := passwords.Policy()
+	if err != nil {
+		return
+	}
+	if policy.Expired(user) {
+		err = &NotAuthenticated{
+			Reason: "password expired",
+			Token:  login,
+		}
+		return
+	}
+	err = lockout.Succeeded(user)
+	if err != nil {
 		return
 	}
 	scopes, err := p.cache.FindScopes(user.Subject)
@@ -578,12 +652,55 @@ func (p *Builtin) authUser(req *Request) (jwToken *jwt.Token, err error) {
 	return
 }
 
+// failed records the failed attempt.
+func (p *Builtin) failed(lockout *Lockout, user *User) {
+	err := lockout.Failed(user)
+	if err != nil {
+		Log.Error(err, "")
+	}
+}
+
+// ldapFailed records the failed LDAP attempt.
+func (p *Builtin) ldapFailed(lockout *LdapLockout) {
+	err := lockout.Failed()
+	if err != nil {
+		Log.Error(err, "")
+	}
+}
+
+// reqDB returns the DB for the request.
+// The request DB (transaction) is used when specified.
+func (p *Builtin) reqDB(req *Request) (db *gorm.DB) {
+	db = p.db
+	if req.DB != nil {
+		db = req.DB
+	}
+	return
+}
+
 // authLdapUser authenticates an LDAP user.
+// Failed attempts are counted toward (progressive) lockout.
 func (p *Builtin) authLdapUser(req *Request) (jwToken *jwt.Token, err error) {
+	lockout := LdapLockout{
+		DB:    p.reqDB(req),
+		Login: req.Login,
+		IP:    SourceIP(req.CTX),
+	}
+	if lockout.Locked() {
+		err = &NotAuthenticated{
+			Reason: "user locked",
+			Token:  req.Login,
+		}
+		return
+	}
 	subject, err := p.dsHandler.Authenticate(req.Login, req.Password)
 	if err != nil {
+		if errors.Is(err, &NotAuthenticated{}) {
+			p.ldapFailed(&lockout)
+		}
 		return
 	}
+	lockout.Succeeded()
 	jwToken = jwt.New(jwt.SigningMethodRS256)
 	jwtClaims := jwToken.Claims.(jwt.MapClaims)
 	jwtClaims[ClaimId] = subject.Login()
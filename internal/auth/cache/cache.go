@@ -203,6 +203,21 @@ func (r *Cache) FindToken(token string) (m *Token, err error) {
 	return
 }
 
+// FindAccessToken returns an access token by authId (jti).
+func (r *Cache) FindAccessToken(authId string) (m *Token, err error) {
+	defer r.ensureRefreshed()
+	d := r.data.Load()
+	m, found := d.tokenByAuthId[authId]
+	if !found {
+		err = &NotFound{
+			Resource: "token",
+			Id:       authId,
+		}
+		return
+	}
+	return
+}
+
 // FindSubject returns a subject.
 // Tasks are not stored for performance reasons. They are found
 // by matching the encoded task subject. There is nothing to be
@@ -468,6 +483,7 @@ type Data struct {
 	clientBySubject  map[string]*IdpClient
 	tokenById        map[uint]*Token
 	tokenByDigest    map[string]*Token
+	tokenByAuthId    map[string]*Token
 	scopesBySubject  map[string][]string
 }
 
@@ -490,6 +506,7 @@ func (d *Data) reset() {
 	d.clientBySubject = make(map[string]*IdpClient)
 	d.tokenById = make(map[uint]*Token)
 	d.tokenByDigest = make(map[string]*Token)
+	d.tokenByAuthId = make(map[string]*Token)
 }
 
 // clone returns cloned data.
@@ -516,6 +533,7 @@ func (d *Data) clone() *Data {
 		clientBySubject:  cloneMap(d.clientBySubject),
 		tokenById:        cloneMap(d.tokenById),
 		tokenByDigest:    cloneMap(d.tokenByDigest),
+		tokenByAuthId:    cloneMap(d.tokenByAuthId),
 	}
 }
 
@@ -645,7 +663,7 @@ func (d *Data) getClients(db *gorm.DB) (err error) {
 func (d *Data) getTokens(db *gorm.DB) (err error) {
 	list := []*Token{}
 	db = db.Preload(clause.Associations)
-	db = db.Where("kind", KindAPIKey)
+	db = db.Where("kind IN ?", []string{KindAPIKey, KindAccessToken})
 	db = db.Where("expiration > ?", time.Now())
 	err = db.Find(&list).Error
 	if err != nil {
@@ -653,12 +671,32 @@ func (d *Data) getTokens(db *gorm.DB) (err error) {
 		return
 	}
 	for _, m := range list {
-		d.tokenById[m.ID] = m
-		d.tokenByDigest[m.Digest] = m
+		d.tokenAdded(m)
 	}
 	return
 }
 
+// tokenAdded adds a token.
+// PATs are indexed by digest; access tokens by authId.
+func (d *Data) tokenAdded(m *Token) {
+	d.tokenById[m.ID] = m
+	if m.Kind == KindAccessToken {
+		d.tokenByAuthId[m.AuthId] = m
+	} else {
+		d.tokenByDigest[m.Digest] = m
+	}
+}
+
+// tokenDeleted removes a token.
+func (d *Data) tokenDeleted(m *Token) {
+	delete(d.tokenById, m.ID)
+	if m.Kind == KindAccessToken {
+		delete(d.tokenByAuthId, m.AuthId)
+	} else {
+		delete(d.tokenByDigest, m.Digest)
+	}
+}
+
 // addTokenScopes determine token scopes and update the data.
 func (d *Data) addTokenScopes(m *Token) {
 	if m.Kind != KindAPIKey {
@@ -729,8 +767,13 @@ func (d *Data) addTokenScopes(m *Token) {
 }
 
 // addUserScopes determine user scopes and add to the data.
+// Disabled users have no scopes.
 func (d *Data) addUserScopes(m *User) {
 	scopes := []string{}
+	if m.Disabled {
+		d.scopesBySubject[m.Subject] = scopes
+		return
+	}
 	for _, r := range m.Roles {
 		r, found := d.roleById[r.ID]
 		if !found {
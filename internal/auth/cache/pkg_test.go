@@ -1643,6 +1643,46 @@ func TestFindScopes(t *testing.T) {
 	g.Expect(errors.As(err, &notFound)).To(BeTrue())
 }
 
+// TestFindScopesDisabled tests disabled users have no scopes.
+func TestFindScopesDisabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+
+	role := &Role{
+		Model:  Model{ID: 1},
+		Name:   "admin",
+		Scopes: []string{"applications:get"},
+	}
+	err = db.Create(role).Error
+	g.Expect(err).To(BeNil())
+
+	user := &User{
+		Model:    Model{ID: 501},
+		Subject:  "user-subject-501",
+		Login:    "disableduser",
+		Disabled: true,
+		Roles:    []Role{*role},
+	}
+	err = db.Create(user).Error
+	g.Expect(err).To(BeNil())
+
+	cache := New(db)
+	err = cache.Refresh()
+	g.Expect(err).To(BeNil())
+
+	scopes, err := cache.FindScopes("user-subject-501")
+	g.Expect(err).To(BeNil())
+	g.Expect(scopes).To(BeEmpty())
+
+	user.Disabled = false
+	cache.UserSaved(user)
+	scopes, err = cache.FindScopes("user-subject-501")
+	g.Expect(err).To(BeNil())
+	g.Expect(scopes).To(ContainElement("applications:get"))
+}
+
 // TestTokenDeleted tests standalone TokenDeleted method.
 func TestTokenDeleted(t *testing.T) {
 	g := NewGomegaWithT(t)
@@ -53,8 +53,7 @@ func (r *Tx) UserDeleted(id uint) {
 			}
 			for _, token := range d.tokenById {
 				if token.UserID != nil && *token.UserID == id {
-					delete(d.tokenByDigest, token.Digest)
-					delete(d.tokenById, token.ID)
+					d.tokenDeleted(token)
 				}
 			}
 		})
@@ -82,8 +81,7 @@ func (r *Tx) SaDeleted(id uint) {
 			}
 			for _, token := range d.tokenById {
 				if token.ServiceAccountID != nil && *token.ServiceAccountID == id {
-					delete(d.tokenByDigest, token.Digest)
-					delete(d.tokenById, token.ID)
+					d.tokenDeleted(token)
 				}
 			}
 		})
@@ -111,8 +109,7 @@ func (r *Tx) IdentityDeleted(id uint) {
 			}
 			for _, token := range d.tokenById {
 				if token.IdpIdentityID != nil && *token.IdpIdentityID == id {
-					delete(d.tokenByDigest, token.Digest)
-					delete(d.tokenById, token.ID)
+					d.tokenDeleted(token)
 				}
 			}
 		})
@@ -140,8 +137,7 @@ func (r *Tx) ClientDeleted(id uint) {
 			}
 			for _, token := range d.tokenById {
 				if token.IdpClientID != nil && *token.IdpClientID == id {
-					delete(d.tokenByDigest, token.Digest)
-					delete(d.tokenById, token.ID)
+					d.tokenDeleted(token)
 				}
 			}
 		})
@@ -151,8 +147,7 @@ func (r *Tx) ClientDeleted(id uint) {
 func (r *Tx) TokenSaved(m *Token) {
 	r.changes = append(
 		r.changes, func(d *Data) {
-			d.tokenById[m.ID] = m
-			d.tokenByDigest[m.Digest] = m
+			d.tokenAdded(m)
 		})
 }
 
@@ -162,8 +157,7 @@ func (r *Tx) TokenDeleted(id uint) {
 		r.changes, func(d *Data) {
 			token, found := d.tokenById[id]
 			if found {
-				delete(d.tokenByDigest, token.Digest)
-				delete(d.tokenById, id)
+				d.tokenDeleted(token)
 			}
 		})
 }
@@ -174,8 +168,7 @@ func (r *Tx) GrantDeleted(id uint) {
 		r.changes, func(d *Data) {
 			for _, m := range d.tokenById {
 				if m.GrantID != nil && *m.GrantID == id {
-					delete(d.tokenByDigest, m.Digest)
-					delete(d.tokenById, m.ID)
+					d.tokenDeleted(m)
 				}
 			}
 		})
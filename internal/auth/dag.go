@@ -106,6 +106,13 @@ func (h *DagHandler) VerifySubmit(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	login := ""
+	s, err := h.storage.findSubject(subject)
+	if err == nil {
+		login = s.Login()
+	}
+	audit := Audit{DB: h.storage.db}
+	audit.Succeeded(ctx, MethodDevice, login, subject)
 	pageReq := frontend.Request{
 		Page: frontend.DeviceSucceeded,
 	}
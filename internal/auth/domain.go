@@ -95,7 +95,17 @@ func (d *Tenant) Scopes() (scopes []string) {
 }
 
 // HasScope returns true when the domain has the scope.
+// Constrained scopes must be constrainable and valid.
 func (d *Tenant) HasScope(scope string) (found bool) {
+	s := Scope{}
+	s.With(scope)
+	if s.Constrained() {
+		if !s.Constrainable() || s.Constraint.Validate() != nil {
+			return
+		}
+		base := s.Base()
+		scope = base.String()
+	}
 	_, found = d.scopeByName[scope]
 	return
 }
@@ -218,36 +218,40 @@ func (f *FedIdpLogin) begin() {
 }
 
 // complete handles the redirect back from the external IdP.
+// The (audit) event is recorded.
 func (f *FedIdpLogin) complete() {
-	err := f.validate()
-	if err != nil {
-		_ = f.ctx.Error(err)
-		return
-	}
-	err = f.exchangeCode()
-	if err != nil {
-		_ = f.ctx.Error(err)
-		return
-	}
-	err = f.fetchUserInfo(f.ctx.Request.Context())
-	if err != nil {
-		_ = f.ctx.Error(err)
-		return
-	}
-	err = f.parseAccessToken()
-	if err != nil {
-		_ = f.ctx.Error(err)
-		return
-	}
-	err = f.ensureIdentity()
-	if err != nil {
-		_ = f.ctx.Error(err)
-		return
-	}
-	err = f.issueTokens()
-	if err != nil {
-		_ = f.ctx.Error(err)
-		return
+	var err error
+	defer func() {
+		audit := Audit{DB: f.handler.db}
+		if err != nil {
+			_ = f.ctx.Error(err)
+			login := ""
+			if f.userInfo != nil {
+				login = f.userInfo.PreferredUsername
+			}
+			audit.Failed(f.ctx, MethodIdp, login, err)
+			return
+		}
+		audit.Succeeded(
+			f.ctx,
+			MethodIdp,
+			f.identity.Login,
+			f.identity.Subject)
+	}()
+	for _, step := range []func() error{
+		f.validate,
+		f.exchangeCode,
+		func() error {
+			return f.fetchUserInfo(f.ctx.Request.Context())
+		},
+		f.parseAccessToken,
+		f.ensureIdentity,
+		f.issueTokens,
+	} {
+		err = step()
+		if err != nil {
+			return
+		}
 	}
 }
 
@@ -0,0 +1,422 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/secret"
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"github.com/zitadel/oidc/v3/pkg/oidc"
+	"gorm.io/gorm"
+)
+
+// UnsupportedTokenType revocation (RFC 7009) error.
+const (
+	UnsupportedTokenType = "unsupported_token_type"
+)
+
+// TokenIntrospection token introspection (RFC 7662) response.
+type TokenIntrospection = api.TokenIntrospection
+
+//
+// TokenHandler
+//
+
+// TokenHandler handles token introspection (RFC 7662) and
+// revocation (RFC 7009) requests made by third parties.
+// Requests are authenticated using (confidential) IdP client credentials.
+type TokenHandler struct {
+	provider *Builtin
+}
+
+// Introspect godoc
+// @summary Token introspection (RFC 7662).
+// @description Introspect a hub issued token: access token, refresh
+// @description token or api-key (including task tokens).
+// @description Authenticated using IdP client credentials (basic auth or client_id/client_secret).
+// @description Inactive (unknown, expired, revoked) tokens report only active=false.
+// @description The task field is the task to which the token is bound.
+// @tags auth
+// @accept x-www-form-urlencoded
+// @produce json
+// @success 200 {object} api.TokenIntrospection
+// @router /oidc/introspect [post]
+// @param token formData string true "Token"
+// @param token_type_hint formData string false "Token type hint"
+//
+// Introspect handles token introspection requests.
+func (h *TokenHandler) Introspect(ctx *gin.Context) {
+	if ctx.Request.Method != http.MethodPost {
+		ctx.Status(http.StatusMethodNotAllowed)
+		return
+	}
+	client, err := h.authClient(ctx.Request)
+	if err != nil {
+		h.clientError(ctx, err)
+		return
+	}
+	r := TokenIntrospection{}
+	token := ctx.Request.PostFormValue("token")
+	hint := ctx.Request.PostFormValue("token_type_hint")
+	var state *tokenState
+	if hint != "access_token" {
+		state, err = h.findGrant(ctx, token)
+	}
+	if state == nil {
+		state, err = h.find(ctx, token)
+	}
+	if err != nil {
+		Log.V(2).Info(
+			"Token introspection: not active.",
+			"client",
+			client.ClientId,
+			"reason",
+			err.Error())
+		ctx.JSON(http.StatusOK, r)
+		return
+	}
+	r = state.introspection()
+	ctx.JSON(http.StatusOK, r)
+}
+
+// Revoke godoc
+// @summary Token revocation (RFC 7009).
+// @description Revoke an access or refresh token issued to the client.
+// @description Authenticated using IdP client credentials (basic auth or client_id/client_secret).
+// @description The grant associated with the token is revoked.
+// @description Unknown (or invalid) tokens are ignored.
+// @description Api-keys cannot be revoked (unsupported_token_type).
+// @tags auth
+// @accept x-www-form-urlencoded
+// @success 200
+// @router /oidc/revoke [post]
+// @param token formData string true "Token"
+// @param token_type_hint formData string false "Token type hint"
+//
+// Revoke handles token revocation requests.
+func (h *TokenHandler) Revoke(ctx *gin.Context) {
+	if ctx.Request.Method != http.MethodPost {
+		ctx.Status(http.StatusMethodNotAllowed)
+		return
+	}
+	client, err := h.authClient(ctx.Request)
+	if err != nil {
+		h.clientError(ctx, err)
+		return
+	}
+	token := ctx.Request.PostFormValue("token")
+	if token == "" {
+		err = oidc.ErrInvalidRequest().WithDescription("token must be specified.")
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+	hint := ctx.Request.PostFormValue("token_type_hint")
+	if hint != "access_token" {
+		grant := &Grant{}
+		err = h.provider.db.First(grant, "refreshToken", secret.Hash(token)).Error
+		if err == nil {
+			err = h.revokeGrant(ctx, client, grant)
+			h.revokeResponse(ctx, err)
+			return
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.revokeResponse(ctx, liberr.Wrap(err))
+			return
+		}
+	}
+	state, err := h.find(ctx, token)
+	if err != nil {
+		ctx.Status(http.StatusOK)
+		return
+	}
+	err = h.revokeToken(client, state)
+	h.revokeResponse(ctx, err)
+}
+
+// revokeGrant revokes a grant (refresh token).
+func (h *TokenHandler) revokeGrant(ctx *gin.Context, client *IdpClient, grant *Grant) (err error) {
+	if grant.ClientId != client.ClientId {
+		err = oidc.ErrUnauthorizedClient().
+			WithDescription("token not issued to the client.")
+		return
+	}
+	err = h.provider.storage.deleteGrant(ctx.Request.Context(), grant.AuthId)
+	if err != nil {
+		return
+	}
+	Log.Info(
+		"Grant revoked.",
+		"client",
+		client.ClientId,
+		"authId",
+		grant.AuthId)
+	return
+}
+
+// revokeToken revokes an access token.
+func (h *TokenHandler) revokeToken(client *IdpClient, state *tokenState) (err error) {
+	if state.token == nil || state.token.Kind != KindAccessToken {
+		err = &oidc.Error{
+			ErrorType:   UnsupportedTokenType,
+			Description: "only access and refresh tokens may be revoked.",
+		}
+		return
+	}
+	if state.clientId != client.ClientId {
+		err = oidc.ErrUnauthorizedClient().
+			WithDescription("token not issued to the client.")
+		return
+	}
+	err = h.provider.Revoke(state.token.ID)
+	if err != nil {
+		return
+	}
+	Log.Info(
+		"Token revoked.",
+		"client",
+		client.ClientId,
+		"authId",
+		state.token.AuthId)
+	return
+}
+
+// revokeResponse writes the revocation response.
+func (h *TokenHandler) revokeResponse(ctx *gin.Context, err error) {
+	if err == nil {
+		ctx.Status(http.StatusOK)
+		return
+	}
+	oidcErr := &oidc.Error{}
+	if errors.As(err, &oidcErr) {
+		ctx.JSON(http.StatusBadRequest, oidcErr)
+		return
+	}
+	Log.Error(err, "")
+	ctx.JSON(http.StatusInternalServerError, oidc.ErrServerError())
+}
+
+// authClient authenticates the (confidential) IdP client.
+// The credentials are specified using basic auth or the
+// client_id and client_secret (form) parameters.
+func (h *TokenHandler) authClient(request *http.Request) (client *IdpClient, err error) {
+	id, passphrase, found := request.BasicAuth()
+	if found {
+		id, err = url.QueryUnescape(id)
+		if err != nil {
+			err = oidc.ErrInvalidClient().WithDescription("invalid basic auth header.")
+			return
+		}
+		passphrase, err = url.QueryUnescape(passphrase)
+		if err != nil {
+			err = oidc.ErrInvalidClient().WithDescription("invalid basic auth header.")
+			return
+		}
+	} else {
+		id = request.PostFormValue("client_id")
+		passphrase = request.PostFormValue("client_secret")
+	}
+	if id == "" {
+		err = oidc.ErrInvalidClient().WithDescription("client credentials must be specified.")
+		return
+	}
+	client, err = h.provider.cache.FindClientByStrId(id)
+	if err != nil {
+		err = oidc.ErrInvalidClient().WithDescription("client not-found.")
+		return
+	}
+	if client.Secret == "" {
+		err = oidc.ErrInvalidClient().WithDescription("client not confidential.")
+		return
+	}
+	if !secret.MatchPassword(passphrase, client.Secret) {
+		err = oidc.ErrInvalidClient().WithDescription("clientSecret not-valid.")
+		return
+	}
+	return
+}
+
+// clientError writes the client authentication error.
+func (h *TokenHandler) clientError(ctx *gin.Context, err error) {
+	Log.Info(
+		"Token endpoint: client not authenticated.",
+		"reason",
+		err.Error())
+	ctx.Header("WWW-Authenticate", "Basic")
+	ctx.JSON(http.StatusUnauthorized, err)
+}
+
+// find returns the state of an active token.
+// The token is authenticated the same as an API request.
+// Access tokens must not have been revoked (deleted).
+// The cache is checked first; the DB when not cached.
+func (h *TokenHandler) find(ctx *gin.Context, token string) (state *tokenState, err error) {
+	if token == "" {
+		err = &NotFound{Resource: "token"}
+		return
+	}
+	req := &Request{
+		CTX:   ctx,
+		Token: token,
+	}
+	jwToken, err := h.provider.authToken(req)
+	if err != nil {
+		return
+	}
+	state = &tokenState{
+		jwToken: jwToken,
+		subject: h.provider.Subject(jwToken),
+	}
+	pat, err := h.provider.cache.FindToken(token)
+	if err == nil {
+		if pat.Expiration.Before(time.Now()) {
+			err = &NotValid{
+				Reason:  "Token expired.",
+				TokenId: pat.AuthId,
+			}
+			return
+		}
+		state.token = pat
+		state.expiration = pat.Expiration
+		state.issued = pat.CreateTime
+	} else {
+		err = nil
+	}
+	jti, _ := jwToken.Claims.(jwt.MapClaims)[ClaimId].(string)
+	if state.token == nil && jti != "" {
+		state.token, err = h.provider.cache.FindAccessToken(jti)
+		if err != nil {
+			m := &Token{}
+			err = h.provider.db.First(m, "authId", jti).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					err = &NotValid{
+						Reason:  "Token revoked.",
+						TokenId: jti,
+					}
+				} else {
+					err = liberr.Wrap(err)
+				}
+				return
+			}
+			state.token = m
+		}
+	}
+	err = state.with(h.provider)
+	return
+}
+
+// findGrant returns the state of an active refresh token.
+func (h *TokenHandler) findGrant(ctx *gin.Context, token string) (state *tokenState, err error) {
+	if token == "" {
+		err = &NotFound{Resource: "token"}
+		return
+	}
+	grant, err := h.provider.storage.grantByRefreshToken(ctx.Request.Context(), token)
+	if err != nil {
+		return
+	}
+	if grant.Expiration.Before(time.Now()) {
+		err = &NotValid{
+			Reason:  "Token expired.",
+			TokenId: grant.AuthId,
+		}
+		return
+	}
+	jwToken := jwt.New(jwt.SigningMethodHS512)
+	jwtClaims := jwToken.Claims.(jwt.MapClaims)
+	jwtClaims[ClaimId] = grant.AuthId
+	jwtClaims[ClaimSub] = grant.Subject
+	jwtClaims[ClaimScope] = strings.Join(grant.Scopes, " ")
+	jwtClaims[ClaimIss] = Issuer(ctx.Request)
+	state = &tokenState{
+		jwToken:    jwToken,
+		subject:    grant.Subject,
+		clientId:   grant.ClientId,
+		issued:     grant.Issued,
+		expiration: grant.Expiration,
+	}
+	err = state.with(h.provider)
+	return
+}
+
+// tokenState the state of an active token.
+type tokenState struct {
+	jwToken    *jwt.Token
+	token      *Token
+	subject    string
+	login      string
+	clientId   string
+	issued     time.Time
+	expiration time.Time
+}
+
+// with populates the state using the stored token and cache.
+func (r *tokenState) with(p *Builtin) (err error) {
+	claims := r.jwToken.Claims.(jwt.MapClaims)
+	if r.expiration.IsZero() {
+		exp, _ := claims.GetExpirationTime()
+		if exp != nil {
+			r.expiration = exp.Time
+		}
+		iat, _ := claims.GetIssuedAt()
+		if iat != nil {
+			r.issued = iat.Time
+		}
+	}
+	s, err := p.cache.FindSubject(r.subject)
+	if err == nil {
+		r.login = s.Login()
+	} else {
+		err = nil
+	}
+	m := r.token
+	if m == nil {
+		return
+	}
+	if m.GrantID != nil {
+		grant := &Grant{}
+		err = p.db.First(grant, *m.GrantID).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		r.clientId = grant.ClientId
+		return
+	}
+	if m.IdpClientID != nil {
+		client, findErr := p.cache.FindClientById(*m.IdpClientID)
+		if findErr == nil {
+			r.clientId = client.ClientId
+		}
+	}
+	return
+}
+
+// introspection returns the introspection response.
+func (r *tokenState) introspection() (response TokenIntrospection) {
+	claims := r.jwToken.Claims.(jwt.MapClaims)
+	response.Active = true
+	response.TokenType = oidc.BearerToken
+	response.Subject = r.subject
+	response.Username = r.login
+	response.ClientId = r.clientId
+	response.Scope, _ = claims[ClaimScope].(string)
+	response.Issuer, _ = claims[ClaimIss].(string)
+	response.TokenId, _ = claims[ClaimId].(string)
+	if !r.expiration.IsZero() {
+		response.Expiration = r.expiration.Unix()
+	}
+	if !r.issued.IsZero() {
+		response.IssuedAt = r.issued.Unix()
+	}
+	if r.token != nil && r.token.TaskID != nil {
+		response.Task = *r.token.TaskID
+	}
+	return
+}
@@ -158,6 +158,7 @@ func (r *LDAP) Authenticate(login, password string) (dsUser *LdapUser, err error
 			Reason: "invalid password",
 			Token:  login,
 		}
+		return
 	}
 
 	// Find roles
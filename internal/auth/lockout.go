@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LockoutPolicyKey the setting (policy) for builtin user lockout.
+const (
+	LockoutPolicyKey = "auth.lockout.policy"
+)
+
+// LockoutPolicy account lockout policy.
+type LockoutPolicy struct {
+	// Attempts the number of (consecutive) failed attempts
+	// before the user is locked. 0 = disabled.
+	Attempts int `json:"attempts"`
+	// Duration the initial lockout duration (minutes).
+	// Doubled for each failed attempt while locked out.
+	Duration int `json:"duration"`
+	// MaxDuration the maximum lockout duration (minutes).
+	// Default: 1 day.
+	MaxDuration int `json:"maxDuration"`
+}
+
+// duration returns the lockout duration after the number
+// of (consecutive) failed attempts.
+func (r *LockoutPolicy) duration(failed int) (d time.Duration) {
+	if r.Attempts < 1 || failed < r.Attempts {
+		return
+	}
+	d = time.Duration(r.Duration) * time.Minute
+	maxDuration := r.maxDuration()
+	for range failed - r.Attempts {
+		if d >= maxDuration {
+			break
+		}
+		d *= 2
+	}
+	d = min(d, maxDuration)
+	return
+}
+
+// maxDuration returns the maximum lockout duration.
+func (r *LockoutPolicy) maxDuration() (d time.Duration) {
+	d = time.Duration(r.MaxDuration) * time.Minute
+	if d < 1 {
+		d = 24 * time.Hour
+	}
+	return
+}
+
+// DefaultLockout the default lockout policy.
+var DefaultLockout = LockoutPolicy{
+	Attempts:    5,
+	Duration:    1,
+	MaxDuration: 60,
+}
+
+// ldapFailed tracks the failed LDAP attempts by login and source IP.
+var ldapFailed = struct {
+	mutex sync.Mutex
+	byKey map[string]*ldapAttempts
+}{
+	byKey: make(map[string]*ldapAttempts),
+}
+
+// ldapAttempts failed LDAP attempts.
+type ldapAttempts struct {
+	failed      int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+// Lockout provides progressive lockout of (builtin) users
+// after failed authentication attempts.
+type Lockout struct {
+	DB    *gorm.DB
+	Cache *Cache
+}
+
+// Locked returns true when the user is locked.
+func (r *Lockout) Locked(user *User) (locked bool) {
+	locked = user.LockedUntil != nil && user.LockedUntil.After(time.Now())
+	return
+}
+
+// Failed records a failed attempt.
+// The user is locked when the policy threshold is reached.
+func (r *Lockout) Failed(user *User) (err error) {
+	policy, err := r.Policy()
+	if err != nil {
+		return
+	}
+	user, err = r.find(user.ID)
+	if err != nil {
+		return
+	}
+	user.FailedLogins++
+	d := policy.duration(user.FailedLogins)
+	if d > 0 {
+		lockedUntil := time.Now().Add(d)
+		user.LockedUntil = &lockedUntil
+		Log.Info(
+			"User locked.",
+			"login", user.Login,
+			"failed", user.FailedLogins,
+			"duration", d.String())
+	}
+	err = r.save(user)
+	return
+}
+
+// Succeeded records a successful attempt.
+// The failed attempts are reset.
+// Tracking of the password age begins when not already tracked.
+func (r *Lockout) Succeeded(user *User) (err error) {
+	if user.FailedLogins == 0 &&
+		user.LockedUntil == nil &&
+		user.PasswordChanged != nil {
+		return
+	}
+	user, err = r.find(user.ID)
+	if err != nil {
+		return
+	}
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	if user.PasswordChanged == nil {
+		now := time.Now()
+		user.PasswordChanged = &now
+	}
+	err = r.save(user)
+	return
+}
+
+// Unlock the user.
+func (r *Lockout) Unlock(id uint) (err error) {
+	user, err := r.find(id)
+	if err != nil {
+		return
+	}
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	err = r.save(user)
+	if err != nil {
+		return
+	}
+	Log.Info("User unlocked.", "login", user.Login)
+	return
+}
+
+// Policy returns the lockout policy.
+func (r *Lockout) Policy() (policy LockoutPolicy, err error) {
+	policy = DefaultLockout
+	m := &model.Setting{}
+	err = r.DB.First(m, "key", LockoutPolicyKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	err = m.As(&policy)
+	return
+}
+
+// find returns the user.
+func (r *Lockout) find(id uint) (user *User, err error) {
+	user = &User{}
+	db := r.DB.Preload(clause.Associations)
+	err = db.First(user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = &NotFound{
+				Resource: "user",
+				Id:       fmt.Sprint(id),
+			}
+		} else {
+			err = liberr.Wrap(err)
+		}
+	}
+	return
+}
+
+// save the user lockout fields and update the cache.
+func (r *Lockout) save(user *User) (err error) {
+	db := r.DB.Model(user)
+	db = db.Select("FailedLogins", "LockedUntil", "PasswordChanged")
+	err = db.Updates(user).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	r.Cache.UserSaved(user)
+	return
+}
+
+// LdapLockout provides progressive lockout of LDAP logins
+// after failed authentication attempts using the lockout policy.
+// LDAP users are not stored by the hub so the failed attempts
+// are tracked (in memory) by login and source IP.
+type LdapLockout struct {
+	DB    *gorm.DB
+	Login string
+	IP    string
+}
+
+// Locked returns true when the login is locked.
+func (r *LdapLockout) Locked() (locked bool) {
+	ldapFailed.mutex.Lock()
+	defer ldapFailed.mutex.Unlock()
+	m, found := ldapFailed.byKey[r.key()]
+	if found {
+		locked = m.lockedUntil.After(time.Now())
+	}
+	return
+}
+
+// Failed records a failed attempt.
+// The login is locked when the policy threshold is reached.
+// Attempts older than the maximum lockout duration are discarded.
+func (r *LdapLockout) Failed() (err error) {
+	lockout := Lockout{DB: r.DB}
+	policy, err := lockout.Policy()
+	if err != nil {
+		return
+	}
+	ldapFailed.mutex.Lock()
+	defer ldapFailed.mutex.Unlock()
+	now := time.Now()
+	expired := now.Add(-policy.maxDuration())
+	for key, m := range ldapFailed.byKey {
+		if m.last.Before(expired) && m.lockedUntil.Before(now) {
+			delete(ldapFailed.byKey, key)
+		}
+	}
+	key := r.key()
+	m, found := ldapFailed.byKey[key]
+	if !found {
+		m = &ldapAttempts{}
+		ldapFailed.byKey[key] = m
+	}
+	m.failed++
+	m.last = now
+	d := policy.duration(m.failed)
+	if d > 0 {
+		m.lockedUntil = now.Add(d)
+		Log.Info(
+			"LDAP user locked.",
+			"login", r.Login,
+			"ip", r.IP,
+			"failed", m.failed,
+			"duration", d.String())
+	}
+	return
+}
+
+// Succeeded records a successful attempt.
+// The failed attempts are reset.
+func (r *LdapLockout) Succeeded() {
+	ldapFailed.mutex.Lock()
+	defer ldapFailed.mutex.Unlock()
+	delete(ldapFailed.byKey, r.key())
+}
+
+// key returns the tracking key.
+func (r *LdapLockout) key() (key string) {
+	key = r.Login + "|" + r.IP
+	return
+}
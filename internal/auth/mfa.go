@@ -0,0 +1,408 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/internal/secret"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MfaRolesKey the setting (policy) listing the names of roles
+// for which MFA is required.
+const (
+	MfaRolesKey = "auth.mfa.roles"
+)
+
+// TOTP (RFC 6238) parameters.
+const (
+	TotpIssuer = "Konveyor"
+	TotpDigits = 6
+	TotpPeriod = 30
+	TotpSkew   = 1
+)
+
+// MFA parameters.
+const (
+	MfaRecoveryCodes = 10
+	MfaMaxAttempts   = 5
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// replay tracks the last TOTP step accepted for each user.
+var replay = struct {
+	mutex sync.Mutex
+	step  map[uint]int64
+}{
+	step: make(map[uint]int64),
+}
+
+// Mfa provides TOTP multi-factor authentication for (builtin) users.
+type Mfa struct {
+	DB    *gorm.DB
+	Cache *Cache
+}
+
+// Required returns true when MFA is required for the user.
+// Required when enrolled or the user has a role listed by the policy.
+func (r *Mfa) Required(user *User) (required bool, err error) {
+	if user.TotpEnabled {
+		required = true
+		return
+	}
+	roles, err := r.policy()
+	if err != nil {
+		return
+	}
+	for _, role := range user.Roles {
+		if slices.Contains(roles, role.Name) {
+			required = true
+			break
+		}
+	}
+	return
+}
+
+// Enroll begins enrollment of the user.
+// A new secret is generated and stored (not enabled) until confirmed.
+func (r *Mfa) Enroll(id uint) (key string, err error) {
+	user, err := r.find(id)
+	if err != nil {
+		return
+	}
+	if user.TotpEnabled {
+		err = &BadRequestError{Reason: "MFA already enrolled."}
+		return
+	}
+	key = NewTotpSecret()
+	encrypted := key
+	err = secret.Encrypt(&encrypted)
+	if err != nil {
+		return
+	}
+	user.Totp = encrypted
+	user.TotpEnabled = false
+	user.RecoveryCodes = nil
+	err = r.save(user)
+	return
+}
+
+// Confirm the (pending) enrollment of the user.
+// The code must match the secret returned by Enroll().
+// Returns the (plain) recovery codes.
+func (r *Mfa) Confirm(id uint, code string) (codes []string, err error) {
+	user, err := r.find(id)
+	if err != nil {
+		return
+	}
+	if user.TotpEnabled {
+		err = &BadRequestError{Reason: "MFA already enrolled."}
+		return
+	}
+	if user.Totp == "" {
+		err = &BadRequestError{Reason: "MFA enrollment not started."}
+		return
+	}
+	key, err := r.secret(user)
+	if err != nil {
+		return
+	}
+	codes, err = r.confirm(user, key, code)
+	return
+}
+
+// Verify the code presented by an enrolled user.
+// The code is either a TOTP code or an (unused) recovery code.
+// A TOTP code is accepted once; a recovery code is consumed.
+func (r *Mfa) Verify(id uint, code string) (err error) {
+	user, err := r.find(id)
+	if err != nil {
+		return
+	}
+	if !user.TotpEnabled {
+		err = &NotAuthenticated{
+			Reason: "MFA not enrolled",
+			Token:  user.Login,
+		}
+		return
+	}
+	key, err := r.secret(user)
+	if err != nil {
+		return
+	}
+	if r.match(user.ID, key, code, true) {
+		return
+	}
+	code = r.normalized(code)
+	for i, hashed := range user.RecoveryCodes {
+		if secret.MatchPassword(code, hashed) {
+			user.RecoveryCodes = slices.Delete(user.RecoveryCodes, i, i+1)
+			err = r.save(user)
+			if err == nil {
+				Log.Info(
+					"MFA recovery code used.",
+					"login", user.Login,
+					"remaining", len(user.RecoveryCodes))
+			}
+			return
+		}
+	}
+	err = &NotAuthenticated{
+		Reason: "invalid MFA code",
+		Token:  user.Login,
+	}
+	return
+}
+
+// Match returns true when the TOTP code matches the (enrolled)
+// user secret. Used with basic auth where the code is presented
+// with each request; the code is not restricted to a single use.
+func (r *Mfa) Match(user *User, code string) (matched bool) {
+	if !user.TotpEnabled {
+		return
+	}
+	key, err := r.secret(user)
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	matched = r.match(user.ID, key, code, false)
+	return
+}
+
+// Remove the enrollment of the (current) user.
+// An enrolled user must present a TOTP or (unused) recovery code.
+// A pending enrollment is removed without a code.
+func (r *Mfa) Remove(id uint, code string) (err error) {
+	user, err := r.find(id)
+	if err != nil {
+		return
+	}
+	if user.TotpEnabled {
+		err = r.Verify(id, code)
+		if err != nil {
+			if errors.Is(err, &NotAuthenticated{}) {
+				err = &BadRequestError{Reason: "invalid MFA code."}
+			}
+			return
+		}
+	}
+	err = r.Reset(id)
+	return
+}
+
+// Reset removes the enrollment of the user.
+func (r *Mfa) Reset(id uint) (err error) {
+	user, err := r.find(id)
+	if err != nil {
+		return
+	}
+	user.Totp = ""
+	user.TotpEnabled = false
+	user.RecoveryCodes = nil
+	err = r.save(user)
+	return
+}
+
+// confirm the enrollment using the specified (plain) secret.
+// Used by the login flow where the pending secret is not stored.
+func (r *Mfa) confirm(user *User, key, code string) (codes []string, err error) {
+	if !r.match(user.ID, key, code, true) {
+		err = &BadRequestError{Reason: "invalid MFA code."}
+		return
+	}
+	encrypted := key
+	err = secret.Encrypt(&encrypted)
+	if err != nil {
+		return
+	}
+	user.Totp = encrypted
+	user.TotpEnabled = true
+	user.RecoveryCodes = nil
+	for range MfaRecoveryCodes {
+		code := r.recoveryCode()
+		codes = append(codes, code)
+		user.RecoveryCodes = append(
+			user.RecoveryCodes,
+			secret.HashPassword(r.normalized(code)))
+	}
+	err = r.save(user)
+	if err != nil {
+		return
+	}
+	Log.Info("MFA enrolled.", "login", user.Login)
+	return
+}
+
+// match returns true when the code matches the secret within
+// the permitted skew. When once is true, a code (step) is
+// accepted only once.
+func (r *Mfa) match(userId uint, key, code string, once bool) (matched bool) {
+	code = r.normalized(code)
+	if len(code) != TotpDigits {
+		return
+	}
+	now := time.Now().Unix() / TotpPeriod
+	for i := -TotpSkew; i <= TotpSkew; i++ {
+		step := now + int64(i)
+		expected, err := totpCode(key, step)
+		if err != nil {
+			Log.Error(err, "")
+			return
+		}
+		if !hmac.Equal([]byte(expected), []byte(code)) {
+			continue
+		}
+		if once {
+			matched = r.accepted(userId, step)
+		} else {
+			matched = true
+		}
+		return
+	}
+	return
+}
+
+// accepted records the step accepted for the user.
+// Returns false when the step (or a later step) was already accepted.
+func (r *Mfa) accepted(userId uint, step int64) (accepted bool) {
+	replay.mutex.Lock()
+	defer replay.mutex.Unlock()
+	if step <= replay.step[userId] {
+		return
+	}
+	replay.step[userId] = step
+	accepted = true
+	return
+}
+
+// policy returns the names of roles for which MFA is required.
+func (r *Mfa) policy() (roles []string, err error) {
+	m := &model.Setting{}
+	err = r.DB.First(m, "key", MfaRolesKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	err = m.As(&roles)
+	return
+}
+
+// secret returns the decrypted secret.
+func (r *Mfa) secret(user *User) (key string, err error) {
+	key = user.Totp
+	err = secret.Decrypt(&key)
+	return
+}
+
+// find returns the user.
+func (r *Mfa) find(id uint) (user *User, err error) {
+	user = &User{}
+	db := r.DB.Preload(clause.Associations)
+	err = db.First(user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = &NotFound{
+				Resource: "user",
+				Id:       fmt.Sprint(id),
+			}
+		} else {
+			err = liberr.Wrap(err)
+		}
+	}
+	return
+}
+
+// save the user MFA fields and update the cache.
+func (r *Mfa) save(user *User) (err error) {
+	db := r.DB.Model(user)
+	db = db.Select("Totp", "TotpEnabled", "RecoveryCodes")
+	err = db.Updates(user).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	r.Cache.UserSaved(user)
+	return
+}
+
+// recoveryCode returns a new recovery code.
+// Format: xxxxx-xxxxx.
+func (r *Mfa) recoveryCode() (code string) {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	s := strings.ToLower(b32.EncodeToString(b))[:10]
+	code = s[:5] + "-" + s[5:]
+	return
+}
+
+// normalized returns the code with separators removed.
+func (r *Mfa) normalized(code string) (s string) {
+	s = strings.ToLower(code)
+	s = strings.ReplaceAll(s, "-", "")
+	s = strings.ReplaceAll(s, " ", "")
+	return
+}
+
+// NewTotpSecret returns a new (base32 encoded) TOTP secret.
+func NewTotpSecret() (key string) {
+	b := make([]byte, 20)
+	_, _ = rand.Read(b)
+	key = b32.EncodeToString(b)
+	return
+}
+
+// TotpCode returns the TOTP code for the secret at the specified time.
+func TotpCode(key string, t time.Time) (code string, err error) {
+	code, err = totpCode(key, t.Unix()/TotpPeriod)
+	return
+}
+
+// TotpURI returns the otpauth key URI used by authenticator apps.
+func TotpURI(login, key string) (uri string) {
+	label := url.PathEscape(TotpIssuer + ":" + login)
+	query := url.Values{}
+	query.Set("secret", key)
+	query.Set("issuer", TotpIssuer)
+	query.Set("digits", fmt.Sprint(TotpDigits))
+	query.Set("period", fmt.Sprint(TotpPeriod))
+	uri = "otpauth://totp/" + label + "?" + query.Encode()
+	return
+}
+
+// totpCode returns the TOTP (HOTP) code for the time step.
+func totpCode(key string, step int64) (code string, err error) {
+	b, err := b32.DecodeString(strings.ToUpper(key))
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	h := hmac.New(sha1.New, b)
+	h.Write(msg)
+	sum := h.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	n := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	code = fmt.Sprintf("%0*d", TotpDigits, n%uint32(math.Pow10(TotpDigits)))
+	return
+}
@@ -25,7 +25,7 @@ func (r *NoAuth) Authenticate(request *Request) (jwToken *jwt.Token, err error)
 // For the NoAuth provider, this just returns a single
 // wildcard scope matching everything.
 func (r *NoAuth) Scopes(jwToken *jwt.Token) (scopes []Scope) {
-	scopes = append(scopes, Scope{"*", "*"})
+	scopes = append(scopes, Scope{Resource: "*", Method: "*"})
 	return
 }
 
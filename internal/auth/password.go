@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/internal/secret"
+	"gorm.io/gorm"
+)
+
+// PasswordPolicyKey the setting (policy) for builtin user passwords.
+const (
+	PasswordPolicyKey = "auth.password.policy"
+)
+
+// PasswordPolicy password policy.
+// Zero values are not enforced.
+type PasswordPolicy struct {
+	// MinLength the minimum length.
+	MinLength int `json:"minLength"`
+	// Classes the minimum number of character classes.
+	// Classes: lower, upper, digit and symbol.
+	Classes int `json:"classes"`
+	// History the number of previous passwords that may not be reused.
+	History int `json:"history"`
+	// MaxAge the maximum age (days).
+	MaxAge int `json:"maxAge"`
+}
+
+// Validate the (plain) password.
+func (r *PasswordPolicy) Validate(password string) (err error) {
+	var reasons []string
+	if utf8.RuneCountInString(password) < r.MinLength {
+		reasons = append(
+			reasons,
+			fmt.Sprintf("must contain at least %d characters", r.MinLength))
+	}
+	if r.classes(password) < r.Classes {
+		reasons = append(
+			reasons,
+			fmt.Sprintf(
+				"must contain at least %d of: lowercase, uppercase, digit, symbol",
+				r.Classes))
+	}
+	if len(reasons) > 0 {
+		err = &BadRequestError{
+			Reason: "password " + strings.Join(reasons, "; ") + ".",
+		}
+	}
+	return
+}
+
+// Expired returns true when the user password is older than MaxAge.
+// The age of passwords changed before being tracked is unknown.
+func (r *PasswordPolicy) Expired(user *User) (expired bool) {
+	if r.MaxAge < 1 || user.PasswordChanged == nil {
+		return
+	}
+	maxAge := time.Duration(r.MaxAge) * 24 * time.Hour
+	expired = time.Since(*user.PasswordChanged) > maxAge
+	return
+}
+
+// classes returns the number of character classes in the password.
+func (r *PasswordPolicy) classes(password string) (n int) {
+	found := make(map[int]bool)
+	for _, c := range password {
+		switch {
+		case unicode.IsLower(c):
+			found[0] = true
+		case unicode.IsUpper(c):
+			found[1] = true
+		case unicode.IsDigit(c):
+			found[2] = true
+		default:
+			found[3] = true
+		}
+	}
+	n = len(found)
+	return
+}
+
+// Password manages builtin user passwords.
+type Password struct {
+	DB *gorm.DB
+}
+
+// Set the (plain) password of the user.
+// The password is validated against the policy and history.
+// The user password history and changed timestamp are updated.
+// The password is hashed when the user is encoded (saved).
+func (r *Password) Set(user *User, password string) (err error) {
+	policy, err := r.Policy()
+	if err != nil {
+		return
+	}
+	err = policy.Validate(password)
+	if err != nil {
+		return
+	}
+	var history []string
+	if user.Password != "" {
+		history = append(history, user.Password)
+	}
+	history = append(history, user.PasswordHistory...)
+	if policy.History > 0 {
+		history = history[:min(len(history), policy.History)]
+		for _, hashed := range history {
+			if secret.MatchPassword(password, hashed) {
+				err = &BadRequestError{
+					Reason: fmt.Sprintf(
+						"password may not match the previous %d passwords.",
+						policy.History),
+				}
+				return
+			}
+		}
+	} else {
+		history = nil
+	}
+	now := time.Now()
+	user.Password = password
+	user.PasswordHistory = history
+	user.PasswordChanged = &now
+	return
+}
+
+// Policy returns the password policy.
+func (r *Password) Policy() (policy PasswordPolicy, err error) {
+	m := &model.Setting{}
+	err = r.DB.First(m, "key", PasswordPolicyKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	err = m.As(&policy)
+	return
+}
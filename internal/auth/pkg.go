@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jortel/go-utils/logr"
 	"github.com/konveyor/tackle2-hub/internal/auth/cache"
@@ -35,6 +36,13 @@ const (
 	DevVerifierClientId = "device-verifier"
 )
 
+// Subject kinds.
+const (
+	SubjectUser           = "user"
+	SubjectServiceAccount = "serviceaccount"
+	SubjectTask           = "task"
+)
+
 const (
 	AuthRequestId = "authRequestId"
 )
@@ -127,7 +135,7 @@ type Provider interface {
 	// Cache returns the provider cache.
 	Cache() *Cache
 	// Login begin OIDC auth.
-	Login(w http.ResponseWriter, r *http.Request, reqId string) (err error)
+	Login(ctx *gin.Context, reqId string) (err error)
 	// NewToken creates a new personal access token.
 	NewToken(subject string, lifespan time.Duration, mod ...Mod) (token Token, err error)
 	// Revoke a token.
@@ -146,17 +154,22 @@ type Provider interface {
 	IdpHandler() (h *FedIdpHandler)
 	// DagHandler returns the device access grant handler.
 	DagHandler() (h *DagHandler)
+	// TokenHandler returns the token introspection and revocation handler.
+	TokenHandler() (h *TokenHandler)
+	// ServiceToken mints a (downstream) token for a proxied service.
+	ServiceToken(r *http.Request, service string, caller Result) (token string, err error)
 }
 
 // JWT Claims - Standard claims.
 const (
-	ClaimSub   = "sub"   // Subject
-	ClaimScope = "scope" // Scope
-	ClaimExp   = "exp"   // Expiration Time
-	ClaimIss   = "iss"   // Issuer
-	ClaimAud   = "aud"   // Audience
-	ClaimIat   = "iat"   // Issued At
-	ClaimId    = "jti"   // Token id.
+	ClaimSub   = "sub"                // Subject
+	ClaimScope = "scope"              // Scope
+	ClaimExp   = "exp"                // Expiration Time
+	ClaimIss   = "iss"                // Issuer
+	ClaimAud   = "aud"                // Audience
+	ClaimIat   = "iat"                // Issued At
+	ClaimId    = "jti"                // Token id.
+	ClaimUser  = "preferred_username" // User (login).
 )
 
 // cache aliases
@@ -2,11 +2,14 @@ package auth
 
 import (
 	"context"
+	"crypto/rsa"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -491,6 +494,57 @@ func TestBaseScopeParsing(t *testing.T) {
 	g.Expect(scope.String()).To(Equal("tags:write"))
 }
 
+// TestScopeConstraint tests parsing and validating constrained scopes.
+func TestScopeConstraint(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scope := Scope{}
+	scope.With("applications:put:businessService=1,2")
+	g.Expect(scope.Resource).To(Equal("applications"))
+	g.Expect(scope.Method).To(Equal("put"))
+	g.Expect(scope.Constrained()).To(BeTrue())
+	g.Expect(scope.Constrainable()).To(BeTrue())
+	g.Expect(scope.Constraint.Field).To(Equal(ConstraintBusinessService))
+	g.Expect(scope.Constraint.Validate()).To(BeNil())
+	ids, err := scope.Constraint.IDs()
+	g.Expect(err).To(BeNil())
+	g.Expect(ids).To(Equal([]uint{1, 2}))
+	g.Expect(scope.String()).To(Equal("applications:put:businessService=1,2"))
+	g.Expect(scope.Match("applications", "PUT")).To(BeTrue())
+	base := scope.Base()
+	g.Expect(base.String()).To(Equal("applications:put"))
+
+	// Expanded scopes are constrained.
+	scope = Scope{}
+	scope.With("applications.facts:*:owner=me")
+	g.Expect(scope.Constrainable()).To(BeTrue())
+	g.Expect(scope.Constraint.Validate()).To(BeNil())
+	for _, expanded := range scope.ExpandWith([]string{"applications.facts"}) {
+		g.Expect(expanded.Constrained()).To(BeTrue())
+		g.Expect(expanded.String()).To(HaveSuffix(":owner=me"))
+	}
+
+	// Not valid.
+	for _, s := range []string{
+		"applications:get:businessService=a",
+		"applications:get:businessService=",
+		"applications:get:owner=bob",
+		"applications:get:color=red",
+	} {
+		scope = Scope{}
+		scope.With(s)
+		g.Expect(scope.Constraint.Validate()).ToNot(BeNil(), s)
+	}
+
+	// Not constrainable.
+	scope = Scope{}
+	scope.With("tags:get:contributor=me")
+	g.Expect(scope.Constrainable()).To(BeFalse())
+	scope = Scope{}
+	scope.With("applications:get")
+	g.Expect(scope.Constrained()).To(BeFalse())
+}
+
 // TestNoAuthProvider tests the NoAuth provider fallback behavior.
 func TestNoAuthProvider(t *testing.T) {
 	g := NewGomegaWithT(t)
@@ -2780,6 +2834,687 @@ func TestIdentityProviderInjectDefaultPort(t *testing.T) {
 	g.Expect(idp.RedirectURI).To(Equal("http://hub.example.com:/callback"))
 }
 
+// TestTotpCode tests TOTP codes using the RFC 6238 (SHA1) test vectors.
+func TestTotpCode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	key := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // 12345678901234567890
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+	for _, c := range cases {
+		code, err := TotpCode(key, time.Unix(c.unix, 0))
+		g.Expect(err).To(BeNil())
+		g.Expect(code).To(Equal(c.code))
+	}
+	_, err := TotpCode("not-base32!", time.Now())
+	g.Expect(err).NotTo(BeNil())
+
+	uri := TotpURI("elmer", key)
+	g.Expect(uri).To(HavePrefix("otpauth://totp/" + TotpIssuer + ":elmer?"))
+	g.Expect(uri).To(ContainSubstring("secret=" + key))
+}
+
+// TestMfa tests MFA enrollment, verification and the policy.
+func TestMfa(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+
+	role := &model.Role{Name: "auditor"}
+	err = db.Create(role).Error
+	g.Expect(err).To(BeNil())
+	user := &model.User{
+		Subject:  uuid.New().String(),
+		Login:    "mfa-user",
+		Password: secret.HashPassword("password"),
+		Email:    "mfa@example.com",
+		Roles:    []model.Role{*role},
+	}
+	err = db.Create(user).Error
+	g.Expect(err).To(BeNil())
+
+	provider, err := NewBuiltin(db, &Tenant{})
+	g.Expect(err).To(BeNil())
+	mfa := &Mfa{DB: db, Cache: provider.cache}
+	basic := func(password string) (err error) {
+		request := newTestRequest()
+		request.Login = user.Login
+		request.Password = password
+		_, err = provider.Authenticate(request)
+		return
+	}
+
+	// not required.
+	cached, err := provider.cache.FindUserByLogin(user.Login)
+	g.Expect(err).To(BeNil())
+	required, err := mfa.Required(cached)
+	g.Expect(err).To(BeNil())
+	g.Expect(required).To(BeFalse())
+	g.Expect(basic("password")).To(BeNil())
+
+	// required by policy.
+	err = db.Create(&model.Setting{Key: MfaRolesKey, Value: []string{role.Name}}).Error
+	g.Expect(err).To(BeNil())
+	required, err = mfa.Required(cached)
+	g.Expect(err).To(BeNil())
+	g.Expect(required).To(BeTrue())
+	g.Expect(errors.Is(basic("password"), &NotAuthenticated{})).To(BeTrue())
+
+	// enroll.
+	_, err = mfa.Confirm(user.ID, "000000")
+	g.Expect(errors.Is(err, &BadRequestError{})).To(BeTrue())
+	key, err := mfa.Enroll(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(key).NotTo(BeEmpty())
+	stored := &model.User{}
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(stored.Totp).NotTo(Equal(key))
+	g.Expect(stored.TotpEnabled).To(BeFalse())
+	_, err = mfa.Confirm(user.ID, "bad")
+	g.Expect(errors.Is(err, &BadRequestError{})).To(BeTrue())
+	code, err := TotpCode(key, time.Now())
+	g.Expect(err).To(BeNil())
+	codes, err := mfa.Confirm(user.ID, code)
+	g.Expect(err).To(BeNil())
+	g.Expect(codes).To(HaveLen(MfaRecoveryCodes))
+	_, err = mfa.Enroll(user.ID)
+	g.Expect(errors.Is(err, &BadRequestError{})).To(BeTrue())
+
+	// verify: code accepted once.
+	err = mfa.Verify(user.ID, code)
+	g.Expect(errors.Is(err, &NotAuthenticated{})).To(BeTrue())
+	next, err := TotpCode(key, time.Now().Add(TotpPeriod*time.Second))
+	g.Expect(err).To(BeNil())
+	err = mfa.Verify(user.ID, next)
+	g.Expect(err).To(BeNil())
+
+	// verify: recovery code consumed.
+	err = mfa.Verify(user.ID, strings.ToUpper(codes[0]))
+	g.Expect(err).To(BeNil())
+	err = mfa.Verify(user.ID, codes[0])
+	g.Expect(errors.Is(err, &NotAuthenticated{})).To(BeTrue())
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(stored.RecoveryCodes).To(HaveLen(MfaRecoveryCodes - 1))
+
+	// basic auth: code appended to the password.
+	g.Expect(errors.Is(basic("password"), &NotAuthenticated{})).To(BeTrue())
+	g.Expect(errors.Is(basic("wrong"+code), &NotAuthenticated{})).To(BeTrue())
+	code, err = TotpCode(key, time.Now())
+	g.Expect(err).To(BeNil())
+	g.Expect(basic("password" + code)).To(BeNil())
+	g.Expect(basic("password" + code)).To(BeNil())
+
+	// remove: code required.
+	err = mfa.Remove(user.ID, "")
+	g.Expect(errors.Is(err, &BadRequestError{})).To(BeTrue())
+	err = mfa.Remove(user.ID, codes[0])
+	g.Expect(errors.Is(err, &BadRequestError{})).To(BeTrue())
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(stored.TotpEnabled).To(BeTrue())
+	err = mfa.Remove(user.ID, codes[1])
+	g.Expect(err).To(BeNil())
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(stored.TotpEnabled).To(BeFalse())
+
+	// reset.
+	err = mfa.Reset(user.ID)
+	g.Expect(err).To(BeNil())
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(stored.Totp).To(BeEmpty())
+	g.Expect(stored.TotpEnabled).To(BeFalse())
+	g.Expect(stored.RecoveryCodes).To(BeEmpty())
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+	passwords := &Password{DB: db}
+
+	// no policy.
+	policy, err := passwords.Policy()
+	g.Expect(err).To(BeNil())
+	g.Expect(policy.Validate("a")).To(BeNil())
+	user := &model.User{}
+	g.Expect(passwords.Set(user, "a")).To(BeNil())
+	g.Expect(user.PasswordChanged).NotTo(BeNil())
+	g.Expect(user.PasswordHistory).To(BeEmpty())
+
+	// validate.
+	err = db.Create(
+		&model.Setting{
+			Key: PasswordPolicyKey,
+			Value: PasswordPolicy{
+				MinLength: 8,
+				Classes:   3,
+				History:   2,
+				MaxAge:    30,
+			},
+		}).Error
+	g.Expect(err).To(BeNil())
+	policy, err = passwords.Policy()
+	g.Expect(err).To(BeNil())
+	g.Expect(policy.MinLength).To(Equal(8))
+	g.Expect(errors.Is(policy.Validate("Ab1!"), &BadRequestError{})).To(BeTrue())
+	g.Expect(errors.Is(policy.Validate("abcdefgh1"), &BadRequestError{})).To(BeTrue())
+	g.Expect(policy.Validate("abcdefgH1")).To(BeNil())
+	g.Expect(policy.Validate("abcdefg-1")).To(BeNil())
+
+	// history.
+	user = &model.User{}
+	g.Expect(passwords.Set(user, "Password-1")).To(BeNil())
+	user.Password = secret.HashPassword(user.Password)
+	g.Expect(passwords.Set(user, "Password-2")).To(BeNil())
+	g.Expect(user.PasswordHistory).To(HaveLen(1))
+	user.Password = secret.HashPassword(user.Password)
+	err = passwords.Set(user, "Password-1")
+	g.Expect(errors.Is(err, &BadRequestError{})).To(BeTrue())
+	err = passwords.Set(user, "Password-2")
+	g.Expect(errors.Is(err, &BadRequestError{})).To(BeTrue())
+	g.Expect(passwords.Set(user, "Password-3")).To(BeNil())
+	g.Expect(user.PasswordHistory).To(HaveLen(2))
+	user.Password = secret.HashPassword(user.Password)
+	g.Expect(passwords.Set(user, "Password-1")).To(BeNil())
+
+	// expired.
+	g.Expect(policy.Expired(user)).To(BeFalse())
+	changed := time.Now().Add(-31 * 24 * time.Hour)
+	user.PasswordChanged = &changed
+	g.Expect(policy.Expired(user)).To(BeTrue())
+	user.PasswordChanged = nil
+	g.Expect(policy.Expired(user)).To(BeFalse())
+}
+
+func TestLockout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// progressive.
+	policy := &LockoutPolicy{Attempts: 3, Duration: 1, MaxDuration: 5}
+	g.Expect(policy.duration(2)).To(BeZero())
+	g.Expect(policy.duration(3)).To(Equal(time.Minute))
+	g.Expect(policy.duration(4)).To(Equal(2 * time.Minute))
+	g.Expect(policy.duration(5)).To(Equal(4 * time.Minute))
+	g.Expect(policy.duration(6)).To(Equal(5 * time.Minute))
+	g.Expect(policy.duration(100)).To(Equal(5 * time.Minute))
+	policy = &LockoutPolicy{}
+	g.Expect(policy.duration(100)).To(BeZero())
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+	user := &model.User{
+		Subject:  uuid.New().String(),
+		Login:    "locked-user",
+		Password: secret.HashPassword("password"),
+		Email:    "locked@example.com",
+	}
+	err = db.Create(user).Error
+	g.Expect(err).To(BeNil())
+	provider, err := NewBuiltin(db, &Tenant{})
+	g.Expect(err).To(BeNil())
+	basic := func(password string) (err error) {
+		request := newTestRequest()
+		request.Login = user.Login
+		request.Password = password
+		_, err = provider.Authenticate(request)
+		return
+	}
+	lockout := &Lockout{DB: db, Cache: provider.cache}
+	stored := &model.User{}
+
+	// default policy.
+	for range DefaultLockout.Attempts - 1 {
+		g.Expect(errors.Is(basic("wrong"), &NotAuthenticated{})).To(BeTrue())
+	}
+	g.Expect(basic("password")).To(BeNil())
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(stored.FailedLogins).To(BeZero())
+	g.Expect(stored.PasswordChanged).NotTo(BeNil())
+
+	// locked.
+	for range DefaultLockout.Attempts {
+		g.Expect(errors.Is(basic("wrong"), &NotAuthenticated{})).To(BeTrue())
+	}
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(lockout.Locked(stored)).To(BeTrue())
+	g.Expect(errors.Is(basic("password"), &NotAuthenticated{})).To(BeTrue())
+
+	// unlock.
+	err = lockout.Unlock(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(basic("password")).To(BeNil())
+
+	// disabled.
+	err = db.Create(
+		&model.Setting{
+			Key:   LockoutPolicyKey,
+			Value: LockoutPolicy{},
+		}).Error
+	g.Expect(err).To(BeNil())
+	for range DefaultLockout.Attempts + 1 {
+		g.Expect(errors.Is(basic("wrong"), &NotAuthenticated{})).To(BeTrue())
+	}
+	g.Expect(basic("password")).To(BeNil())
+
+	// password expired.
+	err = db.Create(
+		&model.Setting{
+			Key:   PasswordPolicyKey,
+			Value: PasswordPolicy{MaxAge: 1},
+		}).Error
+	g.Expect(err).To(BeNil())
+	changed := time.Now().Add(-48 * time.Hour)
+	err = db.Model(user).Update("PasswordChanged", &changed).Error
+	g.Expect(err).To(BeNil())
+	err = provider.cache.Refresh()
+	g.Expect(err).To(BeNil())
+	g.Expect(errors.Is(basic("password"), &NotAuthenticated{})).To(BeTrue())
+
+	// audit.
+	var events []model.AuthEvent
+	err = db.Find(&events, "login", user.Login).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(events).NotTo(BeEmpty())
+	last := events[len(events)-1]
+	g.Expect(last.Method).To(Equal(MethodPassword))
+	g.Expect(last.Succeeded).To(BeFalse())
+	g.Expect(last.Reason).To(Equal("password expired"))
+	g.Expect(last.Source).NotTo(BeEmpty())
+}
+
+func TestLoginMfaLockout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+	user := &model.User{
+		Subject:  uuid.New().String(),
+		Login:    "mfa-locked-user",
+		Password: secret.HashPassword("password"),
+		Email:    "mfa-locked@example.com",
+	}
+	err = db.Create(user).Error
+	g.Expect(err).To(BeNil())
+	provider, err := NewBuiltin(db, &Tenant{})
+	g.Expect(err).To(BeNil())
+	mfa := &Mfa{DB: db, Cache: provider.cache}
+	replay.mutex.Lock()
+	delete(replay.step, user.ID)
+	replay.mutex.Unlock()
+	key, err := mfa.Enroll(user.ID)
+	g.Expect(err).To(BeNil())
+	code, err := TotpCode(key, time.Now())
+	g.Expect(err).To(BeNil())
+	_, err = mfa.Confirm(user.ID, code)
+	g.Expect(err).To(BeNil())
+	storage := provider.storage
+	storage.authReqById["login"] = &AuthRequest{
+		AuthRequest: &oidc.AuthRequest{},
+		requestId:   "login",
+		expiration:  time.Now().Add(time.Hour),
+	}
+	post := func(form url.Values) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"http://localhost:8080/login",
+			strings.NewReader(form.Encode()))
+		ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		err := storage.Login(ctx, "login")
+		g.Expect(err).To(BeNil())
+	}
+	password := func() {
+		post(url.Values{
+			"pf-login-username-id": {user.Login},
+			"pf-login-password-id": {"password"},
+		})
+	}
+	setFailed := func(n int, until *time.Time) {
+		err := db.Model(user).Updates(
+			map[string]any{
+				"FailedLogins": n,
+				"LockedUntil":  until,
+			}).Error
+		g.Expect(err).To(BeNil())
+		err = provider.cache.Refresh()
+		g.Expect(err).To(BeNil())
+	}
+	stored := &model.User{}
+
+	// password: lockout not cleared until MFA completes.
+	setFailed(2, nil)
+	password()
+	g.Expect(storage.authReqById["login"].mfa).NotTo(BeNil())
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(stored.FailedLogins).To(Equal(2))
+
+	// locked: MFA step rejected.
+	locked := time.Now().Add(time.Hour)
+	setFailed(2, &locked)
+	code, err = TotpCode(key, time.Now().Add(TotpPeriod*time.Second))
+	g.Expect(err).To(BeNil())
+	post(url.Values{"mfaCode": {code}})
+	g.Expect(storage.authReqById["login"].mfa).To(BeNil())
+	g.Expect(storage.authReqById["login"].done).To(BeFalse())
+
+	// MFA completed: lockout cleared.
+	setFailed(2, nil)
+	password()
+	post(url.Values{"mfaCode": {code}})
+	g.Expect(storage.authReqById["login"].done).To(BeTrue())
+	err = db.First(stored, user.ID).Error
+	g.Expect(err).To(BeNil())
+	g.Expect(stored.FailedLogins).To(BeZero())
+}
+
+func TestLdapLockout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+	err = db.Create(
+		&model.Setting{
+			Key:   LockoutPolicyKey,
+			Value: LockoutPolicy{Attempts: 2, Duration: 1},
+		}).Error
+	g.Expect(err).To(BeNil())
+	provider, err := NewBuiltin(db, &Tenant{})
+	g.Expect(err).To(BeNil())
+	request := newTestRequest()
+	request.Login = "ldap-user"
+	request.Password = "wrong"
+	lockout := &LdapLockout{
+		DB:    db,
+		Login: request.Login,
+		IP:    SourceIP(request.CTX),
+	}
+	other := &LdapLockout{
+		DB:    db,
+		Login: request.Login,
+		IP:    "10.0.0.1",
+	}
+
+	// progressive.
+	g.Expect(lockout.Failed()).To(BeNil())
+	g.Expect(lockout.Locked()).To(BeFalse())
+	g.Expect(lockout.Failed()).To(BeNil())
+	g.Expect(lockout.Locked()).To(BeTrue())
+	g.Expect(other.Locked()).To(BeFalse())
+
+	// locked before the LDAP bind.
+	_, err = provider.Authenticate(request)
+	g.Expect(errors.Is(err, &NotAuthenticated{})).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("user locked"))
+
+	// succeeded.
+	lockout.Succeeded()
+	g.Expect(lockout.Locked()).To(BeFalse())
+	_, err = provider.Authenticate(request)
+	g.Expect(err.Error()).NotTo(ContainSubstring("user locked"))
+}
+
+func TestSourceIP(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(SourceIP(nil)).To(BeEmpty())
+	ctx, engine := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/login", nil)
+	ctx.Request.RemoteAddr = "10.0.0.1:4000"
+	ctx.Request.Header.Set("X-Forwarded-For", "192.168.1.1")
+
+	// untrusted.
+	err := engine.SetTrustedProxies(nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(SourceIP(ctx)).To(Equal("10.0.0.1"))
+
+	// trusted.
+	err = engine.SetTrustedProxies([]string{"10.0.0.0/8"})
+	g.Expect(err).To(BeNil())
+	g.Expect(SourceIP(ctx)).To(Equal("192.168.1.1"))
+}
+
+// TestTokenIntrospection tests token introspection (RFC 7662) and revocation (RFC 7009).
+func TestTokenIntrospection(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+	provider, err := NewBuiltin(db, &Tenant{})
+	g.Expect(err).To(BeNil())
+
+	// Clients.
+	client := &IdpClient{
+		Subject:         uuid.New().String(),
+		ClientId:        "introspect-client",
+		Secret:          secret.HashPassword("secret"),
+		ApplicationType: "web",
+		Grants:          []string{"client_credentials"},
+		Scopes:          []string{"openid"},
+	}
+	err = db.Create(client).Error
+	g.Expect(err).To(BeNil())
+	provider.cache.ClientSaved(client)
+	other := &IdpClient{
+		Subject:         uuid.New().String(),
+		ClientId:        "other-client",
+		Secret:          secret.HashPassword("other"),
+		ApplicationType: "web",
+	}
+	err = db.Create(other).Error
+	g.Expect(err).To(BeNil())
+	provider.cache.ClientSaved(other)
+	public := &IdpClient{
+		Subject:         uuid.New().String(),
+		ClientId:        "public-client",
+		ApplicationType: "native",
+	}
+	err = db.Create(public).Error
+	g.Expect(err).To(BeNil())
+	provider.cache.ClientSaved(public)
+
+	post := func(
+		handler func(*gin.Context),
+		id, passphrase string,
+		form url.Values) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"http://localhost:8080/oidc/introspect",
+			strings.NewReader(form.Encode()))
+		ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if id != "" {
+			ctx.Request.SetBasicAuth(id, passphrase)
+		}
+		handler(ctx)
+		return
+	}
+	h := provider.TokenHandler()
+	introspect := func(token string) (r TokenIntrospection) {
+		w := post(h.Introspect, client.ClientId, "secret", url.Values{"token": {token}})
+		g.Expect(w.Code).To(Equal(http.StatusOK))
+		err := json.Unmarshal(w.Body.Bytes(), &r)
+		g.Expect(err).To(BeNil())
+		return
+	}
+
+	// Task token.
+	sa := &ServiceAccount{
+		Subject: uuid.New().String(),
+		Name:    "introspect-sa",
+	}
+	err = db.Create(sa).Error
+	g.Expect(err).To(BeNil())
+	provider.cache.SaSaved(sa)
+	task := &model.Task{Name: "introspect"}
+	err = db.Create(task).Error
+	g.Expect(err).To(BeNil())
+	pat, err := provider.NewToken(
+		sa.Subject,
+		time.Hour,
+		func(m *Token) {
+			m.TaskID = &task.ID
+		})
+	g.Expect(err).To(BeNil())
+
+	// client authentication.
+	form := url.Values{"token": {pat.Secret}}
+	w := post(h.Introspect, client.ClientId, "wrong", form)
+	g.Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	g.Expect(w.Body.String()).To(ContainSubstring("invalid_client"))
+	w = post(h.Introspect, public.ClientId, "", form)
+	g.Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	w = post(h.Introspect, "", "", form)
+	g.Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	form.Set("client_id", client.ClientId)
+	form.Set("client_secret", "secret")
+	w = post(h.Introspect, "", "", form)
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+
+	// PAT (task).
+	r := introspect(pat.Secret)
+	g.Expect(r.Active).To(BeTrue())
+	g.Expect(r.Subject).To(Equal(sa.Subject))
+	g.Expect(r.Username).To(Equal(sa.Name))
+	g.Expect(r.Task).To(Equal(task.ID))
+	g.Expect(r.TokenId).To(Equal(pat.AuthId))
+	g.Expect(r.Expiration).To(Equal(pat.Expiration.Unix()))
+	g.Expect(r.ClientId).To(BeEmpty())
+
+	// unknown.
+	r = introspect("unknown")
+	g.Expect(r.Active).To(BeFalse())
+	g.Expect(r.Subject).To(BeEmpty())
+
+	// access token.
+	authId := provider.storage.genId()
+	clientReq := &ClientRequest{
+		authId:   authId,
+		clientId: client.ClientId,
+		subject:  client.Subject,
+		scopes:   []string{"openid"},
+		issued:   time.Now(),
+	}
+	_, expiration, err := provider.storage.CreateAccessToken(context.Background(), clientReq)
+	g.Expect(err).To(BeNil())
+	signed := func(authId string, expiration time.Time) (token string) {
+		jwk := provider.keySet.Keys[0]
+		jwToken := jwt.New(jwt.SigningMethodRS256)
+		jwToken.Header["kid"] = jwk.KeyID
+		claims := jwToken.Claims.(jwt.MapClaims)
+		claims[ClaimId] = authId
+		claims[ClaimSub] = client.Subject
+		claims[ClaimScope] = "openid"
+		claims[ClaimIss] = testIssuer()
+		claims[ClaimIat] = time.Now().Unix()
+		claims[ClaimExp] = expiration.Unix()
+		token, err := jwToken.SignedString(jwk.Key())
+		g.Expect(err).To(BeNil())
+		return
+	}
+	accessToken := signed(authId, expiration)
+	_, err = provider.cache.FindAccessToken(authId)
+	g.Expect(err).To(BeNil())
+	r = introspect(accessToken)
+	g.Expect(r.Active).To(BeTrue())
+	g.Expect(r.ClientId).To(Equal(client.ClientId))
+	g.Expect(r.Subject).To(Equal(client.Subject))
+	g.Expect(r.Scope).To(Equal("openid"))
+	g.Expect(r.TokenId).To(Equal(authId))
+	g.Expect(r.Task).To(BeZero())
+
+	// revoke: not issued to the client.
+	form = url.Values{"token": {accessToken}}
+	w = post(h.Revoke, other.ClientId, "other", form)
+	g.Expect(w.Code).To(Equal(http.StatusBadRequest))
+	g.Expect(w.Body.String()).To(ContainSubstring("unauthorized_client"))
+	g.Expect(introspect(accessToken).Active).To(BeTrue())
+
+	// revoke: access token.
+	w = post(h.Revoke, client.ClientId, "secret", form)
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+	g.Expect(introspect(accessToken).Active).To(BeFalse())
+	_, err = provider.cache.FindAccessToken(authId)
+	g.Expect(errors.Is(err, &NotFound{})).To(BeTrue())
+
+	// revoke: PAT not supported.
+	form = url.Values{"token": {pat.Secret}}
+	w = post(h.Revoke, client.ClientId, "secret", form)
+	g.Expect(w.Code).To(Equal(http.StatusBadRequest))
+	g.Expect(w.Body.String()).To(ContainSubstring(UnsupportedTokenType))
+	g.Expect(introspect(pat.Secret).Active).To(BeTrue())
+
+	// revoke: unknown.
+	form = url.Values{"token": {"unknown"}}
+	w = post(h.Revoke, client.ClientId, "secret", form)
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+
+	// revoke: refresh token.
+	grant := &Grant{
+		Kind:         KindAuthCode,
+		ClientId:     client.ClientId,
+		AuthId:       provider.storage.genId(),
+		Subject:      client.Subject,
+		RefreshToken: secret.Hash("refresh-token"),
+		Issued:       time.Now(),
+		Expiration:   time.Now().Add(time.Hour),
+	}
+	err = db.Create(grant).Error
+	g.Expect(err).To(BeNil())
+	refreshReq := &RefreshRequest{
+		grantId:  grant.AuthId,
+		clientId: client.ClientId,
+		subject:  client.Subject,
+		scopes:   []string{"openid"},
+		issued:   time.Now(),
+	}
+	_, expiration, err = provider.storage.CreateAccessToken(context.Background(), refreshReq)
+	g.Expect(err).To(BeNil())
+	grantToken := signed(grant.AuthId, expiration)
+	g.Expect(introspect(grantToken).Active).To(BeTrue())
+
+	// refresh token.
+	r = introspect("refresh-token")
+	g.Expect(r.Active).To(BeTrue())
+	g.Expect(r.ClientId).To(Equal(client.ClientId))
+	g.Expect(r.Subject).To(Equal(client.Subject))
+	g.Expect(r.TokenId).To(Equal(grant.AuthId))
+	g.Expect(r.Expiration).To(Equal(grant.Expiration.Unix()))
+	form = url.Values{
+		"token":           {"refresh-token"},
+		"token_type_hint": {"access_token"},
+	}
+	w = post(h.Introspect, client.ClientId, "secret", form)
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+	g.Expect(w.Body.String()).NotTo(ContainSubstring(client.ClientId))
+
+	form = url.Values{"token": {"refresh-token"}}
+	w = post(h.Revoke, other.ClientId, "other", form)
+	g.Expect(w.Code).To(Equal(http.StatusBadRequest))
+	w = post(h.Revoke, client.ClientId, "secret", form)
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+	err = db.First(&Grant{}, grant.ID).Error
+	g.Expect(errors.Is(err, gorm.ErrRecordNotFound)).To(BeTrue())
+	g.Expect(introspect("refresh-token").Active).To(BeFalse())
+	g.Expect(introspect(grantToken).Active).To(BeFalse())
+}
+
 // setupTestDB creates an in-memory SQLite database for testing.
 func setupTestDB() (db *gorm.DB, err error) {
 	db, err = database.OpenTest()
@@ -2799,6 +3534,8 @@ func setupTestDB() (db *gorm.DB, err error) {
 		&Grant{},
 		&RsaKey{},
 		&Identity{},
+		&model.Setting{},
+		&model.AuthEvent{},
 	)
 	return
 }
@@ -5153,3 +5890,157 @@ func TestSeedServiceAccountsIDPreservation(t *testing.T) {
 	g.Expect(sa2.ID).To(Equal(uint(1)))
 	g.Expect(sa2.Subject).To(Equal(subject1))
 }
+
+// TestServiceToken tests tokens minted for proxied services.
+func TestServiceToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+	provider, err := NewBuiltin(db, &Tenant{})
+	g.Expect(err).To(BeNil())
+	client := &IdpClient{
+		Subject:         uuid.New().String(),
+		ClientId:        "service-client",
+		Secret:          secret.HashPassword("secret"),
+		ApplicationType: "web",
+	}
+	err = db.Create(client).Error
+	g.Expect(err).To(BeNil())
+	provider.cache.ClientSaved(client)
+
+	// mint.
+	caller := Result{
+		Authenticated: true,
+		Subject:       uuid.New().String(),
+		User:          "tester",
+		Scopes: []Scope{
+			{Resource: "services.kai", Method: "get"},
+			{Resource: "applications", Method: "*"},
+		},
+	}
+	req := newTestRequest()
+	token, err := provider.ServiceToken(req.CTX.Request, "kai", caller)
+	g.Expect(err).To(BeNil())
+
+	// verify (JWKS).
+	jwToken, err := jwt.Parse(
+		token,
+		func(jwToken *jwt.Token) (key any, err error) {
+			kid, _ := jwToken.Header["kid"].(string)
+			for _, jwk := range provider.keySet.Keys {
+				if jwk.KeyID == kid {
+					privateKey := jwk.Key().(*rsa.PrivateKey)
+					key = &privateKey.PublicKey
+					return
+				}
+			}
+			err = &NotFound{Resource: kid}
+			return
+		})
+	g.Expect(err).To(BeNil())
+	claims := jwToken.Claims.(jwt.MapClaims)
+	g.Expect(claims[ClaimSub]).To(Equal(caller.Subject))
+	g.Expect(claims[ClaimUser]).To(Equal(caller.User))
+	g.Expect(claims[ClaimScope]).To(Equal("services.kai:get applications:*"))
+	g.Expect(claims[ClaimIss]).To(Equal(testIssuer()))
+	aud, err := jwToken.Claims.GetAudience()
+	g.Expect(err).To(BeNil())
+	g.Expect(aud).To(ConsistOf(ServiceAudience + "kai"))
+	exp, err := jwToken.Claims.GetExpirationTime()
+	g.Expect(err).To(BeNil())
+	g.Expect(exp.Time).To(BeTemporally("~", time.Now().Add(Settings.Service.Lifespan), time.Second))
+
+	// not valid for the hub.
+	req.Token = token
+	_, err = provider.Authenticate(req)
+	g.Expect(errors.Is(err, &NotAuthenticated{})).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("service"))
+
+	// introspection.
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	form := url.Values{"token": {token}}
+	ctx.Request = httptest.NewRequest(
+		http.MethodPost,
+		"http://localhost:8080/oidc/introspect",
+		strings.NewReader(form.Encode()))
+	ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	ctx.Request.SetBasicAuth(client.ClientId, "secret")
+	provider.TokenHandler().Introspect(ctx)
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+	r := TokenIntrospection{}
+	err = json.Unmarshal(w.Body.Bytes(), &r)
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Active).To(BeTrue())
+	g.Expect(r.Subject).To(Equal(caller.Subject))
+	g.Expect(r.Scope).To(Equal("services.kai:get applications:*"))
+}
+
+// TestRequestKind tests the kind of subject authenticated.
+func TestRequestKind(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	db, err := setupTestDB()
+	g.Expect(err).To(BeNil())
+	provider, err := NewBuiltin(db, &Tenant{})
+	g.Expect(err).To(BeNil())
+	savedIdp := Idp()
+	defer func() {
+		SetIdp(savedIdp)
+	}()
+	SetIdp(NewNoAuth(provider))
+
+	user := &User{
+		Subject: uuid.New().String(),
+		Login:   "kind-user",
+		Email:   "kind@example.com",
+	}
+	err = db.Create(user).Error
+	g.Expect(err).To(BeNil())
+	provider.cache.UserSaved(user)
+	sa := &ServiceAccount{
+		Subject: uuid.New().String(),
+		Name:    "kind-sa",
+	}
+	err = db.Create(sa).Error
+	g.Expect(err).To(BeNil())
+	provider.cache.SaSaved(sa)
+	task := &model.Task{Name: "kind"}
+	err = db.Create(task).Error
+	g.Expect(err).To(BeNil())
+
+	kind := func(subject, token string) (result Result) {
+		req := newTestRequest()
+		req.Token = token
+		result.Subject = subject
+		req.kind(&result)
+		return
+	}
+	// user.
+	pat, err := provider.NewToken(user.Subject, time.Hour)
+	g.Expect(err).To(BeNil())
+	result := kind(user.Subject, pat.Secret)
+	g.Expect(result.Kind).To(Equal(SubjectUser))
+	g.Expect(result.Task).To(BeZero())
+	// service account.
+	pat, err = provider.NewToken(sa.Subject, time.Hour)
+	g.Expect(err).To(BeNil())
+	result = kind(sa.Subject, pat.Secret)
+	g.Expect(result.Kind).To(Equal(SubjectServiceAccount))
+	g.Expect(result.Task).To(BeZero())
+	// task.
+	pat, err = provider.NewToken(
+		sa.Subject,
+		time.Hour,
+		func(m *Token) {
+			m.TaskID = &task.ID
+		})
+	g.Expect(err).To(BeNil())
+	result = kind(sa.Subject, pat.Secret)
+	g.Expect(result.Kind).To(Equal(SubjectTask))
+	g.Expect(result.Task).To(Equal(task.ID))
+	// unknown.
+	result = kind("", "")
+	g.Expect(result.Kind).To(Equal(SubjectUser))
+}
@@ -32,9 +32,29 @@ func (r *Request) Authenticate() (result Result, err error) {
 	result.Scopes = Idp().Scopes(jwToken)
 	result.User = Idp().User(jwToken)
 	result.Subject = Idp().Subject(jwToken)
+	r.kind(&result)
 	return
 }
 
+// kind sets the kind of subject authenticated.
+// Task tokens are PATs bound to a task.
+func (r *Request) kind(result *Result) {
+	result.Kind = SubjectUser
+	cache := Idp().Cache()
+	if r.Token != "" {
+		token, err := cache.FindToken(r.Token)
+		if err == nil && token.TaskID != nil {
+			result.Kind = SubjectTask
+			result.Task = *token.TaskID
+			return
+		}
+	}
+	subject, err := cache.FindSubject(result.Subject)
+	if err == nil && subject.ServiceAccount != nil {
+		result.Kind = SubjectServiceAccount
+	}
+}
+
 // With populates the request from the Authorization header.
 func (r *Request) With(header string) {
 	part := strings.Fields(header)
@@ -67,4 +87,8 @@ type Result struct {
 	Subject       string
 	User          string
 	Scopes        []Scope
+	// Kind of subject: user|serviceaccount|task.
+	Kind string
+	// Task to which the (task) token is bound.
+	Task uint
 }
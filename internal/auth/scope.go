@@ -1,6 +1,10 @@
 package auth
 
-import "strings"
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
 
 var verbs = []string{
 	"decrypt",
@@ -11,16 +15,27 @@ var verbs = []string{
 	"put",
 }
 
+// Constraint fields.
+const (
+	ConstraintBusinessService = "businessService"
+	ConstraintOwner           = "owner"
+	ConstraintContributor     = "contributor"
+)
+
+// ConstraintMe the authenticated user.
+const ConstraintMe = "me"
+
 // Scope provides scope behavior.
 type Scope struct {
-	Resource string
-	Method   string
+	Resource   string
+	Method     string
+	Constraint Constraint
 }
 
 // With parses a scope and populate fields.
-// Format: <resource>:<method>
+// Format: <resource>:<method>[:<constraint>]
 func (r *Scope) With(s string) {
-	part := strings.Split(s, ":")
+	part := strings.SplitN(s, ":", 3)
 	n := len(part)
 	if n > 0 {
 		r.Resource = part[0]
@@ -28,6 +43,38 @@ func (r *Scope) With(s string) {
 	if n > 1 {
 		r.Method = part[1]
 	}
+	if n > 2 {
+		r.Constraint.With(part[2])
+	}
+	return
+}
+
+// Base returns the scope without the constraint.
+func (r *Scope) Base() (base Scope) {
+	base = Scope{
+		Resource: r.Resource,
+		Method:   r.Method,
+	}
+	return
+}
+
+// Constrained returns true when the scope is constrained.
+func (r *Scope) Constrained() (b bool) {
+	b = !r.Constraint.Empty()
+	return
+}
+
+// Constrainable returns true when the scope may be constrained.
+// Constraints are supported by application (and application owned) resources.
+func (r *Scope) Constrainable() (b bool) {
+	switch r.Resource {
+	case "applications",
+		"analyses",
+		"assessments":
+		b = true
+	default:
+		b = strings.HasPrefix(r.Resource, "applications.")
+	}
 	return
 }
 
@@ -61,7 +108,13 @@ func (r *Scope) ExpandWith(resources []string) (expanded []Scope) {
 	}
 	for _, n := range nouns {
 		for _, m := range methods {
-			expanded = append(expanded, Scope{Resource: n, Method: m})
+			expanded = append(
+				expanded,
+				Scope{
+					Resource:   n,
+					Method:     m,
+					Constraint: r.Constraint,
+				})
 		}
 	}
 	return
@@ -76,6 +129,78 @@ func (r *Scope) Eq(a, b string) (eq bool) {
 // String representations of the scope.
 func (r *Scope) String() (s string) {
 	s = strings.Join([]string{r.Resource, r.Method}, ":")
+	if r.Constrained() {
+		s += ":" + r.Constraint.String()
+	}
+	return
+}
+
+// Constraint restricts a scope to resource instances.
+// Format: <field>=<value>[,<value>]
+// Fields:
+// - businessService: business service IDs.
+// - owner: me.
+// - contributor: me.
+type Constraint struct {
+	Field  string
+	Values []string
+}
+
+// With parses a constraint and populate fields.
+func (r *Constraint) With(s string) {
+	part := strings.SplitN(s, "=", 2)
+	r.Field = part[0]
+	r.Values = nil
+	if len(part) > 1 && part[1] != "" {
+		r.Values = strings.Split(part[1], ",")
+	}
+}
+
+// Empty returns true when not constrained.
+func (r *Constraint) Empty() (b bool) {
+	b = r.Field == ""
+	return
+}
+
+// Validate the constraint.
+func (r *Constraint) Validate() (err error) {
+	if len(r.Values) == 0 {
+		err = errors.New("constraint value required: " + r.Field)
+		return
+	}
+	switch r.Field {
+	case ConstraintBusinessService:
+		_, err = r.IDs()
+	case ConstraintOwner,
+		ConstraintContributor:
+		for _, v := range r.Values {
+			if v != ConstraintMe {
+				err = errors.New("constraint value must be: " + ConstraintMe)
+				return
+			}
+		}
+	default:
+		err = errors.New("constraint field not supported: " + r.Field)
+	}
+	return
+}
+
+// IDs returns the values as IDs.
+func (r *Constraint) IDs() (ids []uint, err error) {
+	for _, v := range r.Values {
+		n, pErr := strconv.ParseUint(v, 10, 0)
+		if pErr != nil {
+			err = errors.New("constraint value must be an ID: " + v)
+			return
+		}
+		ids = append(ids, uint(n))
+	}
+	return
+}
+
+// String representations of the constraint.
+func (r *Constraint) String() (s string) {
+	s = r.Field + "=" + strings.Join(r.Values, ",")
 	return
 }
 
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	liberr "github.com/jortel/go-utils/error"
+)
+
+// ServiceAudience the audience (prefix) of tokens minted for
+// proxied services. Format: service:<name>.
+// Service tokens are not valid for the hub API.
+const (
+	ServiceAudience = "service:"
+)
+
+// ServiceToken mints a (short-lived) token for a proxied service.
+// The token carries the caller subject, user and (granted) scopes and
+// is signed using the (OIDC) signing key. Services verify the token
+// using the JWKS or the introspection endpoint.
+func (p *Builtin) ServiceToken(request *http.Request, service string, caller Result) (token string, err error) {
+	key := p.keySet.SigningKey()
+	if key == nil {
+		err = liberr.New("signing key not found.")
+		return
+	}
+	scopes := make([]string, 0, len(caller.Scopes))
+	for _, scope := range caller.Scopes {
+		scopes = append(scopes, scope.String())
+	}
+	now := time.Now()
+	jwToken := jwt.New(jwt.SigningMethodRS256)
+	jwToken.Header["kid"] = key.ID()
+	claims := jwToken.Claims.(jwt.MapClaims)
+	claims[ClaimSub] = caller.Subject
+	claims[ClaimUser] = caller.User
+	claims[ClaimScope] = strings.Join(scopes, " ")
+	claims[ClaimAud] = ServiceAudience + service
+	claims[ClaimIss] = Issuer(request)
+	claims[ClaimIat] = now.Unix()
+	claims[ClaimExp] = now.Add(Settings.Service.Lifespan).Unix()
+	token, err = jwToken.SignedString(key.Key())
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+// notServiceToken returns an error when the token was minted
+// for a proxied service.
+func (p *Builtin) notServiceToken(jwToken *jwt.Token) (err error) {
+	audience, _ := jwToken.Claims.GetAudience()
+	for _, aud := range audience {
+		if strings.HasPrefix(aud, ServiceAudience) {
+			err = liberr.Wrap(
+				&NotValid{
+					Reason:  "Aud (service) not valid.",
+					TokenId: p.jti(jwToken),
+				})
+			return
+		}
+	}
+	return
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
+	"github.com/gin-gonic/gin"
 	"github.com/go-jose/go-jose/v4"
 	liberr "github.com/jortel/go-utils/error"
 	frontend "github.com/konveyor/tackle2-hub/internal/frontend/auth"
@@ -346,6 +347,7 @@ func (r *Storage) CreateAccessToken(
 		err = liberr.Wrap(err)
 		return
 	}
+	r.cache.TokenSaved(m)
 
 	if len(s.Scopes) == 0 {
 		Log.Info(
@@ -737,7 +739,9 @@ func (r *Storage) GetRefreshTokenInfo(
 }
 
 // Login handles the authentication flow.
-func (r *Storage) Login(writer http.ResponseWriter, request *http.Request, authReqId string) (err error) {
+func (r *Storage) Login(ctx *gin.Context, authReqId string) (err error) {
+	writer := ctx.Writer
+	request := ctx.Request
 	defer func() {
 		if err != nil {
 			Log.Error(err, "")
@@ -757,6 +761,7 @@ func (r *Storage) Login(writer http.ResponseWriter, request *http.Request, authR
 		domain:    r.domain,
 		cache:     r.cache,
 		dsHandler: r.dsHandler,
+		ctx:       ctx,
 		writer:    writer,
 		request:   request,
 		authReqId: authReqId,
@@ -765,6 +770,44 @@ func (r *Storage) Login(writer http.ResponseWriter, request *http.Request, authR
 	return
 }
 
+// mfaLogin returns the pending MFA step of the auth request.
+func (r *Storage) mfaLogin(id string) (m MfaLogin, found bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	authReq, found := r.authReqById[id]
+	if !found || authReq.mfa == nil || time.Now().After(authReq.expiration) {
+		found = false
+		return
+	}
+	m = *authReq.mfa
+	return
+}
+
+// setMfaLogin sets (or clears) the pending MFA step of the auth request.
+func (r *Storage) setMfaLogin(id string, m *MfaLogin) (err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	authReq, found := r.authReqById[id]
+	if !found {
+		err = oidc.ErrInvalidGrant().WithDescription("authRequest not-found.")
+		return
+	}
+	authReq.mfa = m
+	return
+}
+
+// mfaAuthenticated clears the pending MFA step and records
+// the authentication methods of the auth request.
+func (r *Storage) mfaAuthenticated(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	authReq, found := r.authReqById[id]
+	if found {
+		authReq.mfa = nil
+		authReq.amr = []string{"pwd", "otp", "mfa"}
+	}
+}
+
 // updateAuthRequest updates the auth request with authenticated subject.
 func (r *Storage) updateAuthRequest(id, subject, refreshToken string) (err error) {
 	r.mutex.Lock()
@@ -795,13 +838,17 @@ type Login struct {
 	domain    *Tenant
 	cache     *Cache
 	dsHandler *LdapHandler
+	ctx       *gin.Context
 	writer    http.ResponseWriter
 	request   *http.Request
 	authReqId string
 
 	login        string
 	password     string
+	code         string
+	method       string
 	subject      *Subject
+	pending      *MfaLogin
 	authErrorMsg string
 }
 
@@ -811,6 +858,10 @@ func (r *Login) complete() (err error) {
 	if err != nil {
 		return
 	}
+	if r.code != "" {
+		err = r.completeMfa()
+		return
+	}
 	if r.login == "" || r.password == "" {
 		_ = r.renderPage()
 		return
@@ -818,17 +869,29 @@ func (r *Login) complete() (err error) {
 	err = r.authenticate()
 	if err != nil {
 		Log.Info(err.Error())
-		r.authErrorMsg = "Invalid username or password."
+		r.audit().Failed(r.ctx, r.method, r.login, err)
+		if r.authErrorMsg == "" {
+			r.authErrorMsg = "Invalid username or password."
+		}
 		_ = r.renderPage()
 		err = nil
 		return
 	}
+	required, err := r.mfaRequired()
+	if err != nil {
+		return
+	}
+	if required {
+		err = r.beginMfa()
+		return
+	}
 	err = r.updateAuthRequest()
 	if err != nil {
 		_ = r.renderExpiredPage()
 		err = nil
 		return
 	}
+	r.audit().Succeeded(r.ctx, r.method, r.login, r.subject.Key)
 	r.redirect()
 	return
 }
@@ -842,60 +905,113 @@ func (r *Login) parseCredentials() (err error) {
 	}
 	r.login = r.request.PostFormValue("pf-login-username-id")
 	r.password = r.request.PostFormValue("pf-login-password-id")
+	r.code = r.request.PostFormValue("mfaCode")
 	return
 }
 
 // authenticate validates the user credentials.
-// Sets the Login.subject.
+// Sets the Login.subject and Login.method.
 func (r *Login) authenticate() (err error) {
-	for _, method := range []func() error{
-		r.authUser,
-		r.authLdapUser,
-	} {
-		err = method()
-		if err == nil {
-			// authenticated
-			return
-		}
+	r.method = MethodPassword
+	err = r.authUser()
+	if errors.Is(err, &NotFound{}) {
+		r.method = MethodLdap
+		err = r.authLdapUser()
 		if errors.Is(err, &NotFound{}) {
-			// next
-			continue
-		}
-		if errors.Is(err, &NotAuthenticated{}) {
-			// rejected
-			break
+			r.method = MethodPassword
 		}
 	}
 	return
 }
 
 // authUser authenticates a user.
+// Failed attempts are counted toward (progressive) lockout.
+// When MFA is required, the lockout is cleared by completeMfa.
 func (r *Login) authUser() (err error) {
 	user, err := r.cache.FindUserByLogin(r.login)
-	if err == nil {
-		if !secret.MatchPassword(r.password, user.Password) {
-			err = &NotAuthenticated{
-				Reason: "invalid password",
-				Token:  r.login,
-			}
-			return
+	if err != nil {
+		return
+	}
+	lockout := r.lockout()
+	if lockout.Locked(user) {
+		err = &NotAuthenticated{
+			Reason: "user locked",
+			Token:  r.login,
+		}
+		return
+	}
+	if !secret.MatchPassword(r.password, user.Password) {
+		err = &NotAuthenticated{
+			Reason: "invalid password",
+			Token:  r.login,
 		}
-		var scopes []string
-		scopes, err = r.cache.FindScopes(user.Subject)
+		r.failed(user)
+		return
+	}
+	if user.Disabled {
+		err = &NotAuthenticated{
+			Reason: "user disabled",
+			Token:  r.login,
+		}
+		return
+	}
+	passwords := Password{DB: r.storage.db}
+	policy, err := passwords.Policy()
+	if err != nil {
+		return
+	}
+	if policy.Expired(user) {
+		err = &NotAuthenticated{
+			Reason: "password expired",
+			Token:  r.login,
+		}
+		r.authErrorMsg = "Password expired. Contact your administrator."
+		return
+	}
+	mfa := r.mfa()
+	mfaRequired, err := mfa.Required(user)
+	if err != nil {
+		return
+	}
+	if !mfaRequired {
+		err = lockout.Succeeded(user)
 		if err != nil {
 			return
 		}
-		subject := &Subject{}
-		subject.WithUser(user, scopes)
-		r.subject = subject
+	}
+	scopes, err := r.cache.FindScopes(user.Subject)
+	if err != nil {
 		return
 	}
+	subject := &Subject{}
+	subject.WithUser(user, scopes)
+	r.subject = subject
 	return
 }
 
 // authLdapUser authenticates an LDAP user.
+// Failed attempts are counted toward (progressive) lockout.
 func (r *Login) authLdapUser() (err error) {
+	lockout := &LdapLockout{
+		DB:    r.storage.db,
+		Login: r.login,
+		IP:    SourceIP(r.ctx),
+	}
+	if lockout.Locked() {
+		err = &NotAuthenticated{
+			Reason: "user locked",
+			Token:  r.login,
+		}
+		return
+	}
 	r.subject, err = r.dsHandler.Authenticate(r.login, r.password)
+	if err != nil {
+		if errors.Is(err, &NotAuthenticated{}) {
+			r.ldapFailed(lockout)
+		}
+		return
+	}
+	lockout.Succeeded()
 	return
 }
 
@@ -905,11 +1021,172 @@ func (r *Login) updateAuthRequest() (err error) {
 	return
 }
 
+// mfaRequired returns true when MFA is required for the
+// authenticated (builtin) user.
+func (r *Login) mfaRequired() (required bool, err error) {
+	user := r.subject.User
+	if user == nil {
+		return
+	}
+	mfa := r.mfa()
+	required, err = mfa.Required(user)
+	return
+}
+
+// beginMfa records the pending MFA step and serves the MFA page.
+// Users not enrolled (required by policy) are enrolled using
+// a new secret. The secret is stored when confirmed.
+func (r *Login) beginMfa() (err error) {
+	user := r.subject.User
+	r.pending = &MfaLogin{
+		userId: user.ID,
+		login:  user.Login,
+	}
+	if !user.TotpEnabled {
+		r.pending.secret = NewTotpSecret()
+	}
+	err = r.storage.setMfaLogin(r.authReqId, r.pending)
+	if err != nil {
+		_ = r.renderExpiredPage()
+		err = nil
+		return
+	}
+	_ = r.renderMfaPage()
+	return
+}
+
+// completeMfa verifies the submitted code for the pending MFA step.
+// Locked users are rejected and the lockout is cleared on success.
+// On success, the auth request is updated with the authenticated user.
+// The recovery codes page is served when enrollment is completed.
+func (r *Login) completeMfa() (err error) {
+	pending, found := r.storage.mfaLogin(r.authReqId)
+	if !found {
+		_ = r.renderExpiredPage()
+		return
+	}
+	r.pending = &pending
+	mfa := r.mfa()
+	user, err := mfa.find(pending.userId)
+	if err != nil {
+		return
+	}
+	lockout := r.lockout()
+	if user.Disabled || lockout.Locked(user) {
+		_ = r.storage.setMfaLogin(r.authReqId, nil)
+		r.authErrorMsg = "Invalid username or password."
+		_ = r.renderPage()
+		return
+	}
+	var codes []string
+	if pending.secret == "" {
+		err = mfa.Verify(user.ID, r.code)
+	} else {
+		codes, err = mfa.confirm(user, pending.secret, r.code)
+	}
+	if err != nil {
+		if !errors.Is(err, &NotAuthenticated{}) &&
+			!errors.Is(err, &BadRequestError{}) {
+			return
+		}
+		Log.Info(err.Error())
+		r.audit().Failed(r.ctx, MethodPassword, user.Login, err)
+		r.failed(user)
+		err = nil
+		pending.attempts++
+		if pending.attempts >= MfaMaxAttempts {
+			_ = r.storage.setMfaLogin(r.authReqId, nil)
+			r.authErrorMsg = "Too many invalid codes."
+			_ = r.renderPage()
+			return
+		}
+		_ = r.storage.setMfaLogin(r.authReqId, &pending)
+		r.authErrorMsg = "Invalid code."
+		_ = r.renderMfaPage()
+		return
+	}
+	err = lockout.Succeeded(user)
+	if err != nil {
+		return
+	}
+	err = r.storage.updateAuthRequest(r.authReqId, user.Subject, "")
+	if err != nil {
+		_ = r.renderExpiredPage()
+		err = nil
+		return
+	}
+	r.storage.mfaAuthenticated(r.authReqId)
+	r.audit().Succeeded(r.ctx, MethodPassword, user.Login, user.Subject)
+	if len(codes) > 0 {
+		_ = r.renderRecoveryPage(codes)
+		return
+	}
+	r.redirect()
+	return
+}
+
+// mfa returns the MFA provider.
+func (r *Login) mfa() (mfa *Mfa) {
+	mfa = &Mfa{
+		DB:    r.storage.db,
+		Cache: r.cache,
+	}
+	return
+}
+
+// lockout returns the lockout provider.
+func (r *Login) lockout() (lockout *Lockout) {
+	lockout = &Lockout{
+		DB:    r.storage.db,
+		Cache: r.cache,
+	}
+	return
+}
+
+// failed records the failed attempt.
+func (r *Login) failed(user *User) {
+	lockout := r.lockout()
+	err := lockout.Failed(user)
+	if err != nil {
+		Log.Error(err, "")
+	}
+}
+
+// ldapFailed records the failed LDAP attempt.
+func (r *Login) ldapFailed(lockout *LdapLockout) {
+	err := lockout.Failed()
+	if err != nil {
+		Log.Error(err, "")
+	}
+}
+
+// audit returns the audit recorder.
+func (r *Login) audit() (audit *Audit) {
+	audit = &Audit{DB: r.storage.db}
+	return
+}
+
 // redirect redirects to the authorization callback.
 func (r *Login) redirect() {
+	http.Redirect(r.writer, r.request, r.callbackURL(), http.StatusFound)
+}
+
+// callbackURL returns the authorization callback URL.
+func (r *Login) callbackURL() (u string) {
 	issuer := AppendIssuer(r.request, api.AuthorizeCbRoute)
-	cbURL := fmt.Sprintf("%s?id=%s", issuer, r.authReqId)
-	http.Redirect(r.writer, r.request, cbURL, http.StatusFound)
+	u = fmt.Sprintf("%s?id=%s", issuer, r.authReqId)
+	return
+}
+
+// formAction returns the login form (POST) URL.
+func (r *Login) formAction() (u string) {
+	u = AppendIssuer(
+		r.request,
+		api.LoginRoute) +
+		"?" + AuthRequestId +
+		"=" +
+		r.authReqId
+	return
 }
 
 // renderExpiredPage serves the session-expired page when the auth request
@@ -935,16 +1212,9 @@ func (r *Login) renderPage() (err error) {
 			Log.Error(err, "")
 		}
 	}()
-	formAction := AppendIssuer(
-		r.request,
-		api.LoginRoute) +
-		"?" + AuthRequestId +
-		"=" +
-		r.authReqId
-
 	pageReq := frontend.Request{
 		Page:         frontend.Login,
-		FormAction:   formAction,
+		FormAction:   r.formAction(),
 		ErrorMessage: r.authErrorMsg,
 	}
 
@@ -969,6 +1239,56 @@ func (r *Login) renderPage() (err error) {
 	return
 }
 
+// renderMfaPage serves the MFA (code) page.
+// The enrollment page (secret) is served when enrolling.
+func (r *Login) renderMfaPage() (err error) {
+	defer func() {
+		if err != nil {
+			Log.Error(err, "")
+		}
+	}()
+	pageReq := frontend.Request{
+		Page:         frontend.Mfa,
+		FormAction:   r.formAction(),
+		ErrorMessage: r.authErrorMsg,
+	}
+	if r.pending.secret != "" {
+		pageReq.Page = frontend.MfaEnroll
+		pageReq.MfaSecret = r.pending.secret
+		pageReq.MfaURI = TotpURI(r.pending.login, r.pending.secret)
+	}
+	h := frontend.Handler{}
+	err = h.Render(r.writer, pageReq)
+	return
+}
+
+// renderRecoveryPage serves the recovery codes issued by enrollment
+// with a link to resume the login flow.
+func (r *Login) renderRecoveryPage(codes []string) (err error) {
+	defer func() {
+		if err != nil {
+			Log.Error(err, "")
+		}
+	}()
+	pageReq := frontend.Request{
+		Page:          frontend.MfaRecovery,
+		RecoveryCodes: codes,
+		ContinueURL:   r.callbackURL(),
+	}
+	h := frontend.Handler{}
+	err = h.Render(r.writer, pageReq)
+	return
+}
+
+// MfaLogin the pending MFA step of a login.
+type MfaLogin struct {
+	userId uint
+	login  string
+	// secret (plain) when enrolling.
+	secret   string
+	attempts int
+}
+
 // injectScopes adds user/identity scopes to the token request.
 func (r *Storage) injectScopes(req op.TokenRequest) (err error) {
 	subject := req.GetSubject()
@@ -1198,11 +1518,21 @@ func (r *Storage) grantByRefreshToken(_ context.Context, token string) (m *Grant
 }
 
 // deleteGrant deletes a grant by id.
+// The (cascade) deleted tokens are removed from the cache.
 func (r *Storage) deleteGrant(_ context.Context, id string) (err error) {
-	m := &Grant{}
-	err = r.db.Delete(m, "authId", id).Error
+	var list []*Grant
+	err = r.db.Find(&list, "authId", id).Error
 	if err != nil {
 		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range list {
+		err = r.db.Delete(m).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		r.cache.GrantDeleted(m.ID)
 	}
 	return
 }
@@ -1656,6 +1986,8 @@ type AuthRequest struct {
 	done            bool
 	idpRefreshToken string
 	idpIdentityId   uint
+	mfa             *MfaLogin
+	amr             []string
 }
 
 // GetID returns the request ID.
@@ -1672,7 +2004,10 @@ func (a *AuthRequest) GetACR() (s string) {
 
 // GetAMR returns the AMR.
 func (a *AuthRequest) GetAMR() (amr []string) {
-	amr = []string{"pwd"}
+	amr = a.amr
+	if len(amr) == 0 {
+		amr = []string{"pwd"}
+	}
 	return
 }
 
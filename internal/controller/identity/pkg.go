@@ -0,0 +1,103 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	logr2 "github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/internal/identity"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"gorm.io/gorm"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/storage/names"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	Name = "identity"
+)
+
+var Log = logr2.WithName(Name)
+
+// Add the controller.
+func Add(mgr manager.Manager, db *gorm.DB) (err error) {
+	reconciler := &Reconciler{
+		Client: mgr.GetClient(),
+		Log:    Log,
+		DB:     db,
+	}
+	cnt, err := controller.New(
+		Name,
+		mgr,
+		controller.Options{
+			Reconciler: reconciler,
+		})
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	err = cnt.Watch(
+		source.Kind(
+			mgr.GetCache(),
+			&core.Secret{},
+			&handler.TypedEnqueueRequestForObject[*core.Secret]{}))
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+
+	return
+}
+
+// Reconciler reconciles secrets referenced by identities.
+type Reconciler struct {
+	k8s.Client
+	DB  *gorm.DB
+	Log logr.Logger
+}
+
+// Reconcile a Secret.
+// The cached secret is discarded so that identities are
+// resolved using the current content.
+// Note: Must not be a pointer receiver to ensure that the
+// logger and other state is not shared.
+func (r Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (result reconcile.Result, err error) {
+	r.Log = logr2.WithName(
+		names.SimpleNameGenerator.GenerateName(Name+"|"),
+		"secret",
+		request)
+	identity.Changed(request.Name)
+	referenced, err := r.referenced(request.Name)
+	if err != nil {
+		return
+	}
+	if len(referenced) > 0 {
+		r.Log.Info(
+			"Secret referenced by identities changed.",
+			"identities",
+			referenced)
+	}
+	return
+}
+
+// referenced returns the names of identities that reference the secret.
+func (r *Reconciler) referenced(name string) (referenced []string, err error) {
+	var list []model.Identity
+	db := r.DB.Select("ID", "Name", "Secret")
+	db = db.Where("Secret IS NOT NULL")
+	err = db.Find(&list).Error
+	if err != nil {
+		return
+	}
+	for _, m := range list {
+		if m.Secret != nil && m.Secret.Name == name {
+			referenced = append(referenced, m.Name)
+		}
+	}
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/jortel/go-utils/logr"
 	"github.com/konveyor/tackle2-hub/internal/controller/addon"
 	"github.com/konveyor/tackle2-hub/internal/controller/client"
+	"github.com/konveyor/tackle2-hub/internal/controller/identity"
 	"github.com/konveyor/tackle2-hub/internal/controller/idp"
 	"github.com/konveyor/tackle2-hub/internal/controller/ldap"
 	"gorm.io/gorm"
@@ -37,5 +38,9 @@ func Add(mgr manager.Manager, db *gorm.DB) (err error) {
 	if err != nil {
 		return
 	}
+	err = identity.Add(mgr, db)
+	if err != nil {
+		return
+	}
 	return
 }
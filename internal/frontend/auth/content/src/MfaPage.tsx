@@ -0,0 +1,113 @@
+import React, { useState } from "react";
+import {
+  ActionGroup,
+  Alert,
+  Button,
+  ClipboardCopy,
+  Form,
+  FormGroup,
+  FormHelperText,
+  HelperText,
+  HelperTextItem,
+  TextInput,
+  LoginPage as PFLoginPage,
+} from "@patternfly/react-core";
+import { ExclamationCircleIcon } from "@patternfly/react-icons";
+
+import { brandingStrings } from "./branding";
+import type { LoginConfig } from "./types";
+
+interface MfaPageProps {
+  config: LoginConfig;
+}
+
+// MfaPage renders the second (TOTP) login step using PatternFly 6 components.
+// When enrolling ("mfa-enroll"), the secret and otpauth URI are shown so the
+// key can be added to an authenticator app before the code is entered.
+// A recovery code may be entered in place of the authenticator code.
+export const MfaPage: React.FC<MfaPageProps> = ({ config }) => {
+  const [code, setCode] = useState("");
+
+  const { page, formAction, errorMessage, mfaSecret, mfaUri } = config;
+  const { mfaPage, styles, application } = brandingStrings;
+
+  const enrolling = page === "mfa-enroll";
+  const hasError = Boolean(errorMessage);
+
+  const title = enrolling
+    ? mfaPage?.enrollTitle ?? "Set up two-factor authentication"
+    : mfaPage?.title ?? "Two-factor authentication";
+  const subtitle = enrolling
+    ? mfaPage?.enrollSubtitle ??
+      "Add the key to your authenticator app and enter the code"
+    : mfaPage?.subtitle ?? "Enter the code from your authenticator app";
+
+  return (
+    <PFLoginPage
+      loginTitle={title}
+      loginSubtitle={subtitle}
+      backgroundImgSrc={styles?.backgroundImage ?? ""}
+      brandImgProps={{
+        src: styles?.brandImage ?? "",
+        alt: application?.name ?? application?.title ?? "",
+        widths: { default: "260px" }
+      }}
+    >
+      {enrolling && (
+        <>
+          <Alert
+            variant="info"
+            isInline
+            isPlain
+            title="Two-factor authentication is required for your account."
+            style={{ marginBottom: "var(--pf-t--global--spacer--md)" }}
+          />
+          <FormGroup label="Key" fieldId="mfaSecret">
+            <ClipboardCopy id="mfaSecret" isReadOnly hoverTip="Copy" clickTip="Copied">
+              {mfaSecret ?? ""}
+            </ClipboardCopy>
+          </FormGroup>
+          <FormGroup label="Key URI" fieldId="mfaUri">
+            <ClipboardCopy id="mfaUri" isReadOnly hoverTip="Copy" clickTip="Copied">
+              {mfaUri ?? ""}
+            </ClipboardCopy>
+          </FormGroup>
+        </>
+      )}
+      <Form method="post" action={formAction ?? ""} id="pf-mfa-form-id">
+        <FormGroup label="Code" fieldId="mfaCode">
+          <TextInput
+            id="mfaCode"
+            name="mfaCode"
+            type="text"
+            autoComplete="one-time-code"
+            value={code}
+            onChange={(_e, v) => setCode(v)}
+            validated={hasError ? "error" : "default"}
+            autoFocus
+            required
+          />
+          {hasError && (
+            <FormHelperText>
+              <HelperText>
+                <HelperTextItem icon={<ExclamationCircleIcon />} variant="error">
+                  {errorMessage}
+                </HelperTextItem>
+              </HelperText>
+            </FormHelperText>
+          )}
+        </FormGroup>
+        <ActionGroup>
+          <Button
+            variant="primary"
+            type="submit"
+            isBlock
+            isDisabled={code.trim().length === 0}
+          >
+            Verify
+          </Button>
+        </ActionGroup>
+      </Form>
+    </PFLoginPage>
+  );
+};
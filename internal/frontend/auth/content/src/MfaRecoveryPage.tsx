@@ -0,0 +1,62 @@
+import React from "react";
+import {
+  ActionGroup,
+  Alert,
+  Button,
+  ClipboardCopy,
+  ClipboardCopyVariant,
+  LoginPage as PFLoginPage,
+} from "@patternfly/react-core";
+
+import { brandingStrings } from "./branding";
+import type { LoginConfig } from "./types";
+
+interface MfaRecoveryPageProps {
+  config: LoginConfig;
+}
+
+// MfaRecoveryPage renders the recovery codes issued when MFA enrollment is
+// completed during login. The codes are shown once; the continue link
+// resumes the login flow.
+export const MfaRecoveryPage: React.FC<MfaRecoveryPageProps> = ({ config }) => {
+  const { recoveryCodes, continueUrl } = config;
+  const { mfaPage, styles, application } = brandingStrings;
+
+  return (
+    <PFLoginPage
+      loginTitle={mfaPage?.recoveryTitle ?? "Save your recovery codes"}
+      backgroundImgSrc={styles?.backgroundImage ?? ""}
+      brandImgProps={{
+        src: styles?.brandImage ?? "",
+        alt: application?.name ?? application?.title ?? "",
+        widths: { default: "260px" }
+      }}
+    >
+      <Alert
+        variant="warning"
+        isInline
+        isPlain
+        title={
+          mfaPage?.recoveryMessage ??
+          "Each code may be used once in place of an authenticator code. They will not be shown again."
+        }
+        style={{ marginBottom: "var(--pf-t--global--spacer--md)" }}
+      />
+      <ClipboardCopy
+        isReadOnly
+        isCode
+        variant={ClipboardCopyVariant.expansion}
+        isExpanded
+        hoverTip="Copy"
+        clickTip="Copied"
+      >
+        {(recoveryCodes ?? []).join("\n")}
+      </ClipboardCopy>
+      <ActionGroup>
+        <Button component="a" variant="primary" href={continueUrl ?? ""} isBlock>
+          Continue
+        </Button>
+      </ActionGroup>
+    </PFLoginPage>
+  );
+};
@@ -23,6 +23,14 @@ export interface LoginBrandingStrings {
     successTitle: string;
     successMessage: string;
   };
+  mfaPage?: {
+    title?: string;
+    subtitle?: string;
+    enrollTitle?: string;
+    enrollSubtitle?: string;
+    recoveryTitle?: string;
+    recoveryMessage?: string;
+  };
   styles: {
     brandImage?: string;
     backgroundImage?: string;
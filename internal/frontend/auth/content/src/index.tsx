@@ -5,6 +5,8 @@ import { UserLoginPage } from "./UserLoginPage";
 import { DeviceVerifyPage } from "./DeviceVerifyPage";
 import { DeviceSuccessPage } from "./DeviceSuccessPage";
 import { SessionExpiredPage } from "./SessionExpiredPage";
+import { MfaPage } from "./MfaPage";
+import { MfaRecoveryPage } from "./MfaRecoveryPage";
 import type { LoginConfig } from "./types";
 
 import "@patternfly/react-core/dist/styles/base.css";
@@ -34,6 +36,11 @@ const App = () => {
       return <DeviceSuccessPage config={config} />;
     case "session-expired":
       return <SessionExpiredPage config={config} />;
+    case "mfa":
+    case "mfa-enroll":
+      return <MfaPage config={config} />;
+    case "mfa-recovery":
+      return <MfaRecoveryPage config={config} />;
     default:
       return <UserLoginPage config={config} />;
   }
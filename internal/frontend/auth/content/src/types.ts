@@ -3,7 +3,14 @@
 // background images) is baked into the bundle at build time.
 export interface LoginConfig {
   // Selects which page component to render.
-  page: "login" | "device-verify" | "device-success" | "session-expired";
+  page:
+    | "login"
+    | "device-verify"
+    | "device-success"
+    | "session-expired"
+    | "mfa"
+    | "mfa-enroll"
+    | "mfa-recovery";
 
   // Login page: POST target URL including authRequestId query param.
   formAction?: string;
@@ -21,6 +28,18 @@ export interface LoginConfig {
 
   // Device verify page: POST target URL for user-code form submission.
   deviceFormAction?: string;
+
+  // MFA enroll page: TOTP secret to be entered into an authenticator app.
+  mfaSecret?: string;
+
+  // MFA enroll page: otpauth key URI for the authenticator app.
+  mfaUri?: string;
+
+  // MFA recovery page: recovery codes issued (once) by enrollment.
+  recoveryCodes?: string[];
+
+  // MFA recovery page: link used to resume the login flow.
+  continueUrl?: string;
 }
 
 declare global {
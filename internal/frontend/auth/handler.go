@@ -20,6 +20,9 @@ const (
 	DeviceVerify    = "device-verify"
 	DeviceSucceeded = "device-success"
 	SessionExpired  = "session-expired"
+	Mfa             = "mfa"
+	MfaEnroll       = "mfa-enroll"
+	MfaRecovery     = "mfa-recovery"
 )
 
 const (
@@ -63,6 +66,18 @@ type Request struct {
 
 	// DeviceFormAction is the POST target URL for the device code form.
 	DeviceFormAction string `json:"deviceFormAction,omitempty"`
+
+	// MfaSecret is the TOTP secret shown during enrollment (mfa-enroll page only).
+	MfaSecret string `json:"mfaSecret,omitempty"`
+
+	// MfaURI is the otpauth key URI shown during enrollment (mfa-enroll page only).
+	MfaURI string `json:"mfaUri,omitempty"`
+
+	// RecoveryCodes are shown once after enrollment (mfa-recovery page only).
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+
+	// ContinueURL is the link used to resume the login flow (mfa-recovery page only).
+	ContinueURL string `json:"continueUrl,omitempty"`
 }
 
 // FedIdp describes the external identity provider button.
@@ -0,0 +1,203 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/shared/settings"
+	core "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var Settings = &settings.Settings
+
+// LabelIdentity the label required on secrets referenced by identities.
+const LabelIdentity = "konveyor.io/identity"
+
+// Default (secret) keys.
+const (
+	KeyUser     = "username"
+	KeyPassword = "password"
+	KeyKey      = "ssh-privatekey"
+	KeySettings = "settings"
+)
+
+// secrets (data) cache.
+var secrets = Cache{
+	content: make(map[string]map[string][]byte),
+}
+
+// NotResolved reports an identity that cannot be resolved.
+type NotResolved struct {
+	Identity string
+	Secret   string
+	Reason   string
+}
+
+func (e *NotResolved) Error() string {
+	return fmt.Sprintf(
+		"identity '%s' secret '%s' not resolved: %s",
+		e.Identity,
+		e.Secret,
+		e.Reason)
+}
+
+func (e *NotResolved) Is(err error) (matched bool) {
+	var target *NotResolved
+	matched = errors.As(err, &target)
+	return
+}
+
+// NotLabeled reports a secret not labeled for use by identities.
+type NotLabeled struct {
+	Secret string
+}
+
+func (e *NotLabeled) Error() string {
+	return fmt.Sprintf(
+		"secret '%s' not labeled: %s.",
+		e.Secret,
+		LabelIdentity)
+}
+
+func (e *NotLabeled) Is(err error) (matched bool) {
+	var target *NotLabeled
+	matched = errors.As(err, &target)
+	return
+}
+
+// Resolver resolves identities backed by (k8s) secrets.
+type Resolver struct {
+	Client k8s.Client
+}
+
+// Resolve the identity.
+// The user, password, key and settings are set using the
+// referenced secret. The identity must be decrypted.
+func (r *Resolver) Resolve(m *model.Identity) (err error) {
+	ref := m.Secret
+	if ref == nil || ref.Name == "" {
+		return
+	}
+	data, err := r.data(ref.Name)
+	if err != nil {
+		reason := err.Error()
+		if k8serr.IsNotFound(err) {
+			reason = "not found."
+		}
+		err = &NotResolved{
+			Identity: m.Name,
+			Secret:   ref.Name,
+			Reason:   reason,
+		}
+		return
+	}
+	fields := []struct {
+		key   string
+		def   string
+		value *string
+	}{
+		{key: ref.User, def: KeyUser, value: &m.User},
+		{key: ref.Password, def: KeyPassword, value: &m.Password},
+		{key: ref.Key, def: KeyKey, value: &m.Key},
+		{key: ref.Settings, def: KeySettings, value: &m.Settings},
+	}
+	for _, f := range fields {
+		key := f.key
+		if key == "" {
+			key = f.def
+		}
+		v, found := data[key]
+		if found {
+			*f.value = string(v)
+			continue
+		}
+		if f.key != "" {
+			err = &NotResolved{
+				Identity: m.Name,
+				Secret:   ref.Name,
+				Reason:   fmt.Sprintf("key '%s' not found.", key),
+			}
+			return
+		}
+	}
+	return
+}
+
+// Validate the referenced secret.
+// The secret must be labeled (konveyor.io/identity). Secrets not
+// (yet) created are permitted and reported when resolved.
+func (r *Resolver) Validate(name string) (err error) {
+	_, err = r.data(name)
+	if k8serr.IsNotFound(err) {
+		err = nil
+	}
+	return
+}
+
+// data returns the secret data.
+// Only secrets labeled (konveyor.io/identity) are used.
+// Fetched secrets are cached until changed.
+func (r *Resolver) data(name string) (data map[string][]byte, err error) {
+	data, found := secrets.Get(name)
+	if found {
+		return
+	}
+	secret := &core.Secret{}
+	err = r.Client.Get(
+		context.TODO(),
+		k8s.ObjectKey{
+			Namespace: Settings.Hub.Namespace,
+			Name:      name,
+		},
+		secret)
+	if err != nil {
+		return
+	}
+	_, found = secret.Labels[LabelIdentity]
+	if !found {
+		err = &NotLabeled{Secret: name}
+		return
+	}
+	data = secret.Data
+	secrets.Put(name, data)
+	return
+}
+
+// Changed notifies that a secret has changed.
+// The cached secret is discarded.
+func Changed(name string) {
+	secrets.Delete(name)
+}
+
+// Cache of secret data by name.
+type Cache struct {
+	content map[string]map[string][]byte
+	mutex   sync.RWMutex
+}
+
+// Get cached secret data.
+func (c *Cache) Get(name string) (data map[string][]byte, found bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	data, found = c.content[name]
+	return
+}
+
+// Put secret data.
+func (c *Cache) Put(name string, data map[string][]byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.content[name] = data
+}
+
+// Delete cached secret data.
+func (c *Cache) Delete(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.content, name)
+}
@@ -0,0 +1,120 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/internal/k8s/simulator"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/onsi/gomega"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolve(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	client := simulator.New()
+	secret := &core.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "git-creds",
+			Namespace: Settings.Hub.Namespace,
+			Labels:    map[string]string{LabelIdentity: ""},
+		},
+		Data: map[string][]byte{
+			KeyUser:  []byte("elmer"),
+			KeyKey:   []byte("ssh-key"),
+			"pass":   []byte("rabbit"),
+			"config": []byte("<settings/>"),
+		},
+	}
+	err := client.Create(context.TODO(), secret)
+	g.Expect(err).To(gomega.BeNil())
+	resolver := Resolver{Client: client}
+
+	// not secret backed.
+	m := &model.Identity{Name: "local", User: "bugs"}
+	err = resolver.Resolve(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.User).To(gomega.Equal("bugs"))
+
+	// default and named keys.
+	m = &model.Identity{
+		Name: "git",
+		Secret: &model.IdentitySecret{
+			Name:     secret.Name,
+			Password: "pass",
+			Settings: "config",
+		},
+	}
+	err = resolver.Resolve(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.User).To(gomega.Equal("elmer"))
+	g.Expect(m.Password).To(gomega.Equal("rabbit"))
+	g.Expect(m.Key).To(gomega.Equal("ssh-key"))
+	g.Expect(m.Settings).To(gomega.Equal("<settings/>"))
+
+	// named key not found.
+	m = &model.Identity{
+		Name: "git",
+		Secret: &model.IdentitySecret{
+			Name: secret.Name,
+			User: "missing",
+		},
+	}
+	err = resolver.Resolve(m)
+	g.Expect(errors.Is(err, &NotResolved{})).To(gomega.BeTrue())
+
+	// secret not found.
+	m = &model.Identity{
+		Name:   "git",
+		Secret: &model.IdentitySecret{Name: "missing"},
+	}
+	err = resolver.Resolve(m)
+	g.Expect(errors.Is(err, &NotResolved{})).To(gomega.BeTrue())
+
+	// secret not labeled.
+	unlabeled := &core.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "other",
+			Namespace: Settings.Hub.Namespace,
+		},
+		Data: map[string][]byte{
+			KeyUser: []byte("elmer"),
+		},
+	}
+	err = client.Create(context.TODO(), unlabeled)
+	g.Expect(err).To(gomega.BeNil())
+	m = &model.Identity{
+		Name:   "git",
+		Secret: &model.IdentitySecret{Name: unlabeled.Name},
+	}
+	err = resolver.Resolve(m)
+	g.Expect(errors.Is(err, &NotResolved{})).To(gomega.BeTrue())
+	g.Expect(m.User).To(gomega.BeEmpty())
+
+	// validate.
+	err = resolver.Validate(secret.Name)
+	g.Expect(err).To(gomega.BeNil())
+	err = resolver.Validate("missing")
+	g.Expect(err).To(gomega.BeNil())
+	err = resolver.Validate(unlabeled.Name)
+	g.Expect(errors.Is(err, &NotLabeled{})).To(gomega.BeTrue())
+
+	// changed.
+	secret.Data[KeyUser] = []byte("daffy")
+	err = client.Update(context.TODO(), secret)
+	g.Expect(err).To(gomega.BeNil())
+	m = &model.Identity{
+		Name:   "git",
+		Secret: &model.IdentitySecret{Name: secret.Name},
+	}
+	err = resolver.Resolve(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.User).To(gomega.Equal("elmer"))
+	Changed(secret.Name)
+	err = resolver.Resolve(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.User).To(gomega.Equal("daffy"))
+}
@@ -0,0 +1,258 @@
+package verify
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	urllib "net/url"
+	"os"
+	"time"
+
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/identity"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/internal/scm"
+	"github.com/konveyor/tackle2-hub/internal/secret"
+	"gorm.io/gorm"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Identity kinds.
+const (
+	KindSource = "source"
+	KindMaven  = "maven"
+)
+
+// Target of maven identities.
+const (
+	MavenSettings = "settings.xml"
+)
+
+// Failed reports failed verification.
+type Failed struct {
+	Reason string
+}
+
+func (e *Failed) Error() string {
+	return e.Reason
+}
+
+func (e *Failed) Is(err error) (matched bool) {
+	var target *Failed
+	matched = errors.As(err, &target)
+	return
+}
+
+// NotSupported reports identity kinds that cannot be verified.
+type NotSupported struct {
+	Kind string
+}
+
+func (e *NotSupported) Error() string {
+	return fmt.Sprintf("identity kind: '%s' cannot be verified.", e.Kind)
+}
+
+func (e *NotSupported) Is(err error) (matched bool) {
+	var target *NotSupported
+	matched = errors.As(err, &target)
+	return
+}
+
+// Verifier verifies identity credentials against the target.
+type Verifier struct {
+	DB     *gorm.DB
+	Client k8s.Client
+}
+
+// Verify the identity credentials.
+// Source identities are verified by accessing the repository
+// (git ls-remote|svn info) with the proxies applied. When the
+// application is not specified, the first application (with a
+// repository) referencing the identity is used.
+// Maven identities are verified by parsing the settings.xml.
+// Other kinds are not supported (NotSupported).
+// Failed verification is reported in the result; the returned error
+// reports failures not related to the credentials.
+func (r *Verifier) Verify(m *model.Identity, app *model.Application) (v model.IdentityVerification, err error) {
+	v.Verified = time.Now()
+	if app != nil {
+		v.Application = app.ID
+	}
+	vErr := r.verify(m, app, &v)
+	if vErr != nil {
+		if errors.Is(vErr, &Failed{}) ||
+			errors.Is(vErr, &identity.NotResolved{}) {
+			v.Error = vErr.Error()
+			return
+		}
+		err = vErr
+		return
+	}
+	v.Succeeded = true
+	return
+}
+
+// verify the credentials by kind.
+func (r *Verifier) verify(m *model.Identity, app *model.Application, v *model.IdentityVerification) (err error) {
+	switch m.Kind {
+	case KindSource,
+		KindMaven:
+	default:
+		err = &NotSupported{Kind: m.Kind}
+		return
+	}
+	err = secret.Decrypt(m)
+	if err != nil {
+		err = &Failed{Reason: err.Error()}
+		return
+	}
+	resolver := identity.Resolver{Client: r.Client}
+	err = resolver.Resolve(m)
+	if err != nil {
+		return
+	}
+	switch m.Kind {
+	case KindSource:
+		if app == nil {
+			app, err = r.application(m)
+			if err != nil {
+				return
+			}
+			v.Application = app.ID
+		}
+		v.Target = app.Repository.URL
+		err = r.source(m, app)
+	case KindMaven:
+		v.Target = MavenSettings
+		err = r.maven(m)
+	}
+	return
+}
+
+// source verifies the repository can be accessed.
+func (r *Verifier) source(m *model.Identity, app *model.Application) (err error) {
+	repository := app.Repository
+	if repository.URL == "" {
+		err = &Failed{Reason: "application repository not defined."}
+		return
+	}
+	tmpDir, err := os.MkdirTemp("", "verify-")
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+	remote := scm.Remote{
+		Kind:   repository.Kind,
+		URL:    repository.URL,
+		Branch: repository.Branch,
+		Path:   repository.Path,
+		Identity: &scm.Identity{
+			ID:       m.ID,
+			Name:     m.Name,
+			User:     m.User,
+			Password: m.Password,
+			Key:      m.Key,
+		},
+	}
+	repo, err := scm.New(r.DB, r.Client, tmpDir, remote)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			err = &Failed{Reason: err.Error()}
+		}
+		return
+	}
+	defer func() {
+		_ = repo.Clean()
+	}()
+	err = repo.Verify()
+	if err != nil {
+		err = &Failed{Reason: err.Error()}
+		return
+	}
+	return
+}
+
+// maven verifies the settings.xml can be parsed.
+func (r *Verifier) maven(m *model.Identity) (err error) {
+	if m.Settings == "" {
+		err = &Failed{Reason: "settings not defined."}
+		return
+	}
+	settings := MavenSettingsXML{}
+	err = xml.Unmarshal([]byte(m.Settings), &settings)
+	if err != nil {
+		err = &Failed{Reason: "settings not valid: " + err.Error()}
+		return
+	}
+	err = settings.Validate()
+	return
+}
+
+// application returns the first application (with a repository)
+// referencing the identity.
+func (r *Verifier) application(m *model.Identity) (app *model.Application, err error) {
+	var list []model.Application
+	db := r.DB.Joins("JOIN ApplicationIdentity j ON j.ApplicationID = Application.ID")
+	db = db.Where("j.IdentityID", m.ID)
+	db = db.Order("Application.ID")
+	err = db.Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for i := range list {
+		if list[i].Repository.URL != "" {
+			app = &list[i]
+			return
+		}
+	}
+	err = &Failed{Reason: "no application (repository) references the identity."}
+	return
+}
+
+// MavenSettingsXML the (relevant) content of the maven settings.xml.
+type MavenSettingsXML struct {
+	XMLName xml.Name `xml:"settings"`
+	Servers []struct {
+		ID string `xml:"id"`
+	} `xml:"servers>server"`
+	Mirrors []struct {
+		ID  string `xml:"id"`
+		URL string `xml:"url"`
+	} `xml:"mirrors>mirror"`
+	Repositories []struct {
+		ID  string `xml:"id"`
+		URL string `xml:"url"`
+	} `xml:"profiles>profile>repositories>repository"`
+}
+
+// Validate the settings.
+// Servers must have an ID; mirror and repository URLs must be valid.
+func (s *MavenSettingsXML) Validate() (err error) {
+	for _, server := range s.Servers {
+		if server.ID == "" {
+			err = &Failed{Reason: "settings: server id required."}
+			return
+		}
+	}
+	var urls [][2]string
+	for _, mirror := range s.Mirrors {
+		urls = append(urls, [2]string{"mirror:" + mirror.ID, mirror.URL})
+	}
+	for _, repository := range s.Repositories {
+		urls = append(urls, [2]string{"repository:" + repository.ID, repository.URL})
+	}
+	for _, url := range urls {
+		parsed, pErr := urllib.Parse(url[1])
+		if pErr != nil || parsed.Scheme == "" || parsed.Host == "" {
+			err = &Failed{
+				Reason: fmt.Sprintf("settings: %s url '%s' not valid.", url[0], url[1]),
+			}
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,76 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/konveyor/tackle2-hub/internal/secret"
+	"github.com/onsi/gomega"
+)
+
+func TestVerifyMaven(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	verifier := Verifier{}
+	cases := []struct {
+		settings  string
+		succeeded bool
+	}{
+		{
+			settings: `
+<settings>
+  <servers>
+    <server><id>central</id><username>u</username></server>
+  </servers>
+  <mirrors>
+    <mirror><id>internal</id><url>https://maven.example.com/repo</url></mirror>
+  </mirrors>
+</settings>`,
+			succeeded: true,
+		},
+		{
+			settings:  "",
+			succeeded: false,
+		},
+		{
+			settings:  "<settings><servers>",
+			succeeded: false,
+		},
+		{
+			settings:  "<project/>",
+			succeeded: false,
+		},
+		{
+			settings:  "<settings><servers><server/></servers></settings>",
+			succeeded: false,
+		},
+		{
+			settings:  "<settings><mirrors><mirror><id>m</id><url>nowhere</url></mirror></mirrors></settings>",
+			succeeded: false,
+		},
+	}
+	for _, c := range cases {
+		m := &model.Identity{Kind: KindMaven, Settings: c.settings}
+		err := secret.Encrypt(m)
+		g.Expect(err).To(gomega.BeNil())
+		v, err := verifier.Verify(m, nil)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(v.Succeeded).To(gomega.Equal(c.succeeded), c.settings)
+		g.Expect(v.Target).To(gomega.Equal(MavenSettings))
+		g.Expect(v.Verified.IsZero()).To(gomega.BeFalse())
+		if !c.succeeded {
+			g.Expect(v.Error).ToNot(gomega.BeEmpty())
+		}
+	}
+}
+
+func TestVerifyNotSupported(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	verifier := Verifier{}
+	m := &model.Identity{Kind: "proxy"}
+	v, err := verifier.Verify(m, nil)
+	g.Expect(errors.Is(err, &NotSupported{})).To(gomega.BeTrue())
+	g.Expect(v.Succeeded).To(gomega.BeFalse())
+}
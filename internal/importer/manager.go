@@ -2,66 +2,405 @@ package importer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
-
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	liberr "github.com/jortel/go-utils/error"
+	"github.com/jortel/go-utils/logr"
 	"github.com/konveyor/tackle2-hub/internal/api"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	tasking "github.com/konveyor/tackle2-hub/internal/task"
 	"github.com/konveyor/tackle2-hub/internal/trigger"
 	"github.com/konveyor/tackle2-hub/shared/settings"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var (
+	Log      = logr.New("importer", 0)
 	Settings = &settings.Settings
 )
 
+// FactSource the source of imported facts.
+const FactSource = "import"
+
+// Import actions.
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+)
+
+// ErrRollback rolls back a (failed) row or import.
+var ErrRollback = errors.New("rollback")
+
 // Manager for processing application imports.
 type Manager struct {
 	// DB
 	DB          *gorm.DB
 	TaskManager *tasking.Manager
 	Client      k8sclient.Client
+	// wake notifications.
+	wake chan struct{}
+	// deferred application triggers.
+	launched []launch
+}
+
+// launch a deferred application trigger.
+type launch struct {
+	imp     *model.Import
+	app     *model.Application
+	created bool
 }
 
 // Run the manager.
 func (m *Manager) Run(ctx context.Context) {
+	m.wake = make(chan struct{}, 1)
 	go func() {
+		Log.Info("Started.")
+		defer Log.Info("Stopped.")
 		for {
+			err := m.processImports()
+			if err != nil {
+				Log.Error(err, "")
+			}
 			select {
 			case <-ctx.Done():
 				return
-			default:
-				time.Sleep(time.Second)
-				_ = m.processImports()
+			case <-m.wake:
+			case <-time.After(Settings.Frequency.Import):
 			}
 		}
 	}()
 }
 
-// processImports creates applications and dependencies from
-// unprocessed imports.
+// Wake the manager.
+// Notifies the manager that imports are ready to be processed.
+func (m *Manager) Wake() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// processImports processes unprocessed imports by summary.
+// Dry-run imports are validated only.
 func (m *Manager) processImports() (err error) {
-	list := []model.Import{}
-	db := m.DB.Preload("ImportTags").Preload("ImportSummary")
-	result := db.Find(&list, "processed = ?", false)
-	if result.Error != nil {
-		err = liberr.Wrap(result.Error)
+	var ids []uint
+	db := m.DB.Model(&model.Import{})
+	db = db.Distinct("ImportSummaryID")
+	db = db.Where("processed = ?", false)
+	err = db.Pluck("ImportSummaryID", &ids).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, id := range ids {
+		summary := &model.ImportSummary{}
+		err = m.DB.First(summary, id).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		switch {
+		case summary.DryRun:
+			err = m.validate(summary)
+		case summary.Atomic:
+			err = m.processAtomic(summary)
+		default:
+			err = m.processBatched(summary)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// validate the (dry-run) imports in the summary.
+func (m *Manager) validate(summary *model.ImportSummary) (err error) {
+	validator := Validator{DB: m.DB}
+	validated, err := validator.Validate(summary)
+	if err != nil {
+		return
+	}
+	err = m.DB.Transaction(func(tx *gorm.DB) (err error) {
+		for _, imp := range validated {
+			db := tx.Omit(clause.Associations)
+			err = db.Save(imp).Error
+			if err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		err = liberr.Wrap(err)
+	}
+	return
+}
+
+// processBatched processes the imports in the summary in batches.
+// Each batch is processed in a transaction by a (bounded) pool of
+// workers. Each row is processed in a nested transaction (savepoint)
+// which is rolled back when the row fails.
+// Entities shared by rows (business services, tags, stakeholders) are
+// found or created before the workers run. Rows for the same application
+// are processed in the same batch.
+// Applications are processed before dependencies. Dependencies are
+// processed by a single worker so that cyclic dependencies are detected.
+func (m *Manager) processBatched(summary *model.ImportSummary) (err error) {
+	applications, others, err := m.unprocessed(summary)
+	if err != nil {
+		return
+	}
+	workers := max(Settings.Hub.Import.Workers, 1)
+	if workers > 1 && summary.CreateEntities {
+		err = m.prepare(applications)
+		if err != nil {
+			return
+		}
+	}
+	err = m.parallel(m.batches(applications), workers)
+	if err != nil {
+		return
+	}
+	err = m.parallel(m.batches(others), 1)
+	return
+}
+
+// prepare finds or creates the entities (business services, tags and
+// stakeholders) referenced by the rows in a transaction. Otherwise,
+// workers race to create the same entity in (concurrent) transactions.
+// Rows are not updated; failures are reported when the row is processed.
+func (m *Manager) prepare(rows []*model.Import) (err error) {
+	err = m.DB.Transaction(func(tx *gorm.DB) (err error) {
+		w := *m
+		w.DB = tx
+		for _, imp := range rows {
+			scratch := *imp
+			_, _ = w.businessService(&scratch)
+			_, _ = w.tags(&scratch)
+			_, _ = w.owner(&scratch)
+			_, _ = w.contributors(&scratch)
+		}
+		return
+	})
+	if err != nil {
+		err = liberr.Wrap(err)
+	}
+	return
+}
+
+// batches returns the rows partitioned into batches.
+// Batch size is based on Settings.Hub.Import.Batch. Rows with the same
+// application name or repository URL are kept in the same batch so they
+// are not processed by (concurrent) workers.
+func (m *Manager) batches(rows []*model.Import) (batches [][]*model.Import) {
+	var groups [][]*model.Import
+	index := make(map[string]int)
+	for _, imp := range rows {
+		keys := []string{"name:" + strings.TrimSpace(imp.ApplicationName)}
+		url := strings.TrimSpace(imp.RepositoryURL)
+		if url != "" {
+			keys = append(keys, "url:"+url)
+		}
+		group := -1
+		for _, key := range keys {
+			n, found := index[key]
+			if found {
+				group = n
+				break
+			}
+		}
+		if group == -1 {
+			group = len(groups)
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], imp)
+		for _, key := range keys {
+			if _, found := index[key]; !found {
+				index[key] = group
+			}
+		}
+	}
+	size := max(Settings.Hub.Import.Batch, 1)
+	var batch []*model.Import
+	for _, group := range groups {
+		if len(batch) > 0 && len(batch)+len(group) > size {
+			batches = append(batches, batch)
+			batch = nil
+		}
+		batch = append(batch, group...)
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return
+}
+
+// parallel processes the batches using a (bounded) pool of workers.
+func (m *Manager) parallel(batches [][]*model.Import, workers int) (err error) {
+	workers = max(workers, 1)
+	queue := make(chan []*model.Import)
+	mutex := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range queue {
+				bErr := m.processBatch(batch)
+				if bErr != nil {
+					mutex.Lock()
+					if err == nil {
+						err = bErr
+					}
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	for _, batch := range batches {
+		queue <- batch
+	}
+	close(queue)
+	wg.Wait()
+	return
+}
+
+// processAtomic processes the imports in the summary (all-or-nothing)
+// in a single transaction. When any row fails, the transaction is
+// rolled back and every row is marked invalid.
+func (m *Manager) processAtomic(summary *model.ImportSummary) (err error) {
+	applications, others, err := m.unprocessed(summary)
+	if err != nil {
+		return
+	}
+	rows := append(applications, others...)
+	failed := false
+	var launched []launch
+	err = m.DB.Transaction(func(tx *gorm.DB) (err error) {
+		for _, imp := range rows {
+			launched = append(launched, m.processRow(tx, imp)...)
+			if !imp.IsValid {
+				failed = true
+			}
+		}
+		if failed {
+			err = ErrRollback
+			return
+		}
+		for _, imp := range rows {
+			db := tx.Omit(clause.Associations)
+			err = db.Save(imp).Error
+			if err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil && !errors.Is(err, ErrRollback) {
+		err = liberr.Wrap(err)
+		return
+	}
+	if !failed {
+		m.launch(launched)
+		return
+	}
+	err = m.DB.Transaction(func(tx *gorm.DB) (err error) {
+		for _, imp := range rows {
+			if imp.IsValid {
+				imp.ErrorMessage = "Rolled back: the (all-or-nothing) import failed."
+			}
+			imp.IsValid = false
+			imp.Action = ""
+			imp.Changes = nil
+			db := tx.Omit(clause.Associations)
+			err = db.Save(imp).Error
+			if err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		err = liberr.Wrap(err)
+	}
+	return
+}
+
+// unprocessed returns the unprocessed imports in the summary
+// partitioned into application and other rows.
+func (m *Manager) unprocessed(summary *model.ImportSummary) (applications, others []*model.Import, err error) {
+	var list []model.Import
+	db := m.DB.Preload("ImportTags")
+	db = db.Order("Row, ID")
+	err = db.Find(
+		&list,
+		"ImportSummaryID = ? AND processed = ?",
+		summary.ID,
+		false).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for i := range list {
+		imp := &list[i]
+		imp.ImportSummary = *summary
+		if imp.RecordType1 == api.RecordTypeApplication {
+			applications = append(applications, imp)
+		} else {
+			others = append(others, imp)
+		}
+	}
+	return
+}
+
+// processBatch processes a batch of rows in a transaction.
+// Application triggers are launched after the transaction
+// is committed.
+func (m *Manager) processBatch(batch []*model.Import) (err error) {
+	var launched []launch
+	err = m.DB.Transaction(func(tx *gorm.DB) (err error) {
+		for _, imp := range batch {
+			launched = append(launched, m.processRow(tx, imp)...)
+			db := tx.Omit(clause.Associations)
+			err = db.Save(imp).Error
+			if err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		err = liberr.Wrap(err)
 		return
 	}
-	for _, imp := range list {
+	m.launch(launched)
+	return
+}
+
+// processRow processes a row in a nested transaction (savepoint)
+// which is rolled back when the row fails.
+// Returns the (deferred) application triggers.
+func (m *Manager) processRow(tx *gorm.DB, imp *model.Import) (launched []launch) {
+	_ = tx.Transaction(func(tx *gorm.DB) (err error) {
+		w := *m
+		w.DB = tx
+		w.launched = nil
 		var ok bool
 		switch imp.RecordType1 {
 		case api.RecordTypeApplication:
-			ok = m.createApplication(&imp)
+			ok = w.importApplication(imp)
 		case api.RecordTypeDependency:
-			ok = m.createDependency(&imp)
+			ok = w.createDependency(imp)
 		default:
 			errMsg := ""
 			if imp.RecordType1 == "" {
@@ -73,15 +412,47 @@ func (m *Manager) processImports() (err error) {
 		}
 		imp.IsValid = ok
 		imp.Processed = true
-		result = m.DB.Save(&imp)
-		if result.Error != nil {
-			err = liberr.Wrap(result.Error)
+		if !ok {
+			imp.Action = ""
+			err = ErrRollback
 			return
 		}
-	}
+		launched = w.launched
+		return
+	})
 	return
 }
 
+// launch the (deferred) application triggers.
+// Best effort. Failures are reported in the import.
+func (m *Manager) launch(launched []launch) {
+	tr := trigger.Application{
+		Trigger: trigger.Trigger{
+			TaskManager: m.TaskManager,
+			Client:      m.Client,
+			DB:          m.DB,
+		},
+	}
+	for _, l := range launched {
+		var err error
+		if l.created {
+			err = tr.Created(l.app)
+		} else {
+			err = tr.Updated(l.app)
+		}
+		if err == nil {
+			continue
+		}
+		l.imp.ErrorMessage = fmt.Sprintf(
+			"Failed to launch discovery tasks for Application '%s'.",
+			l.app.Name)
+		err = m.DB.Model(l.imp).Update("ErrorMessage", l.imp.ErrorMessage).Error
+		if err != nil {
+			Log.Error(err, "")
+		}
+	}
+}
+
 // createDependency creates an application dependency from
 // a dependency import record.
 func (m *Manager) createDependency(imp *model.Import) (ok bool) {
@@ -121,6 +492,100 @@ func (m *Manager) createDependency(imp *model.Import) (ok bool) {
 	return
 }
 
+// importApplication creates or updates an application from an
+// application import record based on the summary mode.
+// A committed dry-run row has a planned action (create|update)
+// which must still apply to the inventory.
+func (m *Manager) importApplication(imp *model.Import) (ok bool) {
+	summary := &imp.ImportSummary
+	switch summary.Mode {
+	case api.ImportUpdate,
+		api.ImportUpsert:
+	default:
+		ok = m.createApplication(imp)
+		return
+	}
+	app, found := m.findApplication(imp)
+	if imp.ErrorMessage != "" {
+		return
+	}
+	switch {
+	case imp.Action == ActionCreate && found:
+		imp.ErrorMessage = fmt.Sprintf(
+			"Inventory changed since the dry-run: application '%s' already exists.",
+			m.matchedBy(imp))
+		return
+	case imp.Action == ActionUpdate && !found:
+		imp.ErrorMessage = fmt.Sprintf(
+			"Inventory changed since the dry-run: application '%s' could not be found.",
+			m.matchedBy(imp))
+		return
+	}
+	if !found {
+		if summary.Mode == api.ImportUpdate {
+			imp.ErrorMessage = fmt.Sprintf(
+				"Application '%s' could not be found.",
+				m.matchedBy(imp))
+			return
+		}
+		ok = m.createApplication(imp)
+		return
+	}
+	ok = m.updateApplication(imp, app)
+	return
+}
+
+// findApplication finds an existing application matched
+// by name or repository URL.
+func (m *Manager) findApplication(imp *model.Import) (app *model.Application, found bool) {
+	var list []model.Application
+	db := m.DB.Preload("BusinessService").Preload("Owner").Preload("Contributors")
+	switch imp.ImportSummary.MatchBy {
+	case api.ImportMatchRepository:
+		url := strings.TrimSpace(imp.RepositoryURL)
+		if url == "" {
+			imp.ErrorMessage = "Repository URL is mandatory when matching by repository."
+			return
+		}
+		db = db.Where("json_extract(Repository, '$.url') = ?", url)
+	default:
+		name := strings.TrimSpace(imp.ApplicationName)
+		if name == "" {
+			imp.ErrorMessage = "Application Name is mandatory."
+			return
+		}
+		db = db.Where("name = ?", name)
+	}
+	err := db.Find(&list).Error
+	if err != nil {
+		imp.ErrorMessage = err.Error()
+		return
+	}
+	switch len(list) {
+	case 0:
+	case 1:
+		app = &list[0]
+		found = true
+	default:
+		imp.ErrorMessage = fmt.Sprintf(
+			"Application '%s' matched (%d) applications.",
+			m.matchedBy(imp),
+			len(list))
+	}
+	return
+}
+
+// matchedBy returns the value used to match an existing application.
+func (m *Manager) matchedBy(imp *model.Import) (s string) {
+	switch imp.ImportSummary.MatchBy {
+	case api.ImportMatchRepository:
+		s = strings.TrimSpace(imp.RepositoryURL)
+	default:
+		s = strings.TrimSpace(imp.ApplicationName)
+	}
+	return
+}
+
 // createApplication creates an application from an
 // application import record.
 func (m *Manager) createApplication(imp *model.Import) (ok bool) {
@@ -135,7 +600,303 @@ func (m *Manager) createApplication(imp *model.Import) (ok bool) {
 		return
 	}
 
-	repository := model.Repository{
+	app.Repository = m.repository(imp)
+	app.Binary, ok = m.binary(imp)
+	if !ok {
+		return
+	}
+	businessService, ok := m.businessService(imp)
+	if !ok {
+		return
+	}
+	// Assign business service to the application if was specified
+	if businessService != nil {
+		app.BusinessService = businessService
+	}
+	tags, ok := m.tags(imp)
+	if !ok {
+		return
+	}
+	appTags := []model.ApplicationTag{}
+	for _, tag := range tags {
+		appTags = append(appTags, model.ApplicationTag{TagID: tag.ID, Source: ""})
+	}
+	owner, ok := m.owner(imp)
+	if !ok {
+		return
+	}
+	if owner != nil {
+		app.OwnerID = &owner.ID
+	}
+	app.Contributors, ok = m.contributors(imp)
+	if !ok {
+		return
+	}
+	ok = false
+
+	result := m.DB.Create(app)
+	if result.Error != nil {
+		imp.ErrorMessage = result.Error.Error()
+		return
+	}
+	for i := range appTags {
+		appTags[i].ApplicationID = app.ID
+	}
+	if len(appTags) > 0 {
+		result = m.DB.Create(&appTags)
+		if result.Error != nil {
+			imp.ErrorMessage = result.Error.Error()
+			return
+		}
+	}
+	for _, fact := range m.facts(imp, app) {
+		result = m.DB.Create(&fact)
+		if result.Error != nil {
+			imp.ErrorMessage = result.Error.Error()
+			return
+		}
+	}
+	imp.Action = ActionCreated
+	m.launched = append(
+		m.launched,
+		launch{
+			imp:     imp,
+			app:     app,
+			created: true,
+		})
+
+	ok = true
+	return
+}
+
+// updateApplication updates an existing application from an
+// application import record. Empty fields are ignored.
+// Changed fields are recorded in the import.
+func (m *Manager) updateApplication(imp *model.Import, app *model.Application) (ok bool) {
+	var changes []model.ImportChange
+	changed := func(field, before, after string) {
+		if before != after {
+			changes = append(
+				changes,
+				model.ImportChange{
+					Field:  field,
+					Before: before,
+					After:  after,
+				})
+		}
+	}
+	name := strings.TrimSpace(imp.ApplicationName)
+	if name != "" {
+		changed("name", app.Name, name)
+		app.Name = name
+	}
+	if imp.Description != "" {
+		changed("description", app.Description, imp.Description)
+		app.Description = imp.Description
+	}
+	if imp.Comments != "" {
+		changed("comments", app.Comments, imp.Comments)
+		app.Comments = imp.Comments
+	}
+	if strings.TrimSpace(imp.RepositoryURL) != "" {
+		repository := m.repository(imp)
+		repository.Tag = app.Repository.Tag
+		changed(
+			"repository",
+			repositoryString(app.Repository),
+			repositoryString(repository))
+		app.Repository = repository
+	}
+	binary, ok := m.binary(imp)
+	if !ok {
+		return
+	}
+	if binary != "" {
+		changed("binary", app.Binary, binary)
+		app.Binary = binary
+	}
+	businessService, ok := m.businessService(imp)
+	if !ok {
+		return
+	}
+	if businessService != nil {
+		before := ""
+		if app.BusinessService != nil {
+			before = app.BusinessService.Name
+		}
+		changed("businessService", before, businessService.Name)
+		app.BusinessServiceID = &businessService.ID
+	}
+	owner, ok := m.owner(imp)
+	if !ok {
+		return
+	}
+	if owner != nil {
+		before := ""
+		if app.Owner != nil {
+			before = stakeholderString(app.Owner)
+		}
+		changed("owner", before, stakeholderString(owner))
+		app.OwnerID = &owner.ID
+	}
+	contributors, ok := m.contributors(imp)
+	if !ok {
+		return
+	}
+	if len(contributors) > 0 {
+		var before, after []string
+		for i := range app.Contributors {
+			before = append(before, stakeholderString(&app.Contributors[i]))
+		}
+		for i := range contributors {
+			after = append(after, stakeholderString(&contributors[i]))
+		}
+		sort.Strings(before)
+		sort.Strings(after)
+		changed(
+			"contributors",
+			strings.Join(before, ", "),
+			strings.Join(after, ", "))
+	}
+	tags, ok := m.tags(imp)
+	if !ok {
+		return
+	}
+	ok = false
+	var appTags []model.ApplicationTag
+	db := m.DB.Preload("Tag.Category")
+	err := db.Find(&appTags, "ApplicationID = ? AND Source = ?", app.ID, "").Error
+	if err != nil {
+		imp.ErrorMessage = err.Error()
+		return
+	}
+	assigned := make(map[uint]model.Tag)
+	for _, appTag := range appTags {
+		assigned[appTag.TagID] = appTag.Tag
+	}
+	wanted := make(map[uint]model.Tag)
+	if imp.ImportSummary.TagMode != api.ImportTagReplace || len(tags) == 0 {
+		for id, tag := range assigned {
+			wanted[id] = tag
+		}
+	}
+	for _, tag := range tags {
+		wanted[tag.ID] = tag
+	}
+	changed("tags", tagsString(assigned), tagsString(wanted))
+	facts := m.facts(imp, app)
+	for _, fact := range facts {
+		before := ""
+		current := &model.Fact{}
+		err = m.DB.First(
+			current,
+			map[string]any{
+				"ApplicationID": app.ID,
+				"Key":           fact.Key,
+				"Source":        fact.Source,
+			}).Error
+		if err == nil {
+			before = fmt.Sprint(current.Value)
+		}
+		changed("fact:"+fact.Key, before, fmt.Sprint(fact.Value))
+	}
+
+	err = m.DB.Transaction(func(tx *gorm.DB) (err error) {
+		app.UpdateUser = imp.ImportSummary.CreateUser
+		db := tx.Model(app)
+		db = db.Omit(clause.Associations, "BucketID")
+		err = db.Save(app).Error
+		if err != nil {
+			return
+		}
+		if len(contributors) > 0 {
+			err = tx.Model(app).Association("Contributors").Replace(contributors)
+			if err != nil {
+				return
+			}
+		}
+		for id := range assigned {
+			if _, found := wanted[id]; found {
+				continue
+			}
+			err = tx.Delete(
+				&model.ApplicationTag{},
+				"ApplicationID = ? AND TagID = ? AND Source = ?",
+				app.ID,
+				id,
+				"").Error
+			if err != nil {
+				return
+			}
+		}
+		for id := range wanted {
+			if _, found := assigned[id]; found {
+				continue
+			}
+			appTag := &model.ApplicationTag{
+				ApplicationID: app.ID,
+				TagID:         id,
+				Source:        "",
+			}
+			err = tx.Create(appTag).Error
+			if err != nil {
+				return
+			}
+		}
+		for i := range facts {
+			db := tx.Clauses(clause.OnConflict{UpdateAll: true})
+			err = db.Save(&facts[i]).Error
+			if err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		imp.ErrorMessage = err.Error()
+		return
+	}
+	imp.Changes = changes
+	if len(changes) == 0 {
+		imp.Action = ActionUnchanged
+		ok = true
+		return
+	}
+	imp.Action = ActionUpdated
+	m.launched = append(
+		m.launched,
+		launch{
+			imp: imp,
+			app: app,
+		})
+
+	ok = true
+	return
+}
+
+// facts returns the application facts for the import.
+func (m *Manager) facts(imp *model.Import, app *model.Application) (facts []model.Fact) {
+	keys := []string{}
+	for key := range imp.Facts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		facts = append(
+			facts,
+			model.Fact{
+				ApplicationID: app.ID,
+				Key:           key,
+				Source:        FactSource,
+				Value:         imp.Facts[key],
+			})
+	}
+	return
+}
+
+// repository returns the repository for the import.
+func (m *Manager) repository(imp *model.Import) (repository model.Repository) {
+	repository = model.Repository{
 		Kind:   imp.RepositoryKind,
 		URL:    imp.RepositoryURL,
 		Branch: imp.RepositoryBranch,
@@ -146,8 +907,11 @@ func (m *Manager) createApplication(imp *model.Import) (ok bool) {
 	if repository.Kind == "" {
 		repository.Kind = "git"
 	}
+	return
+}
 
-	app.Repository = repository
+// binary returns the binary coordinates for the import.
+func (m *Manager) binary(imp *model.Import) (binary string, ok bool) {
 	// Validate Binary-related fields (allow all 3 empty or present)
 	if imp.BinaryGroup != "" || imp.BinaryArtifact != "" || imp.BinaryVersion != "" {
 		if imp.BinaryGroup == "" || imp.BinaryArtifact == "" || imp.BinaryVersion == "" {
@@ -158,30 +922,36 @@ func (m *Manager) createApplication(imp *model.Import) (ok bool) {
 
 	// Build Binary attribute
 	if imp.BinaryGroup != "" {
-		app.Binary = fmt.Sprintf("%s:%s:%s", imp.BinaryGroup, imp.BinaryArtifact, imp.BinaryVersion)
+		binary = fmt.Sprintf("%s:%s:%s", imp.BinaryGroup, imp.BinaryArtifact, imp.BinaryVersion)
 		if imp.BinaryPackaging != "" {
 			// Packaging can be empty
-			app.Binary = fmt.Sprintf("%s:%s", app.Binary, imp.BinaryPackaging)
+			binary = fmt.Sprintf("%s:%s", binary, imp.BinaryPackaging)
 		}
 	}
+	ok = true
+	return
+}
 
-	// Assign Business Service
-	businessService := model.BusinessService{}
+// businessService returns the business service for the import.
+// Returns nil when not specified.
+func (m *Manager) businessService(imp *model.Import) (businessService *model.BusinessService, ok bool) {
 	businessServices := []model.BusinessService{}
 	m.DB.Find(&businessServices)
 	normBusinessServiceName := normalizedName(imp.BusinessService)
 	// Find existing BusinessService
-	for _, bs := range businessServices {
+	for i := range businessServices {
+		bs := &businessServices[i]
 		if normalizedName(bs.Name) == normBusinessServiceName {
 			businessService = bs
 		}
 	}
 	// If not found business service in database and import specifies some non-empty business service, proceeed with create it
-	if businessService.ID == 0 && normBusinessServiceName != "" {
+	if businessService == nil && normBusinessServiceName != "" {
 		if imp.ImportSummary.CreateEntities {
 			// Create a new BusinessService if not existed
+			businessService = &model.BusinessService{}
 			businessService.Name = imp.BusinessService
-			result := m.DB.Create(&businessService)
+			result := m.DB.Create(businessService)
 			if result.Error != nil {
 				imp.ErrorMessage = fmt.Sprintf("BusinessService '%s' cannot be created.", imp.BusinessService)
 				return
@@ -191,11 +961,12 @@ func (m *Manager) createApplication(imp *model.Import) (ok bool) {
 			return
 		}
 	}
-	// Assign business service to the application if was specified
-	if businessService.ID != 0 {
-		app.BusinessService = &businessService
-	}
+	ok = true
+	return
+}
 
+// tags returns the (unique) tags for the import.
+func (m *Manager) tags(imp *model.Import) (tags []model.Tag, ok bool) {
 	// Process import Tags & TagCategories
 	allCategories := []model.TagCategory{}
 	m.DB.Find(&allCategories)
@@ -205,7 +976,6 @@ func (m *Manager) createApplication(imp *model.Import) (ok bool) {
 	db.Find(&allTags)
 
 	seenTags := make(map[uint]bool)
-	appTags := []model.ApplicationTag{}
 	for _, impTag := range imp.ImportTags {
 		// Prepare normalized names for importTag
 		normImpTagName := normalizedName(impTag.Name)
@@ -277,21 +1047,27 @@ func (m *Manager) createApplication(imp *model.Import) (ok bool) {
 		}
 		if !seenTags[tag.ID] {
 			seenTags[tag.ID] = true
-			appTags = append(appTags, model.ApplicationTag{TagID: tag.ID, Source: ""})
+			tags = append(tags, *tag)
 		}
 	}
+	ok = true
+	return
+}
 
+// owner returns the owner for the import.
+// Returns nil when not specified.
+func (m *Manager) owner(imp *model.Import) (owner *model.Stakeholder, ok bool) {
 	if imp.Owner != "" {
 		name, email, parsed := parseStakeholder(imp.Owner)
 		if !parsed {
 			imp.ErrorMessage = fmt.Sprintf("Could not parse Owner '%s'.", imp.Owner)
 			return
 		}
-		owner, found := m.findStakeholder(email)
+		stakeholder, found := m.findStakeholder(email)
 		if !found {
 			if imp.ImportSummary.CreateEntities {
 				var err error
-				owner, err = m.createStakeholder(name, email)
+				stakeholder, err = m.createStakeholder(name, email)
 				if err != nil {
 					imp.ErrorMessage = fmt.Sprintf("Owner '%s' could not be created.", imp.Owner)
 					return
@@ -301,8 +1077,14 @@ func (m *Manager) createApplication(imp *model.Import) (ok bool) {
 				return
 			}
 		}
-		app.OwnerID = &owner.ID
+		owner = &stakeholder
 	}
+	ok = true
+	return
+}
+
+// contributors returns the contributors for the import.
+func (m *Manager) contributors(imp *model.Import) (contributors []model.Stakeholder, ok bool) {
 	if imp.Contributors != "" {
 		fields := strings.Split(imp.Contributors, ",")
 		for _, f := range fields {
@@ -325,38 +1107,9 @@ func (m *Manager) createApplication(imp *model.Import) (ok bool) {
 					return
 				}
 			}
-			app.Contributors = append(app.Contributors, contributor)
+			contributors = append(contributors, contributor)
 		}
 	}
-
-	result := m.DB.Create(app)
-	if result.Error != nil {
-		imp.ErrorMessage = result.Error.Error()
-		return
-	}
-	for i := range appTags {
-		appTags[i].ApplicationID = app.ID
-	}
-	result = m.DB.Create(&appTags)
-	if result.Error != nil {
-		imp.ErrorMessage = result.Error.Error()
-		return
-	}
-	// best effort
-	tr := trigger.Application{
-		Trigger: trigger.Trigger{
-			TaskManager: m.TaskManager,
-			Client:      m.Client,
-			DB:          m.DB,
-		},
-	}
-	err := tr.Created(app)
-	if err != nil {
-		imp.ErrorMessage = fmt.Sprintf(
-			"Failed to launch discovery tasks for Application '%s'.",
-			app.Name)
-	}
-
 	ok = true
 	return
 }
@@ -389,6 +1142,40 @@ func normalizedName(name string) (normName string) {
 	return
 }
 
+// repositoryString returns a string representation of the repository.
+func repositoryString(r model.Repository) (s string) {
+	if r.URL == "" {
+		return
+	}
+	s = fmt.Sprintf("%s:%s", r.Kind, r.URL)
+	if r.Branch != "" {
+		s += "@" + r.Branch
+	}
+	if r.Path != "" {
+		s += "#" + r.Path
+	}
+	return
+}
+
+// stakeholderString returns a string representation of the stakeholder.
+// Format: Name <email>.
+func stakeholderString(m *model.Stakeholder) (s string) {
+	s = fmt.Sprintf("%s <%s>", m.Name, m.Email)
+	return
+}
+
+// tagsString returns a (sorted) string representation of the tags.
+// Format: category=tag, ...
+func tagsString(tags map[uint]model.Tag) (s string) {
+	var list []string
+	for _, tag := range tags {
+		list = append(list, tag.Category.Name+"="+tag.Name)
+	}
+	sort.Strings(list)
+	s = strings.Join(list, ", ")
+	return
+}
+
 // parseStakeholder attempts to parse a stakeholder's name and an email address
 // out of a string  like `John Smith <jsmith@example.com>`. The pattern is very
 // simple and treats anything before the first bracket as the name,
@@ -0,0 +1,420 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/api"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"gorm.io/gorm"
+)
+
+// Import (dry-run) actions.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+)
+
+// ScpURL matches scp-like (git) repository URLs.
+// Example: git@github.com:konveyor/tackle2-hub.git
+var ScpURL = regexp.MustCompile(`^([^@/]+@)?[^@/:]+:[^/].*$`)
+
+// Validator validates (dry-run) imports against the inventory.
+// Nothing is created or updated. Every row is validated and all
+// issues are reported.
+type Validator struct {
+	DB *gorm.DB
+	// inventory.
+	applications []model.Application
+	services     map[string]bool
+	categories   map[string]bool
+	tags         map[string]bool
+	stakeholders map[string]bool
+	// application rows by name.
+	seen map[string]int
+	// planned (valid) applications by name.
+	planned map[string]int
+}
+
+// Validate the rows in the import summary.
+// Returns the validated rows keyed by import ID.
+func (r *Validator) Validate(summary *model.ImportSummary) (validated map[uint]*model.Import, err error) {
+	err = r.load()
+	if err != nil {
+		return
+	}
+	var list []model.Import
+	db := r.DB.Preload("ImportTags")
+	db = db.Order("Row, ID")
+	err = db.Find(&list, "ImportSummaryID = ?", summary.ID).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	validated = make(map[uint]*model.Import)
+	for i := range list {
+		imp := &list[i]
+		imp.ImportSummary = *summary
+		imp.Issues = nil
+		imp.Action = ""
+		switch imp.RecordType1 {
+		case api.RecordTypeApplication:
+			r.application(imp)
+		case api.RecordTypeDependency:
+			r.dependency(imp)
+		case "":
+			r.issue(imp, "Empty Record Type.")
+		default:
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"Invalid or unknown Record Type '%s'. Must be '1' for Application or '2' for Dependency.",
+					imp.RecordType1))
+		}
+		imp.IsValid = len(imp.Issues) == 0
+		if len(imp.Issues) > 0 {
+			imp.ErrorMessage = imp.Issues[0]
+		} else {
+			imp.ErrorMessage = ""
+		}
+		imp.Processed = true
+		validated[imp.ID] = imp
+	}
+	return
+}
+
+// application validates an application row.
+func (r *Validator) application(imp *model.Import) {
+	summary := &imp.ImportSummary
+	name := strings.TrimSpace(imp.ApplicationName)
+	var matched []model.Application
+	switch summary.Mode {
+	case api.ImportUpdate,
+		api.ImportUpsert:
+		matched = r.match(imp)
+		switch len(matched) {
+		case 0:
+			if summary.Mode == api.ImportUpdate {
+				r.issue(
+					imp,
+					fmt.Sprintf(
+						"Application '%s' could not be found.",
+						r.matchedBy(imp)))
+			} else {
+				imp.Action = ActionCreate
+			}
+		case 1:
+			imp.Action = ActionUpdate
+		default:
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"Application '%s' matched (%d) applications.",
+					r.matchedBy(imp),
+					len(matched)))
+		}
+	default:
+		imp.Action = ActionCreate
+		if name != "" {
+			for _, m := range r.applications {
+				if m.Name == name {
+					r.issue(
+						imp,
+						fmt.Sprintf(
+							"Application '%s' already exists.",
+							name))
+					break
+				}
+			}
+		}
+	}
+	if name == "" {
+		if imp.Action == ActionCreate {
+			r.issue(imp, "Application Name is mandatory.")
+		}
+	} else {
+		row, found := r.seen[strings.ToLower(name)]
+		if found {
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"Duplicate Application Name '%s' (row %d).",
+					name,
+					row))
+		}
+	}
+	repository := strings.TrimSpace(imp.RepositoryURL)
+	if repository != "" && !r.validURL(repository) {
+		r.issue(
+			imp,
+			fmt.Sprintf(
+				"Repository URL '%s' could not be parsed.",
+				repository))
+	}
+	if imp.BinaryGroup != "" || imp.BinaryArtifact != "" || imp.BinaryVersion != "" {
+		if imp.BinaryGroup == "" || imp.BinaryArtifact == "" || imp.BinaryVersion == "" {
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"Binary-related fields for application %s need to be all present or all empty",
+					imp.ApplicationName))
+		}
+	}
+	service := normalizedName(imp.BusinessService)
+	if service != "" && !r.services[service] && !summary.CreateEntities {
+		r.issue(
+			imp,
+			fmt.Sprintf(
+				"BusinessService '%s' could not be found.",
+				imp.BusinessService))
+	}
+	for _, impTag := range imp.ImportTags {
+		tag := normalizedName(impTag.Name)
+		category := normalizedName(impTag.Category)
+		if tag == "" {
+			continue
+		}
+		if category == "" {
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"Tag '%s' has missing or invalid TagCategory.",
+					impTag.Name))
+			continue
+		}
+		if summary.CreateEntities {
+			continue
+		}
+		if !r.categories[category] {
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"TagCategory '%s' could not be found.",
+					impTag.Category))
+			continue
+		}
+		if !r.tags[category+"/"+tag] {
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"Tag '%s' could not be found.",
+					impTag.Name))
+		}
+	}
+	if imp.Owner != "" {
+		_, email, parsed := parseStakeholder(imp.Owner)
+		if !parsed {
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"Could not parse Owner '%s'.",
+					imp.Owner))
+		} else if !r.stakeholders[email] && !summary.CreateEntities {
+			r.issue(
+				imp,
+				fmt.Sprintf(
+					"Owner '%s' could not be found.",
+					imp.Owner))
+		}
+	}
+	if imp.Contributors != "" {
+		for _, f := range strings.Split(imp.Contributors, ",") {
+			_, email, parsed := parseStakeholder(f)
+			if !parsed {
+				r.issue(
+					imp,
+					fmt.Sprintf(
+						"Could not parse Contributor '%s'.",
+						f))
+			} else if !r.stakeholders[email] && !summary.CreateEntities {
+				r.issue(
+					imp,
+					fmt.Sprintf(
+						"Contributor '%s' could not be found.",
+						strings.TrimSpace(f)))
+			}
+		}
+	}
+	if name != "" {
+		key := strings.ToLower(name)
+		if _, found := r.seen[key]; !found {
+			r.seen[key] = imp.Row
+		}
+		if len(imp.Issues) == 0 {
+			r.planned[key] = imp.Row
+		}
+	}
+}
+
+// dependency validates a dependency row.
+func (r *Validator) dependency(imp *model.Import) {
+	name := strings.TrimSpace(imp.ApplicationName)
+	if !r.exists(name) {
+		r.issue(
+			imp,
+			fmt.Sprintf(
+				"Application '%s' could not be found.",
+				name))
+	}
+	name = strings.TrimSpace(imp.Dependency)
+	if !r.exists(name) {
+		r.issue(
+			imp,
+			fmt.Sprintf(
+				"Application dependency '%s' could not be found.",
+				name))
+	}
+	switch strings.ToLower(strings.TrimSpace(imp.DependencyDirection)) {
+	case "northbound", "southbound":
+		imp.Action = ActionCreate
+	default:
+		r.issue(
+			imp,
+			fmt.Sprintf(
+				"Dependency Direction '%s' must be 'northbound' or 'southbound'.",
+				imp.DependencyDirection))
+	}
+}
+
+// match returns the applications matched by name or repository URL.
+func (r *Validator) match(imp *model.Import) (matched []model.Application) {
+	switch imp.ImportSummary.MatchBy {
+	case api.ImportMatchRepository:
+		url := strings.TrimSpace(imp.RepositoryURL)
+		if url == "" {
+			r.issue(imp, "Repository URL is mandatory when matching by repository.")
+			return
+		}
+		for _, m := range r.applications {
+			if m.Repository.URL == url {
+				matched = append(matched, m)
+			}
+		}
+	default:
+		name := strings.TrimSpace(imp.ApplicationName)
+		if name == "" {
+			r.issue(imp, "Application Name is mandatory.")
+			return
+		}
+		for _, m := range r.applications {
+			if m.Name == name {
+				matched = append(matched, m)
+			}
+		}
+	}
+	return
+}
+
+// matchedBy returns the value used to match an existing application.
+func (r *Validator) matchedBy(imp *model.Import) (s string) {
+	switch imp.ImportSummary.MatchBy {
+	case api.ImportMatchRepository:
+		s = strings.TrimSpace(imp.RepositoryURL)
+	default:
+		s = strings.TrimSpace(imp.ApplicationName)
+	}
+	return
+}
+
+// exists returns true when the named application exists in
+// the inventory or will be created by a (valid) earlier row.
+func (r *Validator) exists(name string) (found bool) {
+	if name == "" {
+		return
+	}
+	_, found = r.planned[strings.ToLower(name)]
+	if found {
+		return
+	}
+	for _, m := range r.applications {
+		if strings.EqualFold(m.Name, name) {
+			found = true
+			break
+		}
+	}
+	return
+}
+
+// validURL returns true when the repository URL can be parsed.
+// Both URLs and scp-like (git) URLs are supported.
+func (r *Validator) validURL(s string) (valid bool) {
+	if ScpURL.MatchString(s) && !strings.Contains(s, "://") {
+		valid = true
+		return
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return
+	}
+	valid = u.Scheme != "" && u.Host != ""
+	return
+}
+
+// issue adds an issue to the row.
+// Duplicate issues are ignored.
+func (r *Validator) issue(imp *model.Import, issue string) {
+	for _, s := range imp.Issues {
+		if s == issue {
+			return
+		}
+	}
+	imp.Issues = append(imp.Issues, issue)
+}
+
+// load the inventory.
+func (r *Validator) load() (err error) {
+	r.seen = make(map[string]int)
+	r.planned = make(map[string]int)
+	r.services = make(map[string]bool)
+	r.categories = make(map[string]bool)
+	r.tags = make(map[string]bool)
+	r.stakeholders = make(map[string]bool)
+	r.applications = nil
+	db := r.DB.Select("ID", "Name", "Repository")
+	err = db.Find(&r.applications).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	var services []model.BusinessService
+	err = r.DB.Find(&services).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range services {
+		r.services[normalizedName(m.Name)] = true
+	}
+	var categories []model.TagCategory
+	err = r.DB.Find(&categories).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range categories {
+		r.categories[normalizedName(m.Name)] = true
+	}
+	var tags []model.Tag
+	err = r.DB.Preload("Category").Find(&tags).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range tags {
+		key := normalizedName(m.Category.Name) + "/" + normalizedName(m.Name)
+		r.tags[key] = true
+	}
+	var stakeholders []model.Stakeholder
+	err = r.DB.Find(&stakeholders).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range stakeholders {
+		r.stakeholders[strings.ToLower(m.Email)] = true
+	}
+	return
+}
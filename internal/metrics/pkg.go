@@ -22,4 +22,20 @@ var (
 		Name: "konveyor_issues_exported_total",
 		Help: "The total number of issues exported to external trackers",
 	})
+	LimitAdmitted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konveyor_api_limit_admitted_total",
+		Help: "The total number of API requests admitted by the limiter",
+	}, []string{"kind", "group"})
+	LimitRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konveyor_api_limit_rejected_total",
+		Help: "The total number of API requests rejected by the limiter",
+	}, []string{"kind", "group", "reason"})
+	LimitInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konveyor_api_limit_inflight",
+		Help: "The current number of (limited) API requests in flight",
+	}, []string{"kind", "group"})
+	LimitSubjects = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "konveyor_api_limit_subjects",
+		Help: "The current number of subjects tracked by the limiter",
+	})
 )
@@ -0,0 +1,56 @@
+package v24
+
+import (
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/migration/v24/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Migration struct{}
+
+func (r Migration) Apply(db *gorm.DB) (err error) {
+	err = db.AutoMigrate(r.Models()...)
+	if err != nil {
+		return
+	}
+	err = r.versionQuestionnaires(db)
+	return
+}
+
+func (r Migration) Models() []any {
+	return model.All()
+}
+
+// versionQuestionnaires records the initial version of
+// each (existing) questionnaire.
+func (r Migration) versionQuestionnaires(db *gorm.DB) (err error) {
+	var list []model.Questionnaire
+	err = db.Where("Version IS NULL OR Version = 0").Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for i := range list {
+		q := &list[i]
+		version := &model.QuestionnaireVersion{
+			QuestionnaireID: q.ID,
+			Version:         1,
+			Sections:        q.Sections,
+			Thresholds:      q.Thresholds,
+			RiskMessages:    q.RiskMessages,
+		}
+		version.CreateUser = q.CreateUser
+		err = db.Omit(clause.Associations).Create(version).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		err = db.Model(q).UpdateColumn("Version", version.Version).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	return
+}
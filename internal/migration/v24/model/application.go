@@ -0,0 +1,396 @@
+package model
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/konveyor/tackle2-hub/internal/migration/json"
+	"gorm.io/gorm"
+)
+
+type Application struct {
+	Model
+	BucketOwner
+	Name              string `gorm:"index;unique;not null"`
+	Description       string
+	Review            *Review        `gorm:"constraint:OnDelete:CASCADE"`
+	Repository        Repository     `gorm:"type:json;serializer:json"`
+	Assets            Repository     `gorm:"type:json;serializer:json"`
+	Coordinates       *json.Document `gorm:"type:json;serializer:json"`
+	Binary            string
+	Facts             []Fact `gorm:"constraint:OnDelete:CASCADE"`
+	Comments          string
+	Tasks             []Task     `gorm:"constraint:OnDelete:CASCADE"`
+	Tags              []Tag      `gorm:"many2many:ApplicationTags"`
+	Identities        []Identity `gorm:"many2many:ApplicationIdentity;constraint:OnDelete:CASCADE"`
+	BusinessServiceID *uint      `gorm:"index"`
+	BusinessService   *BusinessService
+	OwnerID           *uint         `gorm:"index"`
+	Owner             *Stakeholder  `gorm:"foreignKey:OwnerID"`
+	Contributors      []Stakeholder `gorm:"many2many:ApplicationContributors;constraint:OnDelete:CASCADE"`
+	Analyses          []Analysis    `gorm:"constraint:OnDelete:CASCADE"`
+	MigrationWaveID   *uint         `gorm:"index"`
+	MigrationWave     *MigrationWave
+	PlatformID        *uint `gorm:"index"`
+	Platform          *Platform
+	Ticket            *Ticket      `gorm:"constraint:OnDelete:CASCADE"`
+	Assessments       []Assessment `gorm:"constraint:OnDelete:CASCADE"`
+	Manifest          []Manifest   `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type Fact struct {
+	ApplicationID uint   `gorm:"<-:create;primaryKey"`
+	Key           string `gorm:"<-:create;primaryKey"`
+	Source        string `gorm:"<-:create;primaryKey;not null"`
+	Value         any    `gorm:"type:json;not null;serializer:json"`
+	Application   *Application
+}
+
+// ApplicationTag represents a row in the join table for the
+// many-to-many relationship between Applications and Tags.
+type ApplicationTag struct {
+	ApplicationID uint        `gorm:"primaryKey"`
+	TagID         uint        `gorm:"primaryKey"`
+	Source        string      `gorm:"primaryKey;not null"`
+	Application   Application `gorm:"constraint:OnDelete:CASCADE"`
+	Tag           Tag         `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// TableName must return "ApplicationTags" to ensure compatibility
+// with the autogenerated join table name.
+func (ApplicationTag) TableName() string {
+	return "ApplicationTags"
+}
+
+type ApplicationIdentity struct {
+	ApplicationID uint        `gorm:"primaryKey"`
+	IdentityID    uint        `gorm:"primaryKey;index"`
+	Role          string      `gorm:"primaryKey"`
+	Application   Application `gorm:"constraint:OnDelete:CASCADE"`
+	Identity      Identity    `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// depMutex ensures Dependency.Create() is not executed concurrently.
+var depMutex sync.Mutex
+
+type Dependency struct {
+	Model
+	ToID   uint         `gorm:"index"`
+	To     *Application `gorm:"foreignKey:ToID;constraint:OnDelete:CASCADE"`
+	FromID uint         `gorm:"index"`
+	From   *Application `gorm:"foreignKey:FromID;constraint:OnDelete:CASCADE"`
+}
+
+// Create a dependency synchronized using a mutex.
+func (r *Dependency) Create(db *gorm.DB) (err error) {
+	depMutex.Lock()
+	defer depMutex.Unlock()
+	err = db.Create(r).Error
+	return
+}
+
+// BeforeCreate detects cyclic dependencies.
+func (r *Dependency) BeforeCreate(db *gorm.DB) (err error) {
+	if r.FromID == r.ToID {
+		err = DependencyCyclicError{}
+		return
+	}
+	visited := make(map[uint]bool)
+	var queue []uint
+	queue = append(queue, r.FromID)
+	visited[r.FromID] = true
+	for len(queue) > 0 {
+		var deps []Dependency
+		err = db.Select("FromID").
+			Where("ToID IN ?", queue).
+			Find(&deps).Error
+		if err != nil {
+			return
+		}
+		queue = queue[:0]
+		for _, dep := range deps {
+			if dep.FromID == r.ToID {
+				err = DependencyCyclicError{}
+				return
+			}
+			if !visited[dep.FromID] {
+				visited[dep.FromID] = true
+				queue = append(queue, dep.FromID)
+			}
+		}
+	}
+
+	return
+}
+
+// DependencyCyclicError reports cyclic Dependency error.
+type DependencyCyclicError struct{}
+
+func (e DependencyCyclicError) Error() string {
+	return "Cyclic dependencies are not permitted."
+}
+
+type BusinessService struct {
+	Model
+	Name          string `gorm:"index;unique;not null"`
+	Description   string
+	Applications  []Application `gorm:"constraint:OnDelete:SET NULL"`
+	StakeholderID *uint         `gorm:"index"`
+	Stakeholder   *Stakeholder
+}
+
+type JobFunction struct {
+	Model
+	UUID         *string `gorm:"uniqueIndex"`
+	Username     string
+	Name         string        `gorm:"index;unique;not null"`
+	Stakeholders []Stakeholder `gorm:"constraint:OnDelete:SET NULL"`
+}
+
+type Stakeholder struct {
+	Model
+	Name             string             `gorm:"not null;"`
+	Email            string             `gorm:"index;unique;not null"`
+	Groups           []StakeholderGroup `gorm:"many2many:StakeholderGroupStakeholder;constraint:OnDelete:CASCADE"`
+	BusinessServices []BusinessService  `gorm:"constraint:OnDelete:SET NULL"`
+	JobFunctionID    *uint              `gorm:"index"`
+	JobFunction      *JobFunction
+	Owns             []Application   `gorm:"foreignKey:OwnerID;constraint:OnDelete:SET NULL"`
+	Contributes      []Application   `gorm:"many2many:ApplicationContributors;constraint:OnDelete:CASCADE"`
+	MigrationWaves   []MigrationWave `gorm:"many2many:MigrationWaveStakeholders;constraint:OnDelete:CASCADE"`
+	Assessments      []Assessment    `gorm:"many2many:AssessmentStakeholders;constraint:OnDelete:CASCADE"`
+	Archetypes       []Archetype     `gorm:"many2many:ArchetypeStakeholders;constraint:OnDelete:CASCADE"`
+}
+
+type StakeholderGroup struct {
+	Model
+	Name           string `gorm:"index;unique;not null"`
+	Username       string
+	Description    string
+	Stakeholders   []Stakeholder   `gorm:"many2many:StakeholderGroupStakeholder;constraint:OnDelete:CASCADE"`
+	MigrationWaves []MigrationWave `gorm:"many2many:MigrationWaveStakeholderGroups;constraint:OnDelete:CASCADE"`
+	Assessments    []Assessment    `gorm:"many2many:AssessmentStakeholderGroups;constraint:OnDelete:CASCADE"`
+	Archetypes     []Archetype     `gorm:"many2many:ArchetypeStakeholderGroups;constraint:OnDelete:CASCADE"`
+}
+
+type MigrationWave struct {
+	Model
+	Name              string             `gorm:"uniqueIndex:MigrationWaveA"`
+	StartDate         time.Time          `gorm:"uniqueIndex:MigrationWaveA"`
+	EndDate           time.Time          `gorm:"uniqueIndex:MigrationWaveA"`
+	Applications      []Application      `gorm:"constraint:OnDelete:SET NULL"`
+	Stakeholders      []Stakeholder      `gorm:"many2many:MigrationWaveStakeholders;constraint:OnDelete:CASCADE"`
+	StakeholderGroups []StakeholderGroup `gorm:"many2many:MigrationWaveStakeholderGroups;constraint:OnDelete:CASCADE"`
+}
+
+type Archetype struct {
+	Model
+	Name              string
+	Description       string
+	Comments          string
+	Selector          string
+	ParentID          *uint              `gorm:"index"`
+	Parent            *Archetype         `gorm:"constraint:OnDelete:SET NULL"`
+	Review            *Review            `gorm:"constraint:OnDelete:CASCADE"`
+	Assessments       []Assessment       `gorm:"constraint:OnDelete:CASCADE"`
+	CriteriaTags      []Tag              `gorm:"many2many:ArchetypeCriteriaTags;constraint:OnDelete:CASCADE"`
+	Tags              []Tag              `gorm:"many2many:ArchetypeTags;constraint:OnDelete:CASCADE"`
+	Stakeholders      []Stakeholder      `gorm:"many2many:ArchetypeStakeholders;constraint:OnDelete:CASCADE"`
+	StakeholderGroups []StakeholderGroup `gorm:"many2many:ArchetypeStakeholderGroups;constraint:OnDelete:CASCADE"`
+	Profiles          []TargetProfile    `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type TargetProfile struct {
+	Model
+	Name              string `gorm:"uniqueIndex:targetProfileA;not null"`
+	ArchetypeID       uint   `gorm:"uniqueIndex:targetProfileA;not null"`
+	Archetype         Archetype
+	Generators        []ProfileGenerator `gorm:"order:Index;constraint:OnDelete:CASCADE"`
+	AnalysisProfileID *uint              `gorm:"index"`
+	AnalysisProfile   *AnalysisProfile   `gorm:"constraint:OnDelete:SET NULL"`
+}
+
+type ProfileGenerator struct {
+	GeneratorID     uint `gorm:"index"`
+	TargetProfileID uint `gorm:"index;uniqueIndex:profileGeneratorA"`
+	Index           int  `gorm:"index;uniqueIndex:profileGeneratorA"`
+	TargetProfile   TargetProfile
+	Generator       Generator
+}
+
+type Tag struct {
+	Model
+	UUID       *string `gorm:"uniqueIndex"`
+	Name       string  `gorm:"uniqueIndex:tagA;not null"`
+	CategoryID uint    `gorm:"uniqueIndex:tagA;index;not null"`
+	Category   TagCategory
+}
+
+type TagCategory struct {
+	Model
+	UUID  *string `gorm:"uniqueIndex"`
+	Name  string  `gorm:"index;unique;not null"`
+	Color string
+	Tags  []Tag `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
+}
+
+type Ticket struct {
+	Model
+	// Kind of ticket in the external tracker.
+	Kind string `gorm:"not null"`
+	// Parent resource that this ticket should belong to in the tracker. (e.g. Jira project)
+	Parent string `gorm:"not null"`
+	// Custom fields to send to the tracker when creating the ticket
+	Fields json.Map `gorm:"type:json;serializer:json"`
+	// Whether the last attempt to do something with the ticket reported an error
+	Error bool
+	// Error message, if any
+	Message string
+	// Whether the ticket was created in the external tracker
+	Created bool
+	// Reference id in external tracker
+	Reference string
+	// URL to ticket in external tracker
+	Link string
+	// Status of ticket in external tracker
+	Status        string
+	LastUpdated   time.Time
+	Application   *Application
+	ApplicationID uint `gorm:"uniqueIndex:ticketA;not null"`
+	Tracker       *Tracker
+	TrackerID     uint `gorm:"uniqueIndex:ticketA;not null"`
+}
+
+type Tracker struct {
+	Model
+	Name        string `gorm:"index;unique;not null"`
+	URL         string
+	Kind        string
+	Identity    *Identity
+	IdentityID  uint
+	Connected   bool
+	LastUpdated time.Time
+	Message     string
+	Insecure    bool
+	Tickets     []Ticket
+}
+
+type Import struct {
+	Model
+	Filename            string
+	ApplicationName     string
+	BusinessService     string
+	Comments            string
+	Dependency          string
+	DependencyDirection string
+	Description         string
+	ErrorMessage        string
+	IsValid             bool
+	RecordType1         string
+	ImportSummary       ImportSummary
+	ImportSummaryID     uint `gorm:"index"`
+	Processed           bool
+	ImportTags          []ImportTag `gorm:"constraint:OnDelete:CASCADE"`
+	BinaryGroup         string
+	BinaryArtifact      string
+	BinaryVersion       string
+	BinaryPackaging     string
+	RepositoryKind      string
+	RepositoryURL       string
+	RepositoryBranch    string
+	RepositoryPath      string
+	Owner               string
+	Contributors        string
+	Action              string
+	Changes             []ImportChange `gorm:"type:json;serializer:json"`
+	Row                 int
+	Issues              []string          `gorm:"type:json;serializer:json"`
+	Facts               map[string]string `gorm:"type:json;serializer:json"`
+}
+
+func (r *Import) AsMap() (m map[string]any) {
+	m = make(map[string]any)
+	m["filename"] = r.Filename
+	m["applicationName"] = r.ApplicationName
+	// "Application Name" is necessary in order for
+	// the UI to display the error report correctly.
+	m["Application Name"] = r.ApplicationName
+	m["businessService"] = r.BusinessService
+	m["comments"] = r.Comments
+	m["dependency"] = r.Dependency
+	m["dependencyDirection"] = r.DependencyDirection
+	m["description"] = r.Description
+	m["errorMessage"] = r.ErrorMessage
+	m["isValid"] = r.IsValid
+	m["processed"] = r.Processed
+	m["recordType1"] = r.RecordType1
+	m["action"] = r.Action
+	m["changes"] = r.Changes
+	m["row"] = r.Row
+	m["issues"] = r.Issues
+	m["facts"] = r.Facts
+	for i, tag := range r.ImportTags {
+		m[fmt.Sprintf("category%v", i+1)] = tag.Category
+		m[fmt.Sprintf("tag%v", i+1)] = tag.Name
+	}
+	return
+}
+
+type ImportSummary struct {
+	Model
+	Content        []byte
+	Filename       string
+	ImportStatus   string
+	Imports        []Import `gorm:"constraint:OnDelete:CASCADE"`
+	CreateEntities bool
+	Mode           string
+	TagMode        string
+	MatchBy        string
+	DryRun         bool
+	Atomic         bool
+	Format         string
+	ProfileID      *uint `gorm:"index"`
+	Profile        *ImportProfile
+}
+
+type ImportTag struct {
+	Model
+	Name     string
+	Category string
+	ImportID uint `gorm:"index"`
+	Import   *Import
+}
+
+//
+// JSON Fields.
+//
+
+// ImportChange represents a field changed by an import.
+type ImportChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type ImportProfile struct {
+	Model
+	Name        string `gorm:"uniqueIndex;not null"`
+	Description string
+	RecordType  string
+	Columns     []ImportColumn `gorm:"type:json;serializer:json"`
+}
+
+type ImportColumn struct {
+	Name  string `json:"name"`
+	Field string `json:"field"`
+}
+
+// Repository represents an SCM repository.
+type Repository struct {
+	Kind   string `json:"kind"`
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+	Tag    string `json:"tag"`
+	Path   string `json:"path"`
+}
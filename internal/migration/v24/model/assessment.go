@@ -0,0 +1,199 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Questionnaire struct {
+	Model
+	UUID         *string `gorm:"uniqueIndex"`
+	Name         string  `gorm:"unique"`
+	Description  string
+	Required     bool
+	Version      uint
+	Sections     []Section    `gorm:"type:json;serializer:json"`
+	Thresholds   Thresholds   `gorm:"type:json;serializer:json"`
+	RiskMessages RiskMessages `gorm:"type:json;serializer:json"`
+	Scoring      Scoring      `gorm:"type:json;serializer:json"`
+	Assessments  []Assessment `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// Builtin returns true if this is a Konveyor-provided questionnaire.
+func (r *Questionnaire) Builtin() bool {
+	return r.UUID != nil
+}
+
+// AfterSave records a new version when the content has changed.
+func (r *Questionnaire) AfterSave(db *gorm.DB) (err error) {
+	db = db.Session(&gorm.Session{NewDB: true})
+	latest := &QuestionnaireVersion{}
+	err = db.Where("QuestionnaireID", r.ID).Order("Version DESC").Limit(1).Find(latest).Error
+	if err != nil {
+		return
+	}
+	if latest.ID == 0 || !latest.Matches(r) {
+		version := &QuestionnaireVersion{
+			QuestionnaireID: r.ID,
+			Version:         latest.Version + 1,
+			Sections:        r.Sections,
+			Thresholds:      r.Thresholds,
+			RiskMessages:    r.RiskMessages,
+			Scoring:         r.Scoring,
+		}
+		version.CreateUser = r.UpdateUser
+		if version.CreateUser == "" {
+			version.CreateUser = r.CreateUser
+		}
+		err = db.Omit(clause.Associations).Create(version).Error
+		if err != nil {
+			return
+		}
+		latest = version
+	}
+	if r.Version != latest.Version {
+		r.Version = latest.Version
+		err = db.Model(r).UpdateColumn("Version", r.Version).Error
+	}
+	return
+}
+
+// QuestionnaireVersion an (immutable) version of a questionnaire.
+type QuestionnaireVersion struct {
+	Model
+	QuestionnaireID uint          `gorm:"uniqueIndex:questionnaireVersionA;not null"`
+	Questionnaire   Questionnaire `gorm:"constraint:OnDelete:CASCADE"`
+	Version         uint          `gorm:"uniqueIndex:questionnaireVersionA;not null"`
+	Sections        []Section     `gorm:"type:json;serializer:json"`
+	Thresholds      Thresholds    `gorm:"type:json;serializer:json"`
+	RiskMessages    RiskMessages  `gorm:"type:json;serializer:json"`
+	Scoring         Scoring       `gorm:"type:json;serializer:json"`
+}
+
+// Matches returns true when the version content matches the questionnaire.
+func (r *QuestionnaireVersion) Matches(q *Questionnaire) (matched bool) {
+	type content struct {
+		Sections     []Section
+		Thresholds   Thresholds
+		RiskMessages RiskMessages
+		Scoring      Scoring
+	}
+	a, err := json.Marshal(content{r.Sections, r.Thresholds, r.RiskMessages, r.Scoring})
+	if err != nil {
+		return
+	}
+	b, err := json.Marshal(content{q.Sections, q.Thresholds, q.RiskMessages, q.Scoring})
+	if err != nil {
+		return
+	}
+	matched = bytes.Equal(a, b)
+	return
+}
+
+type Assessment struct {
+	Model
+	ApplicationID        *uint `gorm:"uniqueIndex:AssessmentA"`
+	Application          *Application
+	ArchetypeID          *uint `gorm:"uniqueIndex:AssessmentB"`
+	Archetype            *Archetype
+	QuestionnaireID      uint `gorm:"uniqueIndex:AssessmentA;uniqueIndex:AssessmentB"`
+	Questionnaire        Questionnaire
+	QuestionnaireVersion uint
+	Sections             []Section          `gorm:"type:json;serializer:json"`
+	Thresholds           Thresholds         `gorm:"type:json;serializer:json"`
+	RiskMessages         RiskMessages       `gorm:"type:json;serializer:json"`
+	Scoring              Scoring            `gorm:"type:json;serializer:json"`
+	Stakeholders         []Stakeholder      `gorm:"many2many:AssessmentStakeholders;constraint:OnDelete:CASCADE"`
+	StakeholderGroups    []StakeholderGroup `gorm:"many2many:AssessmentStakeholderGroups;constraint:OnDelete:CASCADE"`
+}
+
+type Review struct {
+	Model
+	BusinessCriticality uint   `gorm:"not null"`
+	EffortEstimate      string `gorm:"not null"`
+	ProposedAction      string `gorm:"not null"`
+	WorkPriority        uint   `gorm:"not null"`
+	Comments            string
+	ApplicationID       *uint `gorm:"uniqueIndex"`
+	Application         *Application
+	ArchetypeID         *uint `gorm:"uniqueIndex"`
+	Archetype           *Archetype
+}
+
+//
+// JSON Fields.
+//
+
+// Section represents a group of questions in a questionnaire.
+type Section struct {
+	Order     uint       `json:"order" yaml:"order"`
+	Name      string     `json:"name" yaml:"name"`
+	Questions []Question `json:"questions" yaml:"questions" binding:"min=1,dive"`
+	Comment   string     `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Weight    float64    `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// Question represents a question in a questionnaire.
+type Question struct {
+	ID          string           `json:"id,omitempty" yaml:"id,omitempty"`
+	Order       uint             `json:"order" yaml:"order"`
+	Text        string           `json:"text" yaml:"text"`
+	Explanation string           `json:"explanation" yaml:"explanation"`
+	IncludeFor  []CategorizedTag `json:"includeFor,omitempty" yaml:"includeFor,omitempty"`
+	ExcludeFor  []CategorizedTag `json:"excludeFor,omitempty" yaml:"excludeFor,omitempty"`
+	ShowWhen    []AnswerRef      `json:"showWhen,omitempty" yaml:"showWhen,omitempty"`
+	Weight      float64          `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Answers     []Answer         `json:"answers" yaml:"answers" binding:"min=1,dive"`
+}
+
+// AnswerRef references an answer (by order) to a question in the questionnaire.
+type AnswerRef struct {
+	Section  uint `json:"section" yaml:"section"`
+	Question uint `json:"question" yaml:"question"`
+	Answer   uint `json:"answer" yaml:"answer"`
+}
+
+// Answer represents an answer to a question in a questionnaire.
+type Answer struct {
+	Order            uint             `json:"order" yaml:"order"`
+	Text             string           `json:"text" yaml:"text"`
+	Risk             string           `json:"risk" yaml:"risk" binding:"oneof=red yellow green unknown"`
+	Rationale        string           `json:"rationale" yaml:"rationale"`
+	Mitigation       string           `json:"mitigation" yaml:"mitigation"`
+	Score            *float64         `json:"score,omitempty" yaml:"score,omitempty"`
+	ApplyTags        []CategorizedTag `json:"applyTags,omitempty" yaml:"applyTags,omitempty"`
+	AutoAnswerFor    []CategorizedTag `json:"autoAnswerFor,omitempty" yaml:"autoAnswerFor,omitempty"`
+	AutoAnswerWhen   string           `json:"autoAnswerWhen,omitempty" yaml:"autoAnswerWhen,omitempty"`
+	Selected         bool             `json:"selected,omitempty" yaml:"selected,omitempty"`
+	AutoAnswered     bool             `json:"autoAnswered,omitempty" yaml:"autoAnswered,omitempty"`
+	AutoAnswerReason string           `json:"autoAnswerReason,omitempty" yaml:"autoAnswerReason,omitempty"`
+}
+
+// CategorizedTag represents a human-readable pair of category and tag.
+type CategorizedTag struct {
+	Category string `json:"category" yaml:"category"`
+	Tag      string `json:"tag" yaml:"tag"`
+}
+
+// RiskMessages contains messages to display for each risk level.
+type RiskMessages struct {
+	Red     string `json:"red" yaml:"red"`
+	Yellow  string `json:"yellow" yaml:"yellow"`
+	Green   string `json:"green" yaml:"green"`
+	Unknown string `json:"unknown" yaml:"unknown"`
+}
+
+// Thresholds contains the threshold values for determining risk for the questionnaire.
+type Thresholds struct {
+	Red     uint `json:"red" yaml:"red"`
+	Yellow  uint `json:"yellow" yaml:"yellow"`
+	Unknown uint `json:"unknown" yaml:"unknown"`
+}
+
+// Scoring contains the (weighted) scoring for the questionnaire.
+type Scoring struct {
+	Formula string `json:"formula,omitempty" yaml:"formula,omitempty"`
+}
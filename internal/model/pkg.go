@@ -2,7 +2,7 @@ package model
 
 import (
 	"github.com/konveyor/tackle2-hub/internal/migration/json"
-	"github.com/konveyor/tackle2-hub/internal/migration/v23/model"
+	"github.com/konveyor/tackle2-hub/internal/migration/v24/model"
 )
 
 // Field (data) types.
@@ -28,8 +28,11 @@ type File = model.File
 type Fact = model.Fact
 type Generator = model.Generator
 type Identity = model.Identity
+type IdentitySecret = model.IdentitySecret
+type IdentityVerification = model.IdentityVerification
 type Import = model.Import
 type ImportSummary = model.ImportSummary
+type ImportProfile = model.ImportProfile
 type ImportTag = model.ImportTag
 type JobFunction = model.JobFunction
 type Manifest = model.Manifest
@@ -39,6 +42,7 @@ type Platform = model.Platform
 type ProfileGenerator = model.ProfileGenerator
 type Proxy = model.Proxy
 type Questionnaire = model.Questionnaire
+type QuestionnaireVersion = model.QuestionnaireVersion
 type Review = model.Review
 type Setting = model.Setting
 type RuleSet = model.RuleSet
@@ -62,6 +66,8 @@ type RsaKey = model.RsaKey
 type IdpIdentity = model.IdpIdentity
 type Grant = model.Grant
 type Token = model.Token
+type Idempotency = model.Idempotency
+type AuthEvent = model.AuthEvent
 
 // JSON fields
 type Ref = json.Ref
@@ -70,6 +76,8 @@ type Data = json.Data
 type Document = json.Document
 type ArchivedInsight = model.ArchivedInsight
 type Attachment = model.Attachment
+type ImportChange = model.ImportChange
+type ImportColumn = model.ImportColumn
 type Link = model.Link
 type Repository = model.Repository
 type TargetLabel = model.TargetLabel
@@ -84,7 +92,9 @@ type TargetSelection = model.TargetSelection
 type Section = model.Section
 type Question = model.Question
 type Answer = model.Answer
+type AnswerRef = model.AnswerRef
 type Thresholds = model.Thresholds
+type Scoring = model.Scoring
 type RiskMessages = model.RiskMessages
 type CategorizedTag = model.CategorizedTag
 
@@ -85,3 +85,26 @@ func (r *GrantReaper) Run() {
 		Log.Error(err, "")
 	}
 }
+
+// AuthEventReaper deletes expired authentication (audit) events.
+type AuthEventReaper struct {
+	// DB
+	DB *gorm.DB
+}
+
+// Run deletes events older than the TTL.
+func (r *AuthEventReaper) Run() {
+	Log.V(1).Info("Reaping auth events.")
+	mark := time.Now().Add(-Settings.Auth.Event.TTL)
+	result := r.DB.Delete(&model.AuthEvent{}, "createTime < ?", mark)
+	if result.Error != nil {
+		Log.Error(result.Error, "")
+		return
+	}
+	if result.RowsAffected > 0 {
+		Log.Info(
+			"Expired auth events deleted.",
+			"count",
+			result.RowsAffected)
+	}
+}
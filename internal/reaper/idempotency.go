@@ -0,0 +1,30 @@
+package reaper
+
+import (
+	"time"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"gorm.io/gorm"
+)
+
+// IdempotencyReaper deletes expired idempotency keys.
+type IdempotencyReaper struct {
+	// DB
+	DB *gorm.DB
+}
+
+// Run deletes expired idempotency keys.
+func (r *IdempotencyReaper) Run() {
+	Log.V(1).Info("Reaping idempotency keys.")
+	result := r.DB.Delete(&model.Idempotency{}, "expiration < ?", time.Now())
+	if result.Error != nil {
+		Log.Error(result.Error, "")
+		return
+	}
+	if result.RowsAffected > 0 {
+		Log.Info(
+			"Expired idempotency keys deleted.",
+			"count",
+			result.RowsAffected)
+	}
+}
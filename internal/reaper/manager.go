@@ -103,6 +103,12 @@ func (m *Manager) Iterate() {
 		&TokenReaper{
 			DB: m.DB,
 		},
+		&IdempotencyReaper{
+			DB: m.DB,
+		},
+		&AuthEventReaper{
+			DB: m.DB,
+		},
 	}
 	for _, r := range registered {
 		r.Run()
@@ -7,11 +7,13 @@ import (
 	"sync"
 
 	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/internal/identity"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/internal/secret"
 	"github.com/konveyor/tackle2-hub/shared/nas"
 	"github.com/konveyor/tackle2-hub/shared/scm"
 	"gorm.io/gorm"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var (
@@ -19,8 +21,8 @@ var (
 )
 
 // GetMirror returns a mirror for the remote.
-func GetMirror(db *gorm.DB, remote scm.Remote) (mirror *Mirror) {
-	mirror = mirrorMap.Find(db, remote)
+func GetMirror(db *gorm.DB, client k8s.Client, remote scm.Remote) (mirror *Mirror) {
+	mirror = mirrorMap.Find(db, client, remote)
 	return
 }
 
@@ -31,7 +33,7 @@ type MirrorMap struct {
 }
 
 // Find returns a mirror for the remote.
-func (m *MirrorMap) Find(db *gorm.DB, remote scm.Remote) (mirror *Mirror) {
+func (m *MirrorMap) Find(db *gorm.DB, client k8s.Client, remote scm.Remote) (mirror *Mirror) {
 	mirror = func() (mirror *Mirror) {
 		m.mutex.Lock()
 		defer m.mutex.Unlock()
@@ -40,6 +42,7 @@ func (m *MirrorMap) Find(db *gorm.DB, remote scm.Remote) (mirror *Mirror) {
 			mirror = &Mirror{
 				Remote: remote,
 				DB:     db,
+				Client: client,
 			}
 			m.content[remote.URL] = mirror
 		}
@@ -54,6 +57,7 @@ func (m *MirrorMap) Find(db *gorm.DB, remote scm.Remote) (mirror *Mirror) {
 // Mirror provides a (mirror) repository.
 type Mirror struct {
 	DB     *gorm.DB
+	Client k8s.Client
 	Remote scm.Remote
 	mutex  sync.Mutex
 }
@@ -91,16 +95,16 @@ func (m *Mirror) update() (err error) {
 		err = liberr.Wrap(err)
 		return
 	}
-	identity, err := m.identity()
+	id, err := m.identity()
 	if err != nil {
 		return
 	}
 	remote := scm.Remote{
 		URL:      m.Remote.URL,
-		Identity: identity,
+		Identity: id,
 	}
 	var r scm.SCM
-	r, err = New(m.DB, m.home(), remote)
+	r, err = New(m.DB, m.Client, m.home(), remote)
 	if err != nil {
 		return
 	}
@@ -134,6 +138,7 @@ func (m *Mirror) update() (err error) {
 }
 
 // identity returns the default source identity.
+// Identities backed by a (k8s) secret are resolved.
 func (m *Mirror) identity() (id *scm.Identity, err error) {
 	md := &model.Identity{}
 	db := m.DB
@@ -153,6 +158,11 @@ func (m *Mirror) identity() (id *scm.Identity, err error) {
 		err = liberr.Wrap(err)
 		return
 	}
+	resolver := identity.Resolver{Client: m.Client}
+	err = resolver.Resolve(md)
+	if err != nil {
+		return
+	}
 	id = &scm.Identity{}
 	id.ID = md.ID
 	id.Name = md.Name
@@ -4,10 +4,12 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/konveyor/tackle2-hub/internal/identity"
 	"github.com/konveyor/tackle2-hub/internal/model"
 	"github.com/konveyor/tackle2-hub/shared/scm"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var (
@@ -25,7 +27,8 @@ type Proxy = scm.Proxy
 type ProxyMap = scm.ProxyMap
 
 // New SCM repository factory.
-func New(db *gorm.DB, destDir string, remote Remote) (r SCM, err error) {
+// The client is used to resolve identities backed by (k8s) secrets.
+func New(db *gorm.DB, client k8s.Client, destDir string, remote Remote) (r SCM, err error) {
 	switch remote.Kind {
 	case "subversion":
 		m := model.Setting{}
@@ -41,7 +44,7 @@ func New(db *gorm.DB, destDir string, remote Remote) (r SCM, err error) {
 		svn.Remote = remote
 		svn.Path = destDir
 		svn.Home = filepath.Join(Home, ".svn", svn.Id())
-		svn.Proxies, err = proxyMap(db)
+		svn.Proxies, err = proxyMap(db, client)
 		if err != nil {
 			return
 		}
@@ -60,7 +63,7 @@ func New(db *gorm.DB, destDir string, remote Remote) (r SCM, err error) {
 		git.Remote = remote
 		git.Path = destDir
 		git.Home = filepath.Join(Home, ".git", git.Id())
-		git.Proxies, err = proxyMap(db)
+		git.Proxies, err = proxyMap(db, client)
 		if err != nil {
 			return
 		}
@@ -74,7 +77,7 @@ func New(db *gorm.DB, destDir string, remote Remote) (r SCM, err error) {
 }
 
 // proxyMap returns a map of proxies.
-func proxyMap(db *gorm.DB) (pm ProxyMap, err error) {
+func proxyMap(db *gorm.DB, client k8s.Client) (pm ProxyMap, err error) {
 	pm = make(ProxyMap)
 	var list []model.Proxy
 	db = db.Preload(clause.Associations)
@@ -94,6 +97,11 @@ func proxyMap(db *gorm.DB) (pm ProxyMap, err error) {
 			Excluded: p.Excluded,
 		}
 		if p.Identity != nil {
+			resolver := identity.Resolver{Client: client}
+			err = resolver.Resolve(p.Identity)
+			if err != nil {
+				return
+			}
 			proxy.Identity = &Identity{
 				ID:       p.Identity.ID,
 				Name:     p.Identity.Name,
@@ -0,0 +1,169 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LegacyKeyId the ID of the (legacy) passphrase key.
+// Secrets encrypted using the legacy key are not prefixed.
+const LegacyKeyId = "legacy"
+
+// retired key IDs.
+var retired = struct {
+	sync.RWMutex
+	ids map[string]bool
+}{
+	ids: make(map[string]bool),
+}
+
+// KeyNotFound reports a key not found (or retired).
+type KeyNotFound struct {
+	ID string
+}
+
+func (e *KeyNotFound) Error() string {
+	return fmt.Sprintf("encryption key '%s' not found.", e.ID)
+}
+
+func (e *KeyNotFound) Is(err error) (matched bool) {
+	var target *KeyNotFound
+	matched = errors.As(err, &target)
+	return
+}
+
+// KeyInUse reports a key that cannot be retired.
+type KeyInUse struct {
+	ID     string
+	Reason string
+}
+
+func (e *KeyInUse) Error() string {
+	return fmt.Sprintf("encryption key '%s' in use: %s", e.ID, e.Reason)
+}
+
+func (e *KeyInUse) Is(err error) (matched bool) {
+	var target *KeyInUse
+	matched = errors.As(err, &target)
+	return
+}
+
+// Keyring provides encryption using versioned keys.
+// Encrypted secrets are prefixed with the key ID: $<id>$<encrypted>.
+// Secrets encrypted using the legacy key are not prefixed.
+type Keyring struct {
+	// Current the ID of the key used to encrypt.
+	Current string
+	// keys by ID.
+	keys map[string]Cipher
+}
+
+// NewKeyring returns a keyring built using the settings.
+// Retired keys are omitted.
+func NewKeyring() (r *Keyring) {
+	r = &Keyring{}
+	r.Use(Settings.Passphrase)
+	for id, passphrase := range Settings.Keys {
+		r.Add(id, passphrase)
+	}
+	if Settings.KeyId != "" {
+		r.Current = Settings.KeyId
+	}
+	retired.RLock()
+	defer retired.RUnlock()
+	for id := range retired.ids {
+		delete(r.keys, id)
+	}
+	return
+}
+
+// Use sets the legacy (current) key using the passphrase.
+func (r *Keyring) Use(passphrase string) {
+	r.Add(LegacyKeyId, passphrase)
+	r.Current = LegacyKeyId
+}
+
+// Add a key.
+func (r *Keyring) Add(id, passphrase string) {
+	if r.keys == nil {
+		r.keys = make(map[string]Cipher)
+	}
+	cipher := &AESGCM{}
+	cipher.Use(passphrase)
+	r.keys[id] = cipher
+}
+
+// Ids returns the (sorted) key IDs.
+func (r *Keyring) Ids() (ids []string) {
+	for id := range r.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return
+}
+
+// Encrypt plain string using the current key.
+// Returns the (key ID prefixed) encrypted string.
+func (r *Keyring) Encrypt(plain string) (encrypted string, err error) {
+	if plain == "" {
+		encrypted = plain
+		return
+	}
+	cipher, found := r.keys[r.Current]
+	if !found {
+		err = &KeyNotFound{ID: r.Current}
+		return
+	}
+	encrypted, err = cipher.Encrypt(plain)
+	if err != nil {
+		return
+	}
+	if r.Current != LegacyKeyId {
+		encrypted = "$" + r.Current + "$" + encrypted
+	}
+	return
+}
+
+// Decrypt an encrypted string using the key
+// identified by the prefix.
+func (r *Keyring) Decrypt(encrypted string) (plain string, err error) {
+	if encrypted == "" {
+		plain = encrypted
+		return
+	}
+	id, encrypted := KeyId(encrypted)
+	cipher, found := r.keys[id]
+	if !found {
+		err = &KeyNotFound{ID: id}
+		return
+	}
+	plain, err = cipher.Decrypt(encrypted)
+	return
+}
+
+// KeyId returns the ID of the key used to encrypt
+// and the (unprefixed) encrypted string.
+func KeyId(encrypted string) (id, unprefixed string) {
+	id = LegacyKeyId
+	unprefixed = encrypted
+	if !strings.HasPrefix(encrypted, "$") {
+		return
+	}
+	part := strings.SplitN(encrypted[1:], "$", 2)
+	if len(part) == 2 && part[0] != "" {
+		id = part[0]
+		unprefixed = part[1]
+	}
+	return
+}
+
+// Retire a key.
+// The key can no longer be used to encrypt or decrypt.
+func Retire(id string) {
+	retired.Lock()
+	defer retired.Unlock()
+	retired.ids[id] = true
+}
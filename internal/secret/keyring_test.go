@@ -0,0 +1,267 @@
+package secret
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+func TestKeyring(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	legacy := &Keyring{}
+	legacy.Use("MyPassphrase")
+	encrypted, err := legacy.Encrypt("hello")
+	g.Expect(err).To(gomega.BeNil())
+	id, _ := KeyId(encrypted)
+	g.Expect(id).To(gomega.Equal(LegacyKeyId))
+
+	keyring := &Keyring{}
+	keyring.Use("MyPassphrase")
+	keyring.Add("k1", "first")
+	keyring.Add("k2", "second")
+	keyring.Current = "k2"
+	g.Expect(keyring.Ids()).To(gomega.Equal([]string{"k1", "k2", LegacyKeyId}))
+	//
+	// Decrypt legacy.
+	plain, err := keyring.Decrypt(encrypted)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(plain).To(gomega.Equal("hello"))
+	//
+	// Encrypt using the current key.
+	encrypted, err = keyring.Encrypt("hello")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(strings.HasPrefix(encrypted, "$k2$")).To(gomega.BeTrue())
+	id, unprefixed := KeyId(encrypted)
+	g.Expect(id).To(gomega.Equal("k2"))
+	g.Expect(unprefixed).To(gomega.Equal(strings.TrimPrefix(encrypted, "$k2$")))
+	plain, err = keyring.Decrypt(encrypted)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(plain).To(gomega.Equal("hello"))
+	//
+	// Key not found.
+	_, err = legacy.Decrypt(encrypted)
+	g.Expect(errors.Is(err, &KeyNotFound{})).To(gomega.BeTrue())
+}
+
+type rotated struct {
+	ID       uint `gorm:"primaryKey"`
+	Name     string
+	Password string `secret:""`
+	Key      string `secret:""`
+}
+
+func TestRotator(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	saved := *Settings
+	t.Cleanup(func() {
+		*Settings = saved
+	})
+	Settings.Passphrase = "MyPassphrase"
+	Settings.Keys = map[string]string{"k1": "first"}
+	Settings.KeyId = ""
+	Settings.Rotation.Batch = 2
+
+	db, err := gorm.Open(
+		sqlite.Open("file::memory:"),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(&rotated{}, &model.Setting{})
+	g.Expect(err).To(gomega.BeNil())
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		m := &rotated{Name: name, Password: "password-" + name}
+		err = Encrypt(m)
+		g.Expect(err).To(gomega.BeNil())
+		err = db.Create(m).Error
+		g.Expect(err).To(gomega.BeNil())
+	}
+	rotator := &Rotator{
+		DB:     db,
+		Models: []any{rotated{}, struct{ Name string }{}},
+	}
+	refs, err := rotator.References()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(refs).To(gomega.Equal(map[string]int{LegacyKeyId: 5}))
+	//
+	// Rotate.
+	Settings.KeyId = "k1"
+	rotation, err := rotator.Start()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(rotation.KeyId).To(gomega.Equal("k1"))
+	g.Eventually(func() string {
+		return rotator.Status().State
+	}, 5*time.Second, 10*time.Millisecond).Should(gomega.Equal(RotationSucceeded))
+	rotation = rotator.Status()
+	g.Expect(rotation.Kinds).To(gomega.Equal(
+		[]RotatedKind{
+			{Kind: "rotated", Total: 5, Processed: 5, Rotated: 5},
+		}))
+	refs, err = rotator.References()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(refs).To(gomega.Equal(map[string]int{"k1": 5}))
+	m := &rotated{}
+	err = db.First(m, "Name", "c").Error
+	g.Expect(err).To(gomega.BeNil())
+	err = Decrypt(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.Password).To(gomega.Equal("password-c"))
+	//
+	// Rotate (updated concurrently).
+	Settings.Keys["k2"] = "second"
+	Settings.KeyId = "k2"
+	stale := &rotated{}
+	err = db.First(stale, "Name", "d").Error
+	g.Expect(err).To(gomega.BeNil())
+	fetched := stale.Password
+	m = &rotated{ID: stale.ID, Password: "updated"}
+	err = Encrypt(m)
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Model(m).Select("Password").Updates(m).Error
+	g.Expect(err).To(gomega.BeNil())
+	done, failed, skipped, err := rotator.rotate(NewKeyring(), stale, rotator.fields(reflect.TypeOf(*stale)))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(done).To(gomega.BeFalse())
+	g.Expect(failed).To(gomega.BeFalse())
+	g.Expect(skipped).To(gomega.BeTrue())
+	m = &rotated{}
+	err = db.First(m, "Name", "d").Error
+	g.Expect(err).To(gomega.BeNil())
+	err = Decrypt(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.Password).To(gomega.Equal("updated"))
+	delete(Settings.Keys, "k2")
+	Settings.KeyId = "k1"
+	err = db.Model(m).Select("Password").Updates(&rotated{Password: fetched}).Error
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Retire.
+	err = rotator.Retire("k1")
+	g.Expect(errors.Is(err, &KeyInUse{})).To(gomega.BeTrue())
+	err = rotator.Retire("unknown")
+	g.Expect(errors.Is(err, &KeyNotFound{})).To(gomega.BeTrue())
+	err = rotator.Retire(LegacyKeyId)
+	g.Expect(err).To(gomega.BeNil())
+	t.Cleanup(func() {
+		retired.Lock()
+		defer retired.Unlock()
+		delete(retired.ids, LegacyKeyId)
+	})
+	g.Expect(NewKeyring().Ids()).To(gomega.Equal([]string{"k1"}))
+	//
+	// Load (persisted).
+	retired.Lock()
+	delete(retired.ids, LegacyKeyId)
+	retired.Unlock()
+	err = rotator.Load()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(NewKeyring().Ids()).To(gomega.Equal([]string{"k1"}))
+	//
+	// Load (referenced key not found).
+	delete(Settings.Keys, "k1")
+	err = rotator.Load()
+	g.Expect(errors.Is(err, &KeyNotFound{})).To(gomega.BeTrue())
+}
+
+func TestRotatorManifest(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	saved := *Settings
+	t.Cleanup(func() {
+		*Settings = saved
+	})
+	Settings.Passphrase = "MyPassphrase"
+	Settings.Keys = map[string]string{"k1": "first"}
+	Settings.KeyId = ""
+
+	db, err := gorm.Open(
+		sqlite.Open("file::memory:"),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(&model.Manifest{}, &model.Setting{})
+	g.Expect(err).To(gomega.BeNil())
+	m := &model.Manifest{
+		Content: model.Map{"name": "test"},
+		Secret: model.Map{
+			"user":     "user",
+			"password": "password",
+		},
+	}
+	err = Encrypt(m)
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Omit(clause.Associations).Create(m).Error
+	g.Expect(err).To(gomega.BeNil())
+	rotator := &Rotator{
+		DB:     db,
+		Models: []any{model.Manifest{}},
+	}
+	//
+	// Rotate.
+	Settings.KeyId = "k1"
+	_, err = rotator.Start()
+	g.Expect(err).To(gomega.BeNil())
+	g.Eventually(func() string {
+		return rotator.Status().State
+	}, 5*time.Second, 10*time.Millisecond).Should(gomega.Equal(RotationSucceeded))
+	rotation := rotator.Status()
+	g.Expect(rotation.Kinds).To(gomega.Equal(
+		[]RotatedKind{
+			{Kind: "Manifest", Total: 1, Processed: 1, Rotated: 1},
+		}))
+	refs, err := rotator.References()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(refs).To(gomega.Equal(map[string]int{"k1": 2}))
+	m = &model.Manifest{}
+	err = db.First(m).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = Decrypt(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.Secret).To(gomega.Equal(
+		model.Map{
+			"user":     "user",
+			"password": "password",
+		}))
+	//
+	// Rotate (updated concurrently).
+	Settings.Keys["k2"] = "second"
+	Settings.KeyId = "k2"
+	stale := &model.Manifest{}
+	err = db.First(stale).Error
+	g.Expect(err).To(gomega.BeNil())
+	m = &model.Manifest{Secret: model.Map{"password": "updated"}}
+	m.ID = stale.ID
+	err = Encrypt(m)
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Model(m).Select("Secret").Updates(m).Error
+	g.Expect(err).To(gomega.BeNil())
+	done, failed, skipped, err := rotator.rotate(NewKeyring(), stale, rotator.fields(reflect.TypeOf(*stale)))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(done).To(gomega.BeFalse())
+	g.Expect(failed).To(gomega.BeFalse())
+	g.Expect(skipped).To(gomega.BeTrue())
+	m = &model.Manifest{}
+	err = db.First(m).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = Decrypt(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.Secret).To(gomega.Equal(model.Map{"password": "updated"}))
+}
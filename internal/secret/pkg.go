@@ -20,9 +20,7 @@ type Cipher interface {
 // - struct - (string) fields with `secret:` tag are encrypted.
 // - map[string]any - string fields are encrypted.
 func Encrypt(object any) (err error) {
-	cipher := &AESGCM{}
-	cipher.Use(Settings.Passphrase)
-	secret := Secret{Cipher: cipher}
+	secret := Secret{Cipher: NewKeyring()}
 	err = secret.Encrypt(object)
 	err = liberr.Wrap(err)
 	return
@@ -34,9 +32,7 @@ func Encrypt(object any) (err error) {
 // - struct - (string) fields with `secret:` tag are decrypted.
 // - map[string]any - string fields are decrypted.
 func Decrypt(object any) (err error) {
-	cipher := &AESGCM{}
-	cipher.Use(Settings.Passphrase)
-	secret := Secret{Cipher: cipher}
+	secret := Secret{Cipher: NewKeyring()}
 	err = secret.Decrypt(object)
 	err = liberr.Wrap(err)
 	return
@@ -48,9 +44,7 @@ func Decrypt(object any) (err error) {
 // - struct - (string) fields with `secret:` tag are encoded based on tag (value).
 // - map[string]any - string fields are encrypted.
 func Encode(object any) (fields []Field, err error) {
-	cipher := &AESGCM{}
-	cipher.Use(Settings.Passphrase)
-	secret := Secret{Cipher: cipher}
+	secret := Secret{Cipher: NewKeyring()}
 	fields, err = secret.Encode(object)
 	err = liberr.Wrap(err)
 	return
@@ -62,9 +56,7 @@ func Encode(object any) (fields []Field, err error) {
 // - struct - (string) fields with `secret:` tag are decoded based on tag (value).
 // - map[string]any - string fields are decrypted.
 func Decode(object any) (err error) {
-	cipher := &AESGCM{}
-	cipher.Use(Settings.Passphrase)
-	secret := Secret{Cipher: cipher}
+	secret := Secret{Cipher: NewKeyring()}
 	_, err = secret.Decode(object)
 	err = liberr.Wrap(err)
 	return
@@ -76,6 +68,12 @@ func Fields(object any) (fields []Field, err error) {
 	return
 }
 
+// References returns the number of encrypted fields by key ID.
+func References(object any) (refs map[string]int, err error) {
+	refs, err = Secret{}.References(object)
+	return
+}
+
 // Redact updates the value of secret fields with a `mask`.
 func Redact(object any, mask string) (err error) {
 	err = Secret{}.Redact(object, mask)
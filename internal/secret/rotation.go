@@ -0,0 +1,467 @@
+package secret
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var Log = logr.New("secret", 0)
+
+// RetiredKey the setting used to persist the retired key IDs.
+const RetiredKey = "encryption.retired"
+
+// Rotation states.
+const (
+	RotationReady     = "Ready"
+	RotationRunning   = "Running"
+	RotationSucceeded = "Succeeded"
+	RotationFailed    = "Failed"
+)
+
+// RotationInProgress reports a rotation already in progress.
+type RotationInProgress struct {
+}
+
+func (e *RotationInProgress) Error() string {
+	return "key rotation in progress."
+}
+
+func (e *RotationInProgress) Is(err error) (matched bool) {
+	var target *RotationInProgress
+	matched = errors.As(err, &target)
+	return
+}
+
+// RotatedKind reports the rotation of a model (kind).
+type RotatedKind struct {
+	// Kind the model kind.
+	Kind string
+	// Total number of rows.
+	Total int
+	// Processed number of rows.
+	Processed int
+	// Rotated number of rows (re-encrypted).
+	Rotated int
+	// Failed number of rows that could not be decrypted.
+	Failed int
+	// Skipped number of rows updated (concurrently) during the rotation.
+	Skipped int
+}
+
+// Rotation reports the progress of a key rotation.
+type Rotation struct {
+	State      string
+	KeyId      string
+	Started    time.Time
+	Terminated time.Time
+	Kinds      []RotatedKind
+	Error      string
+}
+
+// Rotator re-encrypts secret fields using the current key.
+type Rotator struct {
+	// DB
+	DB *gorm.DB
+	// Models to be rotated.
+	// Models without encrypted fields are ignored.
+	Models []any
+	// rotation (status).
+	rotation Rotation
+	mutex    sync.Mutex
+}
+
+// Start a rotation (in the background).
+// Each model (kind) is processed in batches.
+func (r *Rotator) Start() (rotation Rotation, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.rotation.State == RotationRunning {
+		err = &RotationInProgress{}
+		return
+	}
+	keyring := NewKeyring()
+	r.rotation = Rotation{
+		State:   RotationRunning,
+		KeyId:   keyring.Current,
+		Started: time.Now(),
+	}
+	for _, mt := range r.encrypted() {
+		r.rotation.Kinds = append(
+			r.rotation.Kinds,
+			RotatedKind{
+				Kind: mt.Name(),
+			})
+	}
+	rotation = r.status()
+	go func() {
+		Log.Info("Rotation started.", "key", keyring.Current)
+		err := r.run(keyring)
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.rotation.Terminated = time.Now()
+		if err != nil {
+			Log.Error(err, "Rotation failed.")
+			r.rotation.State = RotationFailed
+			r.rotation.Error = err.Error()
+		} else {
+			Log.Info("Rotation succeeded.", "key", keyring.Current)
+			r.rotation.State = RotationSucceeded
+		}
+	}()
+	return
+}
+
+// Status returns the rotation status.
+func (r *Rotator) Status() (rotation Rotation) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	rotation = r.status()
+	return
+}
+
+// References returns the number of encrypted fields by key ID.
+func (r *Rotator) References() (refs map[string]int, err error) {
+	refs = make(map[string]int)
+	for _, mt := range r.encrypted() {
+		err = r.batched(mt, func(object any) (err error) {
+			found, err := References(object)
+			if err != nil {
+				return
+			}
+			for id, n := range found {
+				refs[id] += n
+			}
+			return
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Load the (persisted) retired keys.
+// Fails when a key referenced by encrypted fields is not found
+// or has been retired.
+func (r *Rotator) Load() (err error) {
+	ids, err := r.retired()
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		Retire(id)
+	}
+	refs, err := r.References()
+	if err != nil {
+		return
+	}
+	keyring := NewKeyring()
+	for id := range refs {
+		if !slices.Contains(keyring.Ids(), id) {
+			err = liberr.Wrap(&KeyNotFound{ID: id})
+			return
+		}
+	}
+	return
+}
+
+// Retire a key.
+// The current key and keys referenced by encrypted fields
+// cannot be retired. The retirement is persisted.
+func (r *Rotator) Retire(id string) (err error) {
+	keyring := NewKeyring()
+	found := false
+	for _, kid := range keyring.Ids() {
+		if kid == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		err = &KeyNotFound{ID: id}
+		return
+	}
+	if id == keyring.Current {
+		err = &KeyInUse{ID: id, Reason: "current key."}
+		return
+	}
+	if r.Status().State == RotationRunning {
+		err = &RotationInProgress{}
+		return
+	}
+	refs, err := r.References()
+	if err != nil {
+		return
+	}
+	n := refs[id]
+	if n > 0 {
+		err = &KeyInUse{ID: id, Reason: "referenced by encrypted fields."}
+		return
+	}
+	err = r.persist(id)
+	if err != nil {
+		return
+	}
+	Retire(id)
+	return
+}
+
+// retired returns the (persisted) retired key IDs.
+func (r *Rotator) retired() (ids []string, err error) {
+	var list []model.Setting
+	db := r.DB.Where("Key", RetiredKey)
+	err = db.Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	if len(list) == 0 {
+		return
+	}
+	err = list[0].As(&ids)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+// persist the retired key ID.
+func (r *Rotator) persist(id string) (err error) {
+	ids, err := r.retired()
+	if err != nil {
+		return
+	}
+	if slices.Contains(ids, id) {
+		return
+	}
+	ids = append(ids, id)
+	setting := &model.Setting{Key: RetiredKey}
+	db := r.DB.Where("Key", RetiredKey)
+	err = db.FirstOrCreate(setting).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	setting.Value = ids
+	err = r.DB.Save(setting).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+// run the rotation.
+func (r *Rotator) run(keyring *Keyring) (err error) {
+	for i, mt := range r.encrypted() {
+		var total int64
+		err = r.DB.Model(reflect.New(mt).Interface()).Count(&total).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		r.update(func() {
+			r.rotation.Kinds[i].Total = int(total)
+		})
+		fields := r.fields(mt)
+		err = r.batched(mt, func(object any) (err error) {
+			rotated, failed, skipped, err := r.rotate(keyring, object, fields)
+			r.update(func() {
+				kind := &r.rotation.Kinds[i]
+				kind.Processed++
+				if rotated {
+					kind.Rotated++
+				}
+				if failed {
+					kind.Failed++
+				}
+				if skipped {
+					kind.Skipped++
+				}
+			})
+			return
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// rotate re-encrypts the object fields using the current key.
+// Objects with fields that cannot be decrypted are reported as failed.
+// The update is conditional on the fields not having been changed since
+// fetched. Objects changed (and encrypted using the current key) by
+// concurrent writes are reported as skipped.
+func (r *Rotator) rotate(keyring *Keyring, object any, fields []string) (rotated, failed, skipped bool, err error) {
+	secret := Secret{Cipher: keyring}
+	refs, err := secret.References(object)
+	if err != nil {
+		return
+	}
+	for id := range refs {
+		if id != keyring.Current {
+			rotated = true
+			break
+		}
+	}
+	if !rotated {
+		return
+	}
+	fetched, err := r.values(object, fields)
+	if err != nil {
+		return
+	}
+	dErr := secret.Decrypt(object)
+	if dErr != nil {
+		Log.Error(dErr, "Decrypt failed.")
+		rotated = false
+		failed = true
+		return
+	}
+	err = secret.Encrypt(object)
+	if err != nil {
+		return
+	}
+	db := r.DB.Model(object)
+	db = db.Select(fields)
+	for i, name := range fields {
+		column := clause.Column{Name: name}
+		if fetched[i] == nil {
+			db = db.Where("? IS NULL", column)
+		} else {
+			db = db.Where("CAST(? AS TEXT) = ?", column, fetched[i])
+		}
+	}
+	result := db.Updates(object)
+	if result.Error != nil {
+		err = liberr.Wrap(result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		rotated = false
+		skipped = true
+	}
+	return
+}
+
+// values returns the (column) values of the named fields as stored.
+// Fields other than strings are stored using the (gorm) json serializer.
+// Values are compared as TEXT because serialized fields are stored as BLOB.
+func (r *Rotator) values(object any, fields []string) (values []any, err error) {
+	mv := reflect.ValueOf(object).Elem()
+	for _, name := range fields {
+		fv := mv.FieldByName(name)
+		if fv.Kind() == reflect.String {
+			values = append(values, fv.String())
+			continue
+		}
+		var b []byte
+		b, err = json.Marshal(fv.Interface())
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		if string(b) == "null" {
+			values = append(values, nil)
+		} else {
+			values = append(values, string(b))
+		}
+	}
+	return
+}
+
+// batched calls fn for each row of the model (kind).
+// Rows are fetched in batches ordered by ID.
+func (r *Rotator) batched(mt reflect.Type, fn func(object any) error) (err error) {
+	batch := Settings.Rotation.Batch
+	if batch < 1 {
+		batch = 100
+	}
+	last := uint64(0)
+	for {
+		list := reflect.New(reflect.SliceOf(reflect.PointerTo(mt)))
+		db := r.DB.Where("ID > ?", last)
+		db = db.Order("ID")
+		db = db.Limit(batch)
+		err = db.Find(list.Interface()).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		items := list.Elem()
+		for i := 0; i < items.Len(); i++ {
+			object := items.Index(i)
+			last = object.Elem().FieldByName("ID").Uint()
+			err = fn(object.Interface())
+			if err != nil {
+				return
+			}
+		}
+		if items.Len() < batch {
+			break
+		}
+	}
+	return
+}
+
+// encrypted returns the model (struct) types with encrypted fields.
+func (r *Rotator) encrypted() (kinds []reflect.Type) {
+	for _, m := range r.Models {
+		mt := reflect.TypeOf(m)
+		if mt.Kind() == reflect.Ptr {
+			mt = mt.Elem()
+		}
+		if mt.Kind() != reflect.Struct {
+			continue
+		}
+		if len(r.fields(mt)) > 0 {
+			kinds = append(kinds, mt)
+		}
+	}
+	return
+}
+
+// fields returns the names of the encrypted (root) fields.
+func (r *Rotator) fields(mt reflect.Type) (names []string) {
+	for i := 0; i < mt.NumField(); i++ {
+		ft := mt.Field(i)
+		tag, found := ft.Tag.Lookup("secret")
+		if !found || !ft.IsExported() {
+			continue
+		}
+		switch tag {
+		case "", TagEncrypted:
+			names = append(names, ft.Name)
+		}
+	}
+	return
+}
+
+// update the rotation status.
+func (r *Rotator) update(fn func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	fn()
+}
+
+// status returns a copy of the rotation status.
+func (r *Rotator) status() (rotation Rotation) {
+	rotation = r.rotation
+	rotation.Kinds = append([]RotatedKind{}, r.rotation.Kinds...)
+	if rotation.State == "" {
+		rotation.State = RotationReady
+	}
+	return
+}
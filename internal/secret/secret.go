@@ -156,6 +156,26 @@ func (r Secret) Fields(object any) (fields []Field, err error) {
 	return
 }
 
+// References returns the number of encrypted fields by key ID.
+func (r Secret) References(object any) (refs map[string]int, err error) {
+	refs = make(map[string]int)
+	selector := func(f Field) func(string) string {
+		return func(in string) (out string) {
+			out = in
+			switch f.tag {
+			case "", TagEncrypted:
+				if in != "" {
+					id, _ := KeyId(in)
+					refs[id]++
+				}
+			}
+			return
+		}
+	}
+	_, err = r.Update(object, selector, Field{})
+	return
+}
+
 // Redact updates the value of secret fields with a `mask`.
 func (r Secret) Redact(object any, mask string) (err error) {
 	selector := func(f Field) func(string) string {
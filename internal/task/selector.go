@@ -37,8 +37,21 @@ func NewSelector(db *gorm.DB, task *Task) (selector *Selector) {
 	return
 }
 
+// NewPredicateSelector returns a selector with the specified predicates.
+// The predicates are keyed by kind and matched using the pattern.
+func NewPredicateSelector(pattern *regexp.Regexp, predicate map[string]Predicate) (selector *Selector) {
+	selector = &Selector{
+		pattern:   pattern,
+		predicate: predicate,
+	}
+	return
+}
+
 // Selector used to match addons and extensions.
 type Selector struct {
+	// pattern used to match predicates.
+	// Default: PredRegex.
+	pattern   *regexp.Regexp
 	predicate map[string]Predicate
 }
 
@@ -48,8 +61,12 @@ func (r *Selector) Match(selector string) (matched bool, err error) {
 		matched = true
 		return
 	}
+	pattern := r.pattern
+	if pattern == nil {
+		pattern = PredRegex
+	}
 	params := make(map[string]string)
-	found := PredRegex.FindAllStringSubmatch(selector, -1)
+	found := pattern.FindAllStringSubmatch(selector, -1)
 	for _, m := range found {
 		kind := m[1]
 		p, found := r.predicate[kind]
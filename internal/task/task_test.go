@@ -3,6 +3,7 @@ package task
 import (
 	"bytes"
 	"io"
+	"regexp"
 	"strconv"
 	"testing"
 
@@ -465,6 +466,11 @@ func TestPredRegex(t *testing.T) {
 
 	matches = PredRegex.FindAllStringSubmatch(":Language=Java", -1)
 	g.Expect(len(matches)).To(gomega.Equal(0))
+
+	// Values may contain parentheses.
+	matches = PredRegex.FindAllStringSubmatch("tag:Language=Java(EE)", -1)
+	g.Expect(len(matches)).To(gomega.Equal(1))
+	g.Expect(matches[0][2]).To(gomega.Equal("Language=Java(EE)"))
 }
 
 func TestSelector(t *testing.T) {
@@ -489,6 +495,11 @@ func TestSelector(t *testing.T) {
 	m, err = selector.Match("T:false || T:false")
 	g.Expect(err).To(gomega.BeNil())
 	g.Expect(m).To(gomega.BeFalse())
+	// Grouped using a pattern which excludes parentheses.
+	selector.pattern = regexp.MustCompile(`(\w+):([^\s|&()]+)`)
+	m, err = selector.Match("!(T:false || T:false) && T:true")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m).To(gomega.BeTrue())
 }
 
 type _TestPredicate struct {
@@ -30,6 +30,7 @@ type Application struct {
 	Assessed        bool          `json:"assessed"`
 	Risk            string        `json:"risk"`
 	Confidence      int           `json:"confidence"`
+	Score           float64       `json:"score"`
 	Effort          int           `json:"effort"`
 }
 
@@ -190,10 +191,58 @@ type Import map[string]any
 // ImportSummary REST resource.
 type ImportSummary struct {
 	Resource       `yaml:",inline"`
-	Filename       string    `json:"filename"`
-	ImportStatus   string    `json:"importStatus" yaml:"importStatus"`
-	ImportTime     time.Time `json:"importTime" yaml:"importTime"`
-	ValidCount     int       `json:"validCount" yaml:"validCount"`
-	InvalidCount   int       `json:"invalidCount" yaml:"invalidCount"`
-	CreateEntities bool      `json:"createEntities" yaml:"createEntities"`
+	Filename       string         `json:"filename"`
+	ImportStatus   string         `json:"importStatus" yaml:"importStatus"`
+	ImportTime     time.Time      `json:"importTime" yaml:"importTime"`
+	ValidCount     int            `json:"validCount" yaml:"validCount"`
+	InvalidCount   int            `json:"invalidCount" yaml:"invalidCount"`
+	CreateEntities bool           `json:"createEntities" yaml:"createEntities"`
+	Mode           string         `json:"mode"`
+	TagMode        string         `json:"tagMode" yaml:"tagMode"`
+	MatchBy        string         `json:"matchBy" yaml:"matchBy"`
+	DryRun         bool           `json:"dryRun" yaml:"dryRun"`
+	Atomic         bool           `json:"atomic"`
+	Format         string         `json:"format,omitempty" yaml:",omitempty"`
+	Profile        *Ref           `json:"profile,omitempty" yaml:",omitempty"`
+	Changes        []ImportChange `json:"changes,omitempty" yaml:",omitempty"`
+	Report         []ImportRow    `json:"report,omitempty" yaml:",omitempty"`
+}
+
+// ImportChange REST nested resource.
+// A field changed by an import.
+type ImportChange struct {
+	Import      uint   `json:"import"`
+	Application string `json:"application"`
+	Field       string `json:"field"`
+	Before      string `json:"before"`
+	After       string `json:"after"`
+}
+
+// ImportProfile REST resource.
+// Maps source columns onto import fields.
+type ImportProfile struct {
+	Resource    `yaml:",inline"`
+	Name        string         `json:"name" binding:"required"`
+	Description string         `json:"description,omitempty" yaml:",omitempty"`
+	RecordType  string         `json:"recordType,omitempty" yaml:"recordType,omitempty"`
+	Columns     []ImportColumn `json:"columns" binding:"required,min=1,dive"`
+}
+
+// ImportColumn REST nested resource.
+// Maps a source column onto an import field.
+type ImportColumn struct {
+	Name  string `json:"name" binding:"required"`
+	Field string `json:"field" binding:"required"`
+}
+
+// ImportRow REST nested resource.
+// The (dry-run) validation report for an imported row.
+type ImportRow struct {
+	Import      uint     `json:"import"`
+	Row         int      `json:"row"`
+	RecordType  string   `json:"recordType" yaml:"recordType"`
+	Application string   `json:"application"`
+	Action      string   `json:"action,omitempty" yaml:",omitempty"`
+	Valid       bool     `json:"valid"`
+	Issues      []string `json:"issues,omitempty" yaml:",omitempty"`
 }
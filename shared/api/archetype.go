@@ -11,20 +11,59 @@ type TargetProfile struct {
 // Archetype REST resource.
 type Archetype struct {
 	Resource          `yaml:",inline"`
-	Name              string          `json:"name" yaml:"name"`
-	Description       string          `json:"description" yaml:"description"`
-	Comments          string          `json:"comments" yaml:"comments"`
-	Tags              []TagRef        `json:"tags" yaml:"tags"`
-	Criteria          []TagRef        `json:"criteria" yaml:"criteria"`
-	Stakeholders      []Ref           `json:"stakeholders" yaml:"stakeholders"`
-	StakeholderGroups []Ref           `json:"stakeholderGroups" yaml:"stakeholderGroups"`
-	Applications      []Ref           `json:"applications" yaml:"applications"`
-	Assessments       []Ref           `json:"assessments" yaml:"assessments"`
-	Assessed          bool            `json:"assessed"`
-	Risk              string          `json:"risk"`
-	Confidence        int             `json:"confidence"`
-	Review            *Ref            `json:"review"`
-	Profiles          []TargetProfile `json:"profiles" yaml:",omitempty"`
+	Name              string              `json:"name" yaml:"name"`
+	Description       string              `json:"description" yaml:"description"`
+	Comments          string              `json:"comments" yaml:"comments"`
+	Tags              []TagRef            `json:"tags" yaml:"tags"`
+	Criteria          []TagRef            `json:"criteria" yaml:"criteria"`
+	Selector          string              `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Parent            *Ref                `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Stakeholders      []Ref               `json:"stakeholders" yaml:"stakeholders"`
+	StakeholderGroups []Ref               `json:"stakeholderGroups" yaml:"stakeholderGroups"`
+	Applications      []Ref               `json:"applications" yaml:"applications"`
+	Assessments       []Ref               `json:"assessments" yaml:"assessments"`
+	Assessed          bool                `json:"assessed"`
+	Risk              string              `json:"risk"`
+	Confidence        int                 `json:"confidence"`
+	Score             float64             `json:"score"`
+	Review            *Ref                `json:"review"`
+	Profiles          []TargetProfile     `json:"profiles" yaml:",omitempty"`
+	Inherited         *ArchetypeInherited `json:"inherited,omitempty" yaml:",omitempty"`
+}
+
+// ArchetypeInherited (read-only) resources inherited from ancestor archetypes.
+type ArchetypeInherited struct {
+	// Ancestors (parent first).
+	Ancestors         []Ref           `json:"ancestors"`
+	Stakeholders      []Ref           `json:"stakeholders,omitempty"`
+	StakeholderGroups []Ref           `json:"stakeholderGroups,omitempty"`
+	Assessments       []Ref           `json:"assessments,omitempty"`
+	Profiles          []TargetProfile `json:"profiles,omitempty"`
+}
+
+// Membership explains the archetype membership of an application.
+type Membership struct {
+	Archetype Ref `json:"archetype"`
+	// Matched the archetype criteria is matched.
+	Matched bool `json:"matched"`
+	// Member the archetype is (one of) the most specific matched.
+	Member bool `json:"member"`
+	// Missing criteria tags not assigned to the application.
+	Missing []Ref `json:"missing,omitempty" yaml:",omitempty"`
+	// Selector the criteria expression.
+	Selector string `json:"selector,omitempty" yaml:",omitempty"`
+	// Predicates the evaluated selector predicates.
+	Predicates []MembershipPredicate `json:"predicates,omitempty" yaml:",omitempty"`
+	// Superseded by more specific (matched) archetypes.
+	Superseded []Ref `json:"superseded,omitempty" yaml:",omitempty"`
+	// Error selector evaluation error.
+	Error string `json:"error,omitempty" yaml:",omitempty"`
+}
+
+// MembershipPredicate an evaluated selector predicate.
+type MembershipPredicate struct {
+	Predicate string `json:"predicate"`
+	Matched   bool   `json:"matched"`
 }
 
 // Generator REST resource.
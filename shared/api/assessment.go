@@ -2,19 +2,22 @@ package api
 
 // Assessment REST resource.
 type Assessment struct {
-	Resource          `yaml:",inline"`
-	Application       *Ref         `json:"application,omitempty" yaml:",omitempty" binding:"excluded_with=Archetype"`
-	Archetype         *Ref         `json:"archetype,omitempty" yaml:",omitempty" binding:"excluded_with=Application"`
-	Questionnaire     Ref          `json:"questionnaire" binding:"required"`
-	Sections          []Section    `json:"sections" binding:"dive"`
-	Stakeholders      []Ref        `json:"stakeholders"`
-	StakeholderGroups []Ref        `json:"stakeholderGroups" yaml:"stakeholderGroups"`
-	Risk              string       `json:"risk"`
-	Confidence        int          `json:"confidence"`
-	Status            string       `json:"status"`
-	Thresholds        Thresholds   `json:"thresholds"`
-	RiskMessages      RiskMessages `json:"riskMessages" yaml:"riskMessages"`
-	Required          bool         `json:"required"`
+	Resource             `yaml:",inline"`
+	Application          *Ref         `json:"application,omitempty" yaml:",omitempty" binding:"excluded_with=Archetype"`
+	Archetype            *Ref         `json:"archetype,omitempty" yaml:",omitempty" binding:"excluded_with=Application"`
+	Questionnaire        Ref          `json:"questionnaire" binding:"required"`
+	QuestionnaireVersion uint         `json:"questionnaireVersion,omitempty" yaml:"questionnaireVersion,omitempty"`
+	Sections             []Section    `json:"sections" binding:"dive"`
+	Stakeholders         []Ref        `json:"stakeholders"`
+	StakeholderGroups    []Ref        `json:"stakeholderGroups" yaml:"stakeholderGroups"`
+	Risk                 string       `json:"risk"`
+	Confidence           int          `json:"confidence"`
+	Score                float64      `json:"score"`
+	Status               string       `json:"status"`
+	Thresholds           Thresholds   `json:"thresholds"`
+	RiskMessages         RiskMessages `json:"riskMessages" yaml:"riskMessages"`
+	Scoring              Scoring      `json:"scoring,omitempty" yaml:"scoring,omitempty"`
+	Required             bool         `json:"required"`
 }
 
 // Section assessment section.
@@ -23,29 +26,45 @@ type Section struct {
 	Name      string     `json:"name" yaml:"name"`
 	Questions []Question `json:"questions" yaml:"questions" binding:"min=1,dive"`
 	Comment   string     `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Weight    float64    `json:"weight,omitempty" yaml:"weight,omitempty"`
 }
 
 // Question represents a question in a questionnaire.
 type Question struct {
+	ID          string           `json:"id,omitempty" yaml:"id,omitempty"`
 	Order       uint             `json:"order" yaml:"order"`
 	Text        string           `json:"text" yaml:"text"`
 	Explanation string           `json:"explanation" yaml:"explanation"`
 	IncludeFor  []CategorizedTag `json:"includeFor,omitempty" yaml:"includeFor,omitempty"`
 	ExcludeFor  []CategorizedTag `json:"excludeFor,omitempty" yaml:"excludeFor,omitempty"`
+	ShowWhen    []AnswerRef      `json:"showWhen,omitempty" yaml:"showWhen,omitempty"`
+	Weight      float64          `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Hidden      bool             `json:"hidden,omitempty" yaml:"hidden,omitempty"`
 	Answers     []Answer         `json:"answers" yaml:"answers" binding:"min=1,dive"`
 }
 
+// AnswerRef references an answer (by order) to a question in the questionnaire.
+// The question is shown only when one of the referenced answers is selected.
+type AnswerRef struct {
+	Section  uint `json:"section" yaml:"section"`
+	Question uint `json:"question" yaml:"question"`
+	Answer   uint `json:"answer" yaml:"answer"`
+}
+
 // Answer represents an answer to a question in a questionnaire.
 type Answer struct {
-	Order         uint             `json:"order" yaml:"order"`
-	Text          string           `json:"text" yaml:"text"`
-	Risk          string           `json:"risk" yaml:"risk" binding:"oneof=red yellow green unknown"`
-	Rationale     string           `json:"rationale" yaml:"rationale"`
-	Mitigation    string           `json:"mitigation" yaml:"mitigation"`
-	ApplyTags     []CategorizedTag `json:"applyTags,omitempty" yaml:"applyTags,omitempty"`
-	AutoAnswerFor []CategorizedTag `json:"autoAnswerFor,omitempty" yaml:"autoAnswerFor,omitempty"`
-	Selected      bool             `json:"selected,omitempty" yaml:"selected,omitempty"`
-	AutoAnswered  bool             `json:"autoAnswered,omitempty" yaml:"autoAnswered,omitempty"`
+	Order            uint             `json:"order" yaml:"order"`
+	Text             string           `json:"text" yaml:"text"`
+	Risk             string           `json:"risk" yaml:"risk" binding:"oneof=red yellow green unknown"`
+	Rationale        string           `json:"rationale" yaml:"rationale"`
+	Mitigation       string           `json:"mitigation" yaml:"mitigation"`
+	Score            *float64         `json:"score,omitempty" yaml:"score,omitempty"`
+	ApplyTags        []CategorizedTag `json:"applyTags,omitempty" yaml:"applyTags,omitempty"`
+	AutoAnswerFor    []CategorizedTag `json:"autoAnswerFor,omitempty" yaml:"autoAnswerFor,omitempty"`
+	AutoAnswerWhen   string           `json:"autoAnswerWhen,omitempty" yaml:"autoAnswerWhen,omitempty"`
+	Selected         bool             `json:"selected,omitempty" yaml:"selected,omitempty"`
+	AutoAnswered     bool             `json:"autoAnswered,omitempty" yaml:"autoAnswered,omitempty"`
+	AutoAnswerReason string           `json:"autoAnswerReason,omitempty" yaml:"autoAnswerReason,omitempty"`
 }
 
 // CategorizedTag represents a human-readable pair of category and tag.
@@ -69,13 +88,61 @@ type RiskMessages struct {
 	Unknown string `json:"unknown" yaml:"unknown"`
 }
 
+// Scoring the (weighted) scoring.
+// The formula maps the scoring to a risk (red, yellow, green, unknown).
+// Variables: score (0-100) and the weighted percentage of questions
+// by risk: red, yellow, green and unknown.
+type Scoring struct {
+	Formula string `json:"formula,omitempty" yaml:"formula,omitempty"`
+}
+
 type Questionnaire struct {
 	Resource     `yaml:",inline"`
 	Name         string       `json:"name" yaml:"name" binding:"required"`
 	Description  string       `json:"description" yaml:"description"`
 	Required     bool         `json:"required" yaml:"required"`
+	Version      uint         `json:"version,omitempty" yaml:"version,omitempty"`
 	Sections     []Section    `json:"sections" yaml:"sections" binding:"required,min=1,dive"`
 	Thresholds   Thresholds   `json:"thresholds" yaml:"thresholds" binding:"required"`
 	RiskMessages RiskMessages `json:"riskMessages" yaml:"riskMessages" binding:"required"`
+	Scoring      Scoring      `json:"scoring,omitempty" yaml:"scoring,omitempty"`
 	Builtin      bool         `json:"builtin,omitempty" yaml:"builtin,omitempty"`
 }
+
+// QuestionnaireVersion an (immutable) questionnaire version.
+type QuestionnaireVersion struct {
+	Resource      `yaml:",inline"`
+	Questionnaire Ref          `json:"questionnaire"`
+	Version       uint         `json:"version"`
+	Sections      []Section    `json:"sections"`
+	Thresholds    Thresholds   `json:"thresholds"`
+	RiskMessages  RiskMessages `json:"riskMessages" yaml:"riskMessages"`
+	Scoring       Scoring      `json:"scoring,omitempty" yaml:"scoring,omitempty"`
+}
+
+// AssessmentUpgrade upgrades an assessment to the latest
+// questionnaire version and reports how answers were carried forward.
+type AssessmentUpgrade struct {
+	// DryRun reports without updating the assessment.
+	DryRun bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+	// From the previous questionnaire version.
+	From uint `json:"from"`
+	// To the new questionnaire version.
+	To uint `json:"to"`
+	// Carried answers carried forward.
+	Carried []UpgradedQuestion `json:"carried,omitempty" yaml:",omitempty"`
+	// Dropped answers that could not be carried forward.
+	Dropped []UpgradedQuestion `json:"dropped,omitempty" yaml:",omitempty"`
+	// Reanswer questions needing to be (re)answered.
+	Reanswer []UpgradedQuestion `json:"reanswer,omitempty" yaml:",omitempty"`
+}
+
+// UpgradedQuestion a question reported by an assessment upgrade.
+type UpgradedQuestion struct {
+	Section  uint   `json:"section"`
+	Question uint   `json:"question"`
+	ID       string `json:"id,omitempty" yaml:"id,omitempty"`
+	Text     string `json:"text"`
+	Answer   string `json:"answer,omitempty" yaml:"answer,omitempty"`
+	Reason   string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
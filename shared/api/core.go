@@ -71,9 +71,12 @@ type ConfigMap struct {
 }
 
 // Service REST resource.
+// Status: Ready|Unavailable|NotConfigured.
 type Service struct {
-	Name  string `json:"name"`
-	Route string `json:"route"`
+	Name   string `json:"name"`
+	Route  string `json:"route"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty" yaml:",omitempty"`
 }
 
 // File REST resource.
@@ -92,6 +95,35 @@ type Bucket struct {
 	Expiration *time.Time `json:"expiration,omitempty"`
 }
 
+// SecretKey REST resource.
+// An encryption key.
+type SecretKey struct {
+	ID         string `json:"id"`
+	Current    bool   `json:"current"`
+	References int    `json:"references"`
+}
+
+// SecretRotation REST resource.
+// The progress of a key rotation.
+type SecretRotation struct {
+	State      string              `json:"state"`
+	KeyId      string              `json:"keyId,omitempty" yaml:"keyId,omitempty"`
+	Started    *time.Time          `json:"started,omitempty" yaml:",omitempty"`
+	Terminated *time.Time          `json:"terminated,omitempty" yaml:",omitempty"`
+	Kinds      []SecretRotatedKind `json:"kinds,omitempty" yaml:",omitempty"`
+	Error      string              `json:"error,omitempty" yaml:",omitempty"`
+}
+
+// SecretRotatedKind the rotation of a resource kind.
+type SecretRotatedKind struct {
+	Kind      string `json:"kind"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Rotated   int    `json:"rotated"`
+	Failed    int    `json:"failed"`
+	Skipped   int    `json:"skipped"`
+}
+
 // Identity REST resource.
 type Identity struct {
 	Resource    `yaml:",inline"`
@@ -103,6 +135,34 @@ type Identity struct {
 	Password    string `json:"password"`
 	Key         string `json:"key"`
 	Settings    string `json:"settings"`
+	// Secret references a (k8s) secret containing the credentials.
+	// The user, password, key and settings are resolved (from the secret)
+	// when decrypted rather than stored.
+	Secret *IdentitySecret `json:"secret,omitempty" yaml:",omitempty"`
+	// Verification (read-only) the last verification.
+	// Cleared when the identity is updated.
+	Verification *IdentityVerification `json:"verification,omitempty" yaml:",omitempty"`
+}
+
+// IdentitySecret references a (k8s) secret.
+// Fields are the keys within the secret. Defaults:
+// user: username, password: password, key: ssh-privatekey, settings: settings.
+type IdentitySecret struct {
+	Name     string `json:"name" binding:"required"`
+	User     string `json:"user,omitempty" yaml:",omitempty"`
+	Password string `json:"password,omitempty" yaml:",omitempty"`
+	Key      string `json:"key,omitempty" yaml:",omitempty"`
+	Settings string `json:"settings,omitempty" yaml:",omitempty"`
+}
+
+// IdentityVerification reports the verification of the
+// credentials against the target.
+type IdentityVerification struct {
+	Verified    time.Time `json:"verified"`
+	Succeeded   bool      `json:"succeeded"`
+	Application uint      `json:"application,omitempty" yaml:",omitempty"`
+	Target      string    `json:"target,omitempty" yaml:",omitempty"`
+	Error       string    `json:"error,omitempty" yaml:",omitempty"`
 }
 
 // Proxy REST resource.
@@ -175,6 +235,9 @@ type User struct {
 	Name     string `json:"name"`
 	Password string `json:"password" binding:"required,max=72"`
 	Email    string `json:"email" binding:"required"`
+	Disabled bool   `json:"disabled,omitempty" yaml:",omitempty"`
+	Mfa      bool   `json:"mfa,omitempty" yaml:",omitempty"`
+	Locked   bool   `json:"locked,omitempty" yaml:",omitempty"`
 	Roles    []Ref  `json:"roles"`
 	Tokens   []Ref  `json:"tokens"`
 }
@@ -253,3 +316,66 @@ func (p *PAT) String() string {
 
 // APIKey alias.
 type APIKey = PAT
+
+// MfaEnrollment REST resource.
+// The secret and URI are returned when enrollment begins; the
+// recovery codes are returned (once) when the enrollment is confirmed.
+type MfaEnrollment struct {
+	Secret        string   `json:"secret,omitempty" yaml:",omitempty"`
+	URI           string   `json:"uri,omitempty" yaml:",omitempty"`
+	RecoveryCodes []string `json:"recoveryCodes,omitempty" yaml:"recoveryCodes,omitempty"`
+}
+
+// MfaCode REST resource.
+type MfaCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// AuthEvent REST resource.
+type AuthEvent struct {
+	Resource  `yaml:",inline"`
+	Subject   string `json:"subject,omitempty" yaml:",omitempty"`
+	Login     string `json:"login,omitempty" yaml:",omitempty"`
+	Method    string `json:"method"`
+	Source    string `json:"source,omitempty" yaml:",omitempty"`
+	Succeeded bool   `json:"succeeded"`
+	Reason    string `json:"reason,omitempty" yaml:",omitempty"`
+}
+
+// TokenIntrospection token introspection (RFC 7662) response.
+// Task is the task to which the token is bound.
+type TokenIntrospection struct {
+	Active     bool   `json:"active"`
+	Scope      string `json:"scope,omitempty"`
+	ClientId   string `json:"client_id,omitempty"`
+	Username   string `json:"username,omitempty"`
+	TokenType  string `json:"token_type,omitempty"`
+	Expiration int64  `json:"exp,omitempty"`
+	IssuedAt   int64  `json:"iat,omitempty"`
+	Subject    string `json:"sub,omitempty"`
+	Issuer     string `json:"iss,omitempty"`
+	TokenId    string `json:"jti,omitempty"`
+	Task       uint   `json:"task,omitempty"`
+}
+
+// Batch REST resource.
+type Batch struct {
+	// Mode: atomic|item. (default: atomic).
+	Mode       string           `json:"mode,omitempty" yaml:",omitempty"`
+	Committed  bool             `json:"committed"`
+	Operations []BatchOperation `json:"operations" binding:"required,dive"`
+}
+
+// BatchOperation REST nested resource.
+type BatchOperation struct {
+	Method string       `json:"method" binding:"required"`
+	Path   string       `json:"path" binding:"required"`
+	Body   any          `json:"body,omitempty" yaml:",omitempty"`
+	Result *BatchResult `json:"result,omitempty" yaml:",omitempty"`
+}
+
+// BatchResult REST nested resource.
+type BatchResult struct {
+	Status int `json:"status"`
+	Body   any `json:"body,omitempty" yaml:",omitempty"`
+}
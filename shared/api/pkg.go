@@ -9,6 +9,8 @@ const (
 	Wildcard  = "wildcard"
 	FileField = "file"
 	Decrypted = "decrypted"
+	Fields    = "fields"
+	Sort      = "sort"
 )
 
 // Headers
@@ -19,6 +21,9 @@ const (
 	Directory       = "X-Directory"
 	DirectoryExpand = "expand"
 	Total           = "X-Total"
+	IdempotencyKey  = "Idempotency-Key"
+	Replayed        = "Idempotent-Replayed"
+	RetryAfter      = "Retry-After"
 )
 
 // MIME Types
@@ -26,7 +31,10 @@ const (
 	MIMEOCTETSTREAM = "application/octet-stream"
 	MIMEJSON        = "application/json"
 	MIMEYAML        = "application/x-yaml"
+	MIMECSV         = "text/csv"
+	MIMEXLSX        = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	TAR             = "application/x-tar"
+	MIMESCIM        = "application/scim+json"
 )
 
 // Schema Params
@@ -116,6 +124,7 @@ const (
 	AppStakeholdersRoute  = ApplicationRoute + "/stakeholders"
 	AppAssessmentsRoute   = ApplicationRoute + "/assessments"
 	AppAssessmentRoute    = AppAssessmentsRoute + "/:" + ID2
+	AppMembershipRoute    = ApplicationRoute + "/membership"
 )
 
 // Routes - Archetypes
@@ -127,8 +136,9 @@ const (
 
 // Routes - Assessments
 const (
-	AssessmentsRoute = "/assessments"
-	AssessmentRoute  = AssessmentsRoute + "/:" + ID
+	AssessmentsRoute       = "/assessments"
+	AssessmentRoute        = AssessmentsRoute + "/:" + ID
+	AssessmentUpgradeRoute = AssessmentRoute + "/upgrade"
 )
 
 // Routes - Batch
@@ -138,6 +148,12 @@ const (
 	BatchTagsRoute    = BatchRoute + TagsRoute
 )
 
+// Batch modes.
+const (
+	BatchAtomic = "atomic"
+	BatchItem   = "item"
+)
+
 // Routes - Buckets
 const (
 	BucketsRoute       = "/buckets"
@@ -184,15 +200,18 @@ const (
 
 // Routes - Identities
 const (
-	IdentitiesRoute    = "/identities"
-	IdentityRoute      = IdentitiesRoute + "/:" + ID
-	AppIdentitiesRoute = ApplicationRoute + "/identities"
+	IdentitiesRoute     = "/identities"
+	IdentityRoute       = IdentitiesRoute + "/:" + ID
+	IdentityVerifyRoute = IdentityRoute + "/verify"
+	AppIdentitiesRoute  = ApplicationRoute + "/identities"
 )
 
 // Routes - RBAC
 const (
 	UsersRoute                = "/users"
 	UserRoute                 = UsersRoute + "/:" + ID
+	UserMfaRoute              = UserRoute + "/mfa"
+	UserLockRoute             = UserRoute + "/lock"
 	ServiceAccountsRoute      = "/serviceaccounts"
 	ServiceAccountRoute       = ServiceAccountsRoute + "/:" + ID
 	ServiceAccountTokensRoute = ServiceAccountRoute + "/tokens"
@@ -200,6 +219,16 @@ const (
 	RoleRoute                 = RolesRoute + "/:" + ID
 )
 
+// Routes - SCIM
+const (
+	ScimRoute                      = "/scim/v2"
+	ScimUsersRoute                 = ScimRoute + "/Users"
+	ScimUserRoute                  = ScimUsersRoute + "/:" + ID
+	ScimGroupsRoute                = ScimRoute + "/Groups"
+	ScimGroupRoute                 = ScimGroupsRoute + "/:" + ID
+	ScimServiceProviderConfigRoute = ScimRoute + "/ServiceProviderConfig"
+)
+
 // Routes - Auth
 const (
 	AuthRoute            = "/auth"
@@ -210,6 +239,9 @@ const (
 	AuthTokensRoute      = AuthRoute + "/tokens"
 	AuthTokenRoute       = AuthTokensRoute + "/:" + ID
 	AuthTokenRevokeRoute = AuthTokenRoute + "/revoke"
+	AuthMfaRoute         = AuthRoute + "/mfa"
+	AuthMfaConfirmRoute  = AuthMfaRoute + "/confirm"
+	AuthEventsRoute      = AuthRoute + "/events"
 	AuthDevAuthRoute     = AuthRoute + "/device"
 	AuthDevAuthCallback  = AuthDevAuthRoute + "/callback"
 	IdpIdentitiesRoute   = AuthRoute + "/identities"
@@ -234,6 +266,8 @@ const (
 	DeviceLoginRoute = "/device/login"
 	DeviceCbRoute    = "/device/callback"
 	AuthorizeCbRoute = "/authorize/callback"
+	IntrospectRoute  = "/introspect"
+	RevokeRoute      = "/revoke"
 )
 
 // Routes - Imports
@@ -242,10 +276,45 @@ const (
 	SummaryRoute   = SummariesRoute + "/:" + ID
 	UploadRoute    = SummariesRoute + "/upload"
 	DownloadRoute  = SummariesRoute + "/download"
+	CommitRoute    = SummaryRoute + "/commit"
 	ImportsRoute   = "/imports"
 	ImportRoute    = ImportsRoute + "/:" + ID
 )
 
+// Routes - Import profiles
+const (
+	ImportProfilesRoute = "/importprofiles"
+	ImportProfileRoute  = ImportProfilesRoute + "/:" + ID
+	ImportTemplateRoute = ImportProfileRoute + "/template"
+)
+
+// Import formats.
+const (
+	ImportCSV  = "csv"
+	ImportXLSX = "xlsx"
+	ImportJSON = "json"
+	ImportYAML = "yaml"
+)
+
+// Import modes.
+const (
+	ImportCreate = "create"
+	ImportUpdate = "update"
+	ImportUpsert = "upsert"
+)
+
+// Import tag modes.
+const (
+	ImportTagAdd     = "add"
+	ImportTagReplace = "replace"
+)
+
+// Import (application) matching.
+const (
+	ImportMatchName       = "name"
+	ImportMatchRepository = "repository"
+)
+
 // Routes - Job Functions
 const (
 	JobFunctionsRoute = "/jobfunctions"
@@ -281,8 +350,10 @@ const (
 
 // Routes - Questionnaires
 const (
-	QuestionnairesRoute = "/questionnaires"
-	QuestionnaireRoute  = QuestionnairesRoute + "/:" + ID
+	QuestionnairesRoute        = "/questionnaires"
+	QuestionnaireRoute         = QuestionnairesRoute + "/:" + ID
+	QuestionnaireVersionsRoute = QuestionnaireRoute + "/versions"
+	QuestionnaireVersionRoute  = QuestionnaireVersionsRoute + "/:" + ID2
 )
 
 // Routes - Reviews
@@ -306,6 +377,13 @@ const (
 	SchemaFindRoute = SchemaRoute + "/jsd/:" + Domain + "/:" + Variant + "/:" + Subject
 )
 
+// Routes - Secrets (encryption)
+const (
+	SecretKeysRoute     = "/secrets/keys"
+	SecretKeyRoute      = SecretKeysRoute + "/:" + Key
+	SecretRotationRoute = "/secrets/rotation"
+)
+
 // Routes - Services
 const (
 	ServicesRoute      = "/services"
@@ -313,6 +391,13 @@ const (
 	ServiceNestedRoute = ServiceRoute + "/*" + Wildcard
 )
 
+// Service status.
+const (
+	ServiceReady         = "Ready"
+	ServiceUnavailable   = "Unavailable"
+	ServiceNotConfigured = "NotConfigured"
+)
+
 // Routes - Settings
 const (
 	SettingsRoute = "/settings"
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"time"
+)
+
+// SCIM schemas.
+const (
+	ScimSchemaUser      = "urn:ietf:params:scim:schemas:core:2.0:User"
+	ScimSchemaGroup     = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	ScimSchemaList      = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	ScimSchemaPatch     = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ScimSchemaError     = "urn:ietf:params:scim:api:messages:2.0:Error"
+	ScimSchemaSpConfig  = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	ScimResourceUser    = "User"
+	ScimResourceGroup   = "Group"
+	ScimParamFilter     = "filter"
+	ScimParamStartIndex = "startIndex"
+	ScimParamCount      = "count"
+)
+
+// SCIM patch operations.
+const (
+	ScimOpAdd     = "add"
+	ScimOpRemove  = "remove"
+	ScimOpReplace = "replace"
+)
+
+// ScimUser SCIM (2.0) user.
+// Maps to a hub User. The userName is the login.
+type ScimUser struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *ScimName    `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []ScimValue  `json:"emails,omitempty"`
+	Password    string       `json:"password,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []ScimMember `json:"groups,omitempty"`
+	Meta        *ScimMeta    `json:"meta,omitempty"`
+}
+
+// ScimName SCIM user name.
+type ScimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// ScimValue SCIM multi-valued attribute.
+type ScimValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// ScimGroup SCIM (2.0) group.
+// Maps to a hub Role.
+type ScimGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []ScimMember `json:"members,omitempty"`
+	Meta        *ScimMeta    `json:"meta,omitempty"`
+}
+
+// ScimMember SCIM group member (or user group).
+type ScimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// ScimMeta SCIM resource metadata.
+type ScimMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// ScimList SCIM list response.
+type ScimList struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// ScimPatch SCIM patch request.
+type ScimPatch struct {
+	Schemas    []string        `json:"schemas"`
+	Operations []ScimOperation `json:"Operations"`
+}
+
+// ScimOperation SCIM patch operation.
+type ScimOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// ScimError SCIM error response.
+type ScimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// ScimServiceProviderConfig SCIM service provider configuration.
+type ScimServiceProviderConfig struct {
+	Schemas        []string      `json:"schemas"`
+	Patch          ScimSupported `json:"patch"`
+	Bulk           ScimSupported `json:"bulk"`
+	Filter         ScimSupported `json:"filter"`
+	ChangePassword ScimSupported `json:"changePassword"`
+	Sort           ScimSupported `json:"sort"`
+	Etag           ScimSupported `json:"etag"`
+}
+
+// ScimSupported SCIM feature support.
+type ScimSupported struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults,omitempty"`
+}
@@ -1,6 +1,8 @@
 package application
 
 import (
+	"strings"
+
 	"github.com/konveyor/tackle2-hub/shared/api"
 	"github.com/konveyor/tackle2-hub/shared/binding/bucket"
 	"github.com/konveyor/tackle2-hub/shared/binding/client"
@@ -37,6 +39,23 @@ func (h Application) List() (list []api.Application, err error) {
 	return
 }
 
+// Find Applications with filter.
+// The (optional) sort is a list of fields. Example: desc:score
+func (h Application) Find(filter client.Filter, sort ...string) (list []api.Application, err error) {
+	list = []api.Application{}
+	param := []client.Param{filter.Param()}
+	if len(sort) > 0 {
+		param = append(
+			param,
+			client.Param{
+				Key:   api.Sort,
+				Value: strings.Join(sort, ","),
+			})
+	}
+	err = h.client.Get(api.ApplicationsRoute, &list, param...)
+	return
+}
+
 // Update an Application.
 func (h Application) Update(r *api.Application) (err error) {
 	path := client.Path(api.ApplicationRoute).Inject(client.Params{api.ID: r.ID})
@@ -66,6 +85,14 @@ func (h Application) UpdateStakeholders(id uint, stakeholders *api.Stakeholders)
 	return
 }
 
+// Membership explains the archetype membership of an application.
+func (h Application) Membership(id uint) (list []api.Membership, err error) {
+	list = []api.Membership{}
+	path := client.Path(api.AppMembershipRoute).Inject(client.Params{api.ID: id})
+	err = h.client.Get(path, &list)
+	return
+}
+
 // Select returns the API for a selected application.
 func (h Application) Select(id uint) (h2 Selected) {
 	h2 = Selected{}
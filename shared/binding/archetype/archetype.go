@@ -1,6 +1,8 @@
 package archetype
 
 import (
+	"strings"
+
 	"github.com/konveyor/tackle2-hub/shared/api"
 	"github.com/konveyor/tackle2-hub/shared/binding/client"
 )
@@ -36,6 +38,23 @@ func (h Archetype) List() (list []api.Archetype, err error) {
 	return
 }
 
+// Find Archetypes with filter.
+// The (optional) sort is a list of fields. Example: desc:score
+func (h Archetype) Find(filter client.Filter, sort ...string) (list []api.Archetype, err error) {
+	list = []api.Archetype{}
+	param := []client.Param{filter.Param()}
+	if len(sort) > 0 {
+		param = append(
+			param,
+			client.Param{
+				Key:   api.Sort,
+				Value: strings.Join(sort, ","),
+			})
+	}
+	err = h.client.Get(api.ArchetypesRoute, &list, param...)
+	return
+}
+
 // Update a Archetype.
 func (h Archetype) Update(r *api.Archetype) (err error) {
 	path := client.Path(api.ArchetypeRoute).Inject(client.Params{api.ID: r.ID})
@@ -36,3 +36,11 @@ func (h Assessment) Delete(id uint) (err error) {
 	err = h.client.Delete(Path(api.AssessmentRoute).Inject(Params{api.ID: id}))
 	return
 }
+
+// Upgrade an Assessment to the latest questionnaire version.
+func (h Assessment) Upgrade(id uint, dryRun bool) (r *api.AssessmentUpgrade, err error) {
+	r = &api.AssessmentUpgrade{DryRun: dryRun}
+	path := Path(api.AssessmentUpgradeRoute).Inject(Params{api.ID: id})
+	err = h.client.Post(path, r)
+	return
+}
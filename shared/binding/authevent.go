@@ -0,0 +1,25 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// AuthEvent API.
+// Authentication (audit) events.
+type AuthEvent struct {
+	client RestClient
+}
+
+// List AuthEvents.
+func (h AuthEvent) List() (list []api.AuthEvent, err error) {
+	list = []api.AuthEvent{}
+	err = h.client.Get(api.AuthEventsRoute, &list)
+	return
+}
+
+// Find AuthEvents.
+func (h AuthEvent) Find(filter Filter) (list []api.AuthEvent, err error) {
+	list = []api.AuthEvent{}
+	err = h.client.Get(api.AuthEventsRoute, &list, filter.Param())
+	return
+}
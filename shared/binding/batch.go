@@ -0,0 +1,17 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// Batch API.
+type Batch struct {
+	client RestClient
+}
+
+// Create (execute) a Batch.
+// The operation results are populated.
+func (h Batch) Create(r *api.Batch) (err error) {
+	err = h.client.Post(api.BatchRoute, r)
+	return
+}
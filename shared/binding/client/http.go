@@ -2,6 +2,8 @@ package client
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,6 +39,8 @@ type Client struct {
 	auth AuthMethod
 	// Retry limit.
 	Retry uint8
+	// Idempotent POST requests are sent with an Idempotency-Key.
+	Idempotent bool
 	// Error
 	Error error
 }
@@ -56,6 +60,12 @@ func (r *Client) SetRetry(n uint8) {
 	r.Retry = n
 }
 
+// SetIdempotent set whether POST requests are sent with
+// an Idempotency-Key when retries are not enabled.
+func (r *Client) SetIdempotent(b bool) {
+	r.Idempotent = b
+}
+
 // SetTransport set the transport.
 func (r *Client) SetTransport(tp *http.Transport) {
 	r.transport = tp.Clone()
@@ -141,10 +151,10 @@ func (r *Client) Post(path string, object any) (err error) {
 	}
 	status := response.StatusCode
 	switch status {
-	case http.StatusAccepted:
 	case http.StatusNoContent:
 	case http.StatusOK,
-		http.StatusCreated:
+		http.StatusCreated,
+		http.StatusAccepted:
 		var body []byte
 		body, err = io.ReadAll(response.Body)
 		if err != nil {
@@ -152,6 +162,9 @@ func (r *Client) Post(path string, object any) (err error) {
 			return
 		}
 		if len(body) == 0 {
+			if status == http.StatusAccepted {
+				return
+			}
 			empty := &EmptyBody{}
 			empty.With(response)
 			err = empty
@@ -726,11 +739,21 @@ func (r *Client) send(rb func() (*http.Request, error)) (response *http.Response
 		return
 	}
 	r.ensureTransport()
+	key := ""
 	for i := uint8(0); ; i++ {
 		request, err = rb()
 		if err != nil {
 			return
 		}
+		if r.idempotent(request) {
+			if key == "" {
+				key, err = r.idempotencyKey()
+				if err != nil {
+					return
+				}
+			}
+			request.Header.Set(api.IdempotencyKey, key)
+		}
 		authHeader := r.auth.Header()
 		if authHeader != "" {
 			request.Header.Set(api.Authorization, authHeader)
@@ -785,6 +808,30 @@ func (r *Client) send(rb func() (*http.Request, error)) (response *http.Response
 	return
 }
 
+// idempotent returns true when the request must be sent with a
+// (generated) Idempotency-Key. Only POST requests which may be
+// retried, or when requested, and without a key set by the caller.
+func (r *Client) idempotent(request *http.Request) (b bool) {
+	b = request.Method == http.MethodPost &&
+		request.Header.Get(api.IdempotencyKey) == "" &&
+		(r.Idempotent || r.Retry > 0)
+	return
+}
+
+// idempotencyKey returns a new idempotency key.
+// The same key is sent with each (retry) attempt of a POST
+// so the hub replays the response rather than creating duplicates.
+func (r *Client) idempotencyKey() (key string, err error) {
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	key = hex.EncodeToString(b)
+	return
+}
+
 // ensureTransport ensures a transport is set.
 func (r *Client) ensureTransport() {
 	if r.transport != nil {
@@ -840,6 +887,9 @@ type Field struct {
 	Path     string
 	Reader   io.Reader
 	Encoding string
+	// Plain (form value) field.
+	// The filename is omitted.
+	Plain bool
 }
 
 // Write the field content.
@@ -879,6 +929,10 @@ func (f *Field) encoding() (mt string) {
 
 // disposition returns content-disposition.
 func (f *Field) disposition() (d string) {
+	if f.Plain {
+		d = fmt.Sprintf(`form-data; name="%s"`, f.Name)
+		return
+	}
 	d = fmt.Sprintf(`form-data; name="%s"; filename="%s"`, f.Name, pathlib.Base(f.Path))
 	return
 }
@@ -86,6 +86,9 @@ type RestClient interface {
 	Use(auth AuthMethod)
 	// SetRetry set the number of retries.
 	SetRetry(n uint8)
+	// SetIdempotent set whether POST requests are sent with
+	// an Idempotency-Key when retries are not enabled.
+	SetIdempotent(b bool)
 	// SetTransport set the transport.
 	SetTransport(tp *http.Transport)
 	// Transport returns the client transport.
@@ -42,6 +42,10 @@ func (s *Stub) Use(auth AuthMethod) {
 func (s *Stub) SetRetry(n uint8) {
 }
 
+// SetIdempotent set whether POST requests are idempotent.
+func (s *Stub) SetIdempotent(b bool) {
+}
+
 // SetTransport set the transport.
 func (s *Stub) SetTransport(tp *http.Transport) {
 }
@@ -61,6 +61,15 @@ func (h Identity) Delete(id uint) (err error) {
 	return
 }
 
+// Verify an Identity.
+// The verification may be scoped to an application (ID).
+// The verification is returned in r.
+func (h Identity) Verify(id uint, r *api.IdentityVerification) (err error) {
+	path := Path(api.IdentityVerifyRoute).Inject(Params{api.ID: id})
+	err = h.client.Post(path, r)
+	return
+}
+
 // params returns parameters.
 func (h Identity) params(filter ...Filter) (param []Param) {
 	if h.decrypted {
@@ -1,6 +1,11 @@
 package _import
 
 import (
+	"bytes"
+	"net/http"
+	pathlib "path"
+	"strconv"
+
 	"github.com/konveyor/tackle2-hub/shared/api"
 	"github.com/konveyor/tackle2-hub/shared/binding/client"
 )
@@ -21,6 +26,42 @@ func (h Import) Upload(path string) (r *api.ImportSummary, err error) {
 	return
 }
 
+// UploadWith uploads a CSV file with options and create an Import.
+func (h Import) UploadWith(path string, options Options) (r *api.ImportSummary, err error) {
+	r = &api.ImportSummary{}
+	fields := []client.Field{
+		{
+			Name: api.FileField,
+			Path: path,
+		},
+		options.field("fileName", pathlib.Base(path)),
+		options.field("createEntities", strconv.FormatBool(options.CreateEntities)),
+	}
+	if options.Mode != "" {
+		fields = append(fields, options.field("mode", options.Mode))
+	}
+	if options.MatchBy != "" {
+		fields = append(fields, options.field("matchBy", options.MatchBy))
+	}
+	if options.TagMode != "" {
+		fields = append(fields, options.field("tagMode", options.TagMode))
+	}
+	if options.DryRun {
+		fields = append(fields, options.field("dryRun", "true"))
+	}
+	if options.Atomic {
+		fields = append(fields, options.field("atomic", "true"))
+	}
+	if options.Format != "" {
+		fields = append(fields, options.field("format", options.Format))
+	}
+	if options.Profile != 0 {
+		fields = append(fields, options.field("profile", strconv.Itoa(int(options.Profile))))
+	}
+	err = h.client.FileSend(api.UploadRoute, http.MethodPost, fields, r)
+	return
+}
+
 // List returns all Import records.
 func (h Import) List() (list []api.Import, err error) {
 	list = []api.Import{}
@@ -28,6 +69,11 @@ func (h Import) List() (list []api.Import, err error) {
 	return
 }
 
+func (h Import) Profile() (h2 Profile) {
+	h2 = Profile{client: h.client}
+	return
+}
+
 func (h Import) Summary() (h2 Summary) {
 	h2 = Summary{client: h.client}
 	return
@@ -58,8 +104,94 @@ func (h Summary) Delete(id uint) (err error) {
 	return
 }
 
+// Commit a dry-run Import.
+func (h Summary) Commit(id uint) (r *api.ImportSummary, err error) {
+	r = &api.ImportSummary{}
+	path := client.Path(api.CommitRoute).Inject(client.Params{api.ID: id})
+	err = h.client.Post(path, r)
+	return
+}
+
 // Download exports the CSV.
 func (h Summary) Download(destination string) (err error) {
 	err = h.client.FileGet(api.DownloadRoute, destination)
 	return
 }
+
+// Options import upload options.
+type Options struct {
+	// CreateEntities create missing entities.
+	CreateEntities bool
+	// Mode (create|update|upsert).
+	Mode string
+	// MatchBy (name|repository).
+	MatchBy string
+	// TagMode (add|replace).
+	TagMode string
+	// DryRun validate only.
+	DryRun bool
+	// Atomic all-or-nothing.
+	Atomic bool
+	// Format (csv|xlsx|json|yaml).
+	// Default: based on the file extension.
+	Format string
+	// Profile the import profile (ID).
+	Profile uint
+}
+
+// field returns a (plain) form field.
+func (o Options) field(name, value string) (f client.Field) {
+	f = client.Field{
+		Name:     name,
+		Reader:   bytes.NewReader([]byte(value)),
+		Encoding: "text/plain",
+		Plain:    true,
+	}
+	return
+}
+
+// Profile import profile API.
+type Profile struct {
+	client client.RestClient
+}
+
+// Create an import profile.
+func (h Profile) Create(r *api.ImportProfile) (err error) {
+	err = h.client.Post(api.ImportProfilesRoute, r)
+	return
+}
+
+// Get an import profile by ID.
+func (h Profile) Get(id uint) (r *api.ImportProfile, err error) {
+	r = &api.ImportProfile{}
+	path := client.Path(api.ImportProfileRoute).Inject(client.Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+// List import profiles.
+func (h Profile) List() (list []api.ImportProfile, err error) {
+	list = []api.ImportProfile{}
+	err = h.client.Get(api.ImportProfilesRoute, &list)
+	return
+}
+
+// Update an import profile.
+func (h Profile) Update(r *api.ImportProfile) (err error) {
+	path := client.Path(api.ImportProfileRoute).Inject(client.Params{api.ID: r.ID})
+	err = h.client.Put(path, r)
+	return
+}
+
+// Delete an import profile.
+func (h Profile) Delete(id uint) (err error) {
+	err = h.client.Delete(client.Path(api.ImportProfileRoute).Inject(client.Params{api.ID: id}))
+	return
+}
+
+// Template downloads the (CSV) import template.
+func (h Profile) Template(id uint, destination string) (err error) {
+	path := client.Path(api.ImportTemplateRoute).Inject(client.Params{api.ID: id})
+	err = h.client.FileGet(path, destination)
+	return
+}
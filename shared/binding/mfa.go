@@ -0,0 +1,44 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// Mfa API.
+// MFA enrollment of the current (builtin) user.
+type Mfa struct {
+	client RestClient
+}
+
+// Enroll begins enrollment.
+// Returns the secret and otpauth URI.
+func (h Mfa) Enroll() (r *api.MfaEnrollment, err error) {
+	r = &api.MfaEnrollment{}
+	err = h.client.Post(api.AuthMfaRoute, r)
+	return
+}
+
+// Confirm the enrollment using a TOTP code.
+// Returns the recovery codes.
+func (h Mfa) Confirm(code string) (r *api.MfaEnrollment, err error) {
+	r = &api.MfaEnrollment{}
+	body := &struct {
+		api.MfaCode
+		RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+	}{
+		MfaCode: api.MfaCode{Code: code},
+	}
+	err = h.client.Post(api.AuthMfaConfirmRoute, body)
+	if err != nil {
+		return
+	}
+	r.RecoveryCodes = body.RecoveryCodes
+	return
+}
+
+// Delete (remove) the enrollment.
+// The code is a current TOTP or (unused) recovery code.
+func (h Mfa) Delete(code string) (err error) {
+	err = h.client.DeleteWith(api.AuthMfaRoute, &api.MfaCode{Code: code})
+	return
+}
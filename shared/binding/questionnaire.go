@@ -42,3 +42,19 @@ func (h Questionnaire) Delete(id uint) (err error) {
 	err = h.client.Delete(Path(api.QuestionnaireRoute).Inject(Params{api.ID: id}))
 	return
 }
+
+// Versions lists the versions of a Questionnaire.
+func (h Questionnaire) Versions(id uint) (list []api.QuestionnaireVersion, err error) {
+	list = []api.QuestionnaireVersion{}
+	path := Path(api.QuestionnaireVersionsRoute).Inject(Params{api.ID: id})
+	err = h.client.Get(path, &list)
+	return
+}
+
+// Version gets a version of a Questionnaire.
+func (h Questionnaire) Version(id, version uint) (r *api.QuestionnaireVersion, err error) {
+	r = &api.QuestionnaireVersion{}
+	path := Path(api.QuestionnaireVersionRoute).Inject(Params{api.ID: id, api.ID2: version})
+	err = h.client.Get(path, r)
+	return
+}
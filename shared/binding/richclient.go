@@ -37,6 +37,8 @@ type RichClient struct {
 	Application      application.Application
 	Archetype        archetype.Archetype
 	Assessment       Assessment
+	AuthEvent        AuthEvent
+	Batch            Batch
 	Bucket           bucket.Bucket
 	BusinessService  BusinessService
 	ConfigMap        ConfigMap
@@ -49,6 +51,7 @@ type RichClient struct {
 	Import           _import.Import
 	JobFunction      JobFunction
 	Manifest         Manifest
+	Mfa              Mfa
 	MigrationWave    MigrationWave
 	Platform         Platform
 	Proxy            Proxy
@@ -56,7 +59,10 @@ type RichClient struct {
 	Report           report.Report
 	Review           Review
 	Schema           Schema
+	Scim             Scim
 	RuleSet          RuleSet
+	Secret           Secret
+	Service          Service
 	Setting          Setting
 	Stakeholder      Stakeholder
 	StakeholderGroup StakeholderGroup
@@ -93,6 +99,8 @@ func (r *RichClient) build(client RestClient) {
 	r.Application = application.New(client)
 	r.Archetype = archetype.New(client)
 	r.Assessment = Assessment{client: client}
+	r.AuthEvent = AuthEvent{client: client}
+	r.Batch = Batch{client: client}
 	r.Bucket = bucket.New(client)
 	r.BusinessService = BusinessService{client: client}
 	r.ConfigMap = ConfigMap{client: client}
@@ -105,6 +113,7 @@ func (r *RichClient) build(client RestClient) {
 	r.Import = _import.New(client)
 	r.JobFunction = JobFunction{client: client}
 	r.Manifest = Manifest{client: client}
+	r.Mfa = Mfa{client: client}
 	r.MigrationWave = MigrationWave{client: client}
 	r.Platform = Platform{client: client}
 	r.Proxy = Proxy{client: client}
@@ -113,6 +122,9 @@ func (r *RichClient) build(client RestClient) {
 	r.Review = Review{client: client}
 	r.RuleSet = RuleSet{client: client}
 	r.Schema = Schema{client: client}
+	r.Scim = Scim{client: client}
+	r.Secret = Secret{client: client}
+	r.Service = Service{client: client}
 	r.Setting = Setting{client: client}
 	r.Stakeholder = Stakeholder{client: client}
 	r.StakeholderGroup = StakeholderGroup{client: client}
@@ -0,0 +1,120 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// Scim (SCIM 2.0) provisioning API.
+type Scim struct {
+	client RestClient
+}
+
+// UserCreate creates a SCIM user.
+func (h Scim) UserCreate(r *api.ScimUser) (err error) {
+	err = h.client.Post(api.ScimUsersRoute, r)
+	return
+}
+
+// UserGet gets a SCIM user by ID.
+func (h Scim) UserGet(id string) (r *api.ScimUser, err error) {
+	r = &api.ScimUser{}
+	path := Path(api.ScimUserRoute).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+// UserFind finds SCIM users matching the filter.
+func (h Scim) UserFind(filter string) (list []api.ScimUser, err error) {
+	r := struct {
+		api.ScimList
+		Resources []api.ScimUser `json:"Resources"`
+	}{}
+	err = h.client.Get(
+		api.ScimUsersRoute,
+		&r,
+		Param{
+			Key:   api.ScimParamFilter,
+			Value: filter,
+		})
+	list = r.Resources
+	return
+}
+
+// UserUpdate replaces a SCIM user.
+func (h Scim) UserUpdate(r *api.ScimUser) (err error) {
+	path := Path(api.ScimUserRoute).Inject(Params{api.ID: r.ID})
+	err = h.client.Put(path, r)
+	return
+}
+
+// UserPatch patches a SCIM user.
+func (h Scim) UserPatch(id string, patch *api.ScimPatch) (r *api.ScimUser, err error) {
+	path := Path(api.ScimUserRoute).Inject(Params{api.ID: id})
+	err = h.client.Patch(path, patch)
+	if err != nil {
+		return
+	}
+	r, err = h.UserGet(id)
+	return
+}
+
+// UserDelete deletes a SCIM user.
+func (h Scim) UserDelete(id string) (err error) {
+	err = h.client.Delete(Path(api.ScimUserRoute).Inject(Params{api.ID: id}))
+	return
+}
+
+// GroupCreate creates a SCIM group.
+func (h Scim) GroupCreate(r *api.ScimGroup) (err error) {
+	err = h.client.Post(api.ScimGroupsRoute, r)
+	return
+}
+
+// GroupGet gets a SCIM group by ID.
+func (h Scim) GroupGet(id string) (r *api.ScimGroup, err error) {
+	r = &api.ScimGroup{}
+	path := Path(api.ScimGroupRoute).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+// GroupFind finds SCIM groups matching the filter.
+func (h Scim) GroupFind(filter string) (list []api.ScimGroup, err error) {
+	r := struct {
+		api.ScimList
+		Resources []api.ScimGroup `json:"Resources"`
+	}{}
+	err = h.client.Get(
+		api.ScimGroupsRoute,
+		&r,
+		Param{
+			Key:   api.ScimParamFilter,
+			Value: filter,
+		})
+	list = r.Resources
+	return
+}
+
+// GroupUpdate replaces a SCIM group.
+func (h Scim) GroupUpdate(r *api.ScimGroup) (err error) {
+	path := Path(api.ScimGroupRoute).Inject(Params{api.ID: r.ID})
+	err = h.client.Put(path, r)
+	return
+}
+
+// GroupPatch patches a SCIM group.
+func (h Scim) GroupPatch(id string, patch *api.ScimPatch) (r *api.ScimGroup, err error) {
+	path := Path(api.ScimGroupRoute).Inject(Params{api.ID: id})
+	err = h.client.Patch(path, patch)
+	if err != nil {
+		return
+	}
+	r, err = h.GroupGet(id)
+	return
+}
+
+// GroupDelete deletes a SCIM group.
+func (h Scim) GroupDelete(id string) (err error) {
+	err = h.client.Delete(Path(api.ScimGroupRoute).Inject(Params{api.ID: id}))
+	return
+}
@@ -0,0 +1,38 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// Secret (encryption key) API.
+type Secret struct {
+	client RestClient
+}
+
+// Keys returns the encryption keys.
+func (h Secret) Keys() (list []api.SecretKey, err error) {
+	list = []api.SecretKey{}
+	err = h.client.Get(api.SecretKeysRoute, &list)
+	return
+}
+
+// Retire an encryption key.
+func (h Secret) Retire(id string) (err error) {
+	path := Path(api.SecretKeyRoute).Inject(Params{api.Key: id})
+	err = h.client.Delete(path)
+	return
+}
+
+// Rotate starts a key rotation.
+func (h Secret) Rotate() (r *api.SecretRotation, err error) {
+	r = &api.SecretRotation{}
+	err = h.client.Post(api.SecretRotationRoute, r)
+	return
+}
+
+// Rotation returns the key rotation progress.
+func (h Secret) Rotation() (r *api.SecretRotation, err error) {
+	r = &api.SecretRotation{}
+	err = h.client.Get(api.SecretRotationRoute, r)
+	return
+}
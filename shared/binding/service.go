@@ -0,0 +1,18 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/shared/api"
+)
+
+// Service API.
+// Named services proxied by the hub.
+type Service struct {
+	client RestClient
+}
+
+// List Services.
+func (h Service) List() (list []api.Service, err error) {
+	list = []api.Service{}
+	err = h.client.Get(api.ServicesRoute, &list)
+	return
+}
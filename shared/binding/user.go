@@ -54,6 +54,18 @@ func (h User) Delete(id uint) (err error) {
 	return
 }
 
+// MfaReset removes the MFA enrollment of a User.
+func (h User) MfaReset(id uint) (err error) {
+	err = h.client.Delete(Path(api.UserMfaRoute).Inject(Params{api.ID: id}))
+	return
+}
+
+// Unlock a User locked after failed authentication attempts.
+func (h User) Unlock(id uint) (err error) {
+	err = h.client.Delete(Path(api.UserLockRoute).Inject(Params{api.ID: id}))
+	return
+}
+
 // params returns parameters.
 func (h User) params(filter ...Filter) (param []Param) {
 	if h.decrypted {
@@ -132,6 +132,21 @@ func (r *Git) Head() (commit string, err error) {
 	return
 }
 
+// Verify the remote is accessible using the identity.
+// The remote refs are listed (ls-remote); nothing is cloned.
+func (r *Git) Verify() (err error) {
+	err = r.initHome()
+	if err != nil {
+		return
+	}
+	u := r.URL()
+	cmd := r.git()
+	cmd.Options.Add("ls-remote", "--heads")
+	cmd.Options.Add(u.String())
+	err = cmd.Run()
+	return
+}
+
 // URL returns the parsed URL.
 func (r *Git) URL() (u GitURL) {
 	u = GitURL{}
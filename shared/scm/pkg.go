@@ -33,6 +33,7 @@ type SCM interface {
 	Branch(ref string) (err error)
 	Commit(files []string, msg string) (err error)
 	Head() (commit string, err error)
+	Verify() (err error)
 	Clean() (err error)
 }
 
@@ -139,6 +139,20 @@ func (r *Subversion) Head() (commit string, err error) {
 	return
 }
 
+// Verify the remote is accessible using the identity.
+// The remote is queried (info); nothing is checked out.
+func (r *Subversion) Verify() (err error) {
+	err = r.initHome()
+	if err != nil {
+		return
+	}
+	u := r.URL()
+	cmd := r.svn()
+	cmd.Options.Add("info", u.String())
+	err = cmd.Run()
+	return
+}
+
 // URL returns the parsed URL.
 func (r *Subversion) URL() (u *SvnURL) {
 	u = &SvnURL{}
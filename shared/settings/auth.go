@@ -18,6 +18,8 @@ const (
 	EnvTokenLifespan        = "OIDC_TOKEN_LIFESPAN"
 	EnvRefreshTokenLifespan = "OIDC_REFRESH_TOKEN_LIFESPAN"
 	EnvKeyRotation          = "OIDC_KEY_ROTATION"
+	EnvEventTTL             = "AUTH_EVENT_TTL"
+	EnvServiceTokenLifespan = "SERVICE_TOKEN_LIFESPAN"
 )
 
 type Auth struct {
@@ -44,6 +46,14 @@ type Auth struct {
 	Key struct {
 		Rotation time.Duration
 	}
+	// Event (audit) settings.
+	Event struct {
+		TTL time.Duration
+	}
+	// Service (downstream) token settings.
+	Service struct {
+		Lifespan time.Duration
+	}
 }
 
 func (r *Auth) Load() (err error) {
@@ -57,5 +67,7 @@ func (r *Auth) Load() (err error) {
 	r.Token.Lifespan = env.GetSecond(EnvTokenLifespan, 300)                   // second: 5 minutes.
 	r.Token.RefreshLifespan = env.GetSecond(EnvRefreshTokenLifespan, 48*3600) // second: 2 days.
 	r.Key.Rotation = env.GetDay(EnvKeyRotation, 90)
+	r.Event.TTL = env.GetDay(EnvEventTTL, 90)
+	r.Service.Lifespan = env.GetSecond(EnvServiceTokenLifespan, 60) // second: 1 minute.
 	return
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/user"
 	"strconv"
+	"strings"
 	"time"
 
 	liberr "github.com/jortel/go-utils/error"
@@ -14,6 +15,7 @@ const (
 	EnvNamespace               = "NAMESPACE"
 	EnvBuild                   = "BUILD"
 	EnvAPIPort                 = "API_PORT"
+	EnvAPITrustedProxies       = "API_TRUSTED_PROXIES"
 	EnvDbPath                  = "DB_PATH"
 	EnvDbMaxCon                = "DB_MAX_CONNECTION"
 	EnvDbSeedPath              = "DB_SEED_PATH"
@@ -21,6 +23,9 @@ const (
 	EnvRwxSupported            = "RWX_SUPPORTED"
 	EnvCachePvc                = "CACHE_PVC"
 	EnvPassphrase              = "ENCRYPTION_PASSPHRASE"
+	EnvEncryptionKeys          = "ENCRYPTION_KEYS"
+	EnvEncryptionKeyId         = "ENCRYPTION_KEY_ID"
+	EnvEncryptionBatch         = "ENCRYPTION_ROTATION_BATCH"
 	EnvTaskReapCreated         = "TASK_REAP_CREATED"
 	EnvTaskReapSucceeded       = "TASK_REAP_SUCCEEDED"
 	EnvTaskReapFailed          = "TASK_REAP_FAILED"
@@ -38,6 +43,10 @@ const (
 	EnvFrontendAuthDist        = "FRONTEND_AUTH_DIST"
 	EnvBucketTTL               = "BUCKET_TTL"
 	EnvFileTTL                 = "FILE_TTL"
+	EnvIdempotencyTTL          = "IDEMPOTENCY_TTL"
+	EnvImportWorkers           = "IMPORT_WORKERS"
+	EnvImportBatch             = "IMPORT_BATCH"
+	EnvFrequencyImport         = "FREQUENCY_IMPORT"
 	EnvAppName                 = "APP_NAME"
 	EnvDisconnected            = "DISCONNECTED"
 	EnvAnalysisReportPath      = "ANALYSIS_REPORT_PATH"
@@ -63,6 +72,9 @@ type Hub struct {
 	// API settings.
 	API struct {
 		Port int
+		// TrustedProxies addresses (or CIDRs) of proxies trusted
+		// to report the client IP (X-Forwarded-For).
+		TrustedProxies []string
 	}
 	// DB settings.
 	DB struct {
@@ -79,6 +91,17 @@ type Hub struct {
 	File struct {
 		TTL time.Duration
 	}
+	// Idempotency (key) settings.
+	Idempotency struct {
+		TTL time.Duration
+	}
+	// API (request) limits.
+	Limit Limit
+	// Import settings.
+	Import struct {
+		Workers int
+		Batch   int
+	}
 	// Cache settings.
 	Cache struct {
 		RWX bool
@@ -86,7 +109,18 @@ type Hub struct {
 	}
 	// Encryption settings.
 	Encryption struct {
+		// Passphrase the (legacy) passphrase.
+		// Used for secrets encrypted without a key ID.
 		Passphrase string
+		// Keys passphrases by key ID.
+		Keys map[string]string
+		// KeyId the ID of the key used to encrypt.
+		// The (legacy) passphrase is used when not specified.
+		KeyId string
+		// Rotation settings.
+		Rotation struct {
+			Batch int
+		}
 	}
 	// Task
 	Task struct {
@@ -112,6 +146,7 @@ type Hub struct {
 		Task   time.Duration
 		Reaper time.Duration
 		Heap   time.Duration
+		Import time.Duration
 	}
 	// Development environment
 	Development bool
@@ -167,6 +202,15 @@ func (r *Hub) Load() (err error) {
 	} else {
 		r.API.Port = 8080
 	}
+	s, found = os.LookupEnv(EnvAPITrustedProxies)
+	if found {
+		for _, proxy := range strings.Split(s, ",") {
+			proxy = strings.TrimSpace(proxy)
+			if proxy != "" {
+				r.API.TrustedProxies = append(r.API.TrustedProxies, proxy)
+			}
+		}
+	}
 	s, found = os.LookupEnv(EnvDbMaxCon)
 	if found {
 		n, _ := strconv.Atoi(s)
@@ -195,6 +239,37 @@ func (r *Hub) Load() (err error) {
 	if !found {
 		r.Encryption.Passphrase = "tackle"
 	}
+	r.Encryption.Keys = make(map[string]string)
+	s, found = os.LookupEnv(EnvEncryptionKeys)
+	if found {
+		for _, entry := range strings.Split(s, ",") {
+			id, passphrase, _ := strings.Cut(entry, "=")
+			id = strings.TrimSpace(id)
+			if id != "" {
+				r.Encryption.Keys[id] = passphrase
+			}
+		}
+	}
+	r.Encryption.KeyId, found = os.LookupEnv(EnvEncryptionKeyId)
+	if found {
+		_, found = r.Encryption.Keys[r.Encryption.KeyId]
+		if !found {
+			err = liberr.New(
+				"encryption key not found.",
+				"env",
+				EnvEncryptionKeyId,
+				"id",
+				r.Encryption.KeyId)
+			return
+		}
+	}
+	s, found = os.LookupEnv(EnvEncryptionBatch)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Encryption.Rotation.Batch = n
+	} else {
+		r.Encryption.Rotation.Batch = 100
+	}
 	s, found = os.LookupEnv(EnvTaskReapCreated)
 	if found {
 		n, _ := strconv.Atoi(s)
@@ -262,6 +337,13 @@ func (r *Hub) Load() (err error) {
 	} else {
 		r.Frequency.Reaper = 1 * time.Minute
 	}
+	s, found = os.LookupEnv(EnvFrequencyImport)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Frequency.Import = time.Duration(n) * time.Second
+	} else {
+		r.Frequency.Import = 10 * time.Second
+	}
 	s, found = os.LookupEnv(EnvFrequencyHeap)
 	if found {
 		n, _ := strconv.Atoi(s)
@@ -331,6 +413,31 @@ func (r *Hub) Load() (err error) {
 	} else {
 		r.File.TTL = 720 * time.Minute // 12 hours.
 	}
+	s, found = os.LookupEnv(EnvIdempotencyTTL)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Idempotency.TTL = time.Duration(n) * time.Minute
+	} else {
+		r.Idempotency.TTL = 1440 * time.Minute // 24 hours.
+	}
+	err = r.Limit.Load()
+	if err != nil {
+		return
+	}
+	s, found = os.LookupEnv(EnvImportWorkers)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Import.Workers = n
+	} else {
+		r.Import.Workers = 4
+	}
+	s, found = os.LookupEnv(EnvImportBatch)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Import.Batch = n
+	} else {
+		r.Import.Batch = 100
+	}
 	s, found = os.LookupEnv(EnvAppName)
 	if found {
 		r.Product = !(s == "" || s == "tackle")
@@ -0,0 +1,153 @@
+package settings
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/shared/env"
+)
+
+// Environment variables.
+const (
+	EnvLimitEnabled        = "LIMIT_ENABLED"
+	EnvLimitUser           = "LIMIT_USER"
+	EnvLimitServiceAccount = "LIMIT_SERVICE_ACCOUNT"
+	EnvLimitTask           = "LIMIT_TASK"
+	EnvLimitGroups         = "LIMIT_GROUPS"
+)
+
+// Limit API (request) limits.
+// Requests are limited by auth subject with separate
+// budgets for users, service accounts and tasks.
+type Limit struct {
+	// Enabled limits enforced.
+	Enabled bool
+	// User budget.
+	User Budget
+	// ServiceAccount budget.
+	ServiceAccount Budget
+	// Task budget.
+	Task Budget
+	// Groups budgets by route group.
+	// Key: <group>[:<kind>].
+	Groups map[string]Budget
+}
+
+// Load settings.
+func (r *Limit) Load() (err error) {
+	r.Enabled = env.GetBool(EnvLimitEnabled, true)
+	r.User, err = r.budget(EnvLimitUser, "20/40/8")
+	if err != nil {
+		return
+	}
+	r.ServiceAccount, err = r.budget(EnvLimitServiceAccount, "50/100/16")
+	if err != nil {
+		return
+	}
+	r.Task, err = r.budget(EnvLimitTask, "50/100/16")
+	if err != nil {
+		return
+	}
+	r.Groups = make(map[string]Budget)
+	s, found := os.LookupEnv(EnvLimitGroups)
+	if !found {
+		return
+	}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		group, spec, _ := strings.Cut(entry, "=")
+		group = strings.TrimSpace(group)
+		if group == "" {
+			err = liberr.New(
+				"limit group not specified.",
+				"env",
+				EnvLimitGroups,
+				"entry",
+				entry)
+			return
+		}
+		b := Budget{}
+		err = b.With(spec)
+		if err != nil {
+			err = liberr.Wrap(
+				err,
+				"env",
+				EnvLimitGroups,
+				"group",
+				group)
+			return
+		}
+		r.Groups[group] = b
+	}
+	return
+}
+
+// budget returns the budget defined by the environment variable.
+func (r *Limit) budget(name, def string) (b Budget, err error) {
+	err = b.With(env.Get(name, def))
+	if err != nil {
+		err = liberr.Wrap(err, "env", name)
+		return
+	}
+	return
+}
+
+// Budget request budget.
+// Format: <rate>/<burst>/<in-flight>
+// Rate: requests per second.
+// Burst: max requests (bucket) in excess of the rate.
+// InFlight: max concurrent requests.
+// 0=unlimited.
+type Budget struct {
+	Rate     float64
+	Burst    int
+	InFlight int
+}
+
+// With parses the budget.
+func (r *Budget) With(s string) (err error) {
+	part := strings.Split(strings.TrimSpace(s), "/")
+	if len(part) != 3 {
+		err = liberr.New(
+			"budget must be: <rate>/<burst>/<in-flight>.",
+			"budget",
+			s)
+		return
+	}
+	r.Rate, err = strconv.ParseFloat(part[0], 64)
+	if err != nil || r.Rate < 0 {
+		err = liberr.New("budget rate not valid.", "budget", s)
+		return
+	}
+	r.Burst, err = strconv.Atoi(part[1])
+	if err != nil || r.Burst < 0 {
+		err = liberr.New("budget burst not valid.", "budget", s)
+		return
+	}
+	r.InFlight, err = strconv.Atoi(part[2])
+	if err != nil || r.InFlight < 0 {
+		err = liberr.New("budget in-flight not valid.", "budget", s)
+		return
+	}
+	if r.Rate > 0 {
+		r.Burst = max(r.Burst, int(math.Ceil(r.Rate)))
+	}
+	return
+}
+
+// String representation.
+func (r Budget) String() (s string) {
+	s = fmt.Sprintf(
+		"%s/%d/%d",
+		strconv.FormatFloat(r.Rate, 'f', -1, 64),
+		r.Burst,
+		r.InFlight)
+	return
+}
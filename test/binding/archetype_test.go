@@ -838,6 +838,209 @@ func TestArchetypeCriteriaMatching(t *testing.T) {
 	g.Expect(foundAfter).To(BeFalse(), "App without matching tag should disappear from Applications")
 }
 
+// TestArchetypeSelector tests expression-based membership criteria.
+func TestArchetypeSelector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// Create the tags.
+	category := &api.TagCategory{Name: "SelectorCategory"}
+	err := client.TagCategory.Create(category)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.TagCategory.Delete(category.ID)
+	})
+	alpha := &api.Tag{Name: "Alpha", Category: api.Ref{ID: category.ID}}
+	err = client.Tag.Create(alpha)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Tag.Delete(alpha.ID)
+	})
+	beta := &api.Tag{Name: "Beta", Category: api.Ref{ID: category.ID}}
+	err = client.Tag.Create(beta)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Tag.Delete(beta.ID)
+	})
+
+	// Invalid selector rejected.
+	invalid := &api.Archetype{
+		Name:     "Invalid Selector Archetype",
+		Selector: "unknown:thing",
+	}
+	err = client.Archetype.Create(invalid)
+	g.Expect(err).NotTo(BeNil())
+
+	// Create the archetypes.
+	broad := &api.Archetype{
+		Name:     "Broad Selector Archetype",
+		Selector: "tag:SelectorCategory",
+	}
+	err = client.Archetype.Create(broad)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Archetype.Delete(broad.ID)
+	})
+	narrow := &api.Archetype{
+		Name: "Narrow Selector Archetype",
+		Criteria: []api.TagRef{
+			{ID: alpha.ID},
+		},
+		Selector: "!(tag:Selector*=Beta || fact:tier=*)",
+	}
+	err = client.Archetype.Create(narrow)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Archetype.Delete(narrow.ID)
+	})
+
+	// Create the applications.
+	appAlpha := &api.Application{Name: "Selector Alpha App"}
+	err = client.Application.Create(appAlpha)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(appAlpha.ID)
+	})
+	err = client.Application.Select(appAlpha.ID).Tag.Add(alpha.ID)
+	g.Expect(err).To(BeNil())
+	appBeta := &api.Application{Name: "Selector Beta App"}
+	err = client.Application.Create(appBeta)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(appBeta.ID)
+	})
+	err = client.Application.Select(appBeta.ID).Tag.Add(beta.ID)
+	g.Expect(err).To(BeNil())
+
+	// Most specific wins.
+	retrieved, err := client.Application.Get(appAlpha.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(retrieved.Archetypes)).To(Equal(1))
+	g.Expect(retrieved.Archetypes[0].ID).To(Equal(narrow.ID))
+	retrieved, err = client.Application.Get(appBeta.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(retrieved.Archetypes)).To(Equal(1))
+	g.Expect(retrieved.Archetypes[0].ID).To(Equal(broad.ID))
+
+	// Explain.
+	memberships, err := client.Application.Membership(appBeta.ID)
+	g.Expect(err).To(BeNil())
+	var explained *api.Membership
+	for i := range memberships {
+		if memberships[i].Archetype.ID == narrow.ID {
+			explained = &memberships[i]
+			break
+		}
+	}
+	g.Expect(explained).NotTo(BeNil())
+	g.Expect(explained.Matched).To(BeFalse())
+	g.Expect(explained.Member).To(BeFalse())
+	g.Expect(len(explained.Missing)).To(Equal(1))
+	g.Expect(explained.Missing[0].ID).To(Equal(alpha.ID))
+	g.Expect(explained.Predicates).To(ContainElement(
+		api.MembershipPredicate{
+			Predicate: "tag:Selector*=Beta",
+			Matched:   true,
+		}))
+	memberships, err = client.Application.Membership(appAlpha.ID)
+	g.Expect(err).To(BeNil())
+	for _, m := range memberships {
+		if m.Archetype.ID == broad.ID {
+			g.Expect(m.Matched).To(BeTrue())
+			g.Expect(m.Member).To(BeFalse())
+			g.Expect(m.Superseded[0].ID).To(Equal(narrow.ID))
+		}
+	}
+}
+
+// TestArchetypeInheritance tests the archetype parent hierarchy.
+func TestArchetypeInheritance(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// Create the tags and stakeholder.
+	category := &api.TagCategory{Name: "InheritCategory"}
+	err := client.TagCategory.Create(category)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.TagCategory.Delete(category.ID)
+	})
+	java := &api.Tag{Name: "Java", Category: api.Ref{ID: category.ID}}
+	err = client.Tag.Create(java)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Tag.Delete(java.ID)
+	})
+	spring := &api.Tag{Name: "Spring", Category: api.Ref{ID: category.ID}}
+	err = client.Tag.Create(spring)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Tag.Delete(spring.ID)
+	})
+	stakeholder := &api.Stakeholder{Name: "Inherited", Email: "inherited@example.com"}
+	err = client.Stakeholder.Create(stakeholder)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Stakeholder.Delete(stakeholder.ID)
+	})
+
+	// Create the hierarchy.
+	web := &api.Archetype{
+		Name:         "Java Web",
+		Tags:         []api.TagRef{{ID: java.ID}},
+		Stakeholders: []api.Ref{{ID: stakeholder.ID}},
+		Profiles:     []api.TargetProfile{{Name: "Default"}},
+	}
+	err = client.Archetype.Create(web)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Archetype.Delete(web.ID)
+	})
+	boot := &api.Archetype{
+		Name:   "Spring Boot Web",
+		Parent: &api.Ref{ID: web.ID},
+		Tags:   []api.TagRef{{ID: spring.ID}},
+	}
+	err = client.Archetype.Create(boot)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Archetype.Delete(boot.ID)
+	})
+	weblogic := &api.Archetype{
+		Name:     "Spring Boot on WebLogic",
+		Parent:   &api.Ref{ID: boot.ID},
+		Profiles: []api.TargetProfile{{Name: "Default"}},
+	}
+	err = client.Archetype.Create(weblogic)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Archetype.Delete(weblogic.ID)
+	})
+
+	// Inherited.
+	got, err := client.Archetype.Get(weblogic.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(got.Parent.ID).To(Equal(boot.ID))
+	g.Expect(got.Inherited).NotTo(BeNil())
+	g.Expect(len(got.Inherited.Ancestors)).To(Equal(2))
+	g.Expect(got.Inherited.Ancestors[0].ID).To(Equal(boot.ID))
+	g.Expect(got.Inherited.Ancestors[1].ID).To(Equal(web.ID))
+	g.Expect(got.Tags).To(ContainElement(
+		api.TagRef{ID: java.ID, Name: java.Name, Source: "archetype", Virtual: true}))
+	g.Expect(got.Tags).To(ContainElement(
+		api.TagRef{ID: spring.ID, Name: spring.Name, Source: "archetype", Virtual: true}))
+	g.Expect(len(got.Inherited.Stakeholders)).To(Equal(1))
+	g.Expect(got.Inherited.Stakeholders[0].ID).To(Equal(stakeholder.ID))
+	// profile overridden by name.
+	g.Expect(len(got.Inherited.Profiles)).To(Equal(0))
+
+	// Cycle rejected.
+	web.Parent = &api.Ref{ID: weblogic.ID}
+	err = client.Archetype.Update(web)
+	g.Expect(err).NotTo(BeNil())
+	web.Parent = &api.Ref{ID: web.ID}
+	err = client.Archetype.Update(web)
+	g.Expect(err).NotTo(BeNil())
+}
+
 // TestArchetypeAssessmentMultiple tests assessments across multiple archetypes
 func TestArchetypeAssessmentMultiple(t *testing.T) {
 	g := NewGomegaWithT(t)
@@ -0,0 +1,128 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"github.com/konveyor/tackle2-hub/shared/binding"
+	"github.com/konveyor/tackle2-hub/shared/binding/auth"
+	. "github.com/onsi/gomega"
+)
+
+func TestAuthEvent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	user := &api.User{
+		Login:    "test-lockout",
+		Password: "test-lockout-1",
+		Email:    "test-lockout@example.com",
+	}
+	err := client.User.Create(user)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.User.Delete(user.ID)
+	})
+
+	// Client authenticated as the user (no roles).
+	userClient := binding.New(Settings.Addon.Hub.URL)
+	userClient.Client.SetRetry(uint8(1))
+	userClient.Client.Transport().TLSClientConfig = client.Client.Transport().TLSClientConfig
+	userClient.Client.Use(auth.NewBasic("test-lockout", "not-the-password"))
+	_, err = userClient.Setting.List()
+	if err == nil {
+		t.Skip("Auth not required.")
+	}
+
+	// LOCKED: after (default) 5 failed attempts.
+	// The client retries (once) when not authenticated.
+	for range 4 {
+		userClient.Client.Reset()
+		_, err = userClient.Setting.List()
+		g.Expect(err).NotTo(BeNil())
+	}
+	retrieved, err := client.User.Get(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Locked).To(BeTrue())
+	userClient.Client.Reset()
+	userClient.Client.Use(auth.NewBasic("test-lockout", "test-lockout-1"))
+	_, err = userClient.Setting.List()
+	g.Expect(err).NotTo(BeNil())
+	g.Expect(restStatus(err)).NotTo(Equal(http.StatusForbidden))
+
+	// UNLOCK
+	err = client.User.Unlock(user.ID)
+	g.Expect(err).To(BeNil())
+	retrieved, err = client.User.Get(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Locked).To(BeFalse())
+	userClient.Client.Reset()
+	_, err = userClient.Setting.List()
+	g.Expect(restStatus(err)).To(Equal(http.StatusForbidden))
+
+	// EVENTS
+	filter := binding.Filter{}
+	filter.And("login").Eq(user.Login)
+	filter.And("succeeded").Eq(false)
+	events, err := client.AuthEvent.Find(filter)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(events)).To(BeNumerically(">=", 6))
+	for _, event := range events {
+		g.Expect(event.Login).To(Equal(user.Login))
+		g.Expect(event.Method).To(Equal("password"))
+		g.Expect(event.Succeeded).To(BeFalse())
+		g.Expect(event.Source).NotTo(BeEmpty())
+	}
+	g.Expect(events[len(events)-1].Reason).To(Equal("user locked"))
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	policy := &api.Setting{
+		Key: "auth.password.policy",
+		Value: map[string]any{
+			"minLength": 10,
+			"classes":   3,
+			"history":   1,
+		},
+	}
+	err := client.Setting.Create(policy)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Setting.Delete(policy.Key)
+	})
+
+	// CREATE: rejected.
+	user := &api.User{
+		Login:    "test-policy",
+		Password: "password",
+		Email:    "test-policy@example.com",
+	}
+	err = client.User.Create(user)
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+
+	// CREATE
+	user.Password = "Password-01"
+	err = client.User.Create(user)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.User.Delete(user.ID)
+	})
+
+	// UPDATE: reused.
+	user.Password = "Password-01"
+	err = client.User.Update(user)
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+
+	// UPDATE
+	user.Password = "Password-02"
+	err = client.User.Update(user)
+	g.Expect(err).To(BeNil())
+
+	// UPDATE: unchanged (masked).
+	user.Name = "updated"
+	user.Password = api.SecretMask
+	err = client.User.Update(user)
+	g.Expect(err).To(BeNil())
+}
@@ -0,0 +1,125 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/shared/api"
+	. "github.com/onsi/gomega"
+)
+
+func TestBatch(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// Get seeded.
+	seeded, err := client.TagCategory.List()
+	g.Expect(err).To(BeNil())
+
+	// CREATE: atomic batch.
+	// The tag references the category created by the 1st operation.
+	batch := &api.Batch{
+		Operations: []api.BatchOperation{
+			{
+				Method: http.MethodPost,
+				Path:   api.TagCategoriesRoute,
+				Body: api.Map{
+					"name":   "Test Batch Category",
+					"colour": "#00dd00",
+				},
+			},
+			{
+				Method: http.MethodPost,
+				Path:   api.TagsRoute,
+				Body: api.Map{
+					"name": "Test Batch Tag",
+					"category": api.Map{
+						"id": "${0.id}",
+					},
+				},
+			},
+		},
+	}
+	err = client.Batch.Create(batch)
+	g.Expect(err).To(BeNil())
+	g.Expect(batch.Committed).To(BeTrue())
+	for _, op := range batch.Operations {
+		g.Expect(op.Result).NotTo(BeNil())
+		g.Expect(op.Result.Status).To(Equal(http.StatusCreated))
+	}
+	category := &api.TagCategory{}
+	body := api.Map(batch.Operations[0].Result.Body.(map[string]any))
+	err = body.As(category)
+	g.Expect(err).To(BeNil())
+	tag := &api.Tag{}
+	body = api.Map(batch.Operations[1].Result.Body.(map[string]any))
+	err = body.As(tag)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Tag.Delete(tag.ID)
+		_ = client.TagCategory.Delete(category.ID)
+	})
+	g.Expect(tag.Category.ID).To(Equal(category.ID))
+
+	// GET: Retrieve the tag.
+	retrieved, err := client.Tag.Get(tag.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Name).To(Equal("Test Batch Tag"))
+
+	// CREATE: atomic batch rolled back.
+	batch = &api.Batch{
+		Operations: []api.BatchOperation{
+			{
+				Method: http.MethodPost,
+				Path:   api.TagCategoriesRoute,
+				Body: api.Map{
+					"name":   "Test Batch Rollback",
+					"colour": "#00dd00",
+				},
+			},
+			{
+				Method: http.MethodGet,
+				Path:   "/tags/0",
+			},
+			{
+				Method: http.MethodDelete,
+				Path:   "/tags/${1.id}",
+			},
+		},
+	}
+	err = client.Batch.Create(batch)
+	g.Expect(err).To(BeNil())
+	g.Expect(batch.Committed).To(BeFalse())
+	g.Expect(batch.Operations[0].Result.Status).To(Equal(http.StatusCreated))
+	g.Expect(batch.Operations[1].Result.Status).To(Equal(http.StatusNotFound))
+	g.Expect(batch.Operations[2].Result.Status).To(Equal(http.StatusFailedDependency))
+	list, err := client.TagCategory.List()
+	g.Expect(err).To(BeNil())
+	g.Expect(len(list)).To(Equal(len(seeded) + 1))
+
+	// CREATE: item batch.
+	batch = &api.Batch{
+		Mode: api.BatchItem,
+		Operations: []api.BatchOperation{
+			{
+				Method: http.MethodGet,
+				Path:   "/tags/0",
+			},
+			{
+				Method: http.MethodDelete,
+				Path:   "/tags/${1.id}",
+			},
+		},
+	}
+	err = client.Batch.Create(batch)
+	g.Expect(err).To(BeNil())
+	g.Expect(batch.Committed).To(BeFalse())
+	g.Expect(batch.Operations[0].Result.Status).To(Equal(http.StatusNotFound))
+	g.Expect(batch.Operations[1].Result.Status).To(Equal(http.StatusBadRequest))
+
+	// DELETE: the tag.
+	err = client.Tag.Delete(tag.ID)
+	g.Expect(err).To(BeNil())
+	_, err = client.Tag.Get(tag.ID)
+	g.Expect(errors.Is(err, &api.NotFound{})).To(BeTrue())
+}
@@ -2,8 +2,10 @@ package binding
 
 import (
 	"crypto/tls"
+	"errors"
 	"os"
 
+	"github.com/konveyor/tackle2-hub/shared/api"
 	"github.com/konveyor/tackle2-hub/shared/binding"
 	"github.com/konveyor/tackle2-hub/shared/binding/auth"
 	client2 "github.com/konveyor/tackle2-hub/shared/binding/client"
@@ -38,3 +40,12 @@ func init() {
 	authMethod = auth.NewBasic(user, password)
 	client.Client.Use(authMethod)
 }
+
+// restStatus returns the HTTP status reported by a REST error.
+func restStatus(err error) (status int) {
+	restErr := &api.RestError{}
+	if errors.As(err, &restErr) {
+		status = restErr.Status
+	}
+	return
+}
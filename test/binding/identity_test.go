@@ -2,6 +2,7 @@ package binding
 
 import (
 	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/konveyor/tackle2-hub/shared/api"
@@ -254,3 +255,157 @@ func TestIdentityFind(t *testing.T) {
 	g.Expect(identity.ID).To(Equal(direct.ID))
 	g.Expect(identity.Kind).To(Equal(direct.Kind))
 }
+
+// TestIdentitySecret tests identities backed by a (k8s) secret.
+func TestIdentitySecret(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	identity := &api.Identity{
+		Name: "test-secret-identity",
+		Kind: "git",
+		Secret: &api.IdentitySecret{
+			Name:     "test-missing-secret",
+			Password: "token",
+		},
+	}
+
+	// CREATE: Create the identity
+	err := client.Identity.Create(identity)
+	g.Expect(err).To(BeNil())
+	g.Expect(identity.ID).NotTo(BeZero())
+	t.Cleanup(func() {
+		_ = client.Identity.Delete(identity.ID)
+	})
+
+	// GET: the secret reference is returned; values not stored.
+	retrieved, err := client.Identity.Get(identity.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Secret).NotTo(BeNil())
+	g.Expect(retrieved.Secret.Name).To(Equal(identity.Secret.Name))
+	g.Expect(retrieved.Secret.Password).To(Equal(identity.Secret.Password))
+	g.Expect(retrieved.User).To(BeEmpty())
+	g.Expect(retrieved.Password).To(BeEmpty())
+
+	// GET: decrypted; the secret cannot be resolved.
+	_, err = client.Identity.Decrypted().Get(identity.ID)
+	g.Expect(errors.Is(err, &api.Conflict{})).To(BeTrue())
+
+	// UPDATE: values provided by the secret not permitted.
+	identity.Password = "password"
+	err = client.Identity.Update(identity)
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+
+	// CREATE: values provided by the secret not permitted.
+	invalid := &api.Identity{
+		Name:   "test-secret-invalid",
+		Kind:   "git",
+		User:   "test-user",
+		Secret: &api.IdentitySecret{Name: "test-missing-secret"},
+	}
+	err = client.Identity.Create(invalid)
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+}
+
+// TestIdentityVerify tests identity (credential) verification.
+func TestIdentityVerify(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	identity := &api.Identity{
+		Name:     "test-verify-identity",
+		Kind:     "maven",
+		Settings: "<settings><servers><server><id>central</id></server></servers></settings>",
+	}
+	err := client.Identity.Create(identity)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Identity.Delete(identity.ID)
+	})
+
+	// VERIFY: succeeded.
+	verification := &api.IdentityVerification{}
+	err = client.Identity.Verify(identity.ID, verification)
+	g.Expect(err).To(BeNil())
+	g.Expect(verification.Succeeded).To(BeTrue())
+	g.Expect(verification.Target).To(Equal("settings.xml"))
+	g.Expect(verification.Verified.IsZero()).To(BeFalse())
+
+	// GET: verification recorded.
+	retrieved, err := client.Identity.Get(identity.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Verification).NotTo(BeNil())
+	g.Expect(retrieved.Verification.Succeeded).To(BeTrue())
+
+	// UPDATE: verification cleared.
+	identity.Settings = "<settings><servers>"
+	err = client.Identity.Update(identity)
+	g.Expect(err).To(BeNil())
+	retrieved, err = client.Identity.Get(identity.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Verification).To(BeNil())
+
+	// VERIFY: failed.
+	verification = &api.IdentityVerification{}
+	err = client.Identity.Verify(identity.ID, verification)
+	g.Expect(err).To(BeNil())
+	g.Expect(verification.Succeeded).To(BeFalse())
+	g.Expect(verification.Error).ToNot(BeEmpty())
+	retrieved, err = client.Identity.Get(identity.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Verification).NotTo(BeNil())
+	g.Expect(retrieved.Verification.Succeeded).To(BeFalse())
+
+	// VERIFY: source identity not referenced by an application.
+	source := &api.Identity{
+		Name:     "test-verify-source",
+		Kind:     "source",
+		User:     "test-user",
+		Password: "test-password",
+	}
+	err = client.Identity.Create(source)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Identity.Delete(source.ID)
+	})
+	verification = &api.IdentityVerification{}
+	err = client.Identity.Verify(source.ID, verification)
+	g.Expect(err).To(BeNil())
+	g.Expect(verification.Succeeded).To(BeFalse())
+	g.Expect(verification.Error).ToNot(BeEmpty())
+
+	// VERIFY: application not referencing the identity.
+	application := &api.Application{
+		Name: "test-verify-application",
+	}
+	err = client.Application.Create(application)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(application.ID)
+	})
+	verification = &api.IdentityVerification{Application: application.ID}
+	err = client.Identity.Verify(source.ID, verification)
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+
+	// VERIFY: application not found.
+	verification = &api.IdentityVerification{Application: 999999}
+	err = client.Identity.Verify(source.ID, verification)
+	g.Expect(errors.Is(err, &api.NotFound{})).To(BeTrue())
+
+	// VERIFY: kind not supported.
+	proxy := &api.Identity{
+		Name:     "test-verify-proxy",
+		Kind:     "proxy",
+		User:     "test-user",
+		Password: "test-password",
+	}
+	err = client.Identity.Create(proxy)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Identity.Delete(proxy.ID)
+	})
+	verification = &api.IdentityVerification{}
+	err = client.Identity.Verify(proxy.ID, verification)
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+	retrieved, err = client.Identity.Get(proxy.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Verification).To(BeNil())
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/konveyor/tackle2-hub/shared/api"
+	_import "github.com/konveyor/tackle2-hub/shared/binding/import"
 	"github.com/konveyor/tackle2-hub/test/assert"
 	"github.com/konveyor/tackle2-hub/test/cmp"
 	. "github.com/onsi/gomega"
@@ -252,3 +253,479 @@ func TestImport(t *testing.T) {
 	_, err = client.Import.Summary().Get(uploaded.ID)
 	g.Expect(err).NotTo(BeNil())
 }
+
+func TestImportUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	testDir, err := os.MkdirTemp("", "test-import-*")
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = os.RemoveAll(testDir)
+	})
+
+	// Create the application to be updated.
+	application := &api.Application{
+		Name:        "TestImportUpdate",
+		Description: "Original",
+	}
+	err = client.Application.Create(application)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(application.ID)
+	})
+
+	// Write the CSV; empty columns are ignored.
+	header := []string{
+		"Record Type 1",
+		"Application Name",
+		"Description",
+	}
+	row := []string{
+		"1",
+		"TestImportUpdate",
+		"Updated",
+	}
+	for len(header) < 57 {
+		header = append(header, "")
+		row = append(row, "")
+	}
+	csvFile := filepath.Join(testDir, "test_import.csv")
+	csvContent := strings.Join(header, ",") + "\n"
+	csvContent += strings.Join(row, ",") + "\n"
+	err = os.WriteFile(csvFile, []byte(csvContent), 0644)
+	g.Expect(err).To(BeNil())
+
+	// CREATE: Upload in update mode.
+	uploaded, err := client.Import.UploadWith(
+		csvFile,
+		_import.Options{
+			Mode:    api.ImportUpdate,
+			MatchBy: api.ImportMatchName,
+		})
+	g.Expect(err).To(BeNil())
+	g.Expect(uploaded.Mode).To(Equal(api.ImportUpdate))
+	t.Cleanup(func() {
+		_ = client.Import.Summary().Delete(uploaded.ID)
+	})
+
+	// Wait for the import processing to complete
+	time.Sleep(2 * time.Second)
+
+	// Verify the application updated and the change recorded.
+	updated, err := client.Application.Get(application.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(updated.Description).To(Equal("Updated"))
+	summary, err := client.Import.Summary().Get(uploaded.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(summary.Changes)).To(Equal(1))
+	change := summary.Changes[0]
+	g.Expect(change.Field).To(Equal("description"))
+	g.Expect(change.Before).To(Equal("Original"))
+	g.Expect(change.After).To(Equal("Updated"))
+}
+
+func TestImportUpdateTagReplace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	testDir, err := os.MkdirTemp("", "test-import-*")
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = os.RemoveAll(testDir)
+	})
+
+	// Create the application with a (manually) assigned tag.
+	application := &api.Application{
+		Name: "TestImportUpdateTagReplace",
+		Tags: []api.TagRef{{ID: 1}},
+	}
+	err = client.Application.Create(application)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(application.ID)
+	})
+
+	// Write the CSV; the (empty) tags column is ignored.
+	header := []string{
+		"Record Type 1",
+		"Application Name",
+		"Description",
+	}
+	row := []string{
+		"1",
+		"TestImportUpdateTagReplace",
+		"Updated",
+	}
+	for len(header) < 57 {
+		header = append(header, "")
+		row = append(row, "")
+	}
+	csvFile := filepath.Join(testDir, "test_import.csv")
+	csvContent := strings.Join(header, ",") + "\n"
+	csvContent += strings.Join(row, ",") + "\n"
+	err = os.WriteFile(csvFile, []byte(csvContent), 0644)
+	g.Expect(err).To(BeNil())
+
+	// CREATE: Upload in update mode; replace tags.
+	uploaded, err := client.Import.UploadWith(
+		csvFile,
+		_import.Options{
+			Mode:    api.ImportUpdate,
+			MatchBy: api.ImportMatchName,
+			TagMode: api.ImportTagReplace,
+		})
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Import.Summary().Delete(uploaded.ID)
+	})
+
+	// Wait for the import processing to complete
+	time.Sleep(2 * time.Second)
+
+	// Verify the tag is retained.
+	updated, err := client.Application.Get(application.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(updated.Description).To(Equal("Updated"))
+	g.Expect(updated.Tags).To(HaveLen(1))
+	g.Expect(updated.Tags[0].ID).To(Equal(uint(1)))
+}
+
+func TestImportDryRun(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	testDir, err := os.MkdirTemp("", "test-import-*")
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = os.RemoveAll(testDir)
+	})
+
+	// Write the CSV.
+	header := []string{
+		"Record Type 1",
+		"Application Name",
+		"Description",
+	}
+	valid := []string{
+		"1",
+		"TestImportDryRun",
+		"Valid",
+	}
+	invalid := []string{
+		"2",
+		"TestImportDryRun",
+		"",
+		"",
+		"",
+		"TestImportDryRun-Missing",
+		"sideways",
+	}
+	rows := [][]string{header, valid, invalid}
+	csvContent := ""
+	for _, row := range rows {
+		for len(row) < 57 {
+			row = append(row, "")
+		}
+		csvContent += strings.Join(row, ",") + "\n"
+	}
+	csvFile := filepath.Join(testDir, "test_import.csv")
+	err = os.WriteFile(csvFile, []byte(csvContent), 0644)
+	g.Expect(err).To(BeNil())
+
+	// CREATE: Upload as a dry-run.
+	uploaded, err := client.Import.UploadWith(
+		csvFile,
+		_import.Options{
+			DryRun: true,
+		})
+	g.Expect(err).To(BeNil())
+	g.Expect(uploaded.DryRun).To(BeTrue())
+	t.Cleanup(func() {
+		_ = client.Import.Summary().Delete(uploaded.ID)
+	})
+
+	// Wait for the validation to complete
+	time.Sleep(2 * time.Second)
+
+	// Verify the report and nothing created.
+	summary, err := client.Import.Summary().Get(uploaded.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(summary.ValidCount).To(Equal(1))
+	g.Expect(summary.InvalidCount).To(Equal(1))
+	g.Expect(len(summary.Report)).To(Equal(2))
+	g.Expect(summary.Report[0].Row).To(Equal(2))
+	g.Expect(summary.Report[0].Valid).To(BeTrue())
+	g.Expect(summary.Report[0].Action).To(Equal("create"))
+	g.Expect(summary.Report[1].Row).To(Equal(3))
+	g.Expect(summary.Report[1].Valid).To(BeFalse())
+	g.Expect(len(summary.Report[1].Issues)).To(Equal(2))
+	apps, err := client.Application.List()
+	g.Expect(err).To(BeNil())
+	for _, app := range apps {
+		g.Expect(app.Name).NotTo(Equal("TestImportDryRun"))
+	}
+
+	// COMMIT: Apply the previewed rows.
+	committed, err := client.Import.Summary().Commit(uploaded.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(committed.DryRun).To(BeFalse())
+
+	// Wait for the import processing to complete
+	time.Sleep(2 * time.Second)
+
+	var created *api.Application
+	apps, err = client.Application.List()
+	g.Expect(err).To(BeNil())
+	for i := range apps {
+		if apps[i].Name == "TestImportDryRun" {
+			created = &apps[i]
+			break
+		}
+	}
+	g.Expect(created).NotTo(BeNil())
+	_ = client.Application.Delete(created.ID)
+
+	// Commit (again) rejected.
+	_, err = client.Import.Summary().Commit(uploaded.ID)
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestImportDryRunChanged(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	testDir, err := os.MkdirTemp("", "test-import-*")
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = os.RemoveAll(testDir)
+	})
+
+	// Write the CSV.
+	header := []string{
+		"Record Type 1",
+		"Application Name",
+		"Description",
+	}
+	valid := []string{
+		"1",
+		"TestImportDryRunChanged",
+		"Valid",
+	}
+	rows := [][]string{header, valid}
+	csvContent := ""
+	for _, row := range rows {
+		for len(row) < 57 {
+			row = append(row, "")
+		}
+		csvContent += strings.Join(row, ",") + "\n"
+	}
+	csvFile := filepath.Join(testDir, "test_import.csv")
+	err = os.WriteFile(csvFile, []byte(csvContent), 0644)
+	g.Expect(err).To(BeNil())
+
+	// CREATE: Upload as an (upsert) dry-run.
+	uploaded, err := client.Import.UploadWith(
+		csvFile,
+		_import.Options{
+			Mode:   api.ImportUpsert,
+			DryRun: true,
+		})
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Import.Summary().Delete(uploaded.ID)
+	})
+
+	// Wait for the validation to complete
+	time.Sleep(2 * time.Second)
+
+	summary, err := client.Import.Summary().Get(uploaded.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(summary.Report)).To(Equal(1))
+	g.Expect(summary.Report[0].Action).To(Equal("create"))
+
+	// Change the inventory.
+	app := &api.Application{Name: "TestImportDryRunChanged"}
+	err = client.Application.Create(app)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(app.ID)
+	})
+
+	// COMMIT: The planned (create) no longer applies.
+	_, err = client.Import.Summary().Commit(uploaded.ID)
+	g.Expect(err).To(BeNil())
+
+	// Wait for the import processing to complete
+	time.Sleep(2 * time.Second)
+
+	summary, err = client.Import.Summary().Get(uploaded.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(summary.ValidCount).To(Equal(0))
+	g.Expect(summary.InvalidCount).To(Equal(1))
+	imports, err := client.Import.List()
+	g.Expect(err).To(BeNil())
+	found := false
+	for _, imp := range imports {
+		if imp["applicationName"] == "TestImportDryRunChanged" {
+			found = true
+			g.Expect(imp["errorMessage"]).To(ContainSubstring("Inventory changed"))
+		}
+	}
+	g.Expect(found).To(BeTrue())
+	fetched, err := client.Application.Get(app.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(fetched.Description).To(BeEmpty())
+}
+
+func TestImportAtomic(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	testDir, err := os.MkdirTemp("", "test-import-*")
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = os.RemoveAll(testDir)
+	})
+
+	// Write the CSV.
+	header := []string{
+		"Record Type 1",
+		"Application Name",
+		"Description",
+	}
+	valid := []string{
+		"1",
+		"TestImportAtomic",
+		"Valid",
+	}
+	invalid := []string{
+		"3",
+		"TestImportAtomic-Invalid",
+		"Invalid",
+	}
+	rows := [][]string{header, valid, invalid}
+	csvContent := ""
+	for _, row := range rows {
+		for len(row) < 57 {
+			row = append(row, "")
+		}
+		csvContent += strings.Join(row, ",") + "\n"
+	}
+	csvFile := filepath.Join(testDir, "test_import.csv")
+	err = os.WriteFile(csvFile, []byte(csvContent), 0644)
+	g.Expect(err).To(BeNil())
+
+	// CREATE: Upload (all-or-nothing).
+	uploaded, err := client.Import.UploadWith(
+		csvFile,
+		_import.Options{
+			Atomic: true,
+		})
+	g.Expect(err).To(BeNil())
+	g.Expect(uploaded.Atomic).To(BeTrue())
+	t.Cleanup(func() {
+		_ = client.Import.Summary().Delete(uploaded.ID)
+	})
+
+	// Wait for the import processing to complete
+	time.Sleep(2 * time.Second)
+
+	// Verify every row rolled back.
+	summary, err := client.Import.Summary().Get(uploaded.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(summary.ValidCount).To(Equal(0))
+	g.Expect(summary.InvalidCount).To(Equal(2))
+	apps, err := client.Application.List()
+	g.Expect(err).To(BeNil())
+	for _, app := range apps {
+		g.Expect(app.Name).NotTo(Equal("TestImportAtomic"))
+	}
+}
+
+func TestImportProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	testDir, err := os.MkdirTemp("", "test-import-*")
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = os.RemoveAll(testDir)
+	})
+
+	// CREATE: Create the profile.
+	profile := &api.ImportProfile{
+		Name: "CMDB",
+		Columns: []api.ImportColumn{
+			{Name: "Service Name", Field: "applicationName"},
+			{Name: "Summary", Field: "description"},
+			{Name: "CI", Field: "fact:cmdb.ci"},
+		},
+	}
+	err = client.Import.Profile().Create(profile)
+	g.Expect(err).To(BeNil())
+	g.Expect(profile.ID).NotTo(BeZero())
+	t.Cleanup(func() {
+		_ = client.Import.Profile().Delete(profile.ID)
+	})
+
+	// GET: Retrieve the profile.
+	retrieved, err := client.Import.Profile().Get(profile.ID)
+	g.Expect(err).To(BeNil())
+	eq, report := cmp.Eq(profile, retrieved)
+	g.Expect(eq).To(BeTrue(), report)
+
+	// Invalid field rejected.
+	invalid := &api.ImportProfile{
+		Name: "Invalid",
+		Columns: []api.ImportColumn{
+			{Name: "Name", Field: "unknown"},
+		},
+	}
+	err = client.Import.Profile().Create(invalid)
+	g.Expect(err).NotTo(BeNil())
+
+	// GET: Download the template.
+	template := filepath.Join(testDir, "template.csv")
+	err = client.Import.Profile().Template(profile.ID, template)
+	g.Expect(err).To(BeNil())
+	content, err := os.ReadFile(template)
+	g.Expect(err).To(BeNil())
+	g.Expect(string(content)).To(Equal("Service Name,Summary,CI\n"))
+
+	// CREATE: Upload (json) using the profile.
+	document := filepath.Join(testDir, "cmdb.json")
+	err = os.WriteFile(
+		document,
+		[]byte(`[{"Service Name":"TestImportProfile","Summary":"From CMDB","CI":"CI0042"}]`),
+		0644)
+	g.Expect(err).To(BeNil())
+	uploaded, err := client.Import.UploadWith(
+		document,
+		_import.Options{
+			Profile: profile.ID,
+		})
+	g.Expect(err).To(BeNil())
+	g.Expect(uploaded.Format).To(Equal(api.ImportJSON))
+	g.Expect(uploaded.Profile).NotTo(BeNil())
+	t.Cleanup(func() {
+		_ = client.Import.Summary().Delete(uploaded.ID)
+	})
+
+	// Wait for the import processing to complete
+	time.Sleep(2 * time.Second)
+
+	var created *api.Application
+	apps, err := client.Application.List()
+	g.Expect(err).To(BeNil())
+	for i := range apps {
+		if apps[i].Name == "TestImportProfile" {
+			created = &apps[i]
+			break
+		}
+	}
+	g.Expect(created).NotTo(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(created.ID)
+	})
+	g.Expect(created.Description).To(Equal("From CMDB"))
+	facts := client.Application.Select(created.ID).Fact.Source("import")
+	var ci string
+	err = facts.Get("cmdb.ci", &ci)
+	g.Expect(err).To(BeNil())
+	g.Expect(ci).To(Equal("CI0042"))
+}
@@ -0,0 +1,105 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	auth2 "github.com/konveyor/tackle2-hub/internal/auth"
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"github.com/konveyor/tackle2-hub/shared/binding"
+	"github.com/konveyor/tackle2-hub/shared/binding/auth"
+	. "github.com/onsi/gomega"
+)
+
+func TestMfa(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	user := &api.User{
+		Login:    "test-mfa",
+		Password: "test-mfa-1",
+		Email:    "test-mfa@example.com",
+	}
+	err := client.User.Create(user)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.User.Delete(user.ID)
+	})
+
+	// Client authenticated as the user.
+	userClient := binding.New(Settings.Addon.Hub.URL)
+	userClient.Client.SetRetry(uint8(1))
+	userClient.Client.Transport().TLSClientConfig = client.Client.Transport().TLSClientConfig
+	userClient.Client.Use(auth.NewBasic("test-mfa", "not-the-password"))
+	_, err = userClient.Mfa.Enroll()
+	if err == nil {
+		t.Skip("Auth not required.")
+	}
+	userClient.Client.Reset()
+	userClient.Client.Use(auth.NewBasic("test-mfa", "test-mfa-1"))
+
+	// ENROLL
+	enrollment, err := userClient.Mfa.Enroll()
+	g.Expect(err).To(BeNil())
+	g.Expect(enrollment.Secret).NotTo(BeEmpty())
+	g.Expect(enrollment.URI).To(HavePrefix("otpauth://totp/"))
+	retrieved, err := client.User.Get(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Mfa).To(BeFalse())
+
+	// CONFIRM: invalid code.
+	_, err = userClient.Mfa.Confirm("000000x")
+	g.Expect(err).NotTo(BeNil())
+
+	// CONFIRM
+	code, err := auth2.TotpCode(enrollment.Secret, time.Now())
+	g.Expect(err).To(BeNil())
+	confirmed, err := userClient.Mfa.Confirm(code)
+	g.Expect(err).To(BeNil())
+	g.Expect(confirmed.RecoveryCodes).To(HaveLen(auth2.MfaRecoveryCodes))
+	retrieved, err = client.User.Get(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Mfa).To(BeTrue())
+
+	// AUTH: password only.
+	userClient.Client.Reset()
+	userClient.Client.Use(auth.NewBasic("test-mfa", "test-mfa-1"))
+	err = userClient.Mfa.Delete(confirmed.RecoveryCodes[0])
+	g.Expect(err).NotTo(BeNil())
+
+	// DELETE: code required.
+	code, err = auth2.TotpCode(enrollment.Secret, time.Now())
+	g.Expect(err).To(BeNil())
+	userClient.Client.Reset()
+	userClient.Client.Use(auth.NewBasic("test-mfa", "test-mfa-1"+code))
+	err = userClient.Mfa.Delete("000000x")
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+	retrieved, err = client.User.Get(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Mfa).To(BeTrue())
+
+	// AUTH: password and code; DELETE: recovery code.
+	err = userClient.Mfa.Delete(confirmed.RecoveryCodes[0])
+	g.Expect(err).To(BeNil())
+	retrieved, err = client.User.Get(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Mfa).To(BeFalse())
+
+	// RESET (admin): enroll again using the next code.
+	userClient.Client.Reset()
+	userClient.Client.Use(auth.NewBasic("test-mfa", "test-mfa-1"))
+	enrollment, err = userClient.Mfa.Enroll()
+	g.Expect(err).To(BeNil())
+	next := time.Now().Add(auth2.TotpPeriod * time.Second)
+	code, err = auth2.TotpCode(enrollment.Secret, next)
+	g.Expect(err).To(BeNil())
+	_, err = userClient.Mfa.Confirm(code)
+	g.Expect(err).To(BeNil())
+	err = client.User.MfaReset(user.ID)
+	g.Expect(err).To(BeNil())
+	retrieved, err = client.User.Get(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Mfa).To(BeFalse())
+	_, err = userClient.Mfa.Enroll()
+	g.Expect(err).To(BeNil())
+}
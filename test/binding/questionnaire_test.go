@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/konveyor/tackle2-hub/shared/api"
+	"github.com/konveyor/tackle2-hub/shared/binding"
 	"github.com/konveyor/tackle2-hub/test/cmp"
 	. "github.com/onsi/gomega"
 )
@@ -106,6 +107,322 @@ func TestQuestionnaire(t *testing.T) {
 	g.Expect(errors.Is(err, &api.NotFound{})).To(BeTrue())
 }
 
+// TestQuestionnaireVersion tests questionnaire versions and assessment upgrade.
+func TestQuestionnaireVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	questionnaire := &api.Questionnaire{
+		Name:     "Versioned Questionnaire",
+		Required: false,
+		Thresholds: api.Thresholds{
+			Red:     30,
+			Yellow:  20,
+			Unknown: 10,
+		},
+		RiskMessages: api.RiskMessages{
+			Red:     "Red",
+			Yellow:  "Yellow",
+			Green:   "Green",
+			Unknown: "Unknown",
+		},
+		Sections: []api.Section{
+			{
+				Order: 1,
+				Name:  "Section 1",
+				Questions: []api.Question{
+					{
+						Order: 1,
+						ID:    "color",
+						Text:  "What is your favorite color?",
+						Answers: []api.Answer{
+							{Order: 1, Text: "Red", Risk: "red"},
+							{Order: 2, Text: "Green", Risk: "green"},
+						},
+					},
+					{
+						Order: 2,
+						Text:  "What is your favorite shape?",
+						Answers: []api.Answer{
+							{Order: 1, Text: "Circle", Risk: "green"},
+						},
+					},
+				},
+			},
+		},
+	}
+	err := client.Questionnaire.Create(questionnaire)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Questionnaire.Delete(questionnaire.ID)
+	})
+	g.Expect(questionnaire.Version).To(Equal(uint(1)))
+
+	// Assess.
+	app := &api.Application{Name: "Versioned Questionnaire App"}
+	err = client.Application.Create(app)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(app.ID)
+	})
+	assessment := &api.Assessment{
+		Questionnaire: api.Ref{ID: questionnaire.ID},
+	}
+	err = client.Application.Select(app.ID).Assessment.Create(assessment)
+	g.Expect(err).To(BeNil())
+	g.Expect(assessment.QuestionnaireVersion).To(Equal(uint(1)))
+	assessment.Sections[0].Questions[0].Answers[1].Selected = true
+	assessment.Sections[0].Questions[1].Answers[0].Selected = true
+	err = client.Assessment.Update(assessment)
+	g.Expect(err).To(BeNil())
+
+	// Update the questionnaire.
+	questionnaire.Sections[0].Questions[0].Text = "What is your preferred color?"
+	questionnaire.Sections[0].Questions[1] = api.Question{
+		Order: 2,
+		Text:  "What is your favorite number?",
+		Answers: []api.Answer{
+			{Order: 1, Text: "Seven", Risk: "green"},
+		},
+	}
+	err = client.Questionnaire.Update(questionnaire)
+	g.Expect(err).To(BeNil())
+	versions, err := client.Questionnaire.Versions(questionnaire.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(versions)).To(Equal(2))
+	g.Expect(versions[0].Sections[0].Questions[0].Text).To(Equal("What is your favorite color?"))
+	version, err := client.Questionnaire.Version(questionnaire.ID, 2)
+	g.Expect(err).To(BeNil())
+	g.Expect(version.Sections[0].Questions[0].Text).To(Equal("What is your preferred color?"))
+
+	// Upgrade (dry run).
+	report, err := client.Assessment.Upgrade(assessment.ID, true)
+	g.Expect(err).To(BeNil())
+	g.Expect(report.From).To(Equal(uint(1)))
+	g.Expect(report.To).To(Equal(uint(2)))
+	g.Expect(len(report.Carried)).To(Equal(1))
+	g.Expect(report.Carried[0].ID).To(Equal("color"))
+	g.Expect(len(report.Dropped)).To(Equal(1))
+	g.Expect(len(report.Reanswer)).To(Equal(1))
+	retrieved, err := client.Assessment.Get(assessment.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.QuestionnaireVersion).To(Equal(uint(1)))
+
+	// Upgrade.
+	_, err = client.Assessment.Upgrade(assessment.ID, false)
+	g.Expect(err).To(BeNil())
+	retrieved, err = client.Assessment.Get(assessment.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.QuestionnaireVersion).To(Equal(uint(2)))
+	g.Expect(retrieved.Sections[0].Questions[0].Answers[1].Selected).To(BeTrue())
+	g.Expect(retrieved.Sections[0].Questions[1].Text).To(Equal("What is your favorite number?"))
+}
+
+func TestQuestionnaireScoring(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	score := func(n float64) *float64 {
+		return &n
+	}
+	questionnaire := &api.Questionnaire{
+		Name:     "Scored Questionnaire",
+		Required: true,
+		Thresholds: api.Thresholds{
+			Red:     30,
+			Yellow:  20,
+			Unknown: 10,
+		},
+		RiskMessages: api.RiskMessages{
+			Red:     "Red",
+			Yellow:  "Yellow",
+			Green:   "Green",
+			Unknown: "Unknown",
+		},
+		Scoring: api.Scoring{
+			Formula: `score >= 80 ? "green" : score >= 50 ? "yellow" : "red"`,
+		},
+		Sections: []api.Section{
+			{
+				Order:  1,
+				Name:   "Section 1",
+				Weight: 2,
+				Questions: []api.Question{
+					{
+						Order:  1,
+						Text:   "Is the application containerized?",
+						Weight: 3,
+						Answers: []api.Answer{
+							{Order: 1, Text: "Yes", Risk: "green"},
+							{Order: 2, Text: "Partially", Risk: "yellow", Score: score(60)},
+							{Order: 3, Text: "No", Risk: "red"},
+						},
+					},
+					{
+						Order: 2,
+						Text:  "Is the application stateless?",
+						Answers: []api.Answer{
+							{Order: 1, Text: "Yes", Risk: "green"},
+							{Order: 2, Text: "No", Risk: "red"},
+						},
+					},
+				},
+			},
+		},
+	}
+	err := client.Questionnaire.Create(questionnaire)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Questionnaire.Delete(questionnaire.ID)
+	})
+
+	// Assess.
+	assess := func(name string, answers ...int) (app *api.Application) {
+		app = &api.Application{Name: name}
+		err = client.Application.Create(app)
+		g.Expect(err).To(BeNil())
+		t.Cleanup(func() {
+			_ = client.Application.Delete(app.ID)
+		})
+		assessment := &api.Assessment{
+			Questionnaire: api.Ref{ID: questionnaire.ID},
+		}
+		err = client.Application.Select(app.ID).Assessment.Create(assessment)
+		g.Expect(err).To(BeNil())
+		g.Expect(assessment.Scoring).To(Equal(questionnaire.Scoring))
+		for i, answer := range answers {
+			assessment.Sections[0].Questions[i].Answers[answer].Selected = true
+		}
+		err = client.Assessment.Update(assessment)
+		g.Expect(err).To(BeNil())
+		return
+	}
+	appA := assess("Scored App A", 0, 0)
+	appB := assess("Scored App B", 1, 0)
+	appC := assess("Scored App C", 2, 1)
+
+	// (60*6 + 100*2) / 8 = 70.
+	retrieved, err := client.Application.Get(appB.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Score).To(Equal(70.0))
+	g.Expect(retrieved.Risk).To(Equal("yellow"))
+	retrieved, err = client.Application.Get(appA.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Score).To(Equal(100.0))
+	g.Expect(retrieved.Risk).To(Equal("green"))
+	retrieved, err = client.Application.Get(appC.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Score).To(Equal(0.0))
+	g.Expect(retrieved.Risk).To(Equal("red"))
+
+	// Filter and sort.
+	filter := binding.Filter{}
+	filter.And("score").Gt(50)
+	filter.And("name").Like("Scored App*")
+	list, err := client.Application.Find(filter, "desc:score")
+	g.Expect(err).To(BeNil())
+	g.Expect(len(list)).To(Equal(2))
+	g.Expect(list[0].ID).To(Equal(appA.ID))
+	g.Expect(list[1].ID).To(Equal(appB.ID))
+	filter = binding.Filter{}
+	filter.And("risk").Eq("red")
+	filter.And("name").Like("Scored App*")
+	list, err = client.Application.Find(filter)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(list)).To(Equal(1))
+	g.Expect(list[0].ID).To(Equal(appC.ID))
+}
+
+// TestQuestionnaireAutoAnswer tests answers auto-answered by conditions.
+func TestQuestionnaireAutoAnswer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	questionnaire := &api.Questionnaire{
+		Name:     "Auto-answered Questionnaire",
+		Required: true,
+		Thresholds: api.Thresholds{
+			Red:     30,
+			Yellow:  20,
+			Unknown: 10,
+		},
+		RiskMessages: api.RiskMessages{
+			Red:     "Red",
+			Yellow:  "Yellow",
+			Green:   "Green",
+			Unknown: "Unknown",
+		},
+		Sections: []api.Section{
+			{
+				Order: 1,
+				Name:  "Section 1",
+				Questions: []api.Question{
+					{
+						Order: 1,
+						Text:  "Does the application use JMS?",
+						Answers: []api.Answer{
+							{Order: 1, Text: "Yes", Risk: "yellow", AutoAnswerWhen: "rule:jms-*"},
+							{Order: 2, Text: "No", Risk: "green"},
+						},
+					},
+					{
+						Order: 2,
+						Text:  "Does the application have mandatory issues?",
+						Answers: []api.Answer{
+							{Order: 1, Text: "Yes", Risk: "red", AutoAnswerWhen: "insights:mandatory>=2"},
+							{Order: 2, Text: "No", Risk: "green", AutoAnswerWhen: "insights:mandatory<2"},
+						},
+					},
+				},
+			},
+		},
+	}
+	err := client.Questionnaire.Create(questionnaire)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Questionnaire.Delete(questionnaire.ID)
+	})
+
+	app := &api.Application{Name: "Auto-answered App"}
+	err = client.Application.Create(app)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(app.ID)
+	})
+	assessment := &api.Assessment{
+		Questionnaire: api.Ref{ID: questionnaire.ID},
+	}
+	err = client.Application.Select(app.ID).Assessment.Create(assessment)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Assessment.Delete(assessment.ID)
+	})
+	questions := assessment.Sections[0].Questions
+	g.Expect(questions[0].Answers[0].Selected).To(BeFalse())
+	g.Expect(questions[1].Answers[1].Selected).To(BeTrue())
+	g.Expect(questions[1].Answers[1].AutoAnswered).To(BeTrue())
+
+	// Analysis.
+	analysis := &api.Analysis{
+		Application: api.Ref{ID: app.ID},
+		Insights: []api.Insight{
+			{RuleSet: "eap7", Rule: "jms-01", Name: "JMS", Category: "mandatory"},
+			{RuleSet: "eap7", Rule: "jndi-01", Name: "JNDI", Category: "mandatory"},
+		},
+	}
+	err = client.Application.Select(app.ID).Analysis.Create(analysis)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Analysis.Delete(analysis.ID)
+	})
+	retrieved, err := client.Assessment.Get(assessment.ID)
+	g.Expect(err).To(BeNil())
+	questions = retrieved.Sections[0].Questions
+	g.Expect(questions[0].Answers[0].Selected).To(BeTrue())
+	g.Expect(questions[0].Answers[0].AutoAnswered).To(BeTrue())
+	g.Expect(questions[0].Answers[0].AutoAnswerReason).To(Equal("Matched: rule:jms-*"))
+	g.Expect(questions[1].Answers[0].Selected).To(BeTrue())
+	g.Expect(questions[1].Answers[0].AutoAnswerReason).To(Equal("Matched: insights:mandatory>=2"))
+	g.Expect(questions[1].Answers[1].Selected).To(BeFalse())
+}
+
 // TestQuestionnaireValidation tests all validation rules in api.Questionnaire.Validate()
 func TestQuestionnaireValidation(t *testing.T) {
 	g := NewGomegaWithT(t)
@@ -496,6 +813,143 @@ func TestQuestionnaireValidation(t *testing.T) {
 			wantError:     true,
 			errorContains: "all risk messages",
 		},
+		{
+			name: "Condition references missing answer",
+			questionnaire: func() api.Questionnaire {
+				q := validQuestionnaire
+				q.Sections = []api.Section{
+					{
+						Order: 1,
+						Name:  "Section 1",
+						Questions: []api.Question{
+							{
+								Order: 1,
+								Text:  "Question 1",
+								ShowWhen: []api.AnswerRef{
+									{Section: 1, Question: 2, Answer: 9},
+								},
+								Answers: []api.Answer{
+									{Order: 1, Text: "Answer 1", Risk: "green"},
+								},
+							},
+							{
+								Order: 2,
+								Text:  "Question 2",
+								Answers: []api.Answer{
+									{Order: 1, Text: "Answer 1", Risk: "green"},
+								},
+							},
+						},
+					},
+				}
+				return q
+			}(),
+			wantError:     true,
+			errorContains: "does not exist",
+		},
+		{
+			name: "Circular conditions",
+			questionnaire: func() api.Questionnaire {
+				q := validQuestionnaire
+				q.Sections = []api.Section{
+					{
+						Order: 1,
+						Name:  "Section 1",
+						Questions: []api.Question{
+							{
+								Order: 1,
+								Text:  "Question 1",
+								ShowWhen: []api.AnswerRef{
+									{Section: 1, Question: 2, Answer: 1},
+								},
+								Answers: []api.Answer{
+									{Order: 1, Text: "Answer 1", Risk: "green"},
+								},
+							},
+							{
+								Order: 2,
+								Text:  "Question 2",
+								ShowWhen: []api.AnswerRef{
+									{Section: 1, Question: 1, Answer: 1},
+								},
+								Answers: []api.Answer{
+									{Order: 1, Text: "Answer 1", Risk: "green"},
+								},
+							},
+						},
+					},
+				}
+				return q
+			}(),
+			wantError:     true,
+			errorContains: "circular conditions",
+		},
+		{
+			name: "Negative question weight",
+			questionnaire: func() api.Questionnaire {
+				q := validQuestionnaire
+				q.Sections = []api.Section{
+					{
+						Order: 1,
+						Name:  "Section 1",
+						Questions: []api.Question{
+							{
+								Order:  1,
+								Text:   "Question 1",
+								Weight: -1,
+								Answers: []api.Answer{
+									{Order: 1, Text: "Answer 1", Risk: "green"},
+								},
+							},
+						},
+					},
+				}
+				return q
+			}(),
+			wantError:     true,
+			errorContains: "negative weight",
+		},
+		{
+			name: "Scoring formula not valid",
+			questionnaire: func() api.Questionnaire {
+				q := validQuestionnaire
+				q.Scoring = api.Scoring{
+					Formula: `score > 50 ? "blue" : "red"`,
+				}
+				return q
+			}(),
+			wantError:     true,
+			errorContains: "scoring formula",
+		},
+		{
+			name: "Auto-answer condition not valid",
+			questionnaire: func() api.Questionnaire {
+				q := validQuestionnaire
+				q.Sections = []api.Section{
+					{
+						Order: 1,
+						Name:  "Section 1",
+						Questions: []api.Question{
+							{
+								Order: 1,
+								Text:  "Question 1",
+								Answers: []api.Answer{
+									{
+										Order:          1,
+										Text:           "Answer 1",
+										Risk:           "green",
+										AutoAnswerWhen: "unknown:thing",
+									},
+								},
+							},
+						},
+					},
+				}
+				return q
+			}(),
+			wantError:     true,
+			errorContains: "auto-answer condition",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2,9 +2,13 @@ package binding
 
 import (
 	"errors"
+	"net/http"
+	"strconv"
 	"testing"
 
 	"github.com/konveyor/tackle2-hub/shared/api"
+	"github.com/konveyor/tackle2-hub/shared/binding"
+	"github.com/konveyor/tackle2-hub/shared/binding/auth"
 	"github.com/konveyor/tackle2-hub/test/cmp"
 	. "github.com/onsi/gomega"
 )
@@ -78,3 +82,123 @@ func TestRole(t *testing.T) {
 	_, err = client.Role.Get(role.ID)
 	g.Expect(errors.Is(err, &api.NotFound{})).To(BeTrue())
 }
+
+// TestRoleConstrained tests roles with constrained (row-level) scopes.
+func TestRoleConstrained(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// Business services and applications.
+	bs1 := &api.BusinessService{Name: "test-constrained-bs1"}
+	err := client.BusinessService.Create(bs1)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.BusinessService.Delete(bs1.ID)
+	})
+	bs2 := &api.BusinessService{Name: "test-constrained-bs2"}
+	err = client.BusinessService.Create(bs2)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.BusinessService.Delete(bs2.ID)
+	})
+	app1 := &api.Application{
+		Name:            "test-constrained-app1",
+		BusinessService: &api.Ref{ID: bs1.ID},
+	}
+	err = client.Application.Create(app1)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(app1.ID)
+	})
+	app2 := &api.Application{
+		Name:            "test-constrained-app2",
+		BusinessService: &api.Ref{ID: bs2.ID},
+	}
+	err = client.Application.Create(app2)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Application.Delete(app2.ID)
+	})
+
+	// Constraints not valid.
+	for _, scope := range []string{
+		"applications:get:businessService=abc",
+		"applications:get:owner=bob",
+		"tags:get:businessService=1",
+	} {
+		invalid := &api.Role{
+			Name:   "test-constrained-invalid",
+			Scopes: []string{scope},
+		}
+		err = client.Role.Create(invalid)
+		g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest), scope)
+	}
+
+	// CREATE: role constrained to the business service.
+	constraint := "businessService=" + strconv.Itoa(int(bs1.ID))
+	role := &api.Role{
+		Name: "test-constrained",
+		Scopes: []string{
+			"applications:get:" + constraint,
+			"applications:put:" + constraint,
+		},
+	}
+	err = client.Role.Create(role)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Role.Delete(role.ID)
+	})
+	retrieved, err := client.Role.Get(role.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.Scopes).To(ConsistOf(role.Scopes))
+	user := &api.User{
+		Login:    "test-constrained",
+		Password: "test-constrained-1",
+		Email:    "test-constrained@example.com",
+		Roles:    []api.Ref{{ID: role.ID}},
+	}
+	err = client.User.Create(user)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.User.Delete(user.ID)
+	})
+
+	// Client authenticated as the user.
+	userClient := binding.New(Settings.Addon.Hub.URL)
+	userClient.Client.SetRetry(uint8(1))
+	userClient.Client.Transport().TLSClientConfig = client.Client.Transport().TLSClientConfig
+	userClient.Client.Use(auth.NewBasic("test-constrained", "not-the-password"))
+	_, err = userClient.Application.List()
+	if err == nil {
+		t.Skip("Auth not required.")
+	}
+	userClient.Client.Reset()
+	userClient.Client.Use(auth.NewBasic("test-constrained", "test-constrained-1"))
+
+	// LIST: restricted.
+	list, err := userClient.Application.List()
+	g.Expect(err).To(BeNil())
+	g.Expect(len(list)).To(Equal(1))
+	g.Expect(list[0].ID).To(Equal(app1.ID))
+
+	// GET: permitted.
+	_, err = userClient.Application.Get(app1.ID)
+	g.Expect(err).To(BeNil())
+
+	// GET: not permitted.
+	_, err = userClient.Application.Get(app2.ID)
+	g.Expect(restStatus(err)).To(Equal(http.StatusForbidden))
+
+	// UPDATE: permitted.
+	app1.Description = "updated"
+	err = userClient.Application.Update(app1)
+	g.Expect(err).To(BeNil())
+
+	// UPDATE: moved to another business service.
+	app1.BusinessService = &api.Ref{ID: bs2.ID}
+	err = userClient.Application.Update(app1)
+	g.Expect(restStatus(err)).To(Equal(http.StatusForbidden))
+
+	// UPDATE: not permitted.
+	err = userClient.Application.Update(app2)
+	g.Expect(restStatus(err)).To(Equal(http.StatusForbidden))
+}
@@ -0,0 +1,178 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"github.com/konveyor/tackle2-hub/shared/binding"
+	"github.com/konveyor/tackle2-hub/shared/binding/auth"
+	. "github.com/onsi/gomega"
+)
+
+func TestScimUser(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// CREATE
+	user := &api.ScimUser{
+		Schemas:     []string{api.ScimSchemaUser},
+		ExternalID:  "ext-elmer",
+		UserName:    "test-scim-elmer",
+		DisplayName: "Elmer Fudd",
+		Password:    "test-scim-1",
+		Emails: []api.ScimValue{
+			{Value: "elmer@example.com", Primary: true},
+		},
+	}
+	err := client.Scim.UserCreate(user)
+	g.Expect(err).To(BeNil())
+	g.Expect(user.ID).NotTo(BeEmpty())
+	t.Cleanup(func() {
+		_ = client.Scim.UserDelete(user.ID)
+	})
+	g.Expect(*user.Active).To(BeTrue())
+
+	// GET
+	retrieved, err := client.Scim.UserGet(user.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(retrieved.UserName).To(Equal(user.UserName))
+	g.Expect(retrieved.ExternalID).To(Equal(user.ExternalID))
+	g.Expect(retrieved.Emails[0].Value).To(Equal("elmer@example.com"))
+
+	// FIND
+	list, err := client.Scim.UserFind(`userName eq "test-scim-elmer"`)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(list)).To(Equal(1))
+	g.Expect(list[0].ID).To(Equal(user.ID))
+	_, err = client.Scim.UserFind(`userName sw "test"`)
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+
+	// hub user.
+	id, _ := strconv.Atoi(user.ID)
+	hubUser, err := client.User.Get(uint(id))
+	g.Expect(err).To(BeNil())
+	g.Expect(hubUser.Login).To(Equal(user.UserName))
+	g.Expect(hubUser.Name).To(Equal(user.DisplayName))
+	g.Expect(hubUser.Disabled).To(BeFalse())
+
+	// Client authenticated as the user (no roles).
+	userClient := binding.New(Settings.Addon.Hub.URL)
+	userClient.Client.SetRetry(uint8(1))
+	userClient.Client.Transport().TLSClientConfig = client.Client.Transport().TLSClientConfig
+	userClient.Client.Use(auth.NewBasic("test-scim-elmer", "test-scim-1"))
+	_, err = userClient.Application.List()
+	authRequired := err != nil
+	if authRequired {
+		g.Expect(restStatus(err)).To(Equal(http.StatusForbidden))
+	}
+
+	// PATCH (deactivate)
+	patched, err := client.Scim.UserPatch(
+		user.ID,
+		&api.ScimPatch{
+			Schemas: []string{api.ScimSchemaPatch},
+			Operations: []api.ScimOperation{
+				{
+					Op:    api.ScimOpReplace,
+					Path:  "active",
+					Value: false,
+				},
+			},
+		})
+	g.Expect(err).To(BeNil())
+	g.Expect(*patched.Active).To(BeFalse())
+	hubUser, err = client.User.Get(uint(id))
+	g.Expect(err).To(BeNil())
+	g.Expect(hubUser.Disabled).To(BeTrue())
+	if authRequired {
+		_, err = userClient.Application.List()
+		g.Expect(restStatus(err)).To(Equal(http.StatusUnauthorized))
+	}
+
+	// UPDATE (reactivate)
+	active := true
+	user.Active = &active
+	user.DisplayName = "Elmer J. Fudd"
+	err = client.Scim.UserUpdate(user)
+	g.Expect(err).To(BeNil())
+	hubUser, err = client.User.Get(uint(id))
+	g.Expect(err).To(BeNil())
+	g.Expect(hubUser.Disabled).To(BeFalse())
+	g.Expect(hubUser.Name).To(Equal(user.DisplayName))
+
+	// DELETE
+	err = client.Scim.UserDelete(user.ID)
+	g.Expect(err).To(BeNil())
+	_, err = client.Scim.UserGet(user.ID)
+	g.Expect(errors.Is(err, &api.NotFound{})).To(BeTrue())
+}
+
+func TestScimGroup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	user := &api.ScimUser{
+		Schemas:  []string{api.ScimSchemaUser},
+		UserName: "test-scim-bugs",
+	}
+	err := client.Scim.UserCreate(user)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Scim.UserDelete(user.ID)
+	})
+
+	// CREATE
+	group := &api.ScimGroup{
+		Schemas:     []string{api.ScimSchemaGroup},
+		DisplayName: "test-scim-group",
+		Members: []api.ScimMember{
+			{Value: user.ID},
+		},
+	}
+	err = client.Scim.GroupCreate(group)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Scim.GroupDelete(group.ID)
+	})
+	g.Expect(len(group.Members)).To(Equal(1))
+
+	// hub role assigned.
+	id, _ := strconv.Atoi(user.ID)
+	hubUser, err := client.User.Get(uint(id))
+	g.Expect(err).To(BeNil())
+	g.Expect(len(hubUser.Roles)).To(Equal(1))
+	g.Expect(hubUser.Roles[0].Name).To(Equal(group.DisplayName))
+
+	// FIND
+	list, err := client.Scim.GroupFind(`displayName eq "test-scim-group"`)
+	g.Expect(err).To(BeNil())
+	g.Expect(len(list)).To(Equal(1))
+
+	// PATCH (remove member)
+	patched, err := client.Scim.GroupPatch(
+		group.ID,
+		&api.ScimPatch{
+			Schemas: []string{api.ScimSchemaPatch},
+			Operations: []api.ScimOperation{
+				{
+					Op:   api.ScimOpRemove,
+					Path: `members[value eq "` + user.ID + `"]`,
+				},
+			},
+		})
+	g.Expect(err).To(BeNil())
+	g.Expect(patched.Members).To(BeEmpty())
+	hubUser, err = client.User.Get(uint(id))
+	g.Expect(err).To(BeNil())
+	g.Expect(hubUser.Roles).To(BeEmpty())
+
+	// member not found.
+	group.Members = []api.ScimMember{{Value: "999999"}}
+	err = client.Scim.GroupUpdate(group)
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+
+	// reserved roles cannot be deleted.
+	err = client.Scim.GroupDelete("1")
+	g.Expect(restStatus(err)).To(Equal(http.StatusBadRequest))
+}
@@ -0,0 +1,58 @@
+package binding
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/konveyor/tackle2-hub/shared/api"
+	. "github.com/onsi/gomega"
+)
+
+func TestSecretRotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// Create an identity with encrypted fields.
+	identity := &api.Identity{
+		Name:     "Rotated",
+		Kind:     "git",
+		User:     "user",
+		Password: "password",
+	}
+	err := client.Identity.Create(identity)
+	g.Expect(err).To(BeNil())
+	t.Cleanup(func() {
+		_ = client.Identity.Delete(identity.ID)
+	})
+
+	// LIST: the current key is referenced.
+	keys, err := client.Secret.Keys()
+	g.Expect(err).To(BeNil())
+	var current *api.SecretKey
+	for i := range keys {
+		if keys[i].Current {
+			current = &keys[i]
+		}
+	}
+	g.Expect(current).ToNot(BeNil())
+	g.Expect(current.References > 0).To(BeTrue())
+
+	// ROTATE: wait for completion.
+	rotation, err := client.Secret.Rotate()
+	g.Expect(err).To(BeNil())
+	g.Expect(rotation.KeyId).To(Equal(current.ID))
+	g.Eventually(func() string {
+		rotation, err = client.Secret.Rotation()
+		g.Expect(err).To(BeNil())
+		return rotation.State
+	}, 30*time.Second, 100*time.Millisecond).Should(Equal("Succeeded"))
+
+	// Decrypted after rotation.
+	decrypted, err := client.Identity.Decrypted().Get(identity.ID)
+	g.Expect(err).To(BeNil())
+	g.Expect(decrypted.Password).To(Equal("password"))
+
+	// RETIRE: the current key cannot be retired.
+	err = client.Secret.Retire(current.ID)
+	g.Expect(errors.Is(err, &api.Conflict{})).To(BeTrue())
+}
@@ -0,0 +1,44 @@
+package binding
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konveyor/tackle2-hub/shared/api"
+	"github.com/konveyor/tackle2-hub/shared/binding"
+	. "github.com/onsi/gomega"
+)
+
+func TestService(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// LIST
+	list, err := client.Service.List()
+	g.Expect(err).To(BeNil())
+	g.Expect(list).NotTo(BeEmpty())
+	for _, r := range list {
+		g.Expect(r.Name).NotTo(BeEmpty())
+		g.Expect(r.Status).To(BeElementOf(
+			api.ServiceReady,
+			api.ServiceUnavailable,
+			api.ServiceNotConfigured))
+		if r.Route == "" {
+			g.Expect(r.Status).To(Equal(api.ServiceNotConfigured))
+		}
+	}
+
+	// FORWARD (not found).
+	path := binding.Path(api.ServiceRoute).Inject(binding.Params{api.Name: "not-a-service"})
+	err = client.Client.Get(path, &map[string]any{})
+	g.Expect(errors.Is(err, &api.NotFound{})).To(BeTrue())
+
+	// FORWARD (not configured).
+	for _, r := range list {
+		if r.Status != api.ServiceNotConfigured {
+			continue
+		}
+		path = binding.Path(api.ServiceRoute).Inject(binding.Params{api.Name: r.Name})
+		err = client.Client.Get(path, &map[string]any{})
+		g.Expect(err).NotTo(BeNil())
+	}
+}